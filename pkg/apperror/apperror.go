@@ -1,9 +1,171 @@
 package apperror
 
-import "errors"
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
 
 var (
-	ErrZipCodeRequired = errors.New("zipcode is required")
-	ErrZipCodeInvalid  = errors.New("invalid zipcode")
-	ErrZipCodeNotFound = errors.New("can not find zipcode")
+	ErrZipCodeRequired      = errors.New("zipcode is required")
+	ErrZipCodeInvalid       = errors.New("invalid zipcode")
+	ErrZipCodeNotFound      = errors.New("can not find zipcode")
+	ErrForecastDaysInvalid  = errors.New("invalid forecast days")
+	ErrUnitsInvalid         = errors.New("invalid units")
+	ErrDateInvalid          = errors.New("invalid date")
+	ErrDateOutOfRange       = errors.New("date out of range")
+	ErrInvalidRequest       = errors.New("invalid request")
+	ErrInternal             = errors.New("internal server error")
+	ErrUnauthorized         = errors.New("unauthorized")
+	ErrForbidden            = errors.New("insufficient scope")
+	ErrRateLimited          = errors.New("too many requests")
+	ErrWeatherUnavailable   = errors.New("weather data temporarily unavailable")
+	ErrRequestTooLarge      = errors.New("request body too large")
+	ErrUnsupportedMediaType = errors.New("unsupported media type")
+)
+
+// Codes are stable identifiers for the errors above. They are sent to clients
+// next to the (possibly localized) message.
+const (
+	CodeZipCodeRequired      = "zipcode_required"
+	CodeZipCodeInvalid       = "invalid_zipcode"
+	CodeZipCodeNotFound      = "zipcode_not_found"
+	CodeForecastDaysInvalid  = "invalid_forecast_days"
+	CodeUnitsInvalid         = "invalid_units"
+	CodeDateInvalid          = "invalid_date"
+	CodeDateOutOfRange       = "date_out_of_range"
+	CodeInvalidRequest       = "invalid_request"
+	CodeInternal             = "internal_error"
+	CodeUnauthorized         = "unauthorized"
+	CodeForbidden            = "insufficient_scope"
+	CodeRateLimited          = "rate_limited"
+	CodeWeatherUnavailable   = "weather_unavailable"
+	CodeRequestTooLarge      = "request_too_large"
+	CodeUnsupportedMediaType = "unsupported_media_type"
 )
+
+var codes = map[error]string{
+	ErrZipCodeRequired:      CodeZipCodeRequired,
+	ErrZipCodeInvalid:       CodeZipCodeInvalid,
+	ErrZipCodeNotFound:      CodeZipCodeNotFound,
+	ErrForecastDaysInvalid:  CodeForecastDaysInvalid,
+	ErrUnitsInvalid:         CodeUnitsInvalid,
+	ErrDateInvalid:          CodeDateInvalid,
+	ErrDateOutOfRange:       CodeDateOutOfRange,
+	ErrInvalidRequest:       CodeInvalidRequest,
+	ErrInternal:             CodeInternal,
+	ErrUnauthorized:         CodeUnauthorized,
+	ErrForbidden:            CodeForbidden,
+	ErrRateLimited:          CodeRateLimited,
+	ErrWeatherUnavailable:   CodeWeatherUnavailable,
+	ErrRequestTooLarge:      CodeRequestTooLarge,
+	ErrUnsupportedMediaType: CodeUnsupportedMediaType,
+}
+
+// Response is the JSON body of an error response.
+type Response struct {
+	Code    string       `json:"code,omitempty"`
+	Message string       `json:"message"`
+	Fields  []FieldError `json:"fields,omitempty"`
+}
+
+// FieldError describes why one field of a request was rejected. Field is a
+// JSON pointer into the body, such as /cep, or the name of a query parameter.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError is a known error, such as ErrInvalidRequest or
+// ErrZipCodeInvalid, together with the request fields that caused it.
+type ValidationError struct {
+	Err    error
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		messages = append(messages, field.Field+": "+field.Message)
+	}
+	return e.Err.Error() + ": " + strings.Join(messages, "; ")
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// Fields returns the field errors carried by err, if any.
+func Fields(err error) []FieldError {
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		return validationErr.Fields
+	}
+	return nil
+}
+
+// Replace returns known in place of err, keeping the field errors err
+// carries.
+func Replace(err, known error) error {
+	if fields := Fields(err); len(fields) > 0 {
+		return &ValidationError{Err: known, Fields: fields}
+	}
+	return known
+}
+
+// Code returns the code of a known error, or CodeInternal for anything else.
+func Code(err error) string {
+	for known, code := range codes {
+		if errors.Is(err, known) {
+			return code
+		}
+	}
+	return CodeInternal
+}
+
+// HTTPStatus returns the status an HTTP response for err is sent with, or 500
+// for an error that is not known.
+func HTTPStatus(err error) int {
+	switch Code(err) {
+	case CodeZipCodeRequired, CodeZipCodeInvalid, CodeForecastDaysInvalid,
+		CodeUnitsInvalid, CodeDateInvalid, CodeDateOutOfRange:
+		return http.StatusUnprocessableEntity
+	case CodeZipCodeNotFound:
+		return http.StatusNotFound
+	case CodeInvalidRequest:
+		return http.StatusBadRequest
+	case CodeUnauthorized:
+		return http.StatusUnauthorized
+	case CodeForbidden:
+		return http.StatusForbidden
+	case CodeRateLimited:
+		return http.StatusTooManyRequests
+	case CodeWeatherUnavailable:
+		return http.StatusServiceUnavailable
+	case CodeRequestTooLarge:
+		return http.StatusRequestEntityTooLarge
+	case CodeUnsupportedMediaType:
+		return http.StatusUnsupportedMediaType
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// FromCode returns the error registered for code, or nil when it is unknown.
+func FromCode(code string) error {
+	for known, knownCode := range codes {
+		if knownCode == code {
+			return known
+		}
+	}
+	return nil
+}
+
+// Codes lists every registered error code.
+func Codes() []string {
+	list := make([]string, 0, len(codes))
+	for _, code := range codes {
+		list = append(list, code)
+	}
+	return list
+}
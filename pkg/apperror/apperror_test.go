@@ -0,0 +1,49 @@
+package apperror
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestHTTPStatus(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		expectedStatus int
+	}{
+		{name: "invalid zipcode", err: ErrZipCodeInvalid, expectedStatus: http.StatusUnprocessableEntity},
+		{name: "validation error", err: &ValidationError{Err: ErrDateInvalid}, expectedStatus: http.StatusUnprocessableEntity},
+		{name: "wrapped not found", err: fmt.Errorf("lookup: %w", ErrZipCodeNotFound), expectedStatus: http.StatusNotFound},
+		{name: "invalid request", err: ErrInvalidRequest, expectedStatus: http.StatusBadRequest},
+		{name: "rate limited", err: ErrRateLimited, expectedStatus: http.StatusTooManyRequests},
+		{name: "weather unavailable", err: ErrWeatherUnavailable, expectedStatus: http.StatusServiceUnavailable},
+		{name: "too large", err: ErrRequestTooLarge, expectedStatus: http.StatusRequestEntityTooLarge},
+		{name: "unknown", err: errors.New("boom"), expectedStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if status := HTTPStatus(tt.err); status != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, status)
+			}
+		})
+	}
+}
+
+func TestReplace(t *testing.T) {
+	fields := []FieldError{{Field: "/cep", Message: "is required"}}
+
+	err := Replace(&ValidationError{Err: ErrZipCodeRequired, Fields: fields}, ErrZipCodeInvalid)
+	if Code(err) != CodeZipCodeInvalid {
+		t.Errorf("Expected code %q, got %q", CodeZipCodeInvalid, Code(err))
+	}
+	if got := Fields(err); len(got) != 1 || got[0] != fields[0] {
+		t.Errorf("Expected fields %v, got %v", fields, got)
+	}
+
+	if err := Replace(ErrZipCodeRequired, ErrZipCodeInvalid); err != ErrZipCodeInvalid {
+		t.Errorf("Expected %v, got %v", ErrZipCodeInvalid, err)
+	}
+}
@@ -0,0 +1,64 @@
+// Package cache implements key-value caching with a pluggable backend, so
+// replicas can share what they cache through Redis and keep serving from
+// process memory while Redis is unreachable.
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Backend stores values that expire after their TTL; a TTL of zero never
+// expires. Get reports a missing or expired key with ok false and no error.
+type Backend interface {
+	Get(ctx context.Context, key string) (value []byte, ok bool, err error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, key string) error
+}
+
+// Store keeps values of type T in a backend as JSON, under keys prefixed
+// with a namespace and a version, such as service-b:location:v1:01001000.
+// Bumping the version when T changes leaves values in the old format behind
+// instead of failing to decode them.
+type Store[T any] struct {
+	backend Backend
+	prefix  string
+}
+
+func NewStore[T any](backend Backend, namespace string, version int) *Store[T] {
+	return &Store[T]{backend: backend, prefix: fmt.Sprintf("%s:v%d:", namespace, version)}
+}
+
+// Key returns the backend key of key.
+func (s *Store[T]) Key(key string) string {
+	return s.prefix + key
+}
+
+// Get returns the value stored under key. A value that cannot be decoded is
+// returned as an error.
+func (s *Store[T]) Get(ctx context.Context, key string) (*T, bool, error) {
+	data, ok, err := s.backend.Get(ctx, s.Key(key))
+	if err != nil || !ok {
+		return nil, false, err
+	}
+
+	var value T
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, false, fmt.Errorf("decoding %s: %w", s.Key(key), err)
+	}
+	return &value, true, nil
+}
+
+func (s *Store[T]) Set(ctx context.Context, key string, value *T, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("encoding %s: %w", s.Key(key), err)
+	}
+	return s.backend.Set(ctx, s.Key(key), data, ttl)
+}
+
+func (s *Store[T]) Delete(ctx context.Context, key string) error {
+	return s.backend.Delete(ctx, s.Key(key))
+}
@@ -0,0 +1,209 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-a-b-microservices/pkg/zipcode"
+)
+
+type MockLogger struct {
+	errors int
+}
+
+func (m *MockLogger) Info(message string, args ...interface{})  {}
+func (m *MockLogger) Error(message string, args ...interface{}) { m.errors++ }
+func (m *MockLogger) Debug(message string, args ...interface{}) {}
+
+func TestMemoryBackend(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 5, 10, 12, 0, 0, 0, time.UTC)
+	backend := NewMemoryBackend(2)
+	backend.now = func() time.Time { return now }
+
+	backend.Set(ctx, "a", []byte("1"), time.Minute)
+	backend.Set(ctx, "b", []byte("2"), 0)
+	backend.Get(ctx, "a")
+	backend.Set(ctx, "c", []byte("3"), 0)
+
+	if _, ok, _ := backend.Get(ctx, "b"); ok {
+		t.Errorf("Expected the least recently used key to be evicted")
+	}
+	if value, ok, _ := backend.Get(ctx, "a"); !ok || string(value) != "1" {
+		t.Errorf("Expected a to be kept, got %q, %v", value, ok)
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok, _ := backend.Get(ctx, "a"); ok {
+		t.Errorf("Expected a to expire after its TTL")
+	}
+	if _, ok, _ := backend.Get(ctx, "c"); !ok {
+		t.Errorf("Expected a key without TTL to never expire")
+	}
+
+	backend.Delete(ctx, "c")
+	if _, ok, _ := backend.Get(ctx, "c"); ok {
+		t.Errorf("Expected c to be deleted")
+	}
+}
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	backend := NewMemoryBackend(0)
+	locations := NewStore[zipcode.Location](backend, "service-b:location", 1)
+
+	if err := locations.Set(ctx, "01001000", &zipcode.Location{City: "São Paulo", CEP: "01001-000"}, 0); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if _, ok, _ := backend.Get(ctx, "service-b:location:v1:01001000"); !ok {
+		t.Errorf("Expected the key to be namespaced and versioned")
+	}
+
+	location, ok, err := locations.Get(ctx, "01001000")
+	if err != nil || !ok || location.City != "São Paulo" || location.CEP != "01001-000" {
+		t.Errorf("Expected the location back, got %+v, %v, %v", location, ok, err)
+	}
+
+	if _, ok, _ := NewStore[zipcode.Location](backend, "service-b:location", 2).Get(ctx, "01001000"); ok {
+		t.Errorf("Expected a new version not to read the old values")
+	}
+
+	tempC := 21.5
+	weather := NewStore[zipcode.WeatherData](backend, "service-b:weather", 1)
+	weather.Set(ctx, "pt-BR:limeira", &zipcode.WeatherData{
+		Current: zipcode.CurrentWeather{TempC: tempC, FeelsLikeC: &tempC},
+		Stale:   true,
+	}, time.Hour)
+	data, ok, err := weather.Get(ctx, "pt-BR:limeira")
+	if err != nil || !ok || data.Current.TempC != tempC || *data.Current.FeelsLikeC != tempC {
+		t.Fatalf("Expected the weather back, got %+v, %v, %v", data, ok, err)
+	}
+	if data.Stale {
+		t.Errorf("Expected Stale not to be stored, it describes a served copy")
+	}
+
+	backend.Set(ctx, weather.Key("broken"), []byte("{"), 0)
+	if _, ok, err := weather.Get(ctx, "broken"); ok || err == nil {
+		t.Errorf("Expected an undecodable value to be an error, got %v, %v", ok, err)
+	}
+}
+
+// failingBackend fails every call while err is set.
+type failingBackend struct {
+	*MemoryBackend
+	err   error
+	calls int
+}
+
+func (b *failingBackend) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	b.calls++
+	if b.err != nil {
+		return nil, false, b.err
+	}
+	return b.MemoryBackend.Get(ctx, key)
+}
+
+func (b *failingBackend) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	b.calls++
+	if b.err != nil {
+		return b.err
+	}
+	return b.MemoryBackend.Set(ctx, key, value, ttl)
+}
+
+func (b *failingBackend) Delete(ctx context.Context, key string) error {
+	b.calls++
+	if b.err != nil {
+		return b.err
+	}
+	return b.MemoryBackend.Delete(ctx, key)
+}
+
+func TestFallbackBackend_DeleteWhileDegraded(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 5, 10, 12, 0, 0, 0, time.UTC)
+	remote := &failingBackend{MemoryBackend: NewMemoryBackend(0)}
+	local := NewMemoryBackend(0)
+	backend := NewFallbackBackend(remote, local, 5*time.Second, &MockLogger{})
+	backend.now = func() time.Time { return now }
+
+	backend.Set(ctx, "cep", []byte("gone"), 0)
+	remote.err = errors.New("connection refused")
+	backend.Delete(ctx, "cep")
+	if !backend.Degraded() {
+		t.Fatal("Expected the failed delete to degrade the cache")
+	}
+
+	remote.err = nil
+	now = now.Add(5 * time.Second)
+	if _, ok, _ := backend.Get(ctx, "cep"); ok {
+		t.Fatal("Expected the key deleted during the outage not to be read back from the remote")
+	}
+
+	// Any call that reaches the remote starts the owed delete.
+	backend.Get(ctx, "other")
+	deadline := time.Now().Add(time.Second)
+	for backend.isDeleted("cep") && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if _, ok, _ := remote.MemoryBackend.Get(ctx, "cep"); ok {
+		t.Fatal("Expected the key to be deleted from the remote once it recovered")
+	}
+
+	backend.Set(ctx, "cep", []byte("back"), 0)
+	remote.MemoryBackend.Set(ctx, "cep", []byte("from another replica"), 0)
+	if value, _, _ := backend.Get(ctx, "cep"); string(value) != "from another replica" {
+		t.Errorf("Expected the remote to serve the key again, got %q", value)
+	}
+}
+
+func TestFallbackBackend(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 5, 10, 12, 0, 0, 0, time.UTC)
+	remote := &failingBackend{MemoryBackend: NewMemoryBackend(0)}
+	local := NewMemoryBackend(0)
+	log := &MockLogger{}
+	backend := NewFallbackBackend(remote, local, 5*time.Second, log)
+	backend.now = func() time.Time { return now }
+
+	backend.Set(ctx, "a", []byte("1"), 0)
+	remote.MemoryBackend.Set(ctx, "b", []byte("from another replica"), 0)
+	if value, ok, _ := backend.Get(ctx, "b"); !ok || string(value) != "from another replica" {
+		t.Errorf("Expected values shared by other replicas, got %q, %v", value, ok)
+	}
+
+	remote.err = errors.New("connection refused")
+	value, ok, err := backend.Get(ctx, "a")
+	if err != nil || !ok || string(value) != "1" {
+		t.Errorf("Expected the local copy while the remote fails, got %q, %v, %v", value, ok, err)
+	}
+	if !backend.Degraded() || log.errors != 1 {
+		t.Errorf("Expected the failure to be logged once and degrade the cache")
+	}
+
+	calls := remote.calls
+	backend.Set(ctx, "c", []byte("3"), 0)
+	backend.Get(ctx, "c")
+	if remote.calls != calls {
+		t.Errorf("Expected the remote to be left alone for the retry period, got %d calls", remote.calls-calls)
+	}
+
+	remote.err = nil
+	now = now.Add(5 * time.Second)
+	if backend.Degraded() {
+		t.Fatalf("Expected the remote to be retried after 5s")
+	}
+	if _, ok, _ := backend.Get(ctx, "c"); ok {
+		t.Errorf("Expected the remote to serve again, without the value set while it was down")
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	remote.err = context.Canceled
+	backend.Get(canceled, "a")
+	if backend.Degraded() {
+		t.Errorf("Expected a canceled call not to degrade the cache")
+	}
+}
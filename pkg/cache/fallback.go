@@ -0,0 +1,191 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go-a-b-microservices/pkg/logger"
+)
+
+// DefaultRetryAfter is how long a FallbackBackend keeps off a failed remote
+// backend before trying it again.
+const DefaultRetryAfter = 5 * time.Second
+
+// maxDeletedKeys bounds the keys a FallbackBackend remembers deleting while
+// the remote backend failed. The shared values of further keys can be served
+// again after it recovers, until they expire.
+const maxDeletedKeys = 10000
+
+// FallbackBackend shares values through a remote backend, such as Redis,
+// and serves them from a local one while the remote one fails. Values are
+// always written to the local backend too, so it is warm when it is needed.
+//
+// After a remote failure the remote backend is left alone for the retry
+// period, so an unreachable server does not add its timeout to every call.
+// Remote errors are never returned: the cache degrades to local-only
+// instead.
+//
+// A key deleted while the remote backend fails is read from the local
+// backend only until it is deleted from the remote one too, which is retried
+// once the remote backend recovers, so other replicas' copies do not come
+// back.
+type FallbackBackend struct {
+	remote     Backend
+	local      Backend
+	retryAfter time.Duration
+	logger     logger.Logger
+
+	mu        sync.Mutex
+	down      bool
+	downUntil time.Time
+	// deleted are the keys whose remote delete is owed, and repairing is set
+	// while they are deleted.
+	deleted   map[string]struct{}
+	repairing bool
+	now       func() time.Time
+}
+
+// NewFallbackBackend returns a backend that falls back from remote to local,
+// retrying remote after retryAfter, or DefaultRetryAfter when zero.
+func NewFallbackBackend(remote, local Backend, retryAfter time.Duration, log logger.Logger) *FallbackBackend {
+	if retryAfter <= 0 {
+		retryAfter = DefaultRetryAfter
+	}
+	return &FallbackBackend{
+		remote:     remote,
+		local:      local,
+		retryAfter: retryAfter,
+		logger:     log,
+		deleted:    make(map[string]struct{}),
+		now:        time.Now,
+	}
+}
+
+// Degraded reports whether values are currently served from the local
+// backend only.
+func (b *FallbackBackend) Degraded() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.now().Before(b.downUntil)
+}
+
+func (b *FallbackBackend) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	if !b.Degraded() && !b.isDeleted(key) {
+		value, ok, err := b.remote.Get(ctx, key)
+		if err == nil {
+			b.succeeded(ctx)
+			return value, ok, nil
+		}
+		b.failed(ctx, err)
+	}
+	return b.local.Get(ctx, key)
+}
+
+func (b *FallbackBackend) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if !b.Degraded() {
+		if err := b.remote.Set(ctx, key, value, ttl); err != nil {
+			b.failed(ctx, err)
+		} else {
+			b.forget(key)
+			b.succeeded(ctx)
+		}
+	}
+	return b.local.Set(ctx, key, value, ttl)
+}
+
+func (b *FallbackBackend) Delete(ctx context.Context, key string) error {
+	if b.Degraded() {
+		b.remember(key)
+	} else if err := b.remote.Delete(ctx, key); err != nil {
+		b.failed(ctx, err)
+		b.remember(key)
+	} else {
+		b.forget(key)
+		b.succeeded(ctx)
+	}
+	return b.local.Delete(ctx, key)
+}
+
+func (b *FallbackBackend) isDeleted(key string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	_, ok := b.deleted[key]
+	return ok
+}
+
+// remember records that key still has to be deleted from the remote backend.
+func (b *FallbackBackend) remember(key string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.deleted) < maxDeletedKeys {
+		b.deleted[key] = struct{}{}
+	}
+}
+
+// forget records that the remote backend holds the current value of key.
+func (b *FallbackBackend) forget(key string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.deleted, key)
+}
+
+// failed takes the remote backend out for the retry period. A call given up
+// by its caller says nothing about the remote backend.
+func (b *FallbackBackend) failed(ctx context.Context, err error) {
+	if ctx.Err() != nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	if now.Before(b.downUntil) {
+		return
+	}
+	b.down = true
+	b.downUntil = now.Add(b.retryAfter)
+	b.logger.Error("Shared cache failed, using the local cache for %s: %v", b.retryAfter, err)
+}
+
+// succeeded marks the remote backend as working and, when deletes are owed
+// to it, starts sending them.
+func (b *FallbackBackend) succeeded(ctx context.Context) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.down {
+		b.down = false
+		b.logger.Info("Shared cache recovered")
+	}
+	if len(b.deleted) == 0 || b.repairing {
+		return
+	}
+
+	b.repairing = true
+	keys := make([]string, 0, len(b.deleted))
+	for key := range b.deleted {
+		keys = append(keys, key)
+	}
+	// The deletes are not the caller's work, so they neither delay it nor end
+	// with it.
+	go b.repair(context.WithoutCancel(ctx), keys)
+}
+
+// repair deletes keys from the remote backend, stopping at the first failure.
+func (b *FallbackBackend) repair(ctx context.Context, keys []string) {
+	defer func() {
+		b.mu.Lock()
+		b.repairing = false
+		b.mu.Unlock()
+	}()
+
+	for _, key := range keys {
+		if err := b.remote.Delete(ctx, key); err != nil {
+			b.failed(ctx, err)
+			return
+		}
+		b.forget(key)
+	}
+}
@@ -0,0 +1,297 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// RedisError is an error reply from the server, such as WRONGPASS. The
+// connection it came on is still usable.
+type RedisError string
+
+func (e RedisError) Error() string {
+	return "redis: " + string(e)
+}
+
+// errClosed is returned by the commands of a closed backend.
+var errClosed = errors.New("redis: backend closed")
+
+// RedisOptions configures a RedisBackend.
+type RedisOptions struct {
+	// Addr is the host:port of the server.
+	Addr     string
+	Password string
+	DB       int
+	// Timeout bounds connecting and each command, unless the context of the
+	// command ends first. Zero leaves it to the context.
+	Timeout time.Duration
+	// PoolSize is how many idle connections are kept, 10 when zero.
+	PoolSize int
+}
+
+// RedisBackend speaks the Redis protocol (RESP) to a Redis server or to one
+// compatible with it, keeping a pool of connections.
+type RedisBackend struct {
+	options RedisOptions
+	dialer  net.Dialer
+	idle    chan *redisConn
+	closed  chan struct{}
+}
+
+type redisConn struct {
+	net.Conn
+	reader *bufio.Reader
+	writer *bufio.Writer
+}
+
+func NewRedisBackend(options RedisOptions) *RedisBackend {
+	if options.PoolSize <= 0 {
+		options.PoolSize = 10
+	}
+	return &RedisBackend{
+		options: options,
+		dialer:  net.Dialer{Timeout: options.Timeout},
+		idle:    make(chan *redisConn, options.PoolSize),
+		closed:  make(chan struct{}),
+	}
+}
+
+// Ping checks that the server answers.
+func (b *RedisBackend) Ping(ctx context.Context) error {
+	_, err := b.do(ctx, "PING")
+	return err
+}
+
+func (b *RedisBackend) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	reply, err := b.do(ctx, "GET", key)
+	if err != nil {
+		return nil, false, err
+	}
+	if reply == nil {
+		return nil, false, nil
+	}
+	value, ok := reply.([]byte)
+	if !ok {
+		return nil, false, fmt.Errorf("redis: unexpected GET reply %v", reply)
+	}
+	return value, true, nil
+}
+
+func (b *RedisBackend) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	args := []string{"SET", key, string(value)}
+	if ttl > 0 {
+		// Redis rejects a zero PX, so sub-millisecond TTLs round up.
+		args = append(args, "PX", strconv.FormatInt(max(ttl.Milliseconds(), 1), 10))
+	}
+	_, err := b.do(ctx, args...)
+	return err
+}
+
+func (b *RedisBackend) Delete(ctx context.Context, key string) error {
+	_, err := b.do(ctx, "DEL", key)
+	return err
+}
+
+// Close closes the idle connections; the ones in use are closed as they are
+// returned.
+func (b *RedisBackend) Close() error {
+	select {
+	case <-b.closed:
+		return nil
+	default:
+		close(b.closed)
+	}
+	for {
+		select {
+		case conn := <-b.idle:
+			conn.Close()
+		default:
+			return nil
+		}
+	}
+}
+
+// do sends a command and reads its reply: nil, a string, an int64, a []byte
+// or an []interface{} of those.
+func (b *RedisBackend) do(ctx context.Context, args ...string) (interface{}, error) {
+	retried := false
+	for {
+		conn, pooled, err := b.conn(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		reply, err := conn.do(ctx, b.options.Timeout, args...)
+		var redisErr RedisError
+		if err != nil && !errors.As(err, &redisErr) {
+			// The connection is left in an unknown state. An idle one found
+			// closed, by a server restart for instance, is retried once on a
+			// new connection; a timeout is not, as the server is not answering.
+			conn.Close()
+			if pooled && !retried && ctx.Err() == nil && isClosedConn(err) {
+				retried = true
+				continue
+			}
+			return nil, err
+		}
+		b.put(conn)
+		return reply, err
+	}
+}
+
+// isClosedConn reports whether err says the peer closed the connection.
+func isClosedConn(err error) bool {
+	return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE)
+}
+
+// conn returns an idle connection, with pooled true, or a new one.
+func (b *RedisBackend) conn(ctx context.Context) (conn *redisConn, pooled bool, err error) {
+	select {
+	case <-b.closed:
+		return nil, false, errClosed
+	case conn := <-b.idle:
+		return conn, true, nil
+	default:
+	}
+
+	netConn, err := b.dialer.DialContext(ctx, "tcp", b.options.Addr)
+	if err != nil {
+		return nil, false, err
+	}
+	conn = &redisConn{Conn: netConn, reader: bufio.NewReader(netConn), writer: bufio.NewWriter(netConn)}
+
+	if b.options.Password != "" {
+		if _, err := conn.do(ctx, b.options.Timeout, "AUTH", b.options.Password); err != nil {
+			conn.Close()
+			return nil, false, err
+		}
+	}
+	if b.options.DB != 0 {
+		if _, err := conn.do(ctx, b.options.Timeout, "SELECT", strconv.Itoa(b.options.DB)); err != nil {
+			conn.Close()
+			return nil, false, err
+		}
+	}
+	return conn, false, nil
+}
+
+func (b *RedisBackend) put(conn *redisConn) {
+	select {
+	case <-b.closed:
+		conn.Close()
+		return
+	default:
+	}
+	select {
+	case b.idle <- conn:
+	default:
+		conn.Close()
+	}
+}
+
+func (c *redisConn) do(ctx context.Context, timeout time.Duration, args ...string) (interface{}, error) {
+	deadline, ok := ctx.Deadline()
+	if timeout > 0 && (!ok || time.Until(deadline) > timeout) {
+		deadline, ok = time.Now().Add(timeout), true
+	}
+	if ok {
+		c.SetDeadline(deadline)
+	} else {
+		c.SetDeadline(time.Time{})
+	}
+	// A canceled context interrupts the command like an expired deadline.
+	stop := context.AfterFunc(ctx, func() { c.SetDeadline(time.Unix(1, 0)) })
+	defer stop()
+
+	writeCommand(c.writer, args...)
+	if err := c.writer.Flush(); err != nil {
+		return nil, err
+	}
+	return readReply(c.reader)
+}
+
+// writeCommand writes args as a RESP array of bulk strings, the form every
+// command is sent in. Write errors surface on Flush.
+func writeCommand(w *bufio.Writer, args ...string) {
+	fmt.Fprintf(w, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(w, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+}
+
+// readReply reads one RESP value: nil for a null, a string for a simple
+// string, an int64, a []byte for a bulk string or an []interface{} for an
+// array. An error reply is returned as a RedisError, and kept as one inside
+// arrays.
+func readReply(r *bufio.Reader) (interface{}, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 {
+		return nil, errors.New("redis: empty reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, RedisError(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil || size < -1 {
+			return nil, fmt.Errorf("redis: invalid bulk length %q", line)
+		}
+		if size == -1 {
+			return nil, nil
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, err
+		}
+		return data[:size], nil
+	case '*':
+		count, err := strconv.Atoi(line[1:])
+		if err != nil || count < -1 {
+			return nil, fmt.Errorf("redis: invalid array length %q", line)
+		}
+		if count == -1 {
+			return nil, nil
+		}
+		values := make([]interface{}, count)
+		for i := range values {
+			value, err := readReply(r)
+			var redisErr RedisError
+			if errors.As(err, &redisErr) {
+				value = redisErr
+			} else if err != nil {
+				return nil, err
+			}
+			values[i] = value
+		}
+		return values, nil
+	default:
+		return nil, fmt.Errorf("redis: unexpected reply %q", line)
+	}
+}
+
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	if len(line) < 2 || line[len(line)-2] != '\r' {
+		return "", fmt.Errorf("redis: malformed line %q", line)
+	}
+	return line[:len(line)-2], nil
+}
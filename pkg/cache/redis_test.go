@@ -0,0 +1,219 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go-a-b-microservices/pkg/cache/redistest"
+)
+
+func TestRedisBackend(t *testing.T) {
+	server := redistest.NewServer()
+	defer server.Close()
+
+	ctx := context.Background()
+	backend := NewRedisBackend(RedisOptions{Addr: server.Addr(), Timeout: time.Second})
+	defer backend.Close()
+
+	if err := backend.Ping(ctx); err != nil {
+		t.Fatalf("Ping() error = %v", err)
+	}
+
+	value := []byte("{\"city\":\"São Paulo\"}\r\n$3\r\nbinary\x00")
+	if err := backend.Set(ctx, "service-b:location:v1:01001000", value, 90*time.Second); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if ttl := server.TTL("service-b:location:v1:01001000"); ttl != 90*time.Second {
+		t.Errorf("Expected a 90s TTL, got %s", ttl)
+	}
+
+	got, ok, err := backend.Get(ctx, "service-b:location:v1:01001000")
+	if err != nil || !ok || string(got) != string(value) {
+		t.Errorf("Expected the value back unchanged, got %q, %v, %v", got, ok, err)
+	}
+	if _, ok, err := backend.Get(ctx, "missing"); ok || err != nil {
+		t.Errorf("Expected a missing key to be a miss, got %v, %v", ok, err)
+	}
+
+	server.FastForward(90 * time.Second)
+	if _, ok, _ := backend.Get(ctx, "service-b:location:v1:01001000"); ok {
+		t.Errorf("Expected the key to expire with its TTL")
+	}
+
+	backend.Set(ctx, "forever", []byte("1"), 0)
+	backend.Delete(ctx, "forever")
+	if keys := server.Keys(); len(keys) != 0 {
+		t.Errorf("Expected no keys left, got %v", keys)
+	}
+}
+
+func TestRedisBackend_AuthAndDB(t *testing.T) {
+	server := redistest.NewServer()
+	defer server.Close()
+	server.SetPassword("secret")
+
+	ctx := context.Background()
+	wrong := NewRedisBackend(RedisOptions{Addr: server.Addr(), Password: "wrong"})
+	defer wrong.Close()
+	var redisErr RedisError
+	if err := wrong.Ping(ctx); !errors.As(err, &redisErr) || !strings.HasPrefix(string(redisErr), "WRONGPASS") {
+		t.Errorf("Expected a WRONGPASS error, got %v", err)
+	}
+
+	backend := NewRedisBackend(RedisOptions{Addr: server.Addr(), Password: "secret", DB: 2})
+	defer backend.Close()
+	if err := backend.Set(ctx, "a", []byte("1"), 0); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if keys := server.Keys(); len(keys) != 0 {
+		t.Errorf("Expected the key to be stored in db 2, got %v in db 0", keys)
+	}
+
+	expected := []string{"AUTH", "AUTH", "SELECT", "SET"}
+	if commands := server.Commands(); !reflect.DeepEqual(commands, expected) {
+		t.Errorf("Expected %v, got %v", expected, commands)
+	}
+}
+
+func TestRedisBackend_Unreachable(t *testing.T) {
+	server := redistest.NewServer()
+	backend := NewRedisBackend(RedisOptions{Addr: server.Addr(), Timeout: time.Second})
+	defer backend.Close()
+
+	ctx := context.Background()
+	backend.Set(ctx, "a", []byte("1"), 0)
+
+	server.Close()
+	if _, _, err := backend.Get(ctx, "a"); err == nil {
+		t.Fatalf("Expected an error while the server is down")
+	}
+
+	if err := server.Restart(); err != nil {
+		t.Skipf("Cannot listen on %s again: %v", server.Addr(), err)
+	}
+	defer server.Close()
+	if value, ok, err := backend.Get(ctx, "a"); err != nil || !ok || string(value) != "1" {
+		t.Errorf("Expected the backend to reconnect, got %q, %v, %v", value, ok, err)
+	}
+}
+
+func TestRedisBackend_Timeout(t *testing.T) {
+	// A server that accepts connections but never answers.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+	go func() {
+		var conns []net.Conn
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				for _, conn := range conns {
+					conn.Close()
+				}
+				return
+			}
+			conns = append(conns, conn)
+		}
+	}()
+
+	backend := NewRedisBackend(RedisOptions{Addr: listener.Addr().String(), Timeout: 50 * time.Millisecond})
+	defer backend.Close()
+
+	start := time.Now()
+	var netErr net.Error
+	if _, _, err := backend.Get(context.Background(), "a"); !errors.As(err, &netErr) || !netErr.Timeout() {
+		t.Errorf("Expected a timeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the call to give up after 50ms, took %s", elapsed)
+	}
+}
+
+func TestRedisBackend_HungServerIsNotRetried(t *testing.T) {
+	// A server that answers until hung is set, then reads without answering.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+	var hung atomic.Bool
+	var unanswered atomic.Int32
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+				for {
+					if _, err := readReply(reader); err != nil {
+						return
+					}
+					if hung.Load() {
+						unanswered.Add(1)
+						continue
+					}
+					conn.Write([]byte("+PONG\r\n"))
+				}
+			}()
+		}
+	}()
+
+	backend := NewRedisBackend(RedisOptions{Addr: listener.Addr().String(), Timeout: 50 * time.Millisecond})
+	defer backend.Close()
+
+	// Fill the pool with several idle connections.
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			backend.Ping(context.Background())
+		}()
+	}
+	wg.Wait()
+
+	hung.Store(true)
+	if err := backend.Ping(context.Background()); err == nil {
+		t.Fatalf("Expected a timeout from the hung server")
+	}
+	if n := unanswered.Load(); n != 1 {
+		t.Errorf("Expected a timeout not to be retried on other idle connections, got %d attempts", n)
+	}
+}
+
+func TestFallbackBackend_Redis(t *testing.T) {
+	server := redistest.NewServer()
+	defer server.Close()
+
+	ctx := context.Background()
+	redis := NewRedisBackend(RedisOptions{Addr: server.Addr(), Timeout: time.Second})
+	defer redis.Close()
+	replicaA := NewFallbackBackend(redis, NewMemoryBackend(0), time.Minute, &MockLogger{})
+	replicaB := NewFallbackBackend(redis, NewMemoryBackend(0), time.Minute, &MockLogger{})
+
+	replicaA.Set(ctx, "a", []byte("1"), time.Minute)
+	if _, ok, _ := replicaB.Get(ctx, "a"); !ok {
+		t.Errorf("Expected replicas to share values through Redis")
+	}
+
+	server.Close()
+	if value, ok, err := replicaA.Get(ctx, "a"); err != nil || !ok || string(value) != "1" {
+		t.Errorf("Expected replica A to fall back to its own copy, got %q, %v, %v", value, ok, err)
+	}
+	if _, ok, err := replicaB.Get(ctx, "a"); ok || err != nil {
+		t.Errorf("Expected replica B to miss without an error, got %v, %v", ok, err)
+	}
+}
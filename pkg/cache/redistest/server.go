@@ -0,0 +1,352 @@
+// Package redistest provides an in-process server speaking enough of the
+// Redis protocol to test cache.RedisBackend without a Redis server.
+package redistest
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Server answers PING, AUTH, SELECT, GET, SET with EX or PX, DEL, FLUSHALL
+// and QUIT. Its clock only moves with FastForward, so expiry is
+// deterministic.
+type Server struct {
+	mu       sync.Mutex
+	addr     string
+	listener net.Listener
+	conns    map[net.Conn]struct{}
+	password string
+	data     map[int]map[string]entry
+	now      time.Time
+	commands []string
+}
+
+type entry struct {
+	value     string
+	expiresAt time.Time
+}
+
+// NewServer starts a server on a random local port. It panics if it cannot
+// listen, like httptest.NewServer.
+func NewServer() *Server {
+	s := &Server{
+		addr:  "127.0.0.1:0",
+		conns: make(map[net.Conn]struct{}),
+		data:  make(map[int]map[string]entry),
+		now:   time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	if err := s.Restart(); err != nil {
+		panic(fmt.Sprintf("redistest: failed to listen: %v", err))
+	}
+	return s
+}
+
+// Addr returns the host:port the server listens on.
+func (s *Server) Addr() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addr
+}
+
+// Close stops listening and drops every connection, like a server going
+// down. The data is kept for Restart.
+func (s *Server) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.listener != nil {
+		s.listener.Close()
+		s.listener = nil
+	}
+	for conn := range s.conns {
+		conn.Close()
+		delete(s.conns, conn)
+	}
+}
+
+// Restart listens again on the same address after Close.
+func (s *Server) Restart() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	listener, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return err
+	}
+	s.listener = listener
+	s.addr = listener.Addr().String()
+	go s.serve(listener)
+	return nil
+}
+
+// SetPassword makes new connections authenticate with AUTH first.
+func (s *Server) SetPassword(password string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.password = password
+}
+
+// FastForward moves the clock of the server, expiring keys.
+func (s *Server) FastForward(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.now = s.now.Add(d)
+}
+
+// Keys returns the live keys of db 0, sorted.
+func (s *Server) Keys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var keys []string
+	for key := range s.data[0] {
+		if _, ok := s.lookup(0, key); ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// TTL returns the time left before key expires in db 0, zero when it does
+// not expire or does not exist.
+func (s *Server) TTL(key string) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.lookup(0, key)
+	if !ok || e.expiresAt.IsZero() {
+		return 0
+	}
+	return e.expiresAt.Sub(s.now)
+}
+
+// Commands returns the names of the commands received so far.
+func (s *Server) Commands() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.commands...)
+}
+
+func (s *Server) serve(listener net.Listener) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		if s.listener != listener {
+			// Close raced with Accept.
+			s.mu.Unlock()
+			conn.Close()
+			return
+		}
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+		go s.handle(conn)
+	}
+}
+
+func (s *Server) handle(conn net.Conn) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		conn.Close()
+	}()
+
+	reader := bufio.NewReader(conn)
+	writer := bufio.NewWriter(conn)
+	session := &session{}
+	for {
+		args, err := readCommand(reader)
+		if err != nil {
+			if err != io.EOF {
+				fmt.Fprintf(writer, "-ERR %v\r\n", err)
+				writer.Flush()
+			}
+			return
+		}
+		if len(args) == 0 {
+			continue
+		}
+
+		reply, quit := s.exec(session, args)
+		writer.WriteString(reply)
+		if err := writer.Flush(); err != nil || quit {
+			return
+		}
+	}
+}
+
+type session struct {
+	authenticated bool
+	db            int
+}
+
+// exec runs a command and returns its encoded reply.
+func (s *Server) exec(session *session, args []string) (reply string, quit bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	name := strings.ToUpper(args[0])
+	s.commands = append(s.commands, name)
+
+	if s.password != "" && !session.authenticated && name != "AUTH" && name != "QUIT" {
+		return "-NOAUTH Authentication required.\r\n", false
+	}
+
+	switch name {
+	case "PING":
+		return "+PONG\r\n", false
+	case "QUIT":
+		return "+OK\r\n", true
+	case "AUTH":
+		if len(args) != 2 {
+			return wrongArgs(name), false
+		}
+		if args[1] != s.password {
+			return "-WRONGPASS invalid username-password pair or user is disabled.\r\n", false
+		}
+		session.authenticated = true
+		return "+OK\r\n", false
+	case "SELECT":
+		if len(args) != 2 {
+			return wrongArgs(name), false
+		}
+		db, err := strconv.Atoi(args[1])
+		if err != nil || db < 0 || db > 15 {
+			return "-ERR DB index is out of range\r\n", false
+		}
+		session.db = db
+		return "+OK\r\n", false
+	case "GET":
+		if len(args) != 2 {
+			return wrongArgs(name), false
+		}
+		e, ok := s.lookup(session.db, args[1])
+		if !ok {
+			return "$-1\r\n", false
+		}
+		return bulk(e.value), false
+	case "SET":
+		return s.set(session.db, args), false
+	case "DEL":
+		if len(args) < 2 {
+			return wrongArgs(name), false
+		}
+		deleted := 0
+		for _, key := range args[1:] {
+			if _, ok := s.lookup(session.db, key); ok {
+				delete(s.data[session.db], key)
+				deleted++
+			}
+		}
+		return fmt.Sprintf(":%d\r\n", deleted), false
+	case "FLUSHALL":
+		s.data = make(map[int]map[string]entry)
+		return "+OK\r\n", false
+	default:
+		return fmt.Sprintf("-ERR unknown command '%s'\r\n", args[0]), false
+	}
+}
+
+func (s *Server) set(db int, args []string) string {
+	if len(args) != 3 && len(args) != 5 {
+		return wrongArgs("SET")
+	}
+
+	e := entry{value: args[2]}
+	if len(args) == 5 {
+		amount, err := strconv.ParseInt(args[4], 10, 64)
+		if err != nil || amount <= 0 {
+			return "-ERR invalid expire time in 'set' command\r\n"
+		}
+		switch strings.ToUpper(args[3]) {
+		case "EX":
+			e.expiresAt = s.now.Add(time.Duration(amount) * time.Second)
+		case "PX":
+			e.expiresAt = s.now.Add(time.Duration(amount) * time.Millisecond)
+		default:
+			return "-ERR syntax error\r\n"
+		}
+	}
+
+	if s.data[db] == nil {
+		s.data[db] = make(map[string]entry)
+	}
+	s.data[db][args[1]] = e
+	return "+OK\r\n"
+}
+
+// lookup returns the live entry of key, dropping it once expired.
+func (s *Server) lookup(db int, key string) (entry, bool) {
+	e, ok := s.data[db][key]
+	if !ok {
+		return entry{}, false
+	}
+	if !e.expiresAt.IsZero() && !s.now.Before(e.expiresAt) {
+		delete(s.data[db], key)
+		return entry{}, false
+	}
+	return e, true
+}
+
+func bulk(value string) string {
+	return fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+}
+
+func wrongArgs(name string) string {
+	return fmt.Sprintf("-ERR wrong number of arguments for '%s' command\r\n", strings.ToLower(name))
+}
+
+// readCommand reads a command sent as an array of bulk strings.
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(line, "*") {
+		return nil, fmt.Errorf("Protocol error: expected '*', got '%s'", line)
+	}
+	count, err := strconv.Atoi(line[1:])
+	if err != nil || count < 0 {
+		return nil, fmt.Errorf("Protocol error: invalid multibulk length")
+	}
+
+	args := make([]string, count)
+	for i := range args {
+		line, err := readLine(r)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(line, "$") {
+			return nil, fmt.Errorf("Protocol error: expected '$', got '%s'", line)
+		}
+		size, err := strconv.Atoi(line[1:])
+		if err != nil || size < 0 {
+			return nil, fmt.Errorf("Protocol error: invalid bulk length")
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, err
+		}
+		args[i] = string(data[:size])
+	}
+	return args, nil
+}
+
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(line, "\r\n"), nil
+}
@@ -0,0 +1,27 @@
+// Package calltimeout bounds the calls a client makes to another service with
+// a timeout that can be changed on reload.
+package calltimeout
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// Timeout bounds each call a client makes. It can be changed while the client
+// is in use; zero means no bound.
+type Timeout struct {
+	value atomic.Int64
+}
+
+func (t *Timeout) Set(timeout time.Duration) {
+	t.value.Store(int64(timeout))
+}
+
+// Context returns ctx bounded by the current timeout.
+func (t *Timeout) Context(ctx context.Context) (context.Context, context.CancelFunc) {
+	if timeout := time.Duration(t.value.Load()); timeout > 0 {
+		return context.WithTimeout(ctx, timeout)
+	}
+	return context.WithCancel(ctx)
+}
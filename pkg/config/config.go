@@ -1,43 +1,319 @@
+// Package config loads the settings of each service from a config file, the
+// environment and command-line flags, reporting every missing or invalid value
+// at once.
 package config
 
 import (
+	"flag"
+	"fmt"
+	"net/url"
 	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"go-a-b-microservices/pkg/hmacauth"
+	"go-a-b-microservices/pkg/i18n"
+	"go-a-b-microservices/pkg/ratelimit"
+	"go-a-b-microservices/pkg/temperature"
+	"go-a-b-microservices/pkg/tlsconfig"
 
 	"github.com/joho/godotenv"
 )
 
-type Config struct {
-	ServiceAPort   string
-	ServiceBPort   string
-	ServiceBURL    string
-	ViaCepURL      string
-	WeatherAPIURL  string
-	WeatherAPIKey  string
-	ZipkinEndpoint string
-	ServiceName    string
+// Common holds the settings both services share.
+type Common struct {
+	ServiceName     string
+	LogLevel        string   `key:"log.level" env:"LOG_LEVEL" default:"info" oneof:"debug,info,error" reload:"true"`
+	ZipkinEndpoint  *url.URL `key:"tracing.zipkin_endpoint" env:"ZIPKIN_ENDPOINT" default:"http://localhost:9411/api/v2/spans"`
+	DefaultLanguage string   `key:"server.default_language" env:"DEFAULT_LANGUAGE" default:"en"`
+	SecretsFile     string   `key:"secrets.file" env:"SECRETS_FILE" reload:"true"`
+	ServiceAuthKeys string   `key:"service_auth.keys" env:"SERVICE_AUTH_KEYS" required:"true" secret:"list" reload:"true"`
+	TLSCertFile     string   `key:"tls.cert_file" env:"TLS_CERT_FILE"`
+	TLSKeyFile      string   `key:"tls.key_file" env:"TLS_KEY_FILE"`
+	TLSMinVersion   string   `key:"tls.min_version" env:"TLS_MIN_VERSION" default:"1.2"`
+	TLSClientCAFile string   `key:"tls.client_ca_file" env:"TLS_CLIENT_CA_FILE"`
+	OpenAPIDocs     bool     `key:"openapi.docs" env:"OPENAPI_DOCS" default:"false"`
+	MaxBodyBytes    int      `key:"server.max_body_bytes" env:"MAX_REQUEST_BODY_BYTES" default:"1048576" min:"1" reload:"true"`
+
+	files []string
+}
+
+// Files returns the config file and the secret files the settings were read
+// from, which a Reloader watches for changes.
+func (c *Common) Files() []string {
+	return c.files
 }
 
-func LoadConfig(serviceName string) (*Config, error) {
+func (c *Common) common() *Common {
+	return c
+}
+
+// ServiceA holds the settings of Service A.
+type ServiceA struct {
+	Common
+
+	Port              int           `key:"server.port" env:"SERVICE_A_PORT" default:"8080" min:"1" max:"65535"`
+	ServiceBURL       *url.URL      `key:"service_b.url" env:"SERVICE_B_URL" default:"http://localhost:8081"`
+	ServiceBTimeout   time.Duration `key:"service_b.timeout" env:"SERVICE_B_TIMEOUT" default:"10s" min:"0s" reload:"true"`
+	ServiceBTransport string        `key:"service_b.transport" env:"SERVICE_B_TRANSPORT" default:"http" oneof:"http,grpc"`
+	ServiceBGRPCAddr  string        `key:"service_b.grpc_addr" env:"SERVICE_B_GRPC_ADDR" default:"localhost:9090"`
+	ServiceBCAFile    string        `key:"service_b.ca_file" env:"SERVICE_B_CA_FILE"`
+	ServiceBCertFile  string        `key:"service_b.cert_file" env:"SERVICE_B_CLIENT_CERT_FILE"`
+	ServiceBKeyFile   string        `key:"service_b.key_file" env:"SERVICE_B_CLIENT_KEY_FILE"`
+	AuthMode          string        `key:"auth.mode" env:"AUTH_MODE" default:"none"`
+	APIKeys           string        `key:"auth.api_keys" env:"API_KEYS" secret:"list" reload:"true"`
+	APIKeysFile       string        `key:"auth.api_keys_file" env:"API_KEYS_FILE" reload:"true"`
+	JWTIssuer         string        `key:"auth.jwt.issuer" env:"JWT_ISSUER"`
+	JWTAudience       string        `key:"auth.jwt.audience" env:"JWT_AUDIENCE"`
+	JWTRequiredScopes string        `key:"auth.jwt.required_scopes" env:"JWT_REQUIRED_SCOPES"`
+	JWTJWKSURL        *url.URL      `key:"auth.jwt.jwks_url" env:"JWT_JWKS_URL"`
+	JWTPublicKeyFile  string        `key:"auth.jwt.public_key_file" env:"JWT_PUBLIC_KEY_FILE"`
+	JWTJWKSCacheTTL   time.Duration `key:"auth.jwt.jwks_cache_ttl" env:"JWT_JWKS_CACHE_TTL" default:"1h" min:"1s"`
+	JWTLeeway         time.Duration `key:"auth.jwt.leeway" env:"JWT_LEEWAY" default:"30s" min:"0s"`
+	RateLimitRequests int           `key:"rate_limit.requests" env:"RATE_LIMIT_REQUESTS" default:"60" min:"0" reload:"true"`
+	RateLimitPeriod   time.Duration `key:"rate_limit.period" env:"RATE_LIMIT_PERIOD" default:"1m" min:"1ms" reload:"true"`
+	RateLimitBurst    int           `key:"rate_limit.burst" env:"RATE_LIMIT_BURST" default:"0" min:"0" reload:"true"`
+	RateLimitClients  string        `key:"rate_limit.clients" env:"RATE_LIMIT_CLIENTS" reload:"true"`
+}
+
+// ServiceB holds the settings of Service B.
+type ServiceB struct {
+	Common
+
+	Port            int           `key:"server.port" env:"SERVICE_B_PORT" default:"8081" min:"1" max:"65535"`
+	GRPCPort        int           `key:"server.grpc_port" env:"SERVICE_B_GRPC_PORT" default:"9090" min:"1" max:"65535"`
+	AdminPort       int           `key:"server.admin_port" env:"SERVICE_B_ADMIN_PORT" default:"9091" min:"1" max:"65535"`
+	ServiceAuthSkew time.Duration `key:"service_auth.max_skew" env:"SERVICE_AUTH_MAX_SKEW" default:"5m" min:"1s"`
+	ProviderTimeout time.Duration `key:"providers.timeout" env:"PROVIDER_TIMEOUT" default:"10s" min:"0s" reload:"true"`
+	ProviderOrder   string        `key:"providers.order" env:"WEATHER_PROVIDER_ORDER" default:"weatherapi,openmeteo" reload:"true"`
+	ViaCepURL       *url.URL      `key:"providers.viacep.url" env:"VIA_CEP_URL" default:"https://viacep.com.br/ws"`
+	CEPStore        string        `key:"cep_store.driver" env:"CEP_STORE" default:"sqlite" oneof:"sqlite,none"`
+	CEPDirectory
+	WeatherAPIURL     *url.URL      `key:"providers.weatherapi.url" env:"WEATHER_API_URL" default:"https://api.weatherapi.com/v1/current.json"`
+	WeatherAPIKey     string        `key:"providers.weatherapi.key" env:"WEATHER_API_KEY" required:"true" secret:"true" reload:"true"`
+	WeatherAPIMonthly int           `key:"providers.weatherapi.monthly_limit" env:"WEATHER_API_MONTHLY_LIMIT" default:"0" min:"0" reload:"true"`
+	WeatherAPIMinute  int           `key:"providers.weatherapi.minute_limit" env:"WEATHER_API_MINUTE_LIMIT" default:"0" min:"0" reload:"true"`
+	WeatherDegradeAt  int           `key:"providers.weatherapi.degrade_at" env:"WEATHER_API_DEGRADE_AT" default:"95" min:"1" max:"100" reload:"true"`
+	WeatherDegrade    string        `key:"providers.weatherapi.degrade_mode" env:"WEATHER_API_DEGRADE_MODE" default:"cache" oneof:"cache,fallback" reload:"true"`
+	WeatherBudgetFile string        `key:"providers.weatherapi.budget_file" env:"WEATHER_API_BUDGET_FILE"`
+	WeatherLastKnown  time.Duration `key:"providers.weatherapi.last_known_max_age" env:"WEATHER_API_LAST_KNOWN_MAX_AGE" default:"6h" min:"0s" reload:"true"`
+	OpenMeteoURL      *url.URL      `key:"providers.openmeteo.url" env:"OPEN_METEO_URL" default:"https://api.open-meteo.com/v1"`
+	OpenMeteoGeoURL   *url.URL      `key:"providers.openmeteo.geocoding_url" env:"OPEN_METEO_GEOCODING_URL" default:"https://geocoding-api.open-meteo.com/v1"`
+	OpenMeteoArchive  *url.URL      `key:"providers.openmeteo.archive_url" env:"OPEN_METEO_ARCHIVE_URL" default:"https://archive-api.open-meteo.com/v1"`
+	HistoryMaxAgeDays int           `key:"history.max_age_days" env:"HISTORY_MAX_AGE_DAYS" default:"365" min:"1"`
+	HistoryCacheTTL   time.Duration `key:"cache.history.ttl" env:"HISTORY_CACHE_TTL" default:"10m" min:"0s" reload:"true"`
+	HistoryCacheSize  int           `key:"cache.history.size" env:"HISTORY_CACHE_SIZE" default:"10000" min:"0"`
+	WeatherCacheTTL   time.Duration `key:"cache.weather.ttl" env:"WEATHER_CACHE_TTL" default:"5m" min:"0s" reload:"true"`
+	WeatherMaxStale   time.Duration `key:"cache.weather.max_stale" env:"WEATHER_CACHE_MAX_STALE" default:"1h" min:"0s" reload:"true"`
+	WeatherCacheSize  int           `key:"cache.weather.size" env:"WEATHER_CACHE_SIZE" default:"1000" min:"0"`
+	WarmerTopN        int           `key:"cache.weather.warmer.top_n" env:"WEATHER_WARMER_TOP_N" default:"0" min:"0" reload:"true"`
+	WarmerInterval    time.Duration `key:"cache.weather.warmer.interval" env:"WEATHER_WARMER_INTERVAL" default:"1m" min:"1s" reload:"true"`
+	WarmerJitter      time.Duration `key:"cache.weather.warmer.jitter" env:"WEATHER_WARMER_JITTER" default:"15s" min:"0s" reload:"true"`
+	WarmerBudget      int           `key:"cache.weather.warmer.budget_percent" env:"WEATHER_WARMER_BUDGET_PERCENT" default:"80" min:"1" max:"100" reload:"true"`
+	WarmerStateFile   string        `key:"cache.weather.warmer.state_file" env:"WEATHER_WARMER_STATE_FILE"`
+	CacheBackend      string        `key:"cache.backend" env:"CACHE_BACKEND" default:"memory" oneof:"memory,redis"`
+	CacheNamespace    string        `key:"cache.namespace" env:"CACHE_NAMESPACE" default:"service-b"`
+	CacheFallbackSize int           `key:"cache.fallback_size" env:"CACHE_FALLBACK_SIZE" default:"10000" min:"0"`
+	CacheLocationTTL  time.Duration `key:"cache.location_ttl" env:"CACHE_LOCATION_TTL" default:"720h" min:"1m"`
+	RedisAddr         string        `key:"cache.redis.addr" env:"REDIS_ADDR" default:"localhost:6379"`
+	RedisPassword     string        `key:"cache.redis.password" env:"REDIS_PASSWORD" secret:"true"`
+	RedisDB           int           `key:"cache.redis.db" env:"REDIS_DB" default:"0" min:"0" max:"15"`
+	RedisTimeout      time.Duration `key:"cache.redis.timeout" env:"REDIS_TIMEOUT" default:"200ms" min:"1ms"`
+	RedisRetryAfter   time.Duration `key:"cache.redis.retry_after" env:"REDIS_RETRY_AFTER" default:"5s" min:"1s"`
+	TempPrecision     int           `key:"temperature.precision" env:"TEMPERATURE_PRECISION" default:"1" min:"0"`
+	TempRounding      string        `key:"temperature.rounding" env:"TEMPERATURE_ROUNDING" default:"half_up"`
+}
+
+// CEPDirectory holds the settings of the CEP directory of Service B, which the
+// tools that manage it read as well.
+type CEPDirectory struct {
+	CEPStorePath    string        `key:"cep_store.path" env:"CEP_STORE_PATH" default:"cep.db"`
+	CEPStoreRefresh time.Duration `key:"cep_store.refresh_after" env:"CEP_STORE_REFRESH_AFTER" default:"720h" min:"0s" reload:"true"`
+}
+
+// LoadServiceA reads the settings of Service A. Each setting takes the first
+// value found in, from highest to lowest precedence:
+//
+//  1. a command-line flag registered with RegisterFlags, such as --server.port
+//  2. an environment variable, such as SERVICE_A_PORT, or a .env file
+//  3. the config file named by --config or CONFIG_FILE
+//  4. its default
+//
+// Secrets may be given as references, such as file:///run/secrets/name, which
+// are resolved once the value is chosen. The returned error, an *Error, lists
+// every problem found; the configuration is returned with it so it can still
+// be printed.
+func LoadServiceA(flags *flag.FlagSet) (*ServiceA, error) {
+	cfg := &ServiceA{Common: Common{ServiceName: "service-a"}}
+	return cfg, loadLayers(cfg, flags)
+}
+
+// LoadServiceB reads the settings of Service B, as LoadServiceA does.
+func LoadServiceB(flags *flag.FlagSet) (*ServiceB, error) {
+	cfg := &ServiceB{Common: Common{ServiceName: "service-b"}}
+	return cfg, loadLayers(cfg, flags)
+}
+
+// LoadCEPDirectory reads the CEP directory settings from the environment and
+// the config file at path, or CONFIG_FILE when path is empty, as LoadServiceB
+// does, so the tools find the database Service B uses. The other settings in
+// the file are ignored.
+func LoadCEPDirectory(path string) (*CEPDirectory, error) {
+	_ = godotenv.Load()
+	if path == "" {
+		path, _ = os.LookupEnv(fileEnv)
+	}
+
+	var file Source
+	if path != "" {
+		values, err := ReadFile(path)
+		if err != nil {
+			return nil, report([]string{"config file: " + err.Error()})
+		}
+		file = KeySource(values)
+	}
+
+	cfg := &CEPDirectory{}
+	return cfg, report(load(cfg, file, EnvSource(os.LookupEnv)))
+}
+
+type settings interface {
+	common() *Common
+	validate() []string
+}
+
+func loadLayers(cfg settings, flags *flag.FlagSet) error {
 	_ = godotenv.Load()
+	return report(fillLayers(cfg, configFile(flags, os.LookupEnv), EnvSource(os.LookupEnv), flagSource(flags)))
+}
 
-	config := &Config{
-		ServiceAPort:   getEnv("SERVICE_A_PORT", "8080"),
-		ServiceBPort:   getEnv("SERVICE_B_PORT", "8081"),
-		ServiceBURL:    getEnv("SERVICE_B_URL", "http://localhost:8081"),
-		ViaCepURL:      getEnv("VIA_CEP_URL", "https://viacep.com.br/ws"),
-		WeatherAPIURL:  getEnv("WEATHER_API_URL", "https://api.weatherapi.com/v1/current.json"),
-		WeatherAPIKey:  getEnv("WEATHER_API_KEY", ""),
-		ZipkinEndpoint: getEnv("ZIPKIN_ENDPOINT", "http://localhost:9411/api/v2/spans"),
-		ServiceName:    serviceName,
+// fillLayers loads cfg from the config file at path, when there is one, and
+// then from sources, as fill does.
+func fillLayers(cfg settings, path string, sources ...Source) []string {
+	var problems []string
+	var file Source
+	if path != "" {
+		cfg.common().files = []string{path}
+		values, err := ReadFile(path)
+		if err != nil {
+			problems = append(problems, "config file: "+err.Error())
+		} else {
+			problems = append(problems, unknownKeys(cfg, values)...)
+			file = KeySource(values)
+		}
+	}
+
+	return append(problems, fill(cfg, append([]Source{file}, sources...)...)...)
+}
+
+// fill loads cfg from sources, in increasing precedence, resolves its secret
+// references and validates it.
+func fill(cfg settings, sources ...Source) []string {
+	problems := load(cfg, sources...)
+	secretProblems, files := resolveSecrets(cfg, os.LookupEnv)
+	cfg.common().files = append(cfg.common().files, files...)
+	problems = append(problems, secretProblems...)
+	return append(problems, cfg.validate()...)
+}
+
+func report(problems []string) error {
+	if len(problems) == 0 {
+		return nil
+	}
+	return &Error{Problems: problems}
+}
+
+// validate checks the values that depend on each other or on the formats of
+// other packages.
+func (c *Common) validate() []string {
+	var problems []string
+
+	if _, ok := i18n.Parse(c.DefaultLanguage); !ok {
+		problems = append(problems, "DEFAULT_LANGUAGE is not a supported language: "+c.DefaultLanguage)
+	}
+	if _, err := tlsconfig.ParseVersion(c.TLSMinVersion); err != nil {
+		problems = append(problems, "TLS_MIN_VERSION: "+err.Error())
+	}
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		problems = append(problems, "TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	}
+	if c.TLSClientCAFile != "" && c.TLSCertFile == "" {
+		problems = append(problems, "TLS_CLIENT_CA_FILE needs TLS_CERT_FILE and TLS_KEY_FILE")
+	}
+	if c.ServiceAuthKeys != "" {
+		if _, err := hmacauth.ParseKeys(c.ServiceAuthKeys); err != nil {
+			problems = append(problems, "SERVICE_AUTH_KEYS: "+err.Error())
+		}
+	}
+
+	return problems
+}
+
+func (c *ServiceA) validate() []string {
+	problems := c.Common.validate()
+
+	if (c.ServiceBCertFile == "") != (c.ServiceBKeyFile == "") {
+		problems = append(problems, "SERVICE_B_CLIENT_CERT_FILE and SERVICE_B_CLIENT_KEY_FILE must be set together")
+	}
+
+	for _, mode := range strings.Split(c.AuthMode, ",") {
+		switch strings.TrimSpace(mode) {
+		case "none", "":
+		case "apikey":
+			if c.APIKeys == "" && c.APIKeysFile == "" {
+				problems = append(problems, `AUTH_MODE "apikey" needs API_KEYS or API_KEYS_FILE`)
+			}
+		case "jwt":
+			if c.JWTIssuer == "" {
+				problems = append(problems, `AUTH_MODE "jwt" needs JWT_ISSUER`)
+			}
+			if c.JWTJWKSURL == nil && c.JWTPublicKeyFile == "" {
+				problems = append(problems, `AUTH_MODE "jwt" needs JWT_JWKS_URL or JWT_PUBLIC_KEY_FILE`)
+			}
+		default:
+			problems = append(problems, "AUTH_MODE has an unknown mode: "+strings.TrimSpace(mode))
+		}
+	}
+
+	limit := ratelimit.NewLimit(c.RateLimitRequests, c.RateLimitPeriod, c.RateLimitBurst)
+	if _, err := ratelimit.ParseOverrides(c.RateLimitClients, limit); err != nil {
+		problems = append(problems, "RATE_LIMIT_CLIENTS: "+err.Error())
+	}
+
+	return problems
+}
+
+func (c *ServiceB) validate() []string {
+	problems := c.Common.validate()
+
+	if _, err := temperature.ParseRoundingMode(c.TempRounding); err != nil {
+		problems = append(problems, "TEMPERATURE_ROUNDING: "+err.Error())
+	}
+	order, err := ParseProviderOrder(c.ProviderOrder)
+	if err != nil {
+		problems = append(problems, "WEATHER_PROVIDER_ORDER: "+err.Error())
+	} else if c.WeatherDegrade == "fallback" && !slices.Contains(order, "openmeteo") {
+		problems = append(problems, `WEATHER_API_DEGRADE_MODE "fallback" needs openmeteo in WEATHER_PROVIDER_ORDER`)
 	}
 
-	return config, nil
+	return problems
 }
 
-func getEnv(key, defaultValue string) string {
-	value := os.Getenv(key)
-	if value == "" {
-		return defaultValue
+// ParseProviderOrder parses the comma separated weather providers of
+// WEATHER_PROVIDER_ORDER, "weatherapi" and "openmeteo", in the order they are
+// asked.
+func ParseProviderOrder(value string) ([]string, error) {
+	var order []string
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		switch {
+		case name != "weatherapi" && name != "openmeteo":
+			return nil, fmt.Errorf("unknown weather provider %q", name)
+		case slices.Contains(order, name):
+			return nil, fmt.Errorf("weather provider %q is listed twice", name)
+		}
+		order = append(order, name)
 	}
-	return value
+	return order, nil
 }
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// ReadFile reads a YAML (.yaml, .yml) or TOML (.toml) config file and
+// flattens its sections into dotted keys: a port inside a server section is
+// returned as server.port. Lists of values are joined with commas, as the
+// environment variables write them.
+func ReadFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	document := make(map[string]interface{})
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &document)
+	case ".toml":
+		err = toml.Unmarshal(data, &document)
+	default:
+		return nil, fmt.Errorf("%s: unsupported config file format, expected .yaml, .yml or .toml", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	values := make(map[string]string)
+	if err := flatten("", document, values); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return values, nil
+}
+
+func flatten(prefix string, value interface{}, values map[string]string) error {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for name, child := range v {
+			if err := flatten(join(prefix, name), child, values); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			switch item.(type) {
+			case map[string]interface{}, []interface{}:
+				return fmt.Errorf("%s: lists may only hold plain values", prefix)
+			}
+			items = append(items, fmt.Sprint(item))
+		}
+		values[prefix] = strings.Join(items, ",")
+	case nil:
+		values[prefix] = ""
+	default:
+		if prefix == "" {
+			return fmt.Errorf("expected a document of sections")
+		}
+		values[prefix] = fmt.Sprint(v)
+	}
+	return nil
+}
+
+func join(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+// unknownKeys reports the keys in values that are not settings of cfg, so a
+// misspelled key is not silently ignored.
+func unknownKeys(cfg interface{}, values map[string]string) []string {
+	known := make(map[string]bool)
+	walk(reflect.ValueOf(cfg).Elem(), func(field reflect.StructField, _ reflect.Value) {
+		known[field.Tag.Get("key")] = true
+	})
+
+	var problems []string
+	for key := range values {
+		if !known[key] {
+			problems = append(problems, "config file has an unknown setting: "+key)
+		}
+	}
+	sort.Strings(problems)
+	return problems
+}
@@ -0,0 +1,169 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const yamlFile = `
+server:
+  port: 8181
+providers:
+  weatherapi:
+    key: file-key
+    degrade_mode: fallback
+cache:
+  history:
+    ttl: 1m
+temperature:
+  rounding: half_even
+`
+
+const tomlFile = `
+[server]
+port = 8181
+
+[providers.weatherapi]
+key = "file-key"
+degrade_mode = "fallback"
+
+[cache.history]
+ttl = "1m"
+
+[temperature]
+rounding = "half_even"
+`
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestReadFile(t *testing.T) {
+	expected := map[string]string{
+		"server.port":                       "8181",
+		"providers.weatherapi.key":          "file-key",
+		"providers.weatherapi.degrade_mode": "fallback",
+		"cache.history.ttl":                 "1m",
+		"temperature.rounding":              "half_even",
+	}
+
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{name: "YAML", file: "service-b.yaml", content: yamlFile},
+		{name: "YML", file: "service-b.yml", content: yamlFile},
+		{name: "TOML", file: "service-b.toml", content: tomlFile},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := ReadFile(writeFile(t, tt.file, tt.content))
+			if err != nil {
+				t.Fatalf("ReadFile() error = %v", err)
+			}
+			if !reflect.DeepEqual(values, expected) {
+				t.Errorf("Expected %v, got %v", expected, values)
+			}
+		})
+	}
+}
+
+func TestReadFile_Lists(t *testing.T) {
+	values, err := ReadFile(writeFile(t, "service-a.yaml", "auth:\n  mode: [apikey, jwt]\n"))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if values["auth.mode"] != "apikey,jwt" {
+		t.Errorf("Expected apikey,jwt, got %q", values["auth.mode"])
+	}
+}
+
+func TestReadFile_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{name: "Unsupported format", file: "service-b.json", content: `{}`},
+		{name: "Malformed YAML", file: "service-b.yaml", content: "server: [port"},
+		{name: "Malformed TOML", file: "service-b.toml", content: "[server"},
+		{name: "Nested list", file: "service-b.yaml", content: "auth:\n  mode:\n    - [apikey]\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ReadFile(writeFile(t, tt.file, tt.content)); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
+
+func TestUnknownKeys(t *testing.T) {
+	problems := unknownKeys(&ServiceB{}, map[string]string{
+		"server.port":       "8081",
+		"server.prot":       "8081",
+		"service_b.url":     "http://service-b:8081",
+		"cache.history.ttl": "1m",
+	})
+
+	expected := []string{
+		"config file has an unknown setting: server.prot",
+		"config file has an unknown setting: service_b.url",
+	}
+	if !reflect.DeepEqual(problems, expected) {
+		t.Errorf("Expected %v, got %v", expected, problems)
+	}
+}
+
+// TestPrecedence checks that flags win over the environment, which wins over
+// the config file, which wins over the defaults.
+func TestPrecedence(t *testing.T) {
+	values, err := ReadFile(writeFile(t, "service-b.yaml", yamlFile))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+
+	fs := flag.NewFlagSet("service-b", flag.ContinueOnError)
+	RegisterFlags(fs, &ServiceB{})
+	if err := fs.Parse([]string{"--server.port=9191", "--openapi.docs"}); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	cfg := &ServiceB{}
+	problems := fill(cfg, KeySource(values), envSource(map[string]string{
+		"SERVICE_AUTH_KEYS": "k1:secret",
+		"SERVICE_B_PORT":    "8282",
+		"HISTORY_CACHE_TTL": "2m",
+	}), flagSource(fs))
+	if len(problems) > 0 {
+		t.Fatalf("Unexpected problems: %v", problems)
+	}
+
+	if cfg.Port != 9191 {
+		t.Errorf("Expected the flag port 9191, got %d", cfg.Port)
+	}
+	if cfg.HistoryCacheTTL != 2*time.Minute {
+		t.Errorf("Expected the environment TTL 2m, got %s", cfg.HistoryCacheTTL)
+	}
+	if cfg.WeatherAPIKey != "file-key" || cfg.WeatherDegrade != "fallback" {
+		t.Errorf("Expected the file provider settings, got %q and %q", cfg.WeatherAPIKey, cfg.WeatherDegrade)
+	}
+	if !cfg.OpenAPIDocs {
+		t.Error("Expected the boolean flag to enable OpenAPI docs")
+	}
+	if cfg.AdminPort != 9091 {
+		t.Errorf("Expected the default admin port 9091, got %d", cfg.AdminPort)
+	}
+}
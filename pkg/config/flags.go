@@ -0,0 +1,71 @@
+package config
+
+import (
+	"flag"
+	"reflect"
+)
+
+// fileFlag names the flag, and fileEnv the variable, that point to the config
+// file. The flag wins when both are set.
+const (
+	fileFlag = "config"
+	fileEnv  = "CONFIG_FILE"
+)
+
+// RegisterFlags adds --config and a flag for each setting of cfg, a pointer
+// to a struct, to fs. Setting flags are named by their key, such as
+// --server.port.
+func RegisterFlags(fs *flag.FlagSet, cfg interface{}) {
+	fs.String(fileFlag, "", "path to a YAML or TOML config file (env "+fileEnv+")")
+
+	walk(reflect.ValueOf(cfg).Elem(), func(field reflect.StructField, value reflect.Value) {
+		usage := "overrides " + field.Tag.Get("env")
+		if def := field.Tag.Get("default"); def != "" {
+			usage += " (default " + def + ")"
+		}
+		fs.Var(&flagValue{boolean: value.Kind() == reflect.Bool}, field.Tag.Get("key"), usage)
+	})
+}
+
+// flagValue keeps the raw text of a setting flag, which is parsed and checked
+// with the values from the other sources.
+type flagValue struct {
+	value   string
+	boolean bool
+}
+
+func (f *flagValue) String() string {
+	if f == nil {
+		return ""
+	}
+	return f.value
+}
+
+func (f *flagValue) Set(value string) error {
+	f.value = value
+	return nil
+}
+
+func (f *flagValue) IsBoolFlag() bool {
+	return f.boolean
+}
+
+// flagSource returns the setting flags that were given on the command line.
+func flagSource(fs *flag.FlagSet) Source {
+	values := make(map[string]string)
+	fs.Visit(func(f *flag.Flag) {
+		if value, ok := f.Value.(*flagValue); ok {
+			values[f.Name] = value.value
+		}
+	})
+	return KeySource(values)
+}
+
+// configFile returns the config file named by --config or fileEnv.
+func configFile(fs *flag.FlagSet, lookup func(string) (string, bool)) string {
+	if f := fs.Lookup(fileFlag); f != nil && f.Value.String() != "" {
+		return f.Value.String()
+	}
+	path, _ := lookup(fileEnv)
+	return path
+}
@@ -0,0 +1,231 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Settings are declared as struct fields with tags:
+//
+//	key       the dotted name of the setting in a config file and on the
+//	          command line, such as server.port
+//	env       the variable the value is read from
+//	default   the value used when no source sets one
+//	required  "true" when the value must be set
+//	secret    "true" when the value is redacted by Print and may be a
+//	          reference to the secret, see SecretProvider
+//	min, max  bounds for integers and durations
+//	oneof     the comma separated values a string may take
+//	reload    "true" when a running service applies changes without a restart
+//
+// Fields without an env tag are not settings. Embedded structs are walked as
+// if their fields were declared in place.
+
+// Source looks up the raw value of a setting by its key or its environment
+// variable. An empty value counts as unset.
+type Source func(key, env string) (string, bool)
+
+// EnvSource reads settings through lookup, usually os.LookupEnv.
+func EnvSource(lookup func(string) (string, bool)) Source {
+	return func(_, env string) (string, bool) {
+		return lookup(env)
+	}
+}
+
+// KeySource reads settings from values indexed by key.
+func KeySource(values map[string]string) Source {
+	return func(key, _ string) (string, bool) {
+		value, ok := values[key]
+		return value, ok
+	}
+}
+
+// Error lists every problem found while loading a configuration.
+type Error struct {
+	Problems []string
+}
+
+func (e *Error) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	urlType      = reflect.TypeOf(&url.URL{})
+)
+
+// load fills the settings of cfg, a pointer to a struct, from sources given
+// in increasing precedence, and returns a problem for each value that is
+// missing or invalid. Nil sources are skipped.
+func load(cfg interface{}, sources ...Source) []string {
+	var problems []string
+
+	walk(reflect.ValueOf(cfg).Elem(), func(field reflect.StructField, value reflect.Value) {
+		key := field.Tag.Get("env")
+		raw := field.Tag.Get("default")
+		for _, source := range sources {
+			if source == nil {
+				continue
+			}
+			if sourced, _ := source(field.Tag.Get("key"), key); sourced != "" {
+				raw = sourced
+			}
+		}
+		if raw == "" {
+			if field.Tag.Get("required") == "true" {
+				problems = append(problems, key+" is required")
+			}
+			return
+		}
+
+		if err := set(value, raw); err != nil {
+			problems = append(problems, fmt.Sprintf("%s %v", key, err))
+			return
+		}
+		if err := check(field, value); err != nil {
+			problems = append(problems, fmt.Sprintf("%s %v", key, err))
+		}
+	})
+
+	return problems
+}
+
+func walk(v reflect.Value, fn func(field reflect.StructField, value reflect.Value)) {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			walk(v.Field(i), fn)
+			continue
+		}
+		if field.Tag.Get("env") == "" {
+			continue
+		}
+		fn(field, v.Field(i))
+	}
+}
+
+func set(value reflect.Value, raw string) error {
+	switch value.Type() {
+	case durationType:
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("must be a duration such as 30s or 5m, got %q", raw)
+		}
+		value.SetInt(int64(d))
+		return nil
+	case urlType:
+		u, err := url.Parse(raw)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("must be an absolute http or https URL, got %q", raw)
+		}
+		value.Set(reflect.ValueOf(u))
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(raw)
+	case reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("must be an integer, got %q", raw)
+		}
+		value.SetInt(int64(n))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("must be true or false, got %q", raw)
+		}
+		value.SetBool(b)
+	default:
+		panic(fmt.Sprintf("config: unsupported setting type %s", value.Type()))
+	}
+	return nil
+}
+
+// check applies the min, max and oneof tags of field to its loaded value.
+func check(field reflect.StructField, value reflect.Value) error {
+	if oneof := field.Tag.Get("oneof"); oneof != "" {
+		allowed := strings.Split(oneof, ",")
+		for _, option := range allowed {
+			if value.String() == option {
+				return nil
+			}
+		}
+		return fmt.Errorf("must be one of %s, got %q", strings.Join(allowed, ", "), value.String())
+	}
+
+	if value.Type() == durationType {
+		d := time.Duration(value.Int())
+		if min, ok := field.Tag.Lookup("min"); ok && d < mustParseDuration(min) {
+			return fmt.Errorf("must be at least %s, got %s", min, d)
+		}
+		if max, ok := field.Tag.Lookup("max"); ok && d > mustParseDuration(max) {
+			return fmt.Errorf("must be at most %s, got %s", max, d)
+		}
+		return nil
+	}
+
+	if value.Kind() == reflect.Int {
+		n := value.Int()
+		if min, ok := field.Tag.Lookup("min"); ok && n < mustParseInt(min) {
+			return fmt.Errorf("must be at least %s, got %d", min, n)
+		}
+		if max, ok := field.Tag.Lookup("max"); ok && n > mustParseInt(max) {
+			return fmt.Errorf("must be at most %s, got %d", max, n)
+		}
+	}
+	return nil
+}
+
+func mustParseDuration(value string) time.Duration {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		panic(fmt.Sprintf("config: invalid duration bound %q", value))
+	}
+	return d
+}
+
+func mustParseInt(value string) int64 {
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		panic(fmt.Sprintf("config: invalid integer bound %q", value))
+	}
+	return n
+}
+
+// Print writes the settings of cfg, a pointer to a struct, as KEY=value
+// lines in declaration order. Secrets that are set are replaced with
+// "[redacted]", as are passwords in URLs.
+func Print(w io.Writer, cfg interface{}) error {
+	var err error
+	walk(reflect.ValueOf(cfg).Elem(), func(field reflect.StructField, value reflect.Value) {
+		if err != nil {
+			return
+		}
+		_, err = fmt.Fprintf(w, "%s=%s\n", field.Tag.Get("env"), format(field, value))
+	})
+	return err
+}
+
+func format(field reflect.StructField, value reflect.Value) string {
+	if field.Tag.Get("secret") != "" && !value.IsZero() {
+		return "[redacted]"
+	}
+
+	switch value.Type() {
+	case durationType:
+		return time.Duration(value.Int()).String()
+	case urlType:
+		if value.IsNil() {
+			return ""
+		}
+		return value.Interface().(*url.URL).Redacted()
+	}
+	return fmt.Sprint(value.Interface())
+}
@@ -0,0 +1,166 @@
+package config
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"reflect"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"go-a-b-microservices/pkg/logger"
+)
+
+// Changes lists the environment variables of the settings that differ
+// between two configurations of the same type, split by whether a running
+// service applies them.
+func Changes(from, to interface{}) (reloadable, restart []string) {
+	var before []reflect.Value
+	walk(reflect.ValueOf(from).Elem(), func(_ reflect.StructField, value reflect.Value) {
+		before = append(before, value)
+	})
+
+	i := 0
+	walk(reflect.ValueOf(to).Elem(), func(field reflect.StructField, value reflect.Value) {
+		previous := before[i]
+		i++
+		if reflect.DeepEqual(previous.Interface(), value.Interface()) {
+			return
+		}
+		if field.Tag.Get("reload") == "true" {
+			reloadable = append(reloadable, field.Tag.Get("env"))
+		} else {
+			restart = append(restart, field.Tag.Get("env"))
+		}
+	})
+	return reloadable, restart
+}
+
+// Watched is a loaded configuration, such as *ServiceA, that knows the files
+// it was read from.
+type Watched interface {
+	Files() []string
+}
+
+// Reloader loads a configuration again and hands it to the service, which
+// applies the settings tagged reload:"true". A configuration that fails
+// validation is rejected and the running one is kept.
+//
+// The service applies a configuration in two steps so it never runs with
+// part of one: prepare does everything that can fail, such as parsing keys,
+// and returns the function that applies the result, which cannot fail. A
+// prepare error rejects the configuration like a validation error.
+type Reloader[C Watched] struct {
+	load    func() (C, error)
+	prepare func(C) (func(), error)
+	log     logger.Logger
+
+	mu      sync.Mutex
+	started C
+	current C
+}
+
+// NewReloader returns a Reloader for a service started with current.
+func NewReloader[C Watched](current C, load func() (C, error), prepare func(C) (func(), error), log logger.Logger) *Reloader[C] {
+	return &Reloader[C]{load: load, prepare: prepare, log: log, started: current, current: current}
+}
+
+// Reload loads and applies the configuration, logging the outcome. Settings
+// that need a restart are reported and left as they were.
+func (r *Reloader[C]) Reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	next, err := r.load()
+	if err != nil {
+		r.log.Error("Configuration reload rejected, keeping the running configuration: %v", err)
+		return err
+	}
+	apply, err := r.prepare(next)
+	if err != nil {
+		r.log.Error("Configuration reload rejected, keeping the running configuration: %v", err)
+		return err
+	}
+
+	applied, _ := Changes(r.current, next)
+	_, restart := Changes(r.started, next)
+
+	apply()
+	r.current = next
+
+	if len(applied) == 0 {
+		r.log.Info("Configuration reloaded, no setting changed")
+	} else {
+		r.log.Info("Configuration reloaded, applied %s", strings.Join(applied, ", "))
+	}
+	if len(restart) > 0 {
+		r.log.Error("Configuration changes need a restart to take effect: %s", strings.Join(restart, ", "))
+	}
+	return nil
+}
+
+// Watch reloads on SIGHUP and whenever one of the files of the running
+// configuration changes, checking them every interval, so an edited config
+// file or a rotated secret file is picked up. It returns when ctx is done.
+func (r *Reloader[C]) Watch(ctx context.Context, interval time.Duration) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	seen := make(map[string]fileState)
+	r.changed(seen)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hangup:
+			r.log.Info("Received SIGHUP, reloading configuration")
+			_ = r.Reload()
+		case <-ticker.C:
+			if file := r.changed(seen); file != "" {
+				r.log.Info("File %s changed, reloading configuration", file)
+				_ = r.Reload()
+			}
+		}
+	}
+}
+
+// fileState is what Watch compares to notice that a file changed.
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+func (s fileState) differs(other fileState) bool {
+	return !s.modTime.Equal(other.modTime) || s.size != other.size
+}
+
+// changed records the state of the files of the running configuration in
+// seen and returns the first one that differs from what was recorded. A file
+// seen for the first time is not a change, and a missing one, which may be
+// mid-replacement, is checked again on the next call.
+func (r *Reloader[C]) changed(seen map[string]fileState) string {
+	r.mu.Lock()
+	files := r.current.Files()
+	r.mu.Unlock()
+
+	changed := ""
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			continue
+		}
+		state := fileState{modTime: info.ModTime(), size: info.Size()}
+		if previous, ok := seen[file]; ok && previous.differs(state) && changed == "" {
+			changed = file
+		}
+		seen[file] = state
+	}
+	return changed
+}
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type MockLogger struct{}
+
+func (m *MockLogger) Info(message string, args ...interface{})  {}
+func (m *MockLogger) Error(message string, args ...interface{}) {}
+func (m *MockLogger) Debug(message string, args ...interface{}) {}
+
+func loadFile(path string) func() (*ServiceB, error) {
+	return func() (*ServiceB, error) {
+		cfg := &ServiceB{}
+		return cfg, report(fillLayers(cfg, path, envSource(map[string]string{
+			"SERVICE_AUTH_KEYS": "k1:secret",
+			"WEATHER_API_KEY":   "key",
+		})))
+	}
+}
+
+func TestChanges(t *testing.T) {
+	from := &ServiceB{Port: 8081, HistoryCacheTTL: time.Minute, Common: Common{LogLevel: "info"}}
+	to := &ServiceB{Port: 8082, HistoryCacheTTL: 2 * time.Minute, Common: Common{LogLevel: "debug"}}
+
+	reloadable, restart := Changes(from, to)
+	if !reflect.DeepEqual(reloadable, []string{"LOG_LEVEL", "HISTORY_CACHE_TTL"}) {
+		t.Errorf("Unexpected reloadable changes %v", reloadable)
+	}
+	if !reflect.DeepEqual(restart, []string{"SERVICE_B_PORT"}) {
+		t.Errorf("Unexpected restart changes %v", restart)
+	}
+}
+
+func TestReloader_Reload(t *testing.T) {
+	path := writeFile(t, "service-b.yaml", "cache:\n  history:\n    ttl: 1m\n")
+	load := loadFile(path)
+	current, err := load()
+	if err != nil {
+		t.Fatalf("load() error = %v", err)
+	}
+
+	var applied []*ServiceB
+	reloader := NewReloader(current, load, func(next *ServiceB) (func(), error) {
+		if next.HistoryCacheTTL > time.Hour {
+			return nil, errors.New("too long")
+		}
+		return func() { applied = append(applied, next) }, nil
+	}, &MockLogger{})
+
+	if err := os.WriteFile(path, []byte("cache:\n  history:\n    ttl: 5m\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := reloader.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if len(applied) != 1 || applied[0].HistoryCacheTTL != 5*time.Minute {
+		t.Fatalf("Expected the new TTL to be applied, got %v", applied)
+	}
+
+	if err := os.WriteFile(path, []byte("cache:\n  history:\n    ttl: soon\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := reloader.Reload(); err == nil {
+		t.Fatal("Expected an invalid configuration to be rejected")
+	}
+	if len(applied) != 1 {
+		t.Errorf("Expected the rejected configuration not to be applied, got %d applies", len(applied))
+	}
+	if reloader.current.HistoryCacheTTL != 5*time.Minute {
+		t.Errorf("Expected the running configuration to be kept, got TTL %s", reloader.current.HistoryCacheTTL)
+	}
+
+	if err := os.WriteFile(path, []byte("cache:\n  history:\n    ttl: 2h\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := reloader.Reload(); err == nil {
+		t.Fatal("Expected a configuration the service cannot prepare to be rejected")
+	}
+	if len(applied) != 1 || reloader.current.HistoryCacheTTL != 5*time.Minute {
+		t.Errorf("Expected the running configuration to be kept, got %d applies and TTL %s", len(applied), reloader.current.HistoryCacheTTL)
+	}
+}
+
+func TestReloader_Watch(t *testing.T) {
+	path := writeFile(t, "service-b.yaml", "log:\n  level: info\n")
+	load := loadFile(path)
+	current, err := load()
+	if err != nil {
+		t.Fatalf("load() error = %v", err)
+	}
+
+	applied := make(chan *ServiceB, 1)
+	reloader := NewReloader(current, load, func(next *ServiceB) (func(), error) {
+		return func() { applied <- next }, nil
+	}, &MockLogger{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go reloader.Watch(ctx, 10*time.Millisecond)
+
+	// Give the watcher time to record the file as it is now, and make sure the
+	// rewrite gets a different modification time.
+	time.Sleep(50 * time.Millisecond)
+	if err := os.WriteFile(path, []byte("log:\n  level: debug\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Second)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case next := <-applied:
+		if next.LogLevel != "debug" {
+			t.Errorf("Expected log level debug, got %q", next.LogLevel)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the file change to trigger a reload")
+	}
+}
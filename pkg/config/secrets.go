@@ -0,0 +1,229 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// A setting tagged secret:"true" may hold a reference to the secret instead
+// of the secret itself:
+//
+//	file:///run/secrets/weather_key  the contents of a file, without the
+//	                                 trailing newline
+//	env:WEATHER_KEY                  another environment variable
+//	secret:weatherapi.key            a key of the local store in SECRETS_FILE
+//
+// Other schemes resolve through the providers added with
+// RegisterSecretProvider, such as a Vault client. A value that does not start
+// with a scheme is the secret itself, and one that starts with an unknown
+// scheme is rejected rather than used as a secret.
+//
+// Settings tagged secret:"list" hold comma separated id:secret pairs, where a
+// colon is not a scheme. The whole list may still be a reference, but then it
+// is a single entry starting with a known scheme, so no id may be named after
+// one.
+
+// SecretProvider resolves references to secrets kept in a secret manager.
+type SecretProvider interface {
+	// Secret returns the secret ref names. ref is the reference without its
+	// scheme: kv/weather#api_key for vault:kv/weather#api_key.
+	Secret(ref string) (string, error)
+}
+
+// secretSchemes are the schemes resolved by this package.
+var secretSchemes = []string{"file", "env", "secret"}
+
+var (
+	providersMu sync.RWMutex
+	providers   = make(map[string]SecretProvider)
+)
+
+// RegisterSecretProvider resolves references with scheme through provider.
+// Call it before the configuration is loaded, usually from main. It panics if
+// the scheme is already handled.
+func RegisterSecretProvider(scheme string, provider SecretProvider) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+
+	for _, builtin := range secretSchemes {
+		if scheme == builtin {
+			panic("config: secret scheme " + scheme + " is built in")
+		}
+	}
+	if _, ok := providers[scheme]; ok {
+		panic("config: secret scheme " + scheme + " registered twice")
+	}
+	providers[scheme] = provider
+}
+
+// FileStore is a SecretProvider backed by a local YAML or TOML file, read as
+// ReadFile reads config files, with references naming dotted keys such as
+// weatherapi.key. It stands in for a secret manager in development and in
+// deployments that mount secrets as one file. The file is read on every
+// lookup so a rotated secret is picked up on the next reload.
+type FileStore struct {
+	Path string
+}
+
+func (s FileStore) Secret(ref string) (string, error) {
+	values, err := ReadFile(s.Path)
+	if err != nil {
+		var pathErr *fs.PathError
+		if errors.As(err, &pathErr) {
+			return "", err
+		}
+		// Parse errors may quote the file, and so a secret.
+		return "", fmt.Errorf("%s is not a valid YAML or TOML secrets file", s.Path)
+	}
+	value, ok := values[ref]
+	if !ok {
+		return "", fmt.Errorf("%s has no secret %s", s.Path, ref)
+	}
+	return value, nil
+}
+
+// secretResolver resolves the references of one load, recording the files it
+// reads so they can be watched for rotation.
+type secretResolver struct {
+	lookup func(string) (string, bool)
+	store  string
+	files  []string
+}
+
+var schemePattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.-]*$`)
+
+// provider returns the provider registered for scheme.
+func provider(scheme string) (SecretProvider, bool) {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+
+	provider, ok := providers[scheme]
+	return provider, ok
+}
+
+func knownScheme(scheme string) bool {
+	_, ok := provider(scheme)
+	return ok || slices.Contains(secretSchemes, scheme)
+}
+
+// resolveList resolves a list of id:secret pairs, which is a reference only
+// as a whole.
+func (r *secretResolver) resolveList(value string) (string, error) {
+	entries := strings.Split(value, ",")
+	if scheme, _, ok := strings.Cut(value, ":"); ok && len(entries) == 1 && knownScheme(scheme) {
+		return r.resolve(value)
+	}
+
+	for i, entry := range entries {
+		id, _, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if ok && knownScheme(id) {
+			return "", fmt.Errorf("entry %d is named after the secret scheme %s, rename it", i+1, id)
+		}
+	}
+	return value, nil
+}
+
+// resolve returns the secret value refers to, or value itself when it is not
+// a reference. Errors never include the secret.
+func (r *secretResolver) resolve(value string) (string, error) {
+	scheme, ref, ok := strings.Cut(value, ":")
+	if !ok || !schemePattern.MatchString(scheme) {
+		return value, nil
+	}
+
+	var secret string
+	var err error
+	switch scheme {
+	case "file":
+		secret, err = r.file(value)
+	case "env":
+		secret, err = r.env(ref)
+	case "secret":
+		secret, err = r.fromStore(ref)
+	default:
+		provider, registered := provider(scheme)
+		if !registered {
+			// A secret that starts like a reference, such as a password
+			// with a colon, can be kept behind env: or file: instead.
+			return "", fmt.Errorf("no secret provider for %s: references", scheme)
+		}
+		secret, err = provider.Secret(ref)
+	}
+	if err != nil {
+		return "", err
+	}
+	if secret == "" {
+		return "", fmt.Errorf("%s: reference resolved to an empty value", scheme)
+	}
+	return secret, nil
+}
+
+func (r *secretResolver) file(value string) (string, error) {
+	u, err := url.Parse(value)
+	if err != nil || u.Host != "" || u.Path == "" {
+		return "", fmt.Errorf("file: references must be absolute, such as file:///run/secrets/name")
+	}
+
+	data, err := os.ReadFile(u.Path)
+	if err != nil {
+		return "", err
+	}
+	r.files = append(r.files, u.Path)
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+func (r *secretResolver) env(name string) (string, error) {
+	value, ok := r.lookup(name)
+	if !ok || value == "" {
+		return "", fmt.Errorf("environment variable %s is not set", name)
+	}
+	return value, nil
+}
+
+func (r *secretResolver) fromStore(key string) (string, error) {
+	if r.store == "" {
+		return "", fmt.Errorf("secret: references need SECRETS_FILE")
+	}
+
+	secret, err := FileStore{Path: r.store}.Secret(key)
+	if err != nil {
+		return "", err
+	}
+	r.files = append(r.files, r.store)
+	return secret, nil
+}
+
+// resolveSecrets replaces the references held by the secret settings of cfg
+// with the secrets they name, and returns a problem for each that cannot be
+// resolved along with the files read.
+func resolveSecrets(cfg settings, lookup func(string) (string, bool)) ([]string, []string) {
+	resolver := &secretResolver{lookup: lookup, store: cfg.common().SecretsFile}
+
+	var problems []string
+	walk(reflect.ValueOf(cfg).Elem(), func(field reflect.StructField, value reflect.Value) {
+		tag := field.Tag.Get("secret")
+		if (tag != "true" && tag != "list") || value.String() == "" {
+			return
+		}
+		resolve := resolver.resolve
+		if tag == "list" {
+			resolve = resolver.resolveList
+		}
+		secret, err := resolve(value.String())
+		if err != nil {
+			problems = append(problems, field.Tag.Get("env")+": "+err.Error())
+			return
+		}
+		value.SetString(secret)
+	})
+
+	return problems, resolver.files
+}
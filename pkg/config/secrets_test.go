@@ -0,0 +1,248 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type mapProvider map[string]string
+
+func (p mapProvider) Secret(ref string) (string, error) {
+	value, ok := p[ref]
+	if !ok {
+		return "", errors.New("not found")
+	}
+	return value, nil
+}
+
+func TestResolveSecrets(t *testing.T) {
+	keyFile := writeFile(t, "weather_key", "file-key\n")
+	emptyFile := writeFile(t, "empty_key", "\n")
+	store := writeFile(t, "secrets.yaml", "weatherapi:\n  key: store-key\nservice_auth:\n  keys: k1:store-secret\n")
+
+	t.Setenv("REAL_WEATHER_KEY", "env-key")
+	providers["test-vault"] = mapProvider{"kv/weather#api_key": "vault-key"}
+	t.Cleanup(func() { delete(providers, "test-vault") })
+
+	tests := []struct {
+		name             string
+		env              map[string]string
+		expectedKey      string
+		expectedProblems []string
+	}{
+		{name: "Plain value", env: map[string]string{"WEATHER_API_KEY": "plain-key"}, expectedKey: "plain-key"},
+		{name: "File", env: map[string]string{"WEATHER_API_KEY": "file://" + keyFile}, expectedKey: "file-key"},
+		{name: "Environment", env: map[string]string{"WEATHER_API_KEY": "env:REAL_WEATHER_KEY"}, expectedKey: "env-key"},
+		{
+			name:        "Local store",
+			env:         map[string]string{"WEATHER_API_KEY": "secret:weatherapi.key", "SECRETS_FILE": store},
+			expectedKey: "store-key",
+		},
+		{name: "Registered provider", env: map[string]string{"WEATHER_API_KEY": "test-vault:kv/weather#api_key"}, expectedKey: "vault-key"},
+		{
+			name:             "Missing file",
+			env:              map[string]string{"WEATHER_API_KEY": "file:///nonexistent/weather_key"},
+			expectedProblems: []string{"WEATHER_API_KEY: open /nonexistent/weather_key: no such file or directory"},
+		},
+		{
+			name:             "Relative file",
+			env:              map[string]string{"WEATHER_API_KEY": "file://weather_key"},
+			expectedProblems: []string{"WEATHER_API_KEY: file: references must be absolute, such as file:///run/secrets/name"},
+		},
+		{
+			name:             "Empty file",
+			env:              map[string]string{"WEATHER_API_KEY": "file://" + emptyFile},
+			expectedProblems: []string{"WEATHER_API_KEY: file: reference resolved to an empty value"},
+		},
+		{
+			name:             "Unset variable",
+			env:              map[string]string{"WEATHER_API_KEY": "env:MISSING_WEATHER_KEY"},
+			expectedProblems: []string{"WEATHER_API_KEY: environment variable MISSING_WEATHER_KEY is not set"},
+		},
+		{
+			name:             "Store without SECRETS_FILE",
+			env:              map[string]string{"WEATHER_API_KEY": "secret:weatherapi.key"},
+			expectedProblems: []string{"WEATHER_API_KEY: secret: references need SECRETS_FILE"},
+		},
+		{
+			name:             "Missing store key",
+			env:              map[string]string{"WEATHER_API_KEY": "secret:weatherapi.token", "SECRETS_FILE": store},
+			expectedProblems: []string{"WEATHER_API_KEY: " + store + " has no secret weatherapi.token"},
+		},
+		{
+			name:             "Unknown provider",
+			env:              map[string]string{"WEATHER_API_KEY": "vault://kv/weather#api_key"},
+			expectedProblems: []string{"WEATHER_API_KEY: no secret provider for vault: references"},
+		},
+		{
+			name:             "Unknown scheme",
+			env:              map[string]string{"WEATHER_API_KEY": "vualt:kv/weather#api_key"},
+			expectedProblems: []string{"WEATHER_API_KEY: no secret provider for vualt: references"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.env["SERVICE_AUTH_KEYS"] = "k1:secret"
+			cfg := &ServiceB{}
+			problems := fill(cfg, envSource(tt.env))
+			if !reflect.DeepEqual(problems, tt.expectedProblems) {
+				t.Fatalf("Expected problems:\n%s\ngot:\n%s", strings.Join(tt.expectedProblems, "\n"), strings.Join(problems, "\n"))
+			}
+			if len(problems) == 0 && cfg.WeatherAPIKey != tt.expectedKey {
+				t.Errorf("Expected key %q, got %q", tt.expectedKey, cfg.WeatherAPIKey)
+			}
+		})
+	}
+}
+
+// TestResolveSecrets_OnlySecrets checks that references are only resolved in
+// secret settings, and that plain values with a colon are kept.
+func TestResolveSecrets_OnlySecrets(t *testing.T) {
+	cfg := &ServiceB{}
+	problems := fill(cfg, envSource(map[string]string{
+		"SERVICE_AUTH_KEYS":       "k1:secret,k2:other",
+		"WEATHER_API_KEY":         "key",
+		"WEATHER_API_BUDGET_FILE": "env:HOME",
+	}))
+	if len(problems) > 0 {
+		t.Fatalf("Unexpected problems: %v", problems)
+	}
+	if cfg.ServiceAuthKeys != "k1:secret,k2:other" {
+		t.Errorf("Expected the keys to be kept, got %q", cfg.ServiceAuthKeys)
+	}
+	if cfg.WeatherBudgetFile != "env:HOME" {
+		t.Errorf("Expected a setting that is not secret to be kept, got %q", cfg.WeatherBudgetFile)
+	}
+}
+
+func TestResolveSecrets_Lists(t *testing.T) {
+	store := writeFile(t, "secrets.yaml", "service_auth:\n  keys: k1:store-secret,k2:other\n")
+
+	tests := []struct {
+		name             string
+		keys             string
+		expectedKeys     string
+		expectedProblems []string
+	}{
+		{name: "Plain list", keys: "k1:secret", expectedKeys: "k1:secret"},
+		{name: "Secret with a colon", keys: "k1:vault:secret", expectedKeys: "k1:vault:secret"},
+		{name: "Reference", keys: "secret:service_auth.keys", expectedKeys: "k1:store-secret,k2:other"},
+		{
+			name:             "Id named after a scheme",
+			keys:             "k1:secret, env:other",
+			expectedProblems: []string{"SERVICE_AUTH_KEYS: entry 2 is named after the secret scheme env, rename it"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &ServiceB{}
+			problems := fill(cfg, envSource(map[string]string{
+				"SERVICE_AUTH_KEYS": tt.keys,
+				"WEATHER_API_KEY":   "key",
+				"SECRETS_FILE":      store,
+			}))
+			if !reflect.DeepEqual(problems, tt.expectedProblems) {
+				t.Fatalf("Expected problems:\n%s\ngot:\n%s", strings.Join(tt.expectedProblems, "\n"), strings.Join(problems, "\n"))
+			}
+			if len(problems) == 0 && cfg.ServiceAuthKeys != tt.expectedKeys {
+				t.Errorf("Expected keys %q, got %q", tt.expectedKeys, cfg.ServiceAuthKeys)
+			}
+		})
+	}
+}
+
+func TestResolveSecrets_NotPrinted(t *testing.T) {
+	keyFile := writeFile(t, "service_auth_keys", "k1:super-secret\n")
+	store := writeFile(t, "secrets.toml", "[weatherapi]\nkey = \"store-key\n")
+
+	cfg := &ServiceB{}
+	problems := fill(cfg, envSource(map[string]string{
+		"SERVICE_AUTH_KEYS": "file://" + keyFile,
+		"WEATHER_API_KEY":   "secret:weatherapi.key",
+		"SECRETS_FILE":      store,
+	}))
+	if cfg.ServiceAuthKeys != "k1:super-secret" {
+		t.Fatalf("Expected the keys from the file, got %q", cfg.ServiceAuthKeys)
+	}
+
+	expected := []string{"WEATHER_API_KEY: " + store + " is not a valid YAML or TOML secrets file"}
+	if !reflect.DeepEqual(problems, expected) {
+		t.Errorf("Expected %v, got %v", expected, problems)
+	}
+
+	var out bytes.Buffer
+	if err := Print(&out, cfg); err != nil {
+		t.Fatalf("Print() error = %v", err)
+	}
+	for _, secret := range []string{"super-secret", "store-key"} {
+		if strings.Contains(out.String()+strings.Join(problems, "\n"), secret) {
+			t.Errorf("Expected %s not to be printed", secret)
+		}
+	}
+}
+
+func TestReloader_Watch_RotatedSecret(t *testing.T) {
+	keyFile := writeFile(t, "weather_key", "old-key\n")
+	load := func() (*ServiceB, error) {
+		cfg := &ServiceB{}
+		return cfg, report(fill(cfg, envSource(map[string]string{
+			"SERVICE_AUTH_KEYS": "k1:secret",
+			"WEATHER_API_KEY":   "file://" + keyFile,
+		})))
+	}
+	current, err := load()
+	if err != nil {
+		t.Fatalf("load() error = %v", err)
+	}
+	if !reflect.DeepEqual(current.Files(), []string{keyFile}) {
+		t.Fatalf("Expected the secret file to be watched, got %v", current.Files())
+	}
+
+	applied := make(chan *ServiceB, 1)
+	reloader := NewReloader(current, load, func(next *ServiceB) (func(), error) {
+		return func() { applied <- next }, nil
+	}, &MockLogger{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go reloader.Watch(ctx, 10*time.Millisecond)
+
+	time.Sleep(50 * time.Millisecond)
+	if err := os.WriteFile(keyFile, []byte("new-key\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Second)
+	if err := os.Chtimes(keyFile, later, later); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case next := <-applied:
+		if next.WeatherAPIKey != "new-key" {
+			t.Errorf("Expected the rotated key, got %q", next.WeatherAPIKey)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the rotated secret to trigger a reload")
+	}
+}
+
+func TestRegisterSecretProvider(t *testing.T) {
+	for _, scheme := range []string{"file", "env", "secret"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected registering %s to panic", scheme)
+				}
+			}()
+			RegisterSecretProvider(scheme, mapProvider{})
+		}()
+	}
+}
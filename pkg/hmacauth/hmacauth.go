@@ -0,0 +1,289 @@
+// Package hmacauth signs and verifies service-to-service HTTP requests with
+// shared HMAC-SHA256 secrets.
+//
+// A signature covers the method, the request URI, a Unix timestamp, a random
+// nonce and the SHA-256 of the body. The verifier accepts each nonce once
+// while its timestamp is in range, so a captured request cannot be replayed
+// to it. Keys carry an ID so several can be valid at once: the signer always
+// uses the first key of its keyring and the verifier accepts any key it knows,
+// which lets a new key be rolled out before the old one is retired.
+package hmacauth
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go-a-b-microservices/pkg/keylist"
+)
+
+const (
+	HeaderKeyID     = "X-Signature-Key-Id"
+	HeaderTimestamp = "X-Signature-Timestamp"
+	HeaderNonce     = "X-Signature-Nonce"
+	HeaderSignature = "X-Signature"
+
+	// DefaultMaxSkew bounds how old (or how far in the future) a signed
+	// request may be, and so how long its nonce is remembered.
+	DefaultMaxSkew = 5 * time.Minute
+
+	// DefaultMaxBodyBytes bounds the body read to verify a request.
+	DefaultMaxBodyBytes = 1 << 20
+)
+
+var (
+	ErrMissingSignature = errors.New("request is not signed")
+	ErrUnknownKey       = errors.New("unknown signing key")
+	ErrExpired          = errors.New("signature timestamp out of range")
+	ErrInvalidSignature = errors.New("invalid signature")
+	ErrReplayed         = errors.New("signature nonce already used")
+)
+
+type Key struct {
+	ID     string
+	Secret []byte
+}
+
+// ParseKeys parses comma separated "id:secret" pairs, keeping their order. It
+// returns at least one key, so its result can always be set.
+func ParseKeys(value string) ([]Key, error) {
+	entries, err := keylist.Parse(value, "signing key", keylist.ByID)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, errors.New("no signing keys configured")
+	}
+
+	keys := make([]Key, 0, len(entries))
+	for _, entry := range entries {
+		keys = append(keys, Key{ID: entry.ID, Secret: []byte(entry.Secret)})
+	}
+	return keys, nil
+}
+
+type Signer struct {
+	key atomic.Pointer[Key]
+	now func() time.Time
+}
+
+// NewSigner signs with the first key, the one currently active.
+func NewSigner(keys []Key) (*Signer, error) {
+	s := &Signer{now: time.Now}
+	if err := s.SetKeys(keys); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// SetKeys switches to the first of keys, as NewSigner picks it. It is safe to
+// call while the signer is in use.
+func (s *Signer) SetKeys(keys []Key) error {
+	if len(keys) == 0 {
+		return errors.New("no signing keys configured")
+	}
+	key := keys[0]
+	s.key.Store(&key)
+	return nil
+}
+
+// Sign adds the signature headers to req for the given body, which must be
+// the exact bytes sent.
+func (s *Signer) Sign(req *http.Request, body []byte) {
+	signature := s.SignValues(req.Method, req.URL.RequestURI(), body)
+
+	req.Header.Set(HeaderKeyID, signature.KeyID)
+	req.Header.Set(HeaderTimestamp, signature.Timestamp)
+	req.Header.Set(HeaderNonce, signature.Nonce)
+	req.Header.Set(HeaderSignature, signature.Signature)
+}
+
+// Signature holds the values a signed request carries, for transports other
+// than plain HTTP requests.
+type Signature struct {
+	KeyID     string
+	Timestamp string
+	Nonce     string
+	Signature string
+}
+
+// SignValues signs a request with a new nonce, so each call gives a
+// signature the verifier accepts once.
+func (s *Signer) SignValues(method, uri string, body []byte) Signature {
+	key := s.key.Load()
+	timestamp := strconv.FormatInt(s.now().Unix(), 10)
+	nonce := make([]byte, 16)
+	rand.Read(nonce)
+	signature := Signature{
+		KeyID:     key.ID,
+		Timestamp: timestamp,
+		Nonce:     hex.EncodeToString(nonce),
+	}
+	signature.Signature = hex.EncodeToString(sign(key.Secret, method, uri, signature.Timestamp, signature.Nonce, body))
+	return signature
+}
+
+type Verifier struct {
+	keys         atomic.Pointer[map[string][]byte]
+	maxSkew      time.Duration
+	maxBodyBytes atomic.Int64
+	nonces       *nonceSet
+	now          func() time.Time
+}
+
+func NewVerifier(keys []Key, maxSkew time.Duration) (*Verifier, error) {
+	if maxSkew <= 0 {
+		maxSkew = DefaultMaxSkew
+	}
+
+	v := &Verifier{maxSkew: maxSkew, nonces: newNonceSet(), now: time.Now}
+	v.SetMaxBodyBytes(DefaultMaxBodyBytes)
+	if err := v.SetKeys(keys); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// SetMaxBodyBytes sets the size limit of the bodies Verify reads, also while
+// the verifier is in use.
+func (v *Verifier) SetMaxBodyBytes(maxBytes int64) {
+	v.maxBodyBytes.Store(maxBytes)
+}
+
+// SetKeys replaces the accepted keys. It is safe to call while the verifier
+// is in use.
+func (v *Verifier) SetKeys(keys []Key) error {
+	if len(keys) == 0 {
+		return errors.New("no signing keys configured")
+	}
+
+	byID := make(map[string][]byte, len(keys))
+	for _, key := range keys {
+		byID[key.ID] = key.Secret
+	}
+	v.keys.Store(&byID)
+	return nil
+}
+
+// Verify checks the signature of req and returns the ID of the key that
+// signed it. The body is read and replaced so handlers can still decode it; a
+// body over the size limit gives an *http.MaxBytesError.
+func (v *Verifier) Verify(req *http.Request) (string, error) {
+	signature := Signature{
+		KeyID:     req.Header.Get(HeaderKeyID),
+		Timestamp: req.Header.Get(HeaderTimestamp),
+		Nonce:     req.Header.Get(HeaderNonce),
+		Signature: req.Header.Get(HeaderSignature),
+	}
+	if signature.KeyID == "" || signature.Timestamp == "" || signature.Nonce == "" || signature.Signature == "" {
+		return "", ErrMissingSignature
+	}
+
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(http.MaxBytesReader(nil, req.Body, v.maxBodyBytes.Load()))
+		if err != nil {
+			return "", err
+		}
+		req.Body.Close()
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+
+	if err := v.VerifyValues(signature, req.Method, req.URL.RequestURI(), body); err != nil {
+		return "", err
+	}
+	return signature.KeyID, nil
+}
+
+// VerifyValues checks a signature and records its nonce, so the same
+// signature is rejected with ErrReplayed from then on.
+func (v *Verifier) VerifyValues(signature Signature, method, uri string, body []byte) error {
+	if signature.KeyID == "" || signature.Timestamp == "" || signature.Nonce == "" || signature.Signature == "" {
+		return ErrMissingSignature
+	}
+
+	secret, ok := (*v.keys.Load())[signature.KeyID]
+	if !ok {
+		return ErrUnknownKey
+	}
+
+	seconds, err := strconv.ParseInt(signature.Timestamp, 10, 64)
+	if err != nil {
+		return ErrExpired
+	}
+	now := v.now()
+	signedAt := time.Unix(seconds, 0)
+	age := now.Sub(signedAt)
+	if age > v.maxSkew || age < -v.maxSkew {
+		return ErrExpired
+	}
+
+	expected, err := hex.DecodeString(signature.Signature)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	if !hmac.Equal(expected, sign(secret, method, uri, signature.Timestamp, signature.Nonce, body)) {
+		return ErrInvalidSignature
+	}
+
+	// Only signed nonces are recorded, so they cannot be made up to fill the
+	// set. Once the timestamp is out of range the nonce is not needed.
+	if !v.nonces.add(signature.KeyID+":"+signature.Nonce, signedAt.Add(v.maxSkew), now) {
+		return ErrReplayed
+	}
+
+	return nil
+}
+
+func sign(secret []byte, method, uri, timestamp, nonce string, body []byte) []byte {
+	bodyHash := sha256.Sum256(body)
+
+	mac := hmac.New(sha256.New, secret)
+	fmt.Fprintf(mac, "%s\n%s\n%s\n%s\n%s", method, uri, timestamp, nonce, hex.EncodeToString(bodyHash[:]))
+	return mac.Sum(nil)
+}
+
+// nonceSet remembers nonces until they expire.
+type nonceSet struct {
+	mu        sync.Mutex
+	expiresAt map[string]time.Time
+	sweptAt   time.Time
+}
+
+func newNonceSet() *nonceSet {
+	return &nonceSet{expiresAt: make(map[string]time.Time)}
+}
+
+// add records nonce until expiresAt, reporting false if it is already
+// recorded. Expired nonces are dropped once a second at most.
+func (s *nonceSet) add(nonce string, expiresAt, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.sweptAt) >= time.Second {
+		for seen, expiry := range s.expiresAt {
+			if now.After(expiry) {
+				delete(s.expiresAt, seen)
+			}
+		}
+		s.sweptAt = now
+	}
+
+	if expiry, ok := s.expiresAt[nonce]; ok && !now.After(expiry) {
+		return false
+	}
+	s.expiresAt[nonce] = expiresAt
+	return true
+}
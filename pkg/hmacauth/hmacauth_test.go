@@ -0,0 +1,265 @@
+package hmacauth
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type MockLogger struct{}
+
+func (m *MockLogger) Info(message string, args ...interface{})  {}
+func (m *MockLogger) Error(message string, args ...interface{}) {}
+func (m *MockLogger) Debug(message string, args ...interface{}) {}
+
+func TestParseKeys(t *testing.T) {
+	keys, err := ParseKeys("2024-06:new-secret, 2024-01:old-secret")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(keys) != 2 || keys[0].ID != "2024-06" || string(keys[1].Secret) != "old-secret" {
+		t.Errorf("Unexpected keys %+v", keys)
+	}
+
+	for _, value := range []string{"missing-secret", ":secret", "a:1,a:2", " , "} {
+		if _, err := ParseKeys(value); err == nil {
+			t.Errorf("Expected an error for %q", value)
+		}
+	}
+}
+
+func signedRequest(t *testing.T, keys []Key, now time.Time, body string) *http.Request {
+	t.Helper()
+
+	signer, err := NewSigner(keys)
+	if err != nil {
+		t.Fatalf("Failed to build signer: %v", err)
+	}
+	signer.now = func() time.Time { return now }
+
+	req := httptest.NewRequest(http.MethodPost, "/weather?units=C", bytes.NewBufferString(body))
+	signer.Sign(req, []byte(body))
+	return req
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	now := time.Now()
+	oldKey := Key{ID: "old", Secret: []byte("old-secret")}
+	newKey := Key{ID: "new", Secret: []byte("new-secret")}
+
+	verifier, err := NewVerifier([]Key{newKey, oldKey}, time.Minute)
+	if err != nil {
+		t.Fatalf("Failed to build verifier: %v", err)
+	}
+	verifier.now = func() time.Time { return now }
+
+	tests := []struct {
+		name          string
+		request       func() *http.Request
+		expectedKeyID string
+		expectedErr   error
+	}{
+		{
+			name:          "active key",
+			request:       func() *http.Request { return signedRequest(t, []Key{newKey}, now, `{"cep":"01001000"}`) },
+			expectedKeyID: "new",
+		},
+		{
+			name:          "previous key during rotation",
+			request:       func() *http.Request { return signedRequest(t, []Key{oldKey, newKey}, now, `{"cep":"01001000"}`) },
+			expectedKeyID: "old",
+		},
+		{
+			name:        "unsigned",
+			request:     func() *http.Request { return httptest.NewRequest(http.MethodPost, "/weather", nil) },
+			expectedErr: ErrMissingSignature,
+		},
+		{
+			name: "retired key",
+			request: func() *http.Request {
+				return signedRequest(t, []Key{{ID: "retired", Secret: []byte("x")}}, now, "{}")
+			},
+			expectedErr: ErrUnknownKey,
+		},
+		{
+			name:        "stale timestamp",
+			request:     func() *http.Request { return signedRequest(t, []Key{newKey}, now.Add(-2*time.Minute), "{}") },
+			expectedErr: ErrExpired,
+		},
+		{
+			name: "tampered body",
+			request: func() *http.Request {
+				req := signedRequest(t, []Key{newKey}, now, `{"cep":"01001000"}`)
+				req.Body = io.NopCloser(bytes.NewBufferString(`{"cep":"99999999"}`))
+				return req
+			},
+			expectedErr: ErrInvalidSignature,
+		},
+		{
+			name: "tampered query",
+			request: func() *http.Request {
+				req := signedRequest(t, []Key{newKey}, now, "{}")
+				req.URL.RawQuery = "units=F"
+				return req
+			},
+			expectedErr: ErrInvalidSignature,
+		},
+		{
+			name: "missing nonce",
+			request: func() *http.Request {
+				req := signedRequest(t, []Key{newKey}, now, "{}")
+				req.Header.Del(HeaderNonce)
+				return req
+			},
+			expectedErr: ErrMissingSignature,
+		},
+		{
+			name: "tampered nonce",
+			request: func() *http.Request {
+				req := signedRequest(t, []Key{newKey}, now, "{}")
+				req.Header.Set(HeaderNonce, "0123456789abcdef")
+				return req
+			},
+			expectedErr: ErrInvalidSignature,
+		},
+		{
+			name: "wrong secret",
+			request: func() *http.Request {
+				return signedRequest(t, []Key{{ID: "new", Secret: []byte("guess")}}, now, "{}")
+			},
+			expectedErr: ErrInvalidSignature,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keyID, err := verifier.Verify(tt.request())
+
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("Expected error %v, got %v", tt.expectedErr, err)
+			}
+			if keyID != tt.expectedKeyID {
+				t.Errorf("Expected key %q, got %q", tt.expectedKeyID, keyID)
+			}
+		})
+	}
+}
+
+func TestVerifier_Replay(t *testing.T) {
+	now := time.Now()
+	keys := []Key{{ID: "k1", Secret: []byte("secret")}}
+	verifier, _ := NewVerifier(keys, time.Minute)
+	verifier.now = func() time.Time { return now }
+
+	req := signedRequest(t, keys, now, `{"cep":"01001000"}`)
+	replay := req.Clone(req.Context())
+	replay.Body = io.NopCloser(bytes.NewBufferString(`{"cep":"01001000"}`))
+
+	if _, err := verifier.Verify(req); err != nil {
+		t.Fatalf("Expected the first request to verify, got %v", err)
+	}
+	if _, err := verifier.Verify(replay); !errors.Is(err, ErrReplayed) {
+		t.Errorf("Expected the replayed request to be rejected, got %v", err)
+	}
+	if _, err := verifier.Verify(signedRequest(t, keys, now, `{"cep":"01001000"}`)); err != nil {
+		t.Errorf("Expected the same request signed again to verify, got %v", err)
+	}
+
+	// The nonce is forgotten once the timestamp is out of range anyway.
+	now = now.Add(2 * time.Minute)
+	verifier.Verify(signedRequest(t, keys, now, "{}"))
+	if n := len(verifier.nonces.expiresAt); n != 1 {
+		t.Errorf("Expected expired nonces to be dropped, got %d left", n)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	keys := []Key{{ID: "k1", Secret: []byte("secret")}}
+	verifier, _ := NewVerifier(keys, 0)
+
+	handler := Middleware(verifier, &MockLogger{}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Write(body)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, signedRequest(t, keys, time.Now(), `{"cep":"01001000"}`))
+	if rec.Code != http.StatusOK || rec.Body.String() != `{"cep":"01001000"}` {
+		t.Errorf("Expected the signed body to reach the handler, got %d %q", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/weather", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status %d, got %d", http.StatusUnauthorized, rec.Code)
+	}
+
+	verifier.SetMaxBodyBytes(8)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, signedRequest(t, keys, time.Now(), `{"cep":"01001000"}`))
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected status %d for a body over the limit, got %d", http.StatusRequestEntityTooLarge, rec.Code)
+	}
+}
+
+func TestParseKeys_DoesNotQuoteSecrets(t *testing.T) {
+	_, err := ParseKeys("k1:secret, plain-secret")
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+	if bytes.Contains([]byte(err.Error()), []byte("plain-secret")) {
+		t.Errorf("Expected the error not to include the entry, got %q", err)
+	}
+}
+
+// TestSetKeys walks through a key rotation: the verifier learns the new key
+// before the signer switches to it.
+func TestSetKeys(t *testing.T) {
+	oldKey := Key{ID: "old", Secret: []byte("old-secret")}
+	newKey := Key{ID: "new", Secret: []byte("new-secret")}
+
+	signer, err := NewSigner([]Key{oldKey})
+	if err != nil {
+		t.Fatalf("Failed to build signer: %v", err)
+	}
+	verifier, err := NewVerifier([]Key{oldKey}, time.Minute)
+	if err != nil {
+		t.Fatalf("Failed to build verifier: %v", err)
+	}
+
+	verify := func() error {
+		req := httptest.NewRequest(http.MethodGet, "/weather", nil)
+		signer.Sign(req, nil)
+		_, err := verifier.Verify(req)
+		return err
+	}
+
+	if err := verifier.SetKeys([]Key{newKey, oldKey}); err != nil {
+		t.Fatalf("SetKeys() error = %v", err)
+	}
+	if err := verify(); err != nil {
+		t.Fatalf("Expected the old key to still be accepted, got %v", err)
+	}
+
+	if err := signer.SetKeys([]Key{newKey, oldKey}); err != nil {
+		t.Fatalf("SetKeys() error = %v", err)
+	}
+	if err := verify(); err != nil {
+		t.Fatalf("Expected the new key to be accepted, got %v", err)
+	}
+
+	if err := verifier.SetKeys([]Key{oldKey}); err != nil {
+		t.Fatalf("SetKeys() error = %v", err)
+	}
+	if err := verify(); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Expected ErrUnknownKey once the new key is dropped, got %v", err)
+	}
+
+	if err := signer.SetKeys(nil); err == nil {
+		t.Error("Expected an error for an empty keyring")
+	}
+}
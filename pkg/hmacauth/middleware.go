@@ -0,0 +1,47 @@
+package hmacauth
+
+import (
+	"errors"
+	"net/http"
+
+	"go-a-b-microservices/pkg/apperror"
+	"go-a-b-microservices/pkg/i18n"
+	"go-a-b-microservices/pkg/jsonbody"
+	"go-a-b-microservices/pkg/logger"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Middleware rejects requests that are not signed by a known key with 401,
+// and those whose body is over the verifier's size limit with 413.
+func Middleware(verifier *Verifier, log logger.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		span := trace.SpanFromContext(r.Context())
+
+		keyID, err := verifier.Verify(r)
+		if err != nil {
+			span.SetAttributes(attribute.Bool("auth.authenticated", false))
+
+			status, appErr := http.StatusUnauthorized, apperror.ErrUnauthorized
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				log.Error("Rejected request to %s: body over %d bytes", r.URL.Path, maxBytesErr.Limit)
+				status, appErr = http.StatusRequestEntityTooLarge, apperror.ErrRequestTooLarge
+			} else {
+				log.Error("Rejected unsigned request to %s: %v", r.URL.Path, err)
+			}
+
+			jsonbody.Write(w, status, i18n.Localize(i18n.FromContext(r.Context()), appErr))
+			return
+		}
+
+		span.SetAttributes(
+			attribute.Bool("auth.authenticated", true),
+			attribute.String("auth.method", "hmac"),
+			attribute.String("auth.key_id", keyID),
+		)
+
+		next.ServeHTTP(w, r)
+	})
+}
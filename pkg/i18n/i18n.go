@@ -0,0 +1,132 @@
+package i18n
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go-a-b-microservices/pkg/apperror"
+)
+
+type Language string
+
+const (
+	English    Language = "en"
+	Portuguese Language = "pt-BR"
+	Spanish    Language = "es"
+)
+
+// Supported lists the languages with a message catalog.
+var Supported = []Language{English, Portuguese, Spanish}
+
+type contextKey struct{}
+
+func WithLanguage(ctx context.Context, lang Language) context.Context {
+	return context.WithValue(ctx, contextKey{}, lang)
+}
+
+// FromContext returns the negotiated language, or English when none was set.
+func FromContext(ctx context.Context) Language {
+	if lang, ok := ctx.Value(contextKey{}).(Language); ok {
+		return lang
+	}
+	return English
+}
+
+// Parse matches a single language tag against the supported languages,
+// ignoring case and falling back to the primary subtag ("pt" matches pt-BR).
+func Parse(tag string) (Language, bool) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if tag == "" {
+		return "", false
+	}
+
+	for _, lang := range Supported {
+		if strings.ToLower(string(lang)) == tag {
+			return lang, true
+		}
+	}
+
+	primary := strings.SplitN(tag, "-", 2)[0]
+	for _, lang := range Supported {
+		if strings.SplitN(strings.ToLower(string(lang)), "-", 2)[0] == primary {
+			return lang, true
+		}
+	}
+
+	return "", false
+}
+
+// Negotiate picks the supported language with the highest quality value in an
+// Accept-Language header, or fallback when none matches.
+func Negotiate(acceptLanguage string, fallback Language) Language {
+	type candidate struct {
+		tag     string
+		quality float64
+	}
+
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		fields := strings.Split(part, ";")
+		c := candidate{tag: strings.TrimSpace(fields[0]), quality: 1}
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				q, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64)
+				if err != nil {
+					q = 0
+				}
+				c.quality = q
+			}
+		}
+		if c.tag != "" && c.quality > 0 {
+			candidates = append(candidates, c)
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].quality > candidates[j].quality
+	})
+
+	for _, c := range candidates {
+		if c.tag == "*" {
+			return fallback
+		}
+		if lang, ok := Parse(c.tag); ok {
+			return lang
+		}
+	}
+
+	return fallback
+}
+
+// Middleware negotiates the request language, stores it in the context and
+// announces it in the Content-Language header.
+func Middleware(fallback Language, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lang := Negotiate(r.Header.Get("Accept-Language"), fallback)
+		w.Header().Set("Content-Language", string(lang))
+		next.ServeHTTP(w, r.WithContext(WithLanguage(r.Context(), lang)))
+	})
+}
+
+// Localize returns the message for err in lang, with the fields of a
+// validation error. Unknown errors are reported as internal errors so that
+// their details never reach clients.
+func Localize(lang Language, err error) apperror.Response {
+	code := apperror.Code(err)
+	return apperror.Response{Code: code, Message: Message(lang, code), Fields: apperror.Fields(err)}
+}
+
+// Message returns the catalog entry for code, falling back to English.
+func Message(lang Language, code string) string {
+	if message, ok := catalog[lang][code]; ok {
+		return message
+	}
+	if message, ok := catalog[English][code]; ok {
+		return message
+	}
+	return code
+}
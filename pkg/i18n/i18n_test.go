@@ -0,0 +1,56 @@
+package i18n
+
+import (
+	"errors"
+	"testing"
+
+	"go-a-b-microservices/pkg/apperror"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		name           string
+		acceptLanguage string
+		fallback       Language
+		expected       Language
+	}{
+		{name: "empty header uses fallback", acceptLanguage: "", fallback: Portuguese, expected: Portuguese},
+		{name: "exact match", acceptLanguage: "pt-BR", fallback: English, expected: Portuguese},
+		{name: "case insensitive", acceptLanguage: "PT-br", fallback: English, expected: Portuguese},
+		{name: "primary subtag", acceptLanguage: "es-AR", fallback: English, expected: Spanish},
+		{name: "highest quality wins", acceptLanguage: "en;q=0.5, es;q=0.9", fallback: Portuguese, expected: Spanish},
+		{name: "unsupported languages are skipped", acceptLanguage: "fr-FR, de;q=0.9, pt;q=0.8", fallback: English, expected: Portuguese},
+		{name: "wildcard uses fallback", acceptLanguage: "fr, *;q=0.5", fallback: Spanish, expected: Spanish},
+		{name: "zero quality is excluded", acceptLanguage: "es;q=0, en;q=0.1", fallback: Portuguese, expected: English},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Negotiate(tt.acceptLanguage, tt.fallback); got != tt.expected {
+				t.Errorf("Negotiate(%q) = %q, want %q", tt.acceptLanguage, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCatalogCoversEveryCode(t *testing.T) {
+	for _, lang := range Supported {
+		for _, code := range apperror.Codes() {
+			if _, ok := catalog[lang][code]; !ok {
+				t.Errorf("Missing %s message for code %s", lang, code)
+			}
+		}
+	}
+}
+
+func TestLocalize(t *testing.T) {
+	response := Localize(Portuguese, apperror.ErrZipCodeNotFound)
+	if response.Code != apperror.CodeZipCodeNotFound || response.Message != "não foi possível encontrar o CEP" {
+		t.Errorf("Unexpected response %+v", response)
+	}
+
+	response = Localize(English, errors.New("dial tcp: connection refused"))
+	if response.Code != apperror.CodeInternal || response.Message != "internal server error" {
+		t.Errorf("Expected unknown errors to be reported as internal errors, got %+v", response)
+	}
+}
@@ -0,0 +1,57 @@
+package i18n
+
+import "go-a-b-microservices/pkg/apperror"
+
+var catalog = map[Language]map[string]string{
+	English: {
+		apperror.CodeZipCodeRequired:      apperror.ErrZipCodeRequired.Error(),
+		apperror.CodeZipCodeInvalid:       apperror.ErrZipCodeInvalid.Error(),
+		apperror.CodeZipCodeNotFound:      apperror.ErrZipCodeNotFound.Error(),
+		apperror.CodeForecastDaysInvalid:  apperror.ErrForecastDaysInvalid.Error(),
+		apperror.CodeUnitsInvalid:         apperror.ErrUnitsInvalid.Error(),
+		apperror.CodeDateInvalid:          apperror.ErrDateInvalid.Error(),
+		apperror.CodeDateOutOfRange:       apperror.ErrDateOutOfRange.Error(),
+		apperror.CodeInvalidRequest:       apperror.ErrInvalidRequest.Error(),
+		apperror.CodeInternal:             apperror.ErrInternal.Error(),
+		apperror.CodeUnauthorized:         apperror.ErrUnauthorized.Error(),
+		apperror.CodeForbidden:            apperror.ErrForbidden.Error(),
+		apperror.CodeRateLimited:          apperror.ErrRateLimited.Error(),
+		apperror.CodeWeatherUnavailable:   apperror.ErrWeatherUnavailable.Error(),
+		apperror.CodeRequestTooLarge:      apperror.ErrRequestTooLarge.Error(),
+		apperror.CodeUnsupportedMediaType: apperror.ErrUnsupportedMediaType.Error(),
+	},
+	Portuguese: {
+		apperror.CodeZipCodeRequired:      "o CEP é obrigatório",
+		apperror.CodeZipCodeInvalid:       "CEP inválido",
+		apperror.CodeZipCodeNotFound:      "não foi possível encontrar o CEP",
+		apperror.CodeForecastDaysInvalid:  "quantidade de dias de previsão inválida",
+		apperror.CodeUnitsInvalid:         "unidades inválidas",
+		apperror.CodeDateInvalid:          "data inválida",
+		apperror.CodeDateOutOfRange:       "data fora do intervalo permitido",
+		apperror.CodeInvalidRequest:       "requisição inválida",
+		apperror.CodeInternal:             "erro interno do servidor",
+		apperror.CodeUnauthorized:         "não autorizado",
+		apperror.CodeForbidden:            "escopo insuficiente",
+		apperror.CodeRateLimited:          "muitas requisições",
+		apperror.CodeWeatherUnavailable:   "dados meteorológicos temporariamente indisponíveis",
+		apperror.CodeRequestTooLarge:      "corpo da requisição muito grande",
+		apperror.CodeUnsupportedMediaType: "tipo de mídia não suportado",
+	},
+	Spanish: {
+		apperror.CodeZipCodeRequired:      "el código postal es obligatorio",
+		apperror.CodeZipCodeInvalid:       "código postal no válido",
+		apperror.CodeZipCodeNotFound:      "no se puede encontrar el código postal",
+		apperror.CodeForecastDaysInvalid:  "cantidad de días de pronóstico no válida",
+		apperror.CodeUnitsInvalid:         "unidades no válidas",
+		apperror.CodeDateInvalid:          "fecha no válida",
+		apperror.CodeDateOutOfRange:       "fecha fuera del rango permitido",
+		apperror.CodeInvalidRequest:       "solicitud no válida",
+		apperror.CodeInternal:             "error interno del servidor",
+		apperror.CodeUnauthorized:         "no autorizado",
+		apperror.CodeForbidden:            "alcance insuficiente",
+		apperror.CodeRateLimited:          "demasiadas solicitudes",
+		apperror.CodeWeatherUnavailable:   "datos meteorológicos no disponibles temporalmente",
+		apperror.CodeRequestTooLarge:      "cuerpo de la solicitud demasiado grande",
+		apperror.CodeUnsupportedMediaType: "tipo de medio no admitido",
+	},
+}
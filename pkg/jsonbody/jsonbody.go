@@ -0,0 +1,186 @@
+// Package jsonbody reads JSON request bodies strictly: the body must be sent
+// as JSON, fit in a size limit and hold exactly one value with no fields the
+// target type does not have. It also writes JSON responses.
+package jsonbody
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"go-a-b-microservices/pkg/apperror"
+)
+
+// DefaultMaxBytes is the body size limit used when none is configured.
+const DefaultMaxBytes int64 = 1 << 20
+
+// Decoder reads request bodies of up to a configured size.
+type Decoder struct {
+	maxBytes atomic.Int64
+}
+
+// NewDecoder returns a Decoder that rejects bodies over maxBytes, or over
+// DefaultMaxBytes when maxBytes is not positive.
+func NewDecoder(maxBytes int64) *Decoder {
+	d := &Decoder{}
+	d.SetMaxBytes(maxBytes)
+	return d
+}
+
+// SetMaxBytes changes the size limit, as NewDecoder sets it. It is safe to
+// call while the decoder is in use.
+func (d *Decoder) SetMaxBytes(maxBytes int64) {
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxBytes
+	}
+	d.maxBytes.Store(maxBytes)
+}
+
+// Read returns the body of r after checking that it is declared as JSON and
+// is not over the size limit. It returns apperror.ErrUnsupportedMediaType or
+// apperror.ErrRequestTooLarge otherwise. w, which may be nil, lets the server
+// close the connection of an oversized request.
+func (d *Decoder) Read(w http.ResponseWriter, r *http.Request) ([]byte, error) {
+	if !isJSON(r.Header.Get("Content-Type")) {
+		return nil, apperror.ErrUnsupportedMediaType
+	}
+	if r.Body == nil {
+		return nil, nil
+	}
+	defer r.Body.Close()
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, d.maxBytes.Load()))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return nil, apperror.ErrRequestTooLarge
+		}
+		return nil, &apperror.ValidationError{
+			Err:    apperror.ErrInvalidRequest,
+			Fields: []apperror.FieldError{{Field: "/", Message: err.Error()}},
+		}
+	}
+	return body, nil
+}
+
+// Decode reads the body of r, as Read does, into v with Unmarshal.
+func (d *Decoder) Decode(w http.ResponseWriter, r *http.Request, v interface{}) error {
+	body, err := d.Read(w, r)
+	if err != nil {
+		return err
+	}
+	return Unmarshal(body, v)
+}
+
+// Unmarshal decodes the single JSON value in data into v, rejecting fields v
+// does not have and anything after the value. Errors are
+// *apperror.ValidationError values wrapping apperror.ErrInvalidRequest, with
+// the offending field as a JSON pointer.
+func Unmarshal(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(v); err != nil {
+		return invalid(fieldError(err))
+	}
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return invalid(apperror.FieldError{Field: "/", Message: "unexpected data after the JSON value"})
+	}
+	return nil
+}
+
+func invalid(field apperror.FieldError) error {
+	return &apperror.ValidationError{Err: apperror.ErrInvalidRequest, Fields: []apperror.FieldError{field}}
+}
+
+// fieldError describes a decoding error, naming the field it happened at
+// where encoding/json reports it.
+func fieldError(err error) apperror.FieldError {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+
+	switch {
+	case errors.Is(err, io.EOF):
+		return apperror.FieldError{Field: "/", Message: "request body is empty"}
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return apperror.FieldError{Field: "/", Message: "unexpected end of JSON input"}
+	case errors.As(err, &syntaxErr):
+		return apperror.FieldError{
+			Field:   "/",
+			Message: strings.TrimPrefix(syntaxErr.Error(), "json: ") + " at offset " + strconv.FormatInt(syntaxErr.Offset, 10),
+		}
+	case errors.As(err, &typeErr):
+		field := "/"
+		if typeErr.Field != "" {
+			field = pointer(strings.Split(typeErr.Field, "."))
+		}
+		return apperror.FieldError{Field: field, Message: "must be " + jsonType(typeErr.Type.Kind().String()) + ", not " + typeErr.Value}
+	}
+
+	// encoding/json reports unknown fields by name only, in a plain error.
+	if name, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		if unquoted, err := strconv.Unquote(name); err == nil {
+			name = unquoted
+		}
+		return apperror.FieldError{Field: pointer([]string{name}), Message: "unknown field"}
+	}
+
+	return apperror.FieldError{Field: "/", Message: strings.TrimPrefix(err.Error(), "json: ")}
+}
+
+// pointer builds a JSON pointer from field names, escaping them as RFC 6901
+// requires.
+func pointer(names []string) string {
+	replacer := strings.NewReplacer("~", "~0", "/", "~1")
+	var builder strings.Builder
+	for _, name := range names {
+		builder.WriteString("/")
+		builder.WriteString(replacer.Replace(name))
+	}
+	return builder.String()
+}
+
+// jsonType names a Go kind the way a JSON client would read it.
+func jsonType(kind string) string {
+	switch {
+	case kind == "string":
+		return "a string"
+	case kind == "bool":
+		return "a boolean"
+	case kind == "slice" || kind == "array":
+		return "an array"
+	case kind == "struct" || kind == "map":
+		return "an object"
+	case strings.HasPrefix(kind, "float"):
+		return "a number"
+	case strings.HasPrefix(kind, "int") || strings.HasPrefix(kind, "uint"):
+		return "an integer"
+	}
+	return "a " + kind
+}
+
+// Write sends data as a JSON response with statusCode.
+func Write(w http.ResponseWriter, statusCode int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		http.Error(w, "Error encoding response", http.StatusInternalServerError)
+	}
+}
+
+// isJSON reports whether contentType is application/json or a +json type.
+func isJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" ||
+		(strings.HasPrefix(mediaType, "application/") && strings.HasSuffix(mediaType, "+json"))
+}
@@ -0,0 +1,99 @@
+package jsonbody
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-a-b-microservices/pkg/apperror"
+)
+
+type testRequest struct {
+	CEP    string `json:"cep"`
+	Days   int    `json:"days,omitempty"`
+	Nested struct {
+		Hourly bool `json:"hourly"`
+	} `json:"nested"`
+}
+
+func TestUnmarshal(t *testing.T) {
+	tests := []struct {
+		name          string
+		body          string
+		expectedField string
+	}{
+		{name: "Valid", body: `{"cep":"01001000","days":3}`},
+		{name: "Surrounding whitespace", body: " {\"cep\":\"01001000\"}\n"},
+		{name: "Empty", body: ``, expectedField: "/"},
+		{name: "Syntax error", body: `{"cep":}`, expectedField: "/"},
+		{name: "Truncated", body: `{"cep":"0100`, expectedField: "/"},
+		{name: "Unknown field", body: `{"zip":"01001000"}`, expectedField: "/zip"},
+		{name: "Escaped unknown field", body: `{"a/b":1}`, expectedField: "/a~1b"},
+		{name: "Wrong type", body: `{"cep":1001000}`, expectedField: "/cep"},
+		{name: "Nested wrong type", body: `{"cep":"01001000","nested":{"hourly":"yes"}}`, expectedField: "/nested/hourly"},
+		{name: "Trailing value", body: `{"cep":"01001000"}{"cep":"01001000"}`, expectedField: "/"},
+		{name: "Trailing garbage", body: `{"cep":"01001000"} x`, expectedField: "/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var request testRequest
+			err := Unmarshal([]byte(tt.body), &request)
+
+			if tt.expectedField == "" {
+				if err != nil {
+					t.Fatalf("Unmarshal() error = %v", err)
+				}
+				return
+			}
+
+			if !errors.Is(err, apperror.ErrInvalidRequest) {
+				t.Fatalf("Expected ErrInvalidRequest, got %v", err)
+			}
+			fields := apperror.Fields(err)
+			if len(fields) != 1 || fields[0].Field != tt.expectedField || fields[0].Message == "" {
+				t.Errorf("Expected field %q, got %+v", tt.expectedField, fields)
+			}
+		})
+	}
+}
+
+func TestDecoder_Decode(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		maxBytes    int64
+		expectedErr error
+	}{
+		{name: "JSON", contentType: "application/json", body: `{"cep":"01001000"}`},
+		{name: "JSON with charset", contentType: "application/json; charset=utf-8", body: `{"cep":"01001000"}`},
+		{name: "JSON suffix", contentType: "application/problem+json", body: `{"cep":"01001000"}`},
+		{name: "Missing content type", body: `{"cep":"01001000"}`, expectedErr: apperror.ErrUnsupportedMediaType},
+		{name: "Form", contentType: "application/x-www-form-urlencoded", body: `{"cep":"01001000"}`, expectedErr: apperror.ErrUnsupportedMediaType},
+		{name: "Text", contentType: "text/plain", body: `{"cep":"01001000"}`, expectedErr: apperror.ErrUnsupportedMediaType},
+		{name: "At the limit", contentType: "application/json", body: `{"cep":"01001000"}`, maxBytes: 18},
+		{name: "Over the limit", contentType: "application/json", body: `{"cep":"01001000"}`, maxBytes: 17, expectedErr: apperror.ErrRequestTooLarge},
+		{name: "Unknown field", contentType: "application/json", body: `{"zip":"01001000"}`, expectedErr: apperror.ErrInvalidRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+
+			var request testRequest
+			err := NewDecoder(tt.maxBytes).Decode(httptest.NewRecorder(), req, &request)
+			if !errors.Is(err, tt.expectedErr) || (err != nil) != (tt.expectedErr != nil) {
+				t.Fatalf("Decode() error = %v, expected %v", err, tt.expectedErr)
+			}
+			if err == nil && request.CEP != "01001000" {
+				t.Errorf("Expected CEP 01001000, got %q", request.CEP)
+			}
+		})
+	}
+}
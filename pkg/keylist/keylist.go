@@ -0,0 +1,55 @@
+// Package keylist parses the comma separated "id:secret" lists that signing
+// keys and API keys are configured with.
+package keylist
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Entry is one pair of a list.
+type Entry struct {
+	ID     string
+	Secret string
+}
+
+// Parse splits value into its entries, in order, skipping blank ones. name,
+// such as "signing key", describes an entry in errors, which never include a
+// secret. identity tells entries apart: two entries it gives the same string
+// for are an error. An error from identity rejects the entry.
+func Parse(value, name string, identity func(Entry) (string, error)) ([]Entry, error) {
+	var entries []Entry
+	seen := make(map[string]int)
+
+	for i, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		id, secret, ok := strings.Cut(pair, ":")
+		entry := Entry{ID: strings.TrimSpace(id), Secret: strings.TrimSpace(secret)}
+		if !ok || entry.ID == "" || entry.Secret == "" {
+			// The entry is not quoted: it may be the secret itself.
+			return nil, fmt.Errorf("%s entry %d is not in the id:secret form", name, i+1)
+		}
+
+		key, err := identity(entry)
+		if err != nil {
+			return nil, fmt.Errorf("%s entry %d: %w", name, i+1, err)
+		}
+		if first, ok := seen[key]; ok {
+			return nil, fmt.Errorf("%s entry %d repeats entry %d", name, i+1, first)
+		}
+		seen[key] = i + 1
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// ByID tells entries apart by their ID.
+func ByID(entry Entry) (string, error) {
+	return entry.ID, nil
+}
@@ -0,0 +1,61 @@
+package keylist
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	bySecret := func(entry Entry) (string, error) {
+		if entry.Secret == "bad" {
+			return "", errors.New("bad secret")
+		}
+		return entry.Secret, nil
+	}
+
+	tests := []struct {
+		name        string
+		value       string
+		identity    func(Entry) (string, error)
+		expected    []Entry
+		expectedErr string
+	}{
+		{
+			name:     "Entries in order",
+			value:    " k2:new , ,k1:old:with:colons",
+			identity: ByID,
+			expected: []Entry{{ID: "k2", Secret: "new"}, {ID: "k1", Secret: "old:with:colons"}},
+		},
+		{name: "Empty", value: " , ", identity: ByID},
+		{name: "No separator", value: "k1:a,secret", identity: ByID, expectedErr: "key entry 2 is not in the id:secret form"},
+		{name: "No secret", value: "k1:", identity: ByID, expectedErr: "key entry 1 is not in the id:secret form"},
+		{name: "Repeated ID", value: "k1:a,k2:b,k1:c", identity: ByID, expectedErr: "key entry 3 repeats entry 1"},
+		{
+			name:     "Same ID, other identity",
+			value:    "web:a,web:b",
+			identity: bySecret,
+			expected: []Entry{{ID: "web", Secret: "a"}, {ID: "web", Secret: "b"}},
+		},
+		{name: "Rejected by identity", value: "web:a,web:bad", identity: bySecret, expectedErr: "key entry 2: bad secret"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := Parse(tt.value, "key", tt.identity)
+			if tt.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+					t.Fatalf("Expected error containing %q, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if !reflect.DeepEqual(entries, tt.expected) {
+				t.Errorf("Expected %+v, got %+v", tt.expected, entries)
+			}
+		})
+	}
+}
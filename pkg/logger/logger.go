@@ -1,8 +1,11 @@
 package logger
 
 import (
+	"fmt"
 	"log"
 	"os"
+	"strings"
+	"sync/atomic"
 )
 
 type Logger interface {
@@ -11,12 +14,38 @@ type Logger interface {
 	Debug(message string, args ...interface{})
 }
 
+// Level is the least severe kind of message a logger writes.
+type Level int32
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelError
+)
+
+// ParseLevel parses debug, info or error.
+func ParseLevel(value string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "debug":
+		return LevelDebug, nil
+	case "info", "":
+		return LevelInfo, nil
+	case "error":
+		return LevelError, nil
+	default:
+		return 0, fmt.Errorf("unknown log level %q, expected debug, info or error", value)
+	}
+}
+
 type SimpleLogger struct {
 	infoLogger  *log.Logger
 	errorLogger *log.Logger
 	debugLogger *log.Logger
+	level       atomic.Int32
 }
 
+// NewLogger returns a logger that writes every level until SetLevel is
+// called.
 func NewLogger() *SimpleLogger {
 	return &SimpleLogger{
 		infoLogger:  log.New(os.Stdout, "INFO: ", log.Ldate|log.Ltime|log.Lshortfile),
@@ -25,8 +54,20 @@ func NewLogger() *SimpleLogger {
 	}
 }
 
+// SetLevel drops the messages below level. It is safe to call while the
+// logger is in use.
+func (l *SimpleLogger) SetLevel(level Level) {
+	l.level.Store(int32(level))
+}
+
+func (l *SimpleLogger) enabled(level Level) bool {
+	return Level(l.level.Load()) <= level
+}
+
 func (l *SimpleLogger) Info(message string, args ...interface{}) {
-	l.infoLogger.Printf(message, args...)
+	if l.enabled(LevelInfo) {
+		l.infoLogger.Printf(message, args...)
+	}
 }
 
 func (l *SimpleLogger) Error(message string, args ...interface{}) {
@@ -34,5 +75,7 @@ func (l *SimpleLogger) Error(message string, args ...interface{}) {
 }
 
 func (l *SimpleLogger) Debug(message string, args ...interface{}) {
-	l.debugLogger.Printf(message, args...)
+	if l.enabled(LevelDebug) {
+		l.debugLogger.Printf(message, args...)
+	}
 }
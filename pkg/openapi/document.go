@@ -0,0 +1,81 @@
+package openapi
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// NewDocument returns an OpenAPI 3 document with the shared component
+// schemas. Callers add their paths and check the result with Validate.
+func NewDocument(title, description, version string) (*openapi3.T, error) {
+	components, err := NewComponents()
+	if err != nil {
+		return nil, err
+	}
+
+	return &openapi3.T{
+		OpenAPI:    "3.0.3",
+		Info:       &openapi3.Info{Title: title, Description: description, Version: version},
+		Paths:      openapi3.NewPaths(),
+		Components: &components,
+	}, nil
+}
+
+// Validate resolves the schema references of doc, which are generated
+// without their values, and checks that it is a well formed OpenAPI
+// document.
+func Validate(doc *openapi3.T) error {
+	if err := openapi3.NewLoader().ResolveRefsIn(doc, nil); err != nil {
+		return err
+	}
+	return doc.Validate(context.Background())
+}
+
+// JSONOperation describes an endpoint that takes request as its JSON body,
+// unless it is nil, and answers with response. Each of errorStatuses is
+// documented as an ErrorResponse.
+func JSONOperation(id, summary string, request, response *openapi3.SchemaRef, errorStatuses ...int) *openapi3.Operation {
+	operation := &openapi3.Operation{
+		OperationID: id,
+		Summary:     summary,
+		Responses:   openapi3.NewResponsesWithCapacity(len(errorStatuses) + 1),
+	}
+
+	if request != nil {
+		operation.RequestBody = &openapi3.RequestBodyRef{
+			Value: openapi3.NewRequestBody().WithRequired(true).WithJSONSchemaRef(request),
+		}
+	}
+
+	operation.Responses.Set(strconv.Itoa(http.StatusOK), &openapi3.ResponseRef{
+		Value: openapi3.NewResponse().WithDescription(http.StatusText(http.StatusOK)).WithJSONSchemaRef(response),
+	})
+	for _, status := range errorStatuses {
+		operation.Responses.Set(strconv.Itoa(status), &openapi3.ResponseRef{
+			Value: openapi3.NewResponse().WithDescription(http.StatusText(status)).WithJSONSchemaRef(ErrorSchemaRef()),
+		})
+	}
+	return operation
+}
+
+// ErrorSchemaRef refers to the schema of apperror.Response.
+func ErrorSchemaRef() *openapi3.SchemaRef {
+	return openapi3.NewSchemaRef("#/components/schemas/ErrorResponse", nil)
+}
+
+// QueryParameter describes an optional query parameter.
+func QueryParameter(name, description string, schema *openapi3.Schema) *openapi3.ParameterRef {
+	return &openapi3.ParameterRef{
+		Value: openapi3.NewQueryParameter(name).WithDescription(description).WithSchema(schema),
+	}
+}
+
+// UnitsSchema is the schema of the units parameter.
+func UnitsSchema() *openapi3.Schema {
+	schema := openapi3.NewStringSchema()
+	schema.Pattern = unitsPattern
+	return schema
+}
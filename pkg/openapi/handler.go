@@ -0,0 +1,77 @@
+package openapi
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Handler serves doc as JSON.
+func Handler(doc *openapi3.T) (http.Handler, error) {
+	body, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	}), nil
+}
+
+// swaggerUI holds the Swagger UI 5.18.2 stylesheet and bundle, copied from
+// the swagger-ui dist directory with its Apache 2.0 license. They are served by
+// DocsHandler, so the page needs no CDN and loads nothing it was not built
+// with.
+//
+//go:embed swaggerui/swagger-ui.css swaggerui/swagger-ui-bundle.js
+var swaggerUI embed.FS
+
+const docsPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>%[1]s</title>
+  <link rel="stylesheet" href="%[3]s/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="%[3]s/swagger-ui-bundle.js"></script>
+  <script>
+    window.onload = () => { window.ui = SwaggerUIBundle({ url: "%[2]s", dom_id: "#swagger-ui" }); };
+  </script>
+</body>
+</html>
+`
+
+// DocsHandler serves a Swagger UI page for the document at specURL, and the
+// Swagger UI assets below the page's path. Mount it on both "/docs" and
+// "/docs/".
+func DocsHandler(title, specURL string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		if name := path.Base(r.URL.Path); name == "swagger-ui.css" || name == "swagger-ui-bundle.js" {
+			http.ServeFileFS(w, r, swaggerUI, "swaggerui/"+name)
+			return
+		}
+
+		base := html.EscapeString(strings.TrimSuffix(r.URL.Path, "/"))
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprintf(w, docsPage, html.EscapeString(title), html.EscapeString(specURL), base)
+	})
+}
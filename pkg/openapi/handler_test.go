@@ -0,0 +1,40 @@
+package openapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDocsHandler(t *testing.T) {
+	handler := DocsHandler("Test", "/openapi.json")
+
+	tests := []struct {
+		path                string
+		expectedContentType string
+		expectedBody        string
+	}{
+		{path: "/docs", expectedContentType: "text/html", expectedBody: `<script src="/docs/swagger-ui-bundle.js">`},
+		{path: "/docs/", expectedContentType: "text/html", expectedBody: `href="/docs/swagger-ui.css"`},
+		{path: "/docs/swagger-ui.css", expectedContentType: "text/css", expectedBody: ".swagger-ui"},
+		{path: "/docs/swagger-ui-bundle.js", expectedContentType: "text/javascript", expectedBody: "SwaggerUIBundle"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d", rec.Code)
+			}
+			if contentType := rec.Header().Get("Content-Type"); !strings.HasPrefix(contentType, tt.expectedContentType) {
+				t.Errorf("Expected content type %s, got %s", tt.expectedContentType, contentType)
+			}
+			if !strings.Contains(rec.Body.String(), tt.expectedBody) {
+				t.Errorf("Expected the body to contain %q", tt.expectedBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package openapi
+
+import (
+	"reflect"
+	"strings"
+
+	"go-a-b-microservices/pkg/apperror"
+	"go-a-b-microservices/pkg/zipcode"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3gen"
+)
+
+const (
+	cepPattern   = `^\d{8}$`
+	unitsPattern = `^\s*([CFKcfk]\s*(,\s*[CFKcfk]\s*)*)?$`
+	datePattern  = `^\d{4}-\d{2}-\d{2}$`
+)
+
+// fieldErrors names the error a request field that fails validation stands
+// for, so that schema validation reports the same codes as the Validate
+// methods in pkg/zipcode.
+var fieldErrors = map[string]error{
+	"cep":      apperror.ErrZipCodeInvalid,
+	"units":    apperror.ErrUnitsInvalid,
+	"days":     apperror.ErrForecastDaysInvalid,
+	"date":     apperror.ErrDateInvalid,
+	"end_date": apperror.ErrDateInvalid,
+}
+
+// NewComponents generates the schemas of the pkg/zipcode request and response
+// types and of apperror.Response, named after their Go types. A field is
+// required unless it is a pointer or tagged omitempty.
+func NewComponents() (openapi3.Components, error) {
+	schemas := openapi3.Schemas{}
+	generator := openapi3gen.NewGenerator(
+		openapi3gen.UseAllExportedFields(),
+		openapi3gen.CreateComponentSchemas(openapi3gen.ExportComponentSchemasOptions{
+			ExportComponentSchemas: true,
+			ExportTopLevelSchema:   true,
+		}),
+		openapi3gen.CreateTypeNameGenerator(typeName),
+		openapi3gen.SchemaCustomizer(customizeSchema),
+	)
+
+	for _, value := range []interface{}{
+		apperror.Response{},
+		zipcode.ZipCodeRequest{},
+		zipcode.WeatherResponse{},
+		zipcode.ForecastRequest{},
+		zipcode.ForecastResponse{},
+		zipcode.HistoryRequest{},
+		zipcode.HistoryResponse{},
+		zipcode.WeatherBatchRequest{},
+		zipcode.WeatherBatchResponse{},
+	} {
+		if _, err := generator.NewSchemaRefForValue(value, schemas); err != nil {
+			return openapi3.Components{}, err
+		}
+	}
+
+	return openapi3.Components{Schemas: schemas}, nil
+}
+
+// SchemaRef refers to the component schema of the Go type of value.
+func SchemaRef(value interface{}) *openapi3.SchemaRef {
+	return openapi3.NewSchemaRef("#/components/schemas/"+typeName(reflect.TypeOf(value)), nil)
+}
+
+func typeName(t reflect.Type) string {
+	if t == reflect.TypeOf(apperror.Response{}) {
+		return "ErrorResponse"
+	}
+	return t.Name()
+}
+
+// customizeSchema marks the required fields of structs, closes them to other
+// fields and adds the field constraints checked by the Validate methods in
+// pkg/zipcode.
+func customizeSchema(name string, t reflect.Type, tag reflect.StructTag, schema *openapi3.Schema) error {
+	if t.Kind() == reflect.Struct && schema.Type.Is(openapi3.TypeObject) {
+		schema.Required = requiredFields(t)
+		// Requests are decoded strictly, so unknown fields are rejected.
+		schema.AdditionalProperties = openapi3.AdditionalProperties{Has: openapi3.BoolPtr(false)}
+	}
+	// Optional fields are left out rather than sent as null, but a nil slice
+	// that is not omitted is encoded as null.
+	omitEmpty := strings.Contains(tag.Get("json"), ",omitempty")
+	if t.Kind() == reflect.Struct || omitEmpty {
+		schema.Nullable = false
+	}
+	if t.Kind() == reflect.Slice && !omitEmpty && name != "_root" {
+		schema.Nullable = true
+	}
+
+	switch name {
+	case "cep":
+		schema.Pattern = cepPattern
+	case "units":
+		schema.Pattern = unitsPattern
+	case "date", "end_date":
+		if t.Kind() == reflect.String {
+			schema.Format = "date"
+			schema.Pattern = datePattern
+		}
+	case "days":
+		if t.Kind() == reflect.Int {
+			schema.Min = openapi3.Float64Ptr(0)
+			schema.Max = openapi3.Float64Ptr(zipcode.MaxForecastDays)
+		}
+	}
+
+	return nil
+}
+
+func requiredFields(t reflect.Type) []string {
+	var required []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			required = append(required, requiredFields(field.Type)...)
+			continue
+		}
+
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" || field.Type.Kind() == reflect.Pointer || strings.Contains(options, "omitempty") {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		required = append(required, name)
+	}
+	return required
+}
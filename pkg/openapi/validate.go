@@ -0,0 +1,210 @@
+package openapi
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"go-a-b-microservices/pkg/apperror"
+	"go-a-b-microservices/pkg/i18n"
+	"go-a-b-microservices/pkg/jsonbody"
+	"go-a-b-microservices/pkg/logger"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/legacy"
+)
+
+// Validator checks requests and responses against the operations of a
+// document. Routes the document does not describe are not checked.
+type Validator struct {
+	router  routers.Router
+	decoder *jsonbody.Decoder
+}
+
+func NewValidator(doc *openapi3.T) (*Validator, error) {
+	router, err := legacy.NewRouter(doc)
+	if err != nil {
+		return nil, err
+	}
+	return &Validator{router: router, decoder: jsonbody.NewDecoder(jsonbody.DefaultMaxBytes)}, nil
+}
+
+// SetMaxBodyBytes sets the size limit of the request bodies read for
+// validation, which should match the limit of the handlers.
+func (v *Validator) SetMaxBodyBytes(maxBytes int64) {
+	v.decoder.SetMaxBytes(maxBytes)
+}
+
+var options = &openapi3filter.Options{
+	MultiError: true,
+	// Callers are authenticated by their own middleware.
+	AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+}
+
+// ValidateRequest returns an *apperror.ValidationError listing every invalid
+// field of r, or nil. A body is read as jsonbody does, so a request that is
+// not JSON or is over the size limit gets the error the handlers would give.
+// The body is left for the handler to read again.
+func (v *Validator) ValidateRequest(r *http.Request) error {
+	route, pathParams, err := v.router.FindRoute(r)
+	if err != nil {
+		return nil
+	}
+
+	checked := r.Clone(r.Context())
+	if route.Operation.RequestBody != nil {
+		body, err := v.decoder.Read(nil, r)
+		if err != nil {
+			return err
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		checked.Body = io.NopCloser(bytes.NewReader(body))
+		// A +json type is read the same way as application/json.
+		checked.Header.Set("Content-Type", "application/json")
+	}
+
+	err = openapi3filter.ValidateRequest(r.Context(), &openapi3filter.RequestValidationInput{
+		Request:    checked,
+		PathParams: pathParams,
+		Route:      route,
+		Options:    options,
+	})
+	if err == nil {
+		return nil
+	}
+
+	return newValidationError(err)
+}
+
+// ValidateResponse checks a response given to r against the document.
+func (v *Validator) ValidateResponse(r *http.Request, status int, header http.Header, body []byte) error {
+	route, pathParams, err := v.router.FindRoute(r)
+	if err != nil {
+		return err
+	}
+
+	return openapi3filter.ValidateResponse(r.Context(), &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: &openapi3filter.RequestValidationInput{
+			Request:    r,
+			PathParams: pathParams,
+			Route:      route,
+			Options:    options,
+		},
+		Status:  status,
+		Header:  header,
+		Body:    io.NopCloser(bytes.NewReader(body)),
+		Options: options,
+	})
+}
+
+func newValidationError(err error) *apperror.ValidationError {
+	validationErr := &validationError{ValidationError: apperror.ValidationError{Err: apperror.ErrInvalidRequest}}
+
+	// The errors are matched by type rather than with errors.As, which would
+	// look through a RequestError and lose the name of its parameter.
+	var visit func(err error, field string)
+	visit = func(err error, field string) {
+		switch err := err.(type) {
+		case openapi3.MultiError:
+			for _, err := range err {
+				visit(err, field)
+			}
+		case *openapi3filter.RequestError:
+			if err.Parameter != nil {
+				field = err.Parameter.Name
+			}
+			if err.Err == nil {
+				validationErr.add(field, field, err.Reason)
+				return
+			}
+			visit(err.Err, field)
+		case *openapi3.SchemaError:
+			pointer := err.JSONPointer()
+			name := field
+			if len(pointer) > 0 {
+				name = pointer[len(pointer)-1]
+			}
+			if field == "" {
+				field = "/" + strings.Join(pointer, "/")
+			}
+			if property, ok := unknownProperty(err); ok {
+				validationErr.unknownField = true
+				validationErr.add(strings.TrimSuffix(field, "/")+"/"+property, "", "unknown field")
+				return
+			}
+			// A value of the wrong type is a malformed request, as it is when
+			// the handlers fail to decode it.
+			if err.SchemaField == "type" {
+				name = ""
+			}
+			validationErr.add(field, name, err.Reason)
+		case *openapi3filter.ParseError:
+			validationErr.add(field, field, err.Error())
+		default:
+			validationErr.add(field, field, err.Error())
+		}
+	}
+	visit(err, "")
+
+	// A misspelled field is reported as such rather than as the missing
+	// field it was meant to be.
+	if validationErr.unknownField {
+		validationErr.Err = apperror.ErrInvalidRequest
+	}
+	return &validationErr.ValidationError
+}
+
+// validationError collects the field errors of a request.
+type validationError struct {
+	apperror.ValidationError
+	unknownField bool
+}
+
+// add records a field error. The first field that stands for a known error
+// decides the error of the whole request.
+func (e *validationError) add(field, name, message string) {
+	if field == "" {
+		field = "/"
+	}
+	e.Fields = append(e.Fields, apperror.FieldError{Field: field, Message: message})
+
+	if known, ok := fieldErrors[name]; ok && e.Err == apperror.ErrInvalidRequest {
+		e.Err = known
+	}
+}
+
+// unknownProperty returns the name of the property err rejects for not being
+// described by the schema, which is only given in its reason.
+func unknownProperty(err *openapi3.SchemaError) (string, bool) {
+	if err.SchemaField != "properties" {
+		return "", false
+	}
+	var property string
+	if _, scanErr := fmt.Sscanf(err.Reason, "property %q is unsupported", &property); scanErr != nil {
+		return "", false
+	}
+	return property, true
+}
+
+// Middleware rejects requests that do not match the document, answering
+// with the error of the request and the reason each field was rejected. A
+// field that stands for a known error, such as an invalid CEP, gets the same
+// 422 and code as the handlers would give; anything else gets 400 and
+// invalid_request. Bodies that are not JSON or are too large get 415 and 413.
+func Middleware(validator *Validator, log logger.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := validator.ValidateRequest(r)
+		if err == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		log.Debug("Rejected %s %s: %v", r.Method, r.URL.Path, err)
+
+		jsonbody.Write(w, apperror.HTTPStatus(err), i18n.Localize(i18n.FromContext(r.Context()), err))
+	})
+}
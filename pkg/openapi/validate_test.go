@@ -0,0 +1,293 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-a-b-microservices/pkg/apperror"
+	"go-a-b-microservices/pkg/zipcode"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+type MockLogger struct{}
+
+func (m *MockLogger) Info(message string, args ...interface{})  {}
+func (m *MockLogger) Error(message string, args ...interface{}) {}
+func (m *MockLogger) Debug(message string, args ...interface{}) {}
+
+func newTestDocument(t *testing.T) *openapi3.T {
+	t.Helper()
+
+	doc, err := NewDocument("Test", "Test document", "1.0.0")
+	if err != nil {
+		t.Fatalf("NewDocument() error = %v", err)
+	}
+
+	weather := JSONOperation("getWeather", "Weather",
+		SchemaRef(zipcode.ZipCodeRequest{}), SchemaRef(zipcode.WeatherResponse{}),
+		http.StatusBadRequest, http.StatusUnprocessableEntity)
+	weather.Parameters = openapi3.Parameters{
+		QueryParameter("units", "Units", UnitsSchema()),
+		QueryParameter("alerts", "Alerts", openapi3.NewBoolSchema()),
+	}
+	doc.AddOperation("/weather", http.MethodPost, weather)
+	doc.AddOperation("/forecast", http.MethodPost, JSONOperation("getForecast", "Forecast",
+		SchemaRef(zipcode.ForecastRequest{}), SchemaRef(zipcode.ForecastResponse{}), http.StatusUnprocessableEntity))
+	doc.AddOperation("/history", http.MethodPost, JSONOperation("getHistory", "History",
+		SchemaRef(zipcode.HistoryRequest{}), SchemaRef(zipcode.HistoryResponse{}), http.StatusUnprocessableEntity))
+
+	if err := Validate(doc); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	return doc
+}
+
+func TestMiddleware(t *testing.T) {
+	validator, err := NewValidator(newTestDocument(t))
+	if err != nil {
+		t.Fatalf("NewValidator() error = %v", err)
+	}
+	validator.SetMaxBodyBytes(64)
+
+	tests := []struct {
+		name           string
+		path           string
+		contentType    string
+		body           string
+		expectedStatus int
+		expectedCode   string
+		expectedFields []string
+	}{
+		{
+			name:           "Valid request",
+			path:           "/weather",
+			body:           `{"cep":"01001000","units":"C,K"}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Unknown field",
+			path:           "/weather",
+			body:           `{"zip":"01001000"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   apperror.CodeInvalidRequest,
+			expectedFields: []string{"/zip", "/cep"},
+		},
+		{
+			name:           "Not JSON",
+			path:           "/weather",
+			contentType:    "text/plain",
+			body:           `{"cep":"01001000"}`,
+			expectedStatus: http.StatusUnsupportedMediaType,
+			expectedCode:   apperror.CodeUnsupportedMediaType,
+		},
+		{
+			name:           "Too large",
+			path:           "/weather",
+			body:           `{"cep":"01001000","units":"` + strings.Repeat("C,", 32) + `C"}`,
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectedCode:   apperror.CodeRequestTooLarge,
+		},
+		{
+			name:           "Invalid CEP",
+			path:           "/weather",
+			body:           `{"cep":"123"}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedCode:   apperror.CodeZipCodeInvalid,
+			expectedFields: []string{"/cep"},
+		},
+		{
+			name:           "Missing CEP",
+			path:           "/weather",
+			body:           `{"units":"C"}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedCode:   apperror.CodeZipCodeInvalid,
+			expectedFields: []string{"/cep"},
+		},
+		{
+			name:           "Invalid units",
+			path:           "/weather",
+			body:           `{"cep":"01001000","units":"X"}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedCode:   apperror.CodeUnitsInvalid,
+			expectedFields: []string{"/units"},
+		},
+		{
+			name:           "Invalid units query parameter",
+			path:           "/weather?units=X",
+			body:           `{"cep":"01001000"}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedCode:   apperror.CodeUnitsInvalid,
+			expectedFields: []string{"units"},
+		},
+		{
+			name:           "Invalid flag query parameter",
+			path:           "/weather?alerts=maybe",
+			body:           `{"cep":"01001000"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   apperror.CodeInvalidRequest,
+			expectedFields: []string{"alerts"},
+		},
+		{
+			name:           "Wrong type",
+			path:           "/weather",
+			body:           `{"cep":"01001000","alerts":"yes"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   apperror.CodeInvalidRequest,
+			expectedFields: []string{"/alerts"},
+		},
+		{
+			name:           "Wrong type of a known field",
+			path:           "/weather",
+			body:           `{"cep":12345678}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   apperror.CodeInvalidRequest,
+			expectedFields: []string{"/cep"},
+		},
+		{
+			name:           "Malformed JSON",
+			path:           "/weather",
+			body:           `{"cep":`,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   apperror.CodeInvalidRequest,
+		},
+		{
+			name:           "Forecast days out of range",
+			path:           "/forecast",
+			body:           `{"cep":"01001000","days":15}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedCode:   apperror.CodeForecastDaysInvalid,
+			expectedFields: []string{"/days"},
+		},
+		{
+			name:           "Invalid history date",
+			path:           "/history",
+			body:           `{"cep":"01001000","date":"01/02/2024"}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedCode:   apperror.CodeDateInvalid,
+			expectedFields: []string{"/date"},
+		},
+		{
+			name:           "Every invalid field is reported",
+			path:           "/forecast",
+			body:           `{"cep":"123","days":-1}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedCode:   apperror.CodeZipCodeInvalid,
+			expectedFields: []string{"/cep", "/days"},
+		},
+		{
+			name:           "Undocumented route",
+			path:           "/unknown",
+			body:           `{"cep":"123"}`,
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var body map[string]interface{}
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					t.Errorf("Handler could not read the body: %v", err)
+				}
+				w.WriteHeader(http.StatusOK)
+			})
+
+			contentType := tt.contentType
+			if contentType == "" {
+				contentType = "application/json"
+			}
+
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", contentType)
+			rec := httptest.NewRecorder()
+			Middleware(validator, &MockLogger{}, next).ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, rec.Code, rec.Body.String())
+			}
+			if tt.expectedCode == "" {
+				return
+			}
+
+			var response apperror.Response
+			if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if response.Code != tt.expectedCode {
+				t.Errorf("Expected code %q, got %q", tt.expectedCode, response.Code)
+			}
+
+			fields := map[string]bool{}
+			for _, field := range response.Fields {
+				fields[field.Field] = true
+				if field.Message == "" {
+					t.Errorf("Field %q has no message", field.Field)
+				}
+			}
+			for _, field := range tt.expectedFields {
+				if !fields[field] {
+					t.Errorf("Expected field %q in %+v", field, response.Fields)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateResponse(t *testing.T) {
+	validator, err := NewValidator(newTestDocument(t))
+	if err != nil {
+		t.Fatalf("NewValidator() error = %v", err)
+	}
+
+	header := http.Header{"Content-Type": []string{"application/json"}}
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr bool
+	}{
+		{name: "Weather", status: http.StatusOK, body: `{"city":"São Paulo","temp_C":25.5}`},
+		{name: "Error", status: http.StatusUnprocessableEntity, body: `{"code":"invalid_zipcode","message":"invalid zipcode"}`},
+		{name: "Missing city", status: http.StatusOK, body: `{"temp_C":25.5}`, wantErr: true},
+		{name: "Wrong type", status: http.StatusOK, body: `{"city":"São Paulo","temp_C":"hot"}`, wantErr: true},
+		{name: "Error without message", status: http.StatusUnprocessableEntity, body: `{"code":"invalid_zipcode"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/weather", strings.NewReader(`{"cep":"01001000"}`))
+			err := validator.ValidateResponse(req, tt.status, header, []byte(tt.body))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateResponse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHandler(t *testing.T) {
+	handler, err := Handler(newTestDocument(t))
+	if err != nil {
+		t.Fatalf("Handler() error = %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+
+	loaded, err := openapi3.NewLoader().LoadFromData(rec.Body.Bytes())
+	if err != nil {
+		t.Fatalf("Failed to load served document: %v", err)
+	}
+	if err := Validate(loaded); err != nil {
+		t.Errorf("Served document is invalid: %v", err)
+	}
+	if loaded.Paths.Find("/weather") == nil {
+		t.Error("Served document is missing /weather")
+	}
+}
@@ -0,0 +1,66 @@
+package ratelimit
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go-a-b-microservices/pkg/apperror"
+	"go-a-b-microservices/pkg/i18n"
+	"go-a-b-microservices/pkg/jsonbody"
+	"go-a-b-microservices/pkg/logger"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// KeyFunc returns the bucket key for a request.
+type KeyFunc func(r *http.Request) string
+
+// ClientIP keys requests by the IP address of the connection.
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// Middleware sets the RateLimit-* headers on every response and rejects
+// requests over the limit with 429 and Retry-After. Store errors let the
+// request through, so an unavailable shared store does not take the API down.
+func Middleware(limiter *Limiter, keyFunc KeyFunc, log logger.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := keyFunc(r)
+
+		result, err := limiter.Allow(r.Context(), key)
+		if err != nil {
+			log.Error("Rate limit store failed for %s: %v", key, err)
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if result.Limit > 0 {
+			w.Header().Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+			w.Header().Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			w.Header().Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+		}
+
+		if !result.Allowed {
+			log.Info("Rate limited %s on %s", key, r.URL.Path)
+			trace.SpanFromContext(r.Context()).SetAttributes(attribute.Bool("ratelimit.limited", true))
+
+			w.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+			jsonbody.Write(w, http.StatusTooManyRequests, i18n.Localize(i18n.FromContext(r.Context()), apperror.ErrRateLimited))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
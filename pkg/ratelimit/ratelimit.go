@@ -0,0 +1,164 @@
+// Package ratelimit implements token bucket rate limiting with a pluggable
+// bucket store.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Limit allows Requests per Period on average, with bursts of up to Burst
+// requests.
+type Limit struct {
+	Requests int
+	Period   time.Duration
+	Burst    int
+}
+
+// NewLimit builds a limit whose burst defaults to the request count.
+func NewLimit(requests int, period time.Duration, burst int) Limit {
+	if burst <= 0 {
+		burst = requests
+	}
+	return Limit{Requests: requests, Period: period, Burst: burst}
+}
+
+// Rate is the refill rate in tokens per second.
+func (l Limit) Rate() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+func (l Limit) Enabled() bool {
+	return l.Requests > 0 && l.Period > 0
+}
+
+// Result is the outcome of taking a token from a bucket.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is how long until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is how long until the next request is allowed; zero when
+	// this one was.
+	RetryAfter time.Duration
+}
+
+// Store keeps the buckets. Take must update a bucket atomically, so a store
+// shared by several instances (such as Redis) has to do the refill and take in
+// a single operation.
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit, n int, now time.Time) (Result, error)
+}
+
+// Bucket is the state of one token bucket.
+type Bucket struct {
+	Tokens  float64
+	Updated time.Time
+}
+
+// Take refills the bucket for the time elapsed since it was last updated and
+// takes n tokens if available. Stores use it to apply the same arithmetic.
+func (b *Bucket) Take(limit Limit, n int, now time.Time) Result {
+	rate := limit.Rate()
+	burst := float64(limit.Burst)
+
+	if b.Updated.IsZero() {
+		b.Tokens = burst
+	} else if elapsed := now.Sub(b.Updated).Seconds(); elapsed > 0 {
+		b.Tokens = math.Min(burst, b.Tokens+elapsed*rate)
+	}
+	b.Updated = now
+
+	result := Result{Limit: limit.Burst}
+	if cost := float64(n); b.Tokens >= cost {
+		b.Tokens -= cost
+		result.Allowed = true
+	} else {
+		result.RetryAfter = seconds((cost - b.Tokens) / rate)
+	}
+
+	result.Remaining = int(math.Floor(b.Tokens))
+	result.Reset = seconds((burst - b.Tokens) / rate)
+	return result
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+// Limiter applies a default limit and per-key overrides on top of a store.
+type Limiter struct {
+	store     Store
+	mu        sync.RWMutex
+	limit     Limit
+	overrides map[string]Limit
+	now       func() time.Time
+}
+
+func NewLimiter(store Store, limit Limit, overrides map[string]Limit) *Limiter {
+	return &Limiter{store: store, limit: limit, overrides: overrides, now: time.Now}
+}
+
+// SetLimits replaces the default limit and the overrides together, while the
+// limiter is in use. Buckets already in the store keep their tokens.
+func (l *Limiter) SetLimits(limit Limit, overrides map[string]Limit) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.limit = limit
+	l.overrides = overrides
+}
+
+// Allow takes one token from the bucket of key.
+func (l *Limiter) Allow(ctx context.Context, key string) (Result, error) {
+	return l.AllowN(ctx, key, 1)
+}
+
+// AllowN takes n tokens from the bucket of key, for a request that costs as
+// much as n. n is capped at the burst, so such a request can still pass once
+// the bucket is full.
+func (l *Limiter) AllowN(ctx context.Context, key string, n int) (Result, error) {
+	l.mu.RLock()
+	limit, ok := l.overrides[key]
+	if !ok {
+		limit = l.limit
+	}
+	l.mu.RUnlock()
+	if !limit.Enabled() {
+		return Result{Allowed: true}, nil
+	}
+
+	return l.store.Take(ctx, key, limit, min(n, limit.Burst), l.now())
+}
+
+// ParseOverrides parses comma separated "name:requests" pairs into limits
+// sharing the period and burst ratio of base.
+func ParseOverrides(value string, base Limit) (map[string]Limit, error) {
+	overrides := make(map[string]Limit)
+
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name, requestsValue, ok := strings.Cut(entry, ":")
+		requests, err := strconv.Atoi(strings.TrimSpace(requestsValue))
+		if !ok || strings.TrimSpace(name) == "" || err != nil || requests < 0 {
+			return nil, fmt.Errorf("invalid rate limit entry %q, expected name:requests", entry)
+		}
+
+		burst := requests
+		if base.Requests > 0 {
+			burst = requests * base.Burst / base.Requests
+		}
+		overrides[strings.TrimSpace(name)] = NewLimit(requests, base.Period, burst)
+	}
+
+	return overrides, nil
+}
@@ -0,0 +1,162 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type MockLogger struct{}
+
+func (m *MockLogger) Info(message string, args ...interface{})  {}
+func (m *MockLogger) Error(message string, args ...interface{}) {}
+func (m *MockLogger) Debug(message string, args ...interface{}) {}
+
+type failingStore struct{}
+
+func (failingStore) Take(ctx context.Context, key string, limit Limit, n int, now time.Time) (Result, error) {
+	return Result{}, errors.New("store unavailable")
+}
+
+func TestBucket_Take(t *testing.T) {
+	limit := NewLimit(60, time.Minute, 3)
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	bucket := &Bucket{}
+
+	tests := []struct {
+		name              string
+		at                time.Duration
+		expectedAllowed   bool
+		expectedRemaining int
+		expectedRetry     time.Duration
+	}{
+		{name: "first request", at: 0, expectedAllowed: true, expectedRemaining: 2},
+		{name: "burst", at: 0, expectedAllowed: true, expectedRemaining: 1},
+		{name: "burst exhausted", at: 0, expectedAllowed: true, expectedRemaining: 0},
+		{name: "limited", at: 0, expectedAllowed: false, expectedRemaining: 0, expectedRetry: time.Second},
+		{name: "half refilled", at: 500 * time.Millisecond, expectedAllowed: false, expectedRemaining: 0, expectedRetry: 500 * time.Millisecond},
+		{name: "refilled one token", at: time.Second, expectedAllowed: true, expectedRemaining: 0},
+		{name: "refilled to burst", at: time.Hour, expectedAllowed: true, expectedRemaining: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := bucket.Take(limit, 1, start.Add(tt.at))
+
+			if result.Allowed != tt.expectedAllowed || result.Remaining != tt.expectedRemaining {
+				t.Errorf("Expected allowed=%v remaining=%d, got %+v", tt.expectedAllowed, tt.expectedRemaining, result)
+			}
+			if result.RetryAfter != tt.expectedRetry {
+				t.Errorf("Expected retry after %v, got %v", tt.expectedRetry, result.RetryAfter)
+			}
+			if result.Limit != 3 {
+				t.Errorf("Expected limit 3, got %d", result.Limit)
+			}
+		})
+	}
+}
+
+func TestLimiter_AllowN(t *testing.T) {
+	limiter := NewLimiter(NewMemoryStore(), NewLimit(60, time.Minute, 5), nil)
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	limiter.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if result, _ := limiter.AllowN(ctx, "a", 3); !result.Allowed || result.Remaining != 2 {
+		t.Fatalf("Expected 3 of 5 tokens to be taken, got %+v", result)
+	}
+	if result, _ := limiter.AllowN(ctx, "a", 3); result.Allowed || result.RetryAfter != time.Second {
+		t.Errorf("Expected a request over the remaining tokens to wait a second, got %+v", result)
+	}
+	if result, _ := limiter.AllowN(ctx, "b", 50); !result.Allowed || result.Remaining != 0 {
+		t.Errorf("Expected a cost over the burst to take the full bucket, got %+v", result)
+	}
+}
+
+func TestParseOverrides(t *testing.T) {
+	overrides, err := ParseOverrides("mobile:600, web:30", NewLimit(60, time.Minute, 120))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if mobile := overrides["mobile"]; mobile.Requests != 600 || mobile.Burst != 1200 || mobile.Period != time.Minute {
+		t.Errorf("Unexpected mobile limit %+v", mobile)
+	}
+	if web := overrides["web"]; web.Requests != 30 || web.Burst != 60 {
+		t.Errorf("Unexpected web limit %+v", web)
+	}
+
+	for _, value := range []string{"mobile", "mobile:many", ":10", "web:-1"} {
+		if _, err := ParseOverrides(value, NewLimit(60, time.Minute, 0)); err == nil {
+			t.Errorf("Expected an error for %q", value)
+		}
+	}
+}
+
+func TestMemoryStore_SweepsFullBuckets(t *testing.T) {
+	store := NewMemoryStore()
+	limit := NewLimit(10, time.Second, 0)
+	now := time.Now()
+
+	store.Take(context.Background(), "a", limit, 1, now)
+	store.Take(context.Background(), "b", limit, 1, now)
+	if store.Len() != 2 {
+		t.Fatalf("Expected 2 buckets, got %d", store.Len())
+	}
+
+	store.Take(context.Background(), "b", limit, 1, now.Add(2*time.Minute))
+	if store.Len() != 1 {
+		t.Errorf("Expected idle buckets to be swept, got %d", store.Len())
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	limiter := NewLimiter(NewMemoryStore(), NewLimit(2, time.Minute, 0), map[string]Limit{"ip:10.0.0.2": {}})
+	handler := Middleware(limiter, ClientIP, &MockLogger{}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	request := func(remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/zipcode", nil)
+		req.RemoteAddr = remoteAddr
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	request("10.0.0.1:1234")
+	rec := request("10.0.0.1:1235")
+	if rec.Code != http.StatusOK || rec.Header().Get("RateLimit-Limit") != "2" || rec.Header().Get("RateLimit-Remaining") != "0" {
+		t.Errorf("Unexpected response %d %v", rec.Code, rec.Header())
+	}
+
+	rec = request("10.0.0.1:1236")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected status %d, got %d", http.StatusTooManyRequests, rec.Code)
+	}
+	if rec.Header().Get("Retry-After") != "30" || rec.Header().Get("RateLimit-Reset") != "60" {
+		t.Errorf("Unexpected retry headers %v", rec.Header())
+	}
+
+	if rec := request("10.0.0.3:1234"); rec.Code != http.StatusOK {
+		t.Errorf("Expected another client to have its own bucket, got %d", rec.Code)
+	}
+
+	for i := 0; i < 5; i++ {
+		if rec := request("10.0.0.2:1234"); rec.Code != http.StatusOK || rec.Header().Get("RateLimit-Limit") != "" {
+			t.Fatalf("Expected an unlimited override, got %d %v", rec.Code, rec.Header())
+		}
+	}
+
+	failing := Middleware(NewLimiter(failingStore{}, NewLimit(1, time.Minute, 0), nil), ClientIP, &MockLogger{}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	rec = httptest.NewRecorder()
+	failing.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/zipcode", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected requests to pass when the store fails, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,132 @@
+package temperature
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"go-a-b-microservices/pkg/apperror"
+)
+
+const (
+	// KelvinOffset is the exact difference between the Kelvin and Celsius scales.
+	KelvinOffset = 273.15
+	// FahrenheitScale and FahrenheitOffset convert Celsius to Fahrenheit.
+	FahrenheitScale  = 9.0 / 5.0
+	FahrenheitOffset = 32.0
+
+	// DefaultPrecision matches the tenths of a degree reported by WeatherAPI.
+	DefaultPrecision = 1
+)
+
+type Unit string
+
+const (
+	Celsius    Unit = "C"
+	Fahrenheit Unit = "F"
+	Kelvin     Unit = "K"
+)
+
+// AllUnits is the selection used when a request does not ask for specific units.
+var AllUnits = []Unit{Celsius, Fahrenheit, Kelvin}
+
+// ParseUnits parses a comma separated unit selection such as "C,F". An empty
+// selection returns AllUnits.
+func ParseUnits(value string) ([]Unit, error) {
+	if strings.TrimSpace(value) == "" {
+		return AllUnits, nil
+	}
+
+	var units []Unit
+	seen := make(map[Unit]bool)
+	for _, part := range strings.Split(value, ",") {
+		unit := Unit(strings.ToUpper(strings.TrimSpace(part)))
+		switch unit {
+		case Celsius, Fahrenheit, Kelvin:
+		default:
+			return nil, apperror.ErrUnitsInvalid
+		}
+
+		if !seen[unit] {
+			seen[unit] = true
+			units = append(units, unit)
+		}
+	}
+
+	return units, nil
+}
+
+func CelsiusToFahrenheit(celsius float64) float64 {
+	return celsius*FahrenheitScale + FahrenheitOffset
+}
+
+func CelsiusToKelvin(celsius float64) float64 {
+	return celsius + KelvinOffset
+}
+
+type RoundingMode string
+
+const (
+	RoundHalfUp   RoundingMode = "half_up"
+	RoundHalfEven RoundingMode = "half_even"
+	RoundDown     RoundingMode = "down"
+	RoundNone     RoundingMode = "none"
+)
+
+func ParseRoundingMode(value string) (RoundingMode, error) {
+	switch mode := RoundingMode(strings.ToLower(strings.TrimSpace(value))); mode {
+	case RoundHalfUp, RoundHalfEven, RoundDown, RoundNone:
+		return mode, nil
+	case "":
+		return RoundHalfUp, nil
+	default:
+		return "", fmt.Errorf("unknown rounding mode %q", value)
+	}
+}
+
+// Converter converts Celsius readings and rounds the result to a fixed number
+// of decimal places.
+type Converter struct {
+	Precision int
+	Rounding  RoundingMode
+}
+
+// DefaultConverter rounds half up to the provider precision.
+var DefaultConverter = Converter{Precision: DefaultPrecision, Rounding: RoundHalfUp}
+
+func NewConverter(precision int, rounding RoundingMode) Converter {
+	return Converter{Precision: precision, Rounding: rounding}
+}
+
+func (c Converter) Convert(celsius float64, unit Unit) float64 {
+	switch unit {
+	case Fahrenheit:
+		return c.Round(CelsiusToFahrenheit(celsius))
+	case Kelvin:
+		return c.Round(CelsiusToKelvin(celsius))
+	default:
+		return c.Round(celsius)
+	}
+}
+
+func (c Converter) Round(value float64) float64 {
+	if c.Rounding == RoundNone {
+		return value
+	}
+
+	scale := math.Pow(10, float64(c.Precision))
+	// Snap away binary representation error first so that 301.45, stored as
+	// 301.44999999999998863, still rounds as the decimal value it stands for.
+	scaled := math.Round(value*scale*1e6) / 1e6
+
+	switch c.Rounding {
+	case RoundHalfEven:
+		scaled = math.RoundToEven(scaled)
+	case RoundDown:
+		scaled = math.Trunc(scaled)
+	default:
+		scaled = math.Round(scaled)
+	}
+
+	return scaled / scale
+}
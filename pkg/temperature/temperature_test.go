@@ -0,0 +1,229 @@
+package temperature
+
+import (
+	"reflect"
+	"testing"
+
+	"go-a-b-microservices/pkg/apperror"
+)
+
+func TestParseUnits(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    []Unit
+		wantErr error
+	}{
+		{
+			name:  "empty selects all units",
+			value: "",
+			want:  AllUnits,
+		},
+		{
+			name:  "single unit",
+			value: "K",
+			want:  []Unit{Kelvin},
+		},
+		{
+			name:  "subset keeps order and ignores case and spaces",
+			value: "f, c",
+			want:  []Unit{Fahrenheit, Celsius},
+		},
+		{
+			name:  "duplicates are removed",
+			value: "C,C",
+			want:  []Unit{Celsius},
+		},
+		{
+			name:    "unknown unit",
+			value:   "C,R",
+			wantErr: apperror.ErrUnitsInvalid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseUnits(tt.value)
+
+			if err != tt.wantErr {
+				t.Errorf("ParseUnits() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseUnits() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCelsiusToFahrenheit(t *testing.T) {
+	tests := []struct {
+		name     string
+		celsius  float64
+		expected float64
+	}{
+		{
+			name:     "zero celsius",
+			celsius:  0,
+			expected: 32,
+		},
+		{
+			name:     "positive value",
+			celsius:  25,
+			expected: 77,
+		},
+		{
+			name:     "negative value",
+			celsius:  -10,
+			expected: 14,
+		},
+		{
+			name:     "decimal value",
+			celsius:  37.5,
+			expected: 99.5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := CelsiusToFahrenheit(tt.celsius)
+
+			if result != tt.expected {
+				t.Errorf("CelsiusToFahrenheit(%v) = %v, want %v", tt.celsius, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCelsiusToKelvin(t *testing.T) {
+	tests := []struct {
+		name     string
+		celsius  float64
+		expected float64
+	}{
+		{
+			name:     "zero celsius",
+			celsius:  0,
+			expected: 273.15,
+		},
+		{
+			name:     "positive value",
+			celsius:  25,
+			expected: 298.15,
+		},
+		{
+			name:     "negative value",
+			celsius:  -10,
+			expected: 263.15,
+		},
+		{
+			name:     "decimal value",
+			celsius:  37.5,
+			expected: 310.65,
+		},
+		{
+			name:     "absolute zero",
+			celsius:  -273.15,
+			expected: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := CelsiusToKelvin(tt.celsius)
+
+			if result != tt.expected {
+				t.Errorf("CelsiusToKelvin(%v) = %v, want %v", tt.celsius, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestConverter_Convert(t *testing.T) {
+	tests := []struct {
+		name      string
+		converter Converter
+		celsius   float64
+		unit      Unit
+		expected  float64
+	}{
+		{
+			name:      "fahrenheit to provider precision",
+			converter: DefaultConverter,
+			celsius:   28.3,
+			unit:      Fahrenheit,
+			expected:  82.9,
+		},
+		{
+			name:      "kelvin rounds half up",
+			converter: DefaultConverter,
+			celsius:   28.3,
+			unit:      Kelvin,
+			expected:  301.5,
+		},
+		{
+			name:      "two decimals",
+			converter: NewConverter(2, RoundHalfUp),
+			celsius:   28.3,
+			unit:      Fahrenheit,
+			expected:  82.94,
+		},
+		{
+			name:      "kelvin with two decimals is exact",
+			converter: NewConverter(2, RoundHalfUp),
+			celsius:   28.3,
+			unit:      Kelvin,
+			expected:  301.45,
+		},
+		{
+			name:      "half even",
+			converter: NewConverter(1, RoundHalfEven),
+			celsius:   28.3,
+			unit:      Kelvin,
+			expected:  301.4,
+		},
+		{
+			name:      "round down",
+			converter: NewConverter(0, RoundDown),
+			celsius:   28.9,
+			unit:      Celsius,
+			expected:  28,
+		},
+		{
+			name:      "negative half up rounds away from zero",
+			converter: NewConverter(0, RoundHalfUp),
+			celsius:   -2.5,
+			unit:      Celsius,
+			expected:  -3,
+		},
+		{
+			name:      "no rounding",
+			converter: NewConverter(1, RoundNone),
+			celsius:   0,
+			unit:      Kelvin,
+			expected:  273.15,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.converter.Convert(tt.celsius, tt.unit)
+
+			if result != tt.expected {
+				t.Errorf("Convert(%v, %s) = %v, want %v", tt.celsius, tt.unit, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseRoundingMode(t *testing.T) {
+	if mode, err := ParseRoundingMode(""); err != nil || mode != RoundHalfUp {
+		t.Errorf("ParseRoundingMode(\"\") = %v, %v, want half_up", mode, err)
+	}
+	if mode, err := ParseRoundingMode("HALF_EVEN"); err != nil || mode != RoundHalfEven {
+		t.Errorf("ParseRoundingMode(\"HALF_EVEN\") = %v, %v, want half_even", mode, err)
+	}
+	if _, err := ParseRoundingMode("ceil"); err == nil {
+		t.Errorf("Expected an error for an unknown rounding mode")
+	}
+}
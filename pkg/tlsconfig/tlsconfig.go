@@ -0,0 +1,237 @@
+// Package tlsconfig builds TLS configurations whose certificates are reloaded
+// from disk when they change, so certificates can be renewed without a
+// restart.
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"go-a-b-microservices/pkg/logger"
+)
+
+// reloadInterval bounds how often the certificate files are checked.
+const reloadInterval = 10 * time.Second
+
+// ParseVersion parses a minimum TLS version such as "1.2" or "1.3". An empty
+// value means TLS 1.2.
+func ParseVersion(value string) (uint16, error) {
+	switch value {
+	case "", "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("unsupported TLS version %q, expected 1.2 or 1.3", value)
+	}
+}
+
+// CertReloader serves a certificate and key pair, reloading it when either
+// file's modification time changes. A pair that fails to load is logged and
+// the previous one keeps being served.
+type CertReloader struct {
+	certFile string
+	keyFile  string
+	logger   logger.Logger
+
+	mu        sync.Mutex
+	cert      *tls.Certificate
+	modTime   time.Time
+	checkedAt time.Time
+	now       func() time.Time
+}
+
+func NewCertReloader(certFile, keyFile string, log logger.Logger) (*CertReloader, error) {
+	r := &CertReloader{certFile: certFile, keyFile: keyFile, logger: log, now: time.Now}
+
+	modTime, err := r.latestModTime()
+	if err != nil {
+		return nil, err
+	}
+	if err := r.load(modTime); err != nil {
+		return nil, err
+	}
+	r.checkedAt = r.now()
+
+	return r, nil
+}
+
+func (r *CertReloader) Certificate() *tls.Certificate {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	if now.Sub(r.checkedAt) < reloadInterval {
+		return r.cert
+	}
+	r.checkedAt = now
+
+	modTime, err := r.latestModTime()
+	if err != nil {
+		r.logger.Error("Failed to check certificate %s: %v", r.certFile, err)
+		return r.cert
+	}
+	if modTime.Equal(r.modTime) {
+		return r.cert
+	}
+
+	if err := r.load(modTime); err != nil {
+		r.logger.Error("Failed to reload certificate %s: %v", r.certFile, err)
+	} else {
+		r.logger.Info("Reloaded certificate %s", r.certFile)
+	}
+	return r.cert
+}
+
+func (r *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return r.Certificate(), nil
+}
+
+func (r *CertReloader) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return r.Certificate(), nil
+}
+
+func (r *CertReloader) load(modTime time.Time) error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+	r.cert = &cert
+	r.modTime = modTime
+	return nil
+}
+
+func (r *CertReloader) latestModTime() (time.Time, error) {
+	var latest time.Time
+	for _, path := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(path)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+// ServerConfig serves the reloaded certificate over TLS 1.2+ with HTTP/2.
+// When clientCAFile is set, clients must present a certificate signed by one
+// of its CAs.
+func ServerConfig(certs *CertReloader, minVersion uint16, clientCAFile string) (*tls.Config, error) {
+	config := &tls.Config{
+		MinVersion:     minVersion,
+		GetCertificate: certs.GetCertificate,
+		NextProtos:     []string{"h2", "http/1.1"},
+	}
+
+	if clientCAFile != "" {
+		pool, err := loadCertPool(clientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return config, nil
+}
+
+// ClientConfig trusts the CAs in caFile in addition to the system roots, when
+// set, and presents the reloaded client certificate, when given.
+func ClientConfig(caFile string, certs *CertReloader, minVersion uint16) (*tls.Config, error) {
+	config := &tls.Config{MinVersion: minVersion}
+
+	if caFile != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if err := appendCerts(pool, caFile); err != nil {
+			return nil, err
+		}
+		config.RootCAs = pool
+	}
+
+	if certs != nil {
+		config.GetClientCertificate = certs.GetClientCertificate
+	}
+
+	return config, nil
+}
+
+func loadCertPool(path string) (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+	if err := appendCerts(pool, path); err != nil {
+		return nil, err
+	}
+	return pool, nil
+}
+
+func appendCerts(pool *x509.CertPool, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return fmt.Errorf("%s: no certificates found", path)
+	}
+	return nil
+}
+
+// NewServerConfig builds the server configuration from file paths, returning
+// nil when certFile is empty and TLS is disabled.
+func NewServerConfig(certFile, keyFile, minVersion, clientCAFile string, log logger.Logger) (*tls.Config, error) {
+	if certFile == "" {
+		return nil, nil
+	}
+
+	version, err := ParseVersion(minVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	certs, err := NewCertReloader(certFile, keyFile, log)
+	if err != nil {
+		return nil, err
+	}
+
+	return ServerConfig(certs, version, clientCAFile)
+}
+
+// NewClientConfig builds the client configuration from file paths, returning
+// nil when neither a CA bundle nor a client certificate is configured.
+func NewClientConfig(caFile, certFile, keyFile, minVersion string, log logger.Logger) (*tls.Config, error) {
+	if caFile == "" && certFile == "" {
+		return nil, nil
+	}
+
+	version, err := ParseVersion(minVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	var certs *CertReloader
+	if certFile != "" {
+		certs, err = NewCertReloader(certFile, keyFile, log)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return ClientConfig(caFile, certs, version)
+}
+
+// ListenAndServe serves over TLS, with HTTP/2, when the server has a TLS
+// configuration and plain HTTP otherwise.
+func ListenAndServe(server *http.Server) error {
+	if server.TLSConfig != nil {
+		return server.ListenAndServeTLS("", "")
+	}
+	return server.ListenAndServe()
+}
@@ -0,0 +1,189 @@
+package tlsconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type MockLogger struct{}
+
+func (m *MockLogger) Info(message string, args ...interface{})  {}
+func (m *MockLogger) Error(message string, args ...interface{}) {}
+func (m *MockLogger) Debug(message string, args ...interface{}) {}
+
+// writeCert writes a self-signed certificate for localhost and returns the
+// certificate and key paths.
+func writeCert(t *testing.T, dir, name string, serial int64) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(serial),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+
+	certFile := filepath.Join(dir, name+".crt")
+	keyFile := filepath.Join(dir, name+".key")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("Failed to write certificate: %v", err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatalf("Failed to write key: %v", err)
+	}
+
+	return certFile, keyFile
+}
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		value       string
+		expected    uint16
+		expectedErr bool
+	}{
+		{value: "", expected: tls.VersionTLS12},
+		{value: "1.2", expected: tls.VersionTLS12},
+		{value: "1.3", expected: tls.VersionTLS13},
+		{value: "1.1", expectedErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			version, err := ParseVersion(tt.value)
+			if (err != nil) != tt.expectedErr || version != tt.expected {
+				t.Errorf("ParseVersion(%q) = %d, %v", tt.value, version, err)
+			}
+		})
+	}
+}
+
+func TestCertReloader_ReloadsChangedFiles(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeCert(t, dir, "server", 1)
+
+	reloader, err := NewCertReloader(certFile, keyFile, &MockLogger{})
+	if err != nil {
+		t.Fatalf("Failed to load certificate: %v", err)
+	}
+	now := time.Now()
+	reloader.now = func() time.Time { return now }
+
+	serial := func() int64 {
+		leaf, err := x509.ParseCertificate(reloader.Certificate().Certificate[0])
+		if err != nil {
+			t.Fatalf("Failed to parse certificate: %v", err)
+		}
+		return leaf.SerialNumber.Int64()
+	}
+
+	writeCert(t, dir, "server", 2)
+	future := time.Now().Add(time.Minute)
+	os.Chtimes(certFile, future, future)
+
+	if got := serial(); got != 1 {
+		t.Errorf("Expected files not to be checked before the interval, got serial %d", got)
+	}
+
+	now = now.Add(reloadInterval)
+	if got := serial(); got != 2 {
+		t.Errorf("Expected the renewed certificate, got serial %d", got)
+	}
+
+	os.WriteFile(keyFile, []byte("garbage"), 0o600)
+	later := future.Add(time.Minute)
+	os.Chtimes(keyFile, later, later)
+	now = now.Add(reloadInterval)
+	if got := serial(); got != 2 {
+		t.Errorf("Expected a broken pair to keep the previous certificate, got serial %d", got)
+	}
+}
+
+func TestServerConfig_MutualTLSAndHTTP2(t *testing.T) {
+	dir := t.TempDir()
+	serverCert, serverKey := writeCert(t, dir, "server", 1)
+	clientCert, clientKey := writeCert(t, dir, "client", 2)
+
+	serverCerts, err := NewCertReloader(serverCert, serverKey, &MockLogger{})
+	if err != nil {
+		t.Fatalf("Failed to load server certificate: %v", err)
+	}
+	serverConfig, err := ServerConfig(serverCerts, tls.VersionTLS12, clientCert)
+	if err != nil {
+		t.Fatalf("Failed to build server config: %v", err)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	server := &http.Server{
+		Handler:   http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+		TLSConfig: serverConfig,
+		ErrorLog:  log.New(io.Discard, "", 0),
+	}
+	go server.ServeTLS(listener, "", "")
+	defer server.Close()
+	serverURL := "https://" + listener.Addr().String()
+
+	newClient := func(certs *CertReloader) *http.Client {
+		clientConfig, err := ClientConfig(serverCert, certs, tls.VersionTLS12)
+		if err != nil {
+			t.Fatalf("Failed to build client config: %v", err)
+		}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = clientConfig
+		return &http.Client{Transport: transport}
+	}
+
+	clientCerts, err := NewCertReloader(clientCert, clientKey, &MockLogger{})
+	if err != nil {
+		t.Fatalf("Failed to load client certificate: %v", err)
+	}
+
+	resp, err := newClient(clientCerts).Get(serverURL)
+	if err != nil {
+		t.Fatalf("Expected the client certificate to be accepted, got %v", err)
+	}
+	resp.Body.Close()
+	if resp.ProtoMajor != 2 {
+		t.Errorf("Expected HTTP/2, got %s", resp.Proto)
+	}
+
+	if resp, err := newClient(nil).Get(serverURL); err == nil {
+		resp.Body.Close()
+		t.Errorf("Expected a client without a certificate to be rejected")
+	}
+}
@@ -0,0 +1,273 @@
+package weatherpb
+
+import (
+	"time"
+
+	"go-a-b-microservices/pkg/zipcode"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// The New* functions convert the zipcode types into messages and the methods
+// on the messages convert them back, so both ends of the gRPC transport share
+// the JSON API's types.
+
+func NewWeatherRequest(request *zipcode.ZipCodeRequest) *WeatherRequest {
+	return &WeatherRequest{Cep: request.CEP, Units: request.Units, Alerts: request.Alerts, Aqi: request.AQI}
+}
+
+func (x *WeatherRequest) ZipCodeRequest() *zipcode.ZipCodeRequest {
+	return &zipcode.ZipCodeRequest{CEP: x.GetCep(), Units: x.GetUnits(), Alerts: x.GetAlerts(), AQI: x.GetAqi()}
+}
+
+func NewForecastRequest(request *zipcode.ForecastRequest) *ForecastRequest {
+	return &ForecastRequest{Cep: request.CEP, Days: int32(request.Days), Units: request.Units, Hourly: request.Hourly}
+}
+
+func (x *ForecastRequest) ZipCodeRequest() *zipcode.ForecastRequest {
+	return &zipcode.ForecastRequest{CEP: x.GetCep(), Days: int(x.GetDays()), Units: x.GetUnits(), Hourly: x.GetHourly()}
+}
+
+func NewHistoryRequest(request *zipcode.HistoryRequest) *HistoryRequest {
+	return &HistoryRequest{Cep: request.CEP, Date: request.Date, EndDate: request.EndDate, Units: request.Units}
+}
+
+func (x *HistoryRequest) ZipCodeRequest() *zipcode.HistoryRequest {
+	return &zipcode.HistoryRequest{CEP: x.GetCep(), Date: x.GetDate(), EndDate: x.GetEndDate(), Units: x.GetUnits()}
+}
+
+func NewTemperature(temperature zipcode.Temperature) *Temperature {
+	return &Temperature{C: temperature.C, F: temperature.F, K: temperature.K}
+}
+
+func (x *Temperature) Temperature() zipcode.Temperature {
+	if x == nil {
+		return zipcode.Temperature{}
+	}
+	return zipcode.Temperature{C: x.C, F: x.F, K: x.K}
+}
+
+func NewWeatherResponse(response *zipcode.WeatherResponse) *WeatherResponse {
+	conditions := response.Conditions
+	message := &WeatherResponse{
+		City: response.City,
+		Temp: &Temperature{C: response.TempC, F: response.TempF, K: response.TempK},
+		Conditions: &Conditions{
+			FeelsLike:     &Temperature{C: conditions.FeelsLikeC, F: conditions.FeelsLikeF, K: conditions.FeelsLikeK},
+			Humidity:      int32Pointer(conditions.Humidity),
+			WindKph:       conditions.WindKph,
+			WindDegree:    int32Pointer(conditions.WindDegree),
+			WindDir:       conditions.WindDir,
+			PressureMb:    conditions.PressureMb,
+			PrecipMm:      conditions.PrecipMm,
+			Uv:            conditions.UV,
+			Condition:     conditions.Condition,
+			ConditionIcon: conditions.ConditionIcon,
+			IsDay:         conditions.IsDay,
+		},
+		Stale:      response.Stale,
+		AgeSeconds: response.AgeSeconds,
+	}
+
+	for _, alert := range response.Alerts {
+		message.Alerts = append(message.Alerts, &Alert{
+			Headline:    alert.Headline,
+			Event:       alert.Event,
+			Severity:    string(alert.Severity),
+			Urgency:     alert.Urgency,
+			Areas:       alert.Areas,
+			Description: alert.Description,
+			Instruction: alert.Instruction,
+			Effective:   timestamp(alert.Effective),
+			Expires:     timestamp(alert.Expires),
+		})
+	}
+
+	if aq := response.AirQuality; aq != nil {
+		message.AirQuality = &AirQuality{
+			Co:           aq.CO,
+			No2:          aq.NO2,
+			O3:           aq.O3,
+			So2:          aq.SO2,
+			Pm2_5:        aq.PM2_5,
+			Pm10:         aq.PM10,
+			UsEpaIndex:   int32Pointer(aq.USEPAIndex),
+			GbDefraIndex: int32Pointer(aq.GBDefraIndex),
+			Category:     aq.Category,
+		}
+	}
+
+	return message
+}
+
+func (x *WeatherResponse) WeatherResponse() *zipcode.WeatherResponse {
+	temp := x.GetTemp().Temperature()
+	conditions := x.GetConditions()
+	if conditions == nil {
+		conditions = &Conditions{}
+	}
+	feelsLike := conditions.GetFeelsLike().Temperature()
+
+	response := &zipcode.WeatherResponse{
+		City:  x.GetCity(),
+		TempC: temp.C,
+		TempF: temp.F,
+		TempK: temp.K,
+		Conditions: zipcode.Conditions{
+			FeelsLikeC:    feelsLike.C,
+			FeelsLikeF:    feelsLike.F,
+			FeelsLikeK:    feelsLike.K,
+			Humidity:      intPointer(conditions.Humidity),
+			WindKph:       conditions.WindKph,
+			WindDegree:    intPointer(conditions.WindDegree),
+			WindDir:       conditions.GetWindDir(),
+			PressureMb:    conditions.PressureMb,
+			PrecipMm:      conditions.PrecipMm,
+			UV:            conditions.Uv,
+			Condition:     conditions.GetCondition(),
+			ConditionIcon: conditions.GetConditionIcon(),
+			IsDay:         conditions.IsDay,
+		},
+		Stale:      x.GetStale(),
+		AgeSeconds: x.GetAgeSeconds(),
+	}
+
+	for _, alert := range x.GetAlerts() {
+		response.Alerts = append(response.Alerts, zipcode.Alert{
+			Headline:    alert.GetHeadline(),
+			Event:       alert.GetEvent(),
+			Severity:    zipcode.AlertSeverity(alert.GetSeverity()),
+			Urgency:     alert.GetUrgency(),
+			Areas:       alert.GetAreas(),
+			Description: alert.GetDescription(),
+			Instruction: alert.GetInstruction(),
+			Effective:   timePointer(alert.GetEffective()),
+			Expires:     timePointer(alert.GetExpires()),
+		})
+	}
+
+	if aq := x.GetAirQuality(); aq != nil {
+		response.AirQuality = &zipcode.AirQuality{
+			CO:           aq.Co,
+			NO2:          aq.No2,
+			O3:           aq.O3,
+			SO2:          aq.So2,
+			PM2_5:        aq.Pm2_5,
+			PM10:         aq.Pm10,
+			USEPAIndex:   intPointer(aq.UsEpaIndex),
+			GBDefraIndex: intPointer(aq.GbDefraIndex),
+			Category:     aq.GetCategory(),
+		}
+	}
+
+	return response
+}
+
+func NewForecastResponse(response *zipcode.ForecastResponse) *ForecastResponse {
+	message := &ForecastResponse{City: response.City}
+	for _, day := range response.Days {
+		messageDay := &ForecastDay{
+			Date:         day.Date,
+			MinTemp:      NewTemperature(day.MinTemp),
+			MaxTemp:      NewTemperature(day.MaxTemp),
+			ChanceOfRain: int32(day.ChanceOfRain),
+			Condition:    day.Condition,
+		}
+		for _, hour := range day.Hourly {
+			messageDay.Hourly = append(messageDay.Hourly, &ForecastHour{
+				Time:         hour.Time,
+				Temp:         NewTemperature(hour.Temp),
+				ChanceOfRain: int32(hour.ChanceOfRain),
+				Condition:    hour.Condition,
+			})
+		}
+		message.Days = append(message.Days, messageDay)
+	}
+	return message
+}
+
+func (x *ForecastResponse) ForecastResponse() *zipcode.ForecastResponse {
+	response := &zipcode.ForecastResponse{City: x.GetCity(), Days: []zipcode.ForecastDay{}}
+	for _, day := range x.GetDays() {
+		responseDay := zipcode.ForecastDay{
+			Date:         day.GetDate(),
+			MinTemp:      day.GetMinTemp().Temperature(),
+			MaxTemp:      day.GetMaxTemp().Temperature(),
+			ChanceOfRain: int(day.GetChanceOfRain()),
+			Condition:    day.GetCondition(),
+		}
+		for _, hour := range day.GetHourly() {
+			responseDay.Hourly = append(responseDay.Hourly, zipcode.ForecastHour{
+				Time:         hour.GetTime(),
+				Temp:         hour.GetTemp().Temperature(),
+				ChanceOfRain: int(hour.GetChanceOfRain()),
+				Condition:    hour.GetCondition(),
+			})
+		}
+		response.Days = append(response.Days, responseDay)
+	}
+	return response
+}
+
+func NewHistoryResponse(response *zipcode.HistoryResponse) *HistoryResponse {
+	message := &HistoryResponse{City: response.City}
+	for _, day := range response.Days {
+		message.Days = append(message.Days, &HistoryDay{
+			Date:          day.Date,
+			MinTemp:       NewTemperature(day.MinTemp),
+			MaxTemp:       NewTemperature(day.MaxTemp),
+			AvgTemp:       NewTemperature(day.AvgTemp),
+			TotalPrecipMm: day.TotalPrecipMm,
+			AvgHumidity:   int32(day.AvgHumidity),
+			Condition:     day.Condition,
+		})
+	}
+	return message
+}
+
+func (x *HistoryResponse) HistoryResponse() *zipcode.HistoryResponse {
+	response := &zipcode.HistoryResponse{City: x.GetCity(), Days: []zipcode.HistoryDay{}}
+	for _, day := range x.GetDays() {
+		response.Days = append(response.Days, zipcode.HistoryDay{
+			Date:          day.GetDate(),
+			MinTemp:       day.GetMinTemp().Temperature(),
+			MaxTemp:       day.GetMaxTemp().Temperature(),
+			AvgTemp:       day.GetAvgTemp().Temperature(),
+			TotalPrecipMm: day.GetTotalPrecipMm(),
+			AvgHumidity:   int(day.GetAvgHumidity()),
+			Condition:     day.GetCondition(),
+		})
+	}
+	return response
+}
+
+func int32Pointer(value *int) *int32 {
+	if value == nil {
+		return nil
+	}
+	converted := int32(*value)
+	return &converted
+}
+
+func intPointer(value *int32) *int {
+	if value == nil {
+		return nil
+	}
+	converted := int(*value)
+	return &converted
+}
+
+func timestamp(value *time.Time) *timestamppb.Timestamp {
+	if value == nil {
+		return nil
+	}
+	return timestamppb.New(*value)
+}
+
+func timePointer(value *timestamppb.Timestamp) *time.Time {
+	if value == nil {
+		return nil
+	}
+	converted := value.AsTime()
+	return &converted
+}
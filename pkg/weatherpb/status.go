@@ -0,0 +1,86 @@
+package weatherpb
+
+import (
+	"errors"
+
+	"go-a-b-microservices/pkg/apperror"
+	"go-a-b-microservices/pkg/i18n"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errorDomain identifies the apperror codes carried in ErrorInfo details.
+const errorDomain = "go-a-b-microservices"
+
+// MetadataLanguage carries the negotiated language, like Accept-Language does
+// over HTTP.
+const MetadataLanguage = "accept-language"
+
+// StatusError converts err into a gRPC status with a localized message and
+// the apperror code as ErrorInfo reason.
+func StatusError(lang i18n.Language, err error) error {
+	response := i18n.Localize(lang, err)
+
+	st := status.New(statusCode(response.Code), response.Message)
+	if detailed, detailErr := st.WithDetails(&errdetails.ErrorInfo{Reason: response.Code, Domain: errorDomain}); detailErr == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+// AppError converts a status returned by StatusError back into the apperror
+// it was built from. Other errors keep their message.
+func AppError(err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+
+	for _, detail := range st.Details() {
+		info, ok := detail.(*errdetails.ErrorInfo)
+		if !ok || info.GetDomain() != errorDomain {
+			continue
+		}
+		if appErr := apperror.FromCode(info.GetReason()); appErr != nil && info.GetReason() != apperror.CodeInternal {
+			return appErr
+		}
+	}
+
+	return errors.New(st.Message())
+}
+
+// NewError converts err into the Error message of a batch result.
+func NewError(lang i18n.Language, err error) *Error {
+	response := i18n.Localize(lang, err)
+	return &Error{Code: response.Code, Message: response.Message}
+}
+
+// AppError converts a batch result error back into an apperror.
+func (x *Error) AppError() error {
+	if appErr := apperror.FromCode(x.GetCode()); appErr != nil && x.GetCode() != apperror.CodeInternal {
+		return appErr
+	}
+	return errors.New(x.GetMessage())
+}
+
+func statusCode(code string) codes.Code {
+	switch code {
+	case apperror.CodeZipCodeRequired, apperror.CodeZipCodeInvalid, apperror.CodeForecastDaysInvalid,
+		apperror.CodeUnitsInvalid, apperror.CodeDateInvalid, apperror.CodeDateOutOfRange, apperror.CodeInvalidRequest:
+		return codes.InvalidArgument
+	case apperror.CodeZipCodeNotFound:
+		return codes.NotFound
+	case apperror.CodeUnauthorized:
+		return codes.Unauthenticated
+	case apperror.CodeForbidden:
+		return codes.PermissionDenied
+	case apperror.CodeRateLimited:
+		return codes.ResourceExhausted
+	case apperror.CodeWeatherUnavailable:
+		return codes.Unavailable
+	default:
+		return codes.Internal
+	}
+}
@@ -0,0 +1,88 @@
+package weatherpb
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"go-a-b-microservices/pkg/apperror"
+	"go-a-b-microservices/pkg/i18n"
+	"go-a-b-microservices/pkg/zipcode"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestWeatherResponse_RoundTrip(t *testing.T) {
+	tempC, tempK, humidity, isDay := 25.0, 298.2, 80, true
+	effective := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		response *zipcode.WeatherResponse
+	}{
+		{
+			name:     "minimal",
+			response: &zipcode.WeatherResponse{City: "São Paulo"},
+		},
+		{
+			name: "full",
+			response: &zipcode.WeatherResponse{
+				City:  "São Paulo",
+				TempC: &tempC,
+				TempK: &tempK,
+				Conditions: zipcode.Conditions{
+					Humidity:  &humidity,
+					WindDir:   "NE",
+					Condition: "Sunny",
+					IsDay:     &isDay,
+				},
+				Alerts: []zipcode.Alert{{
+					Headline:  "Heat",
+					Severity:  zipcode.SeveritySevere,
+					Effective: &effective,
+				}},
+			},
+		},
+		{
+			name:     "stale",
+			response: &zipcode.WeatherResponse{City: "São Paulo", TempC: &tempC, Stale: true, AgeSeconds: 840},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewWeatherResponse(tt.response).WeatherResponse()
+			if !reflect.DeepEqual(got, tt.response) {
+				t.Errorf("Expected %+v, got %+v", tt.response, got)
+			}
+		})
+	}
+}
+
+func TestStatusError_AppError(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          error
+		expectedCode codes.Code
+		expectedErr  error
+	}{
+		{name: "invalid zipcode", err: apperror.ErrZipCodeInvalid, expectedCode: codes.InvalidArgument, expectedErr: apperror.ErrZipCodeInvalid},
+		{name: "not found", err: apperror.ErrZipCodeNotFound, expectedCode: codes.NotFound, expectedErr: apperror.ErrZipCodeNotFound},
+		{name: "weather unavailable", err: apperror.ErrWeatherUnavailable, expectedCode: codes.Unavailable, expectedErr: apperror.ErrWeatherUnavailable},
+		{name: "unknown error", err: errors.New("boom"), expectedCode: codes.Internal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := StatusError(i18n.English, tt.err)
+			if status.Code(err) != tt.expectedCode {
+				t.Errorf("Expected code %v, got %v", tt.expectedCode, status.Code(err))
+			}
+			if tt.expectedErr != nil && !errors.Is(AppError(err), tt.expectedErr) {
+				t.Errorf("Expected error %v, got %v", tt.expectedErr, AppError(err))
+			}
+		})
+	}
+}
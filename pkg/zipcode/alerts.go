@@ -0,0 +1,85 @@
+package zipcode
+
+import (
+	"strings"
+	"time"
+)
+
+// WeatherOptions selects the optional data a provider should include with the
+// current conditions.
+type WeatherOptions struct {
+	Alerts     bool
+	AirQuality bool
+}
+
+type AlertSeverity string
+
+const (
+	SeverityUnknown  AlertSeverity = "unknown"
+	SeverityMinor    AlertSeverity = "minor"
+	SeverityModerate AlertSeverity = "moderate"
+	SeveritySevere   AlertSeverity = "severe"
+	SeverityExtreme  AlertSeverity = "extreme"
+)
+
+// NormalizeSeverity maps a provider severity onto the CAP severity levels.
+func NormalizeSeverity(value string) AlertSeverity {
+	switch AlertSeverity(strings.ToLower(strings.TrimSpace(value))) {
+	case SeverityMinor:
+		return SeverityMinor
+	case SeverityModerate:
+		return SeverityModerate
+	case SeveritySevere:
+		return SeveritySevere
+	case SeverityExtreme:
+		return SeverityExtreme
+	default:
+		return SeverityUnknown
+	}
+}
+
+// Alert is a severe-weather warning issued for the location.
+type Alert struct {
+	Headline    string        `json:"headline"`
+	Event       string        `json:"event,omitempty"`
+	Severity    AlertSeverity `json:"severity"`
+	Urgency     string        `json:"urgency,omitempty"`
+	Areas       string        `json:"areas,omitempty"`
+	Description string        `json:"description,omitempty"`
+	Instruction string        `json:"instruction,omitempty"`
+	Effective   *time.Time    `json:"effective,omitempty"`
+	Expires     *time.Time    `json:"expires,omitempty"`
+}
+
+// AirQuality holds pollutant concentrations in μg/m³ and the derived indices.
+type AirQuality struct {
+	CO           *float64 `json:"co,omitempty"`
+	NO2          *float64 `json:"no2,omitempty"`
+	O3           *float64 `json:"o3,omitempty"`
+	SO2          *float64 `json:"so2,omitempty"`
+	PM2_5        *float64 `json:"pm2_5,omitempty"`
+	PM10         *float64 `json:"pm10,omitempty"`
+	USEPAIndex   *int     `json:"us_epa_index,omitempty"`
+	GBDefraIndex *int     `json:"gb_defra_index,omitempty"`
+	Category     string   `json:"category,omitempty"`
+}
+
+// EPACategory returns the US EPA category name for an index from 1 to 6.
+func EPACategory(index int) string {
+	switch index {
+	case 1:
+		return "good"
+	case 2:
+		return "moderate"
+	case 3:
+		return "unhealthy_for_sensitive_groups"
+	case 4:
+		return "unhealthy"
+	case 5:
+		return "very_unhealthy"
+	case 6:
+		return "hazardous"
+	default:
+		return ""
+	}
+}
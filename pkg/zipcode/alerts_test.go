@@ -0,0 +1,37 @@
+package zipcode
+
+import "testing"
+
+func TestNormalizeSeverity(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected AlertSeverity
+	}{
+		{value: "Moderate", expected: SeverityModerate},
+		{value: " SEVERE ", expected: SeveritySevere},
+		{value: "extreme", expected: SeverityExtreme},
+		{value: "minor", expected: SeverityMinor},
+		{value: "", expected: SeverityUnknown},
+		{value: "Perigo", expected: SeverityUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if got := NormalizeSeverity(tt.value); got != tt.expected {
+				t.Errorf("NormalizeSeverity(%q) = %q, want %q", tt.value, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEPACategory(t *testing.T) {
+	if got := EPACategory(1); got != "good" {
+		t.Errorf("EPACategory(1) = %q, want good", got)
+	}
+	if got := EPACategory(6); got != "hazardous" {
+		t.Errorf("EPACategory(6) = %q, want hazardous", got)
+	}
+	if got := EPACategory(0); got != "" {
+		t.Errorf("EPACategory(0) = %q, want empty", got)
+	}
+}
@@ -0,0 +1,24 @@
+package zipcode
+
+import "go-a-b-microservices/pkg/apperror"
+
+// MaxBatchSize bounds the requests in one weather batch.
+const MaxBatchSize = 100
+
+// WeatherBatchRequest asks for the weather of several ZIP codes in one call.
+type WeatherBatchRequest struct {
+	Requests []ZipCodeRequest `json:"requests"`
+}
+
+// WeatherBatchResponse holds one result per request, in request order.
+type WeatherBatchResponse struct {
+	Results []WeatherBatchResult `json:"results"`
+}
+
+// WeatherBatchResult is the outcome of the request at Index: either its
+// weather or the error it failed with.
+type WeatherBatchResult struct {
+	Index   int                `json:"index"`
+	Weather *WeatherResponse   `json:"weather,omitempty"`
+	Error   *apperror.Response `json:"error,omitempty"`
+}
@@ -0,0 +1,86 @@
+package zipcode
+
+import (
+	"go-a-b-microservices/pkg/apperror"
+	"go-a-b-microservices/pkg/temperature"
+)
+
+const (
+	DefaultForecastDays = 3
+	MaxForecastDays     = 14
+)
+
+type ForecastRequest struct {
+	CEP    string `json:"cep"`
+	Days   int    `json:"days,omitempty"`
+	Units  string `json:"units,omitempty"`
+	Hourly bool   `json:"hourly,omitempty"`
+}
+
+func (f *ForecastRequest) Validate() error {
+	zipCodeRequest := ZipCodeRequest{CEP: f.CEP}
+	if err := zipCodeRequest.Validate(); err != nil {
+		return err
+	}
+
+	if f.Days < 0 || f.Days > MaxForecastDays {
+		return apperror.ErrForecastDaysInvalid
+	}
+
+	if _, err := temperature.ParseUnits(f.Units); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ForecastDays returns the requested number of days, falling back to
+// DefaultForecastDays when none was given.
+func (f *ForecastRequest) ForecastDays() int {
+	if f.Days == 0 {
+		return DefaultForecastDays
+	}
+	return f.Days
+}
+
+type ForecastResponse struct {
+	City string        `json:"city"`
+	Days []ForecastDay `json:"days"`
+}
+
+type ForecastDay struct {
+	Date         string         `json:"date"`
+	MinTemp      Temperature    `json:"min_temp"`
+	MaxTemp      Temperature    `json:"max_temp"`
+	ChanceOfRain int            `json:"chance_of_rain"`
+	Condition    string         `json:"condition,omitempty"`
+	Hourly       []ForecastHour `json:"hourly,omitempty"`
+}
+
+type ForecastHour struct {
+	Time         string      `json:"time"`
+	Temp         Temperature `json:"temp"`
+	ChanceOfRain int         `json:"chance_of_rain"`
+	Condition    string      `json:"condition,omitempty"`
+}
+
+// ForecastData is the provider-neutral forecast for a city.
+type ForecastData struct {
+	Days []DailyForecast `json:"days"`
+}
+
+type DailyForecast struct {
+	Date         string           `json:"date"`
+	MinTempC     float64          `json:"mintemp_c"`
+	MaxTempC     float64          `json:"maxtemp_c"`
+	ChanceOfRain int              `json:"daily_chance_of_rain"`
+	Condition    *Condition       `json:"condition,omitempty"`
+	Hours        []HourlyForecast `json:"hours,omitempty"`
+}
+
+type HourlyForecast struct {
+	Time         string     `json:"time"`
+	TempC        float64    `json:"temp_c"`
+	ChanceOfRain int        `json:"chance_of_rain"`
+	Condition    *Condition `json:"condition,omitempty"`
+}
@@ -0,0 +1,133 @@
+package zipcode
+
+import (
+	"time"
+
+	"go-a-b-microservices/pkg/apperror"
+	"go-a-b-microservices/pkg/temperature"
+)
+
+const (
+	DateLayout = "2006-01-02"
+
+	// MaxHistoryRangeDays is the longest date range a single history request
+	// may cover, inclusive of both ends.
+	MaxHistoryRangeDays = 30
+
+	// DefaultHistoryMaxAgeDays is how far back history can be requested when
+	// no provider specific limit is configured.
+	DefaultHistoryMaxAgeDays = 365
+)
+
+type HistoryRequest struct {
+	CEP     string `json:"cep"`
+	Date    string `json:"date"`
+	EndDate string `json:"end_date,omitempty"`
+	Units   string `json:"units,omitempty"`
+}
+
+// Validate checks the request fields and the shape of the date range. Checks
+// that depend on the current date are done by ValidateBounds.
+func (h *HistoryRequest) Validate() error {
+	zipCodeRequest := ZipCodeRequest{CEP: h.CEP}
+	if err := zipCodeRequest.Validate(); err != nil {
+		return err
+	}
+
+	start, end, err := h.Dates()
+	if err != nil {
+		return err
+	}
+
+	if end.Before(start) {
+		return apperror.ErrDateInvalid
+	}
+
+	if end.Sub(start) >= MaxHistoryRangeDays*24*time.Hour {
+		return apperror.ErrDateOutOfRange
+	}
+
+	if _, err := temperature.ParseUnits(h.Units); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ValidateBounds rejects ranges that end after today or start more than
+// maxAgeDays before it.
+func (h *HistoryRequest) ValidateBounds(now time.Time, maxAgeDays int) error {
+	start, end, err := h.Dates()
+	if err != nil {
+		return err
+	}
+
+	today := truncateToDate(now)
+	if end.After(today) {
+		return apperror.ErrDateOutOfRange
+	}
+
+	if start.Before(today.AddDate(0, 0, -maxAgeDays)) {
+		return apperror.ErrDateOutOfRange
+	}
+
+	return nil
+}
+
+// Dates returns the requested range. EndDate defaults to Date.
+func (h *HistoryRequest) Dates() (time.Time, time.Time, error) {
+	if h.Date == "" {
+		return time.Time{}, time.Time{}, apperror.ErrDateInvalid
+	}
+
+	start, err := time.Parse(DateLayout, h.Date)
+	if err != nil {
+		return time.Time{}, time.Time{}, apperror.ErrDateInvalid
+	}
+
+	if h.EndDate == "" {
+		return start, start, nil
+	}
+
+	end, err := time.Parse(DateLayout, h.EndDate)
+	if err != nil {
+		return time.Time{}, time.Time{}, apperror.ErrDateInvalid
+	}
+
+	return start, end, nil
+}
+
+func truncateToDate(t time.Time) time.Time {
+	year, month, day := t.UTC().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+type HistoryResponse struct {
+	City string       `json:"city"`
+	Days []HistoryDay `json:"days"`
+}
+
+type HistoryDay struct {
+	Date          string      `json:"date"`
+	MinTemp       Temperature `json:"min_temp"`
+	MaxTemp       Temperature `json:"max_temp"`
+	AvgTemp       Temperature `json:"avg_temp"`
+	TotalPrecipMm float64     `json:"total_precip_mm"`
+	AvgHumidity   int         `json:"avg_humidity"`
+	Condition     string      `json:"condition,omitempty"`
+}
+
+// HistoryData is the provider-neutral observed weather for a city.
+type HistoryData struct {
+	Days []HistoricalDay `json:"days"`
+}
+
+type HistoricalDay struct {
+	Date          string     `json:"date"`
+	MinTempC      float64    `json:"mintemp_c"`
+	MaxTempC      float64    `json:"maxtemp_c"`
+	AvgTempC      float64    `json:"avgtemp_c"`
+	TotalPrecipMm float64    `json:"totalprecip_mm"`
+	AvgHumidity   int        `json:"avghumidity"`
+	Condition     *Condition `json:"condition,omitempty"`
+}
@@ -0,0 +1,104 @@
+package zipcode
+
+import (
+	"testing"
+	"time"
+
+	"go-a-b-microservices/pkg/apperror"
+)
+
+func TestHistoryRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		request HistoryRequest
+		wantErr error
+	}{
+		{
+			name:    "single date",
+			request: HistoryRequest{CEP: "13484000", Date: "2025-01-10"},
+		},
+		{
+			name:    "date range",
+			request: HistoryRequest{CEP: "13484000", Date: "2025-01-01", EndDate: "2025-01-30"},
+		},
+		{
+			name:    "missing date",
+			request: HistoryRequest{CEP: "13484000"},
+			wantErr: apperror.ErrDateInvalid,
+		},
+		{
+			name:    "malformed date",
+			request: HistoryRequest{CEP: "13484000", Date: "10/01/2025"},
+			wantErr: apperror.ErrDateInvalid,
+		},
+		{
+			name:    "end before start",
+			request: HistoryRequest{CEP: "13484000", Date: "2025-01-10", EndDate: "2025-01-09"},
+			wantErr: apperror.ErrDateInvalid,
+		},
+		{
+			name:    "range too long",
+			request: HistoryRequest{CEP: "13484000", Date: "2025-01-01", EndDate: "2025-01-31"},
+			wantErr: apperror.ErrDateOutOfRange,
+		},
+		{
+			name:    "invalid zipcode",
+			request: HistoryRequest{CEP: "123", Date: "2025-01-10"},
+			wantErr: apperror.ErrZipCodeInvalid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.request.Validate()
+
+			if err != tt.wantErr {
+				t.Errorf("HistoryRequest.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHistoryRequest_ValidateBounds(t *testing.T) {
+	now := time.Date(2025, 5, 10, 15, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		request HistoryRequest
+		wantErr error
+	}{
+		{
+			name:    "today is allowed",
+			request: HistoryRequest{Date: "2025-05-10"},
+		},
+		{
+			name:    "oldest allowed date",
+			request: HistoryRequest{Date: "2025-05-03"},
+		},
+		{
+			name:    "future date",
+			request: HistoryRequest{Date: "2025-05-11"},
+			wantErr: apperror.ErrDateOutOfRange,
+		},
+		{
+			name:    "range ending in the future",
+			request: HistoryRequest{Date: "2025-05-09", EndDate: "2025-05-11"},
+			wantErr: apperror.ErrDateOutOfRange,
+		},
+		{
+			name:    "older than max age",
+			request: HistoryRequest{Date: "2025-05-02"},
+			wantErr: apperror.ErrDateOutOfRange,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.request.ValidateBounds(now, 7)
+
+			if err != tt.wantErr {
+				t.Errorf("HistoryRequest.ValidateBounds() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -2,12 +2,24 @@ package zipcode
 
 import (
 	"regexp"
+	"strings"
+	"time"
 
 	"go-a-b-microservices/pkg/apperror"
+	"go-a-b-microservices/pkg/temperature"
 )
 
+var cepPattern = regexp.MustCompile(`^\d{8}$`)
+
 type ZipCodeRequest struct {
-	CEP string `json:"cep"`
+	CEP    string `json:"cep"`
+	Units  string `json:"units,omitempty"`
+	Alerts bool   `json:"alerts,omitempty"`
+	AQI    bool   `json:"aqi,omitempty"`
+}
+
+func (z *ZipCodeRequest) Options() WeatherOptions {
+	return WeatherOptions{Alerts: z.Alerts, AirQuality: z.AQI}
 }
 
 func (z *ZipCodeRequest) Validate() error {
@@ -15,28 +27,108 @@ func (z *ZipCodeRequest) Validate() error {
 		return apperror.ErrZipCodeRequired
 	}
 
-	matched, _ := regexp.MatchString(`^\d{8}$`, z.CEP)
-	if !matched {
+	if !cepPattern.MatchString(z.CEP) {
 		return apperror.ErrZipCodeInvalid
 	}
 
+	if _, err := temperature.ParseUnits(z.Units); err != nil {
+		return err
+	}
+
 	return nil
 }
 
+// ParseCEP returns the eight digits of a CEP written with or without the
+// hyphen, as in 13480-000, so datasets are held to the same rules as
+// requests.
+func ParseCEP(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", apperror.ErrZipCodeRequired
+	}
+	if len(value) == 9 && value[5] == '-' {
+		value = value[:5] + value[6:]
+	}
+	if !cepPattern.MatchString(value) {
+		return "", apperror.ErrZipCodeInvalid
+	}
+	return value, nil
+}
+
 type Location struct {
 	City string `json:"localidade"`
 	CEP  string `json:"cep"`
 }
 
+// WeatherResponse carries the temperature in each requested unit; units that
+// were not requested are omitted.
 type WeatherResponse struct {
-	City  string  `json:"city"`
-	TempC float64 `json:"temp_C"`
-	TempF float64 `json:"temp_F"`
-	TempK float64 `json:"temp_K"`
+	City  string   `json:"city"`
+	TempC *float64 `json:"temp_C,omitempty"`
+	TempF *float64 `json:"temp_F,omitempty"`
+	TempK *float64 `json:"temp_K,omitempty"`
+	Conditions
+	Alerts     []Alert     `json:"alerts,omitempty"`
+	AirQuality *AirQuality `json:"air_quality,omitempty"`
+	// Stale is set when the weather is a cached observation served past its
+	// soft TTL, AgeSeconds old.
+	Stale      bool  `json:"stale,omitempty"`
+	AgeSeconds int64 `json:"age_seconds,omitempty"`
+}
+
+// Conditions holds the optional current conditions returned next to the
+// temperature. Fields are omitted when the provider did not report them.
+type Conditions struct {
+	FeelsLikeC    *float64 `json:"feels_like_C,omitempty"`
+	FeelsLikeF    *float64 `json:"feels_like_F,omitempty"`
+	FeelsLikeK    *float64 `json:"feels_like_K,omitempty"`
+	Humidity      *int     `json:"humidity,omitempty"`
+	WindKph       *float64 `json:"wind_kph,omitempty"`
+	WindDegree    *int     `json:"wind_degree,omitempty"`
+	WindDir       string   `json:"wind_dir,omitempty"`
+	PressureMb    *float64 `json:"pressure_mb,omitempty"`
+	PrecipMm      *float64 `json:"precip_mm,omitempty"`
+	UV            *float64 `json:"uv,omitempty"`
+	Condition     string   `json:"condition,omitempty"`
+	ConditionIcon string   `json:"condition_icon,omitempty"`
+	IsDay         *bool    `json:"is_day,omitempty"`
+}
+
+// Temperature holds a value in each of the requested units.
+type Temperature struct {
+	C *float64 `json:"C,omitempty"`
+	F *float64 `json:"F,omitempty"`
+	K *float64 `json:"K,omitempty"`
 }
 
+// WeatherData is the provider-neutral weather observation for a city.
 type WeatherData struct {
-	Current struct {
-		TempC float64 `json:"temp_c"`
-	} `json:"current"`
+	Current    CurrentWeather `json:"current"`
+	Alerts     []Alert        `json:"alerts,omitempty"`
+	AirQuality *AirQuality    `json:"air_quality,omitempty"`
+	// Stale and Age are set by the cache when it serves an observation past
+	// its soft TTL; providers leave them unset.
+	Stale bool          `json:"-"`
+	Age   time.Duration `json:"-"`
+}
+
+// CurrentWeather holds the conditions observed by the provider. Optional
+// fields are nil when the provider did not report them.
+type CurrentWeather struct {
+	TempC      float64    `json:"temp_c"`
+	FeelsLikeC *float64   `json:"feelslike_c,omitempty"`
+	Humidity   *int       `json:"humidity,omitempty"`
+	WindKph    *float64   `json:"wind_kph,omitempty"`
+	WindDegree *int       `json:"wind_degree,omitempty"`
+	WindDir    string     `json:"wind_dir,omitempty"`
+	PressureMb *float64   `json:"pressure_mb,omitempty"`
+	PrecipMm   *float64   `json:"precip_mm,omitempty"`
+	UV         *float64   `json:"uv,omitempty"`
+	Condition  *Condition `json:"condition,omitempty"`
+	IsDay      *bool      `json:"is_day,omitempty"`
+}
+
+type Condition struct {
+	Text string `json:"text"`
+	Icon string `json:"icon"`
 }
@@ -60,3 +60,32 @@ func TestZipCodeRequest_Validate(t *testing.T) {
 		})
 	}
 }
+
+func TestParseCEP(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr error
+	}{
+		{name: "digits", value: "13480000", want: "13480000"},
+		{name: "hyphenated", value: "13480-000", want: "13480000"},
+		{name: "surrounding spaces", value: " 01001-000 ", want: "01001000"},
+		{name: "empty", value: "  ", wantErr: apperror.ErrZipCodeRequired},
+		{name: "misplaced hyphen", value: "1348-0000", wantErr: apperror.ErrZipCodeInvalid},
+		{name: "too short", value: "1348000", wantErr: apperror.ErrZipCodeInvalid},
+		{name: "dotted", value: "13.480-000", wantErr: apperror.ErrZipCodeInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCEP(tt.value)
+			if err != tt.wantErr {
+				t.Fatalf("ParseCEP(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseCEP(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
@@ -2,66 +2,280 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"go-a-b-microservices/pkg/config"
+	"go-a-b-microservices/pkg/hmacauth"
+	"go-a-b-microservices/pkg/i18n"
 	"go-a-b-microservices/pkg/logger"
+	"go-a-b-microservices/pkg/openapi"
 	"go-a-b-microservices/pkg/otel"
+	"go-a-b-microservices/pkg/ratelimit"
+	"go-a-b-microservices/pkg/tlsconfig"
+	"go-a-b-microservices/service-a/internal/adapter/graphql"
 	custom_http "go-a-b-microservices/service-a/internal/adapter/http"
+	"go-a-b-microservices/service-a/internal/auth"
 	"go-a-b-microservices/service-a/internal/repository"
 	"go-a-b-microservices/service-a/internal/usecase"
 
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
+// configPollInterval is how often the config and secret files are checked for
+// changes.
+const configPollInterval = 2 * time.Second
+
 func main() {
+	config.RegisterFlags(flag.CommandLine, &config.ServiceA{})
+	printConfig := flag.Bool("print-config", false, "print the effective configuration, with secrets redacted, and exit")
+	flag.Parse()
+
 	log := logger.NewLogger()
-	log.Info("Starting Service A")
 
-	cfg, err := config.LoadConfig("service-a")
+	cfg, err := config.LoadServiceA(flag.CommandLine)
+	if *printConfig {
+		if err := config.Print(os.Stdout, cfg); err != nil {
+			log.Error("Failed to print configuration: %v", err)
+			os.Exit(1)
+		}
+	}
 	if err != nil {
 		log.Error("Failed to load configuration: %v", err)
 		os.Exit(1)
 	}
+	if *printConfig {
+		return
+	}
+
+	level, _ := logger.ParseLevel(cfg.LogLevel)
+	log.SetLevel(level)
+	log.Info("Starting Service A")
 
 	ctx := context.Background()
-	tp, err := otel.InitTracer(cfg.ServiceName, cfg.ZipkinEndpoint, log)
+	tp, err := otel.InitTracer(cfg.ServiceName, cfg.ZipkinEndpoint.String(), log)
 	if err != nil {
 		log.Error("Failed to initialize tracer: %v", err)
 		os.Exit(1)
 	}
 	defer otel.ShutdownTracer(ctx, tp, log)
 
-	serviceBClient := repository.NewServiceBClient(cfg, log)
+	serviceBKeys, err := hmacauth.ParseKeys(cfg.ServiceAuthKeys)
+	if err != nil {
+		log.Error("Invalid SERVICE_AUTH_KEYS: %v", err)
+		os.Exit(1)
+	}
+	signer, err := hmacauth.NewSigner(serviceBKeys)
+	if err != nil {
+		log.Error("Service B requests cannot be signed, set SERVICE_AUTH_KEYS: %v", err)
+		os.Exit(1)
+	}
+
+	serviceBTLS, err := tlsconfig.NewClientConfig(cfg.ServiceBCAFile, cfg.ServiceBCertFile, cfg.ServiceBKeyFile, cfg.TLSMinVersion, log)
+	if err != nil {
+		log.Error("Failed to configure TLS for Service B: %v", err)
+		os.Exit(1)
+	}
+
+	var serviceBClient repository.ServiceBClientInterface
+	var setServiceBTimeout func(time.Duration)
+	switch cfg.ServiceBTransport {
+	case "http":
+		httpClient := repository.NewServiceBClient(cfg, log)
+		httpClient.SetSigner(signer)
+		if serviceBTLS != nil {
+			httpClient.SetTLSConfig(serviceBTLS)
+		}
+		serviceBClient = httpClient
+		setServiceBTimeout = httpClient.SetTimeout
+	case "grpc":
+		// An https SERVICE_B_URL means Service B serves TLS on both ports.
+		if serviceBTLS == nil && cfg.ServiceBURL.Scheme == "https" {
+			version, err := tlsconfig.ParseVersion(cfg.TLSMinVersion)
+			if err != nil {
+				log.Error("Invalid TLS_MIN_VERSION: %v", err)
+				os.Exit(1)
+			}
+			serviceBTLS, _ = tlsconfig.ClientConfig("", nil, version)
+		}
+		grpcClient, err := repository.NewServiceBGRPCClient(cfg.ServiceBGRPCAddr, serviceBTLS, signer, log)
+		if err != nil {
+			log.Error("Failed to create gRPC client for Service B: %v", err)
+			os.Exit(1)
+		}
+		defer grpcClient.Close()
+		grpcClient.SetTimeout(cfg.ServiceBTimeout)
+		serviceBClient = grpcClient
+		setServiceBTimeout = grpcClient.SetTimeout
+	default:
+		log.Error("Unsupported SERVICE_B_TRANSPORT: %s", cfg.ServiceBTransport)
+		os.Exit(1)
+	}
+	log.Info("Calling Service B over %s", cfg.ServiceBTransport)
+
 	zipCodeUseCase := usecase.NewZipCodeUseCase(serviceBClient, log)
 	handler := custom_http.NewHandler(zipCodeUseCase, log)
+	handler.SetMaxBodyBytes(int64(cfg.MaxBodyBytes))
 
 	mux := http.NewServeMux()
 
 	handler.RegisterRoutes(mux)
 
-	otelHandler := otelhttp.NewHandler(mux, cfg.ServiceName)
+	graphQLHandler, err := graphql.NewHandler(zipCodeUseCase, log)
+	if err != nil {
+		log.Error("Failed to build GraphQL schema: %v", err)
+		os.Exit(1)
+	}
+	graphQLHandler.SetMaxBodyBytes(int64(cfg.MaxBodyBytes))
+	graphQLHandler.RegisterRoutes(mux)
+
+	defaultLanguage, ok := i18n.Parse(cfg.DefaultLanguage)
+	if !ok {
+		log.Error("Unsupported DEFAULT_LANGUAGE: %s", cfg.DefaultLanguage)
+		os.Exit(1)
+	}
+
+	apiKeys, err := auth.NewKeyStore(cfg)
+	if err != nil {
+		log.Error("Failed to load API keys: %v", err)
+		os.Exit(1)
+	}
+	authenticator, err := auth.NewAuthenticator(cfg, apiKeys)
+	if err != nil {
+		log.Error("Failed to configure authentication: %v", err)
+		os.Exit(1)
+	}
+
+	limit, overrides, err := rateLimits(cfg)
+	if err != nil {
+		log.Error("Invalid RATE_LIMIT_CLIENTS: %v", err)
+		os.Exit(1)
+	}
+	limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), limit, overrides)
+	graphQLHandler.SetRateLimiter(limiter, auth.RateLimitKey)
+
+	apiDoc, err := custom_http.OpenAPI()
+	if err != nil {
+		log.Error("Failed to build OpenAPI document: %v", err)
+		os.Exit(1)
+	}
+	validator, err := openapi.NewValidator(apiDoc)
+	if err != nil {
+		log.Error("Failed to build request validator: %v", err)
+		os.Exit(1)
+	}
+	validator.SetMaxBodyBytes(int64(cfg.MaxBodyBytes))
+	specHandler, err := openapi.Handler(apiDoc)
+	if err != nil {
+		log.Error("Failed to serve OpenAPI document: %v", err)
+		os.Exit(1)
+	}
+
+	// Callers are identified before rate limiting so they are keyed by client,
+	// and rejected after it so failed attempts count against their IP address.
+	var appHandler http.Handler = openapi.Middleware(validator, log, mux)
+	if authenticator != nil {
+		appHandler = auth.Require(appHandler)
+	}
+	appHandler = ratelimit.Middleware(limiter, auth.RateLimitKey, log, appHandler)
+	if authenticator != nil {
+		log.Info("Authentication mode: %s", cfg.AuthMode)
+		appHandler = auth.Identify(authenticator, log, appHandler)
+	} else {
+		log.Info("Authentication is disabled")
+	}
+
+	// The API description is public, outside authentication and rate limits.
+	rootMux := http.NewServeMux()
+	rootMux.Handle("/openapi.json", specHandler)
+	if cfg.OpenAPIDocs {
+		docs := openapi.DocsHandler("Service A", "/openapi.json")
+		rootMux.Handle("/docs", docs)
+		rootMux.Handle("/docs/", docs)
+	}
+	rootMux.Handle("/", appHandler)
+
+	otelHandler := otelhttp.NewHandler(i18n.Middleware(defaultLanguage, rootMux), cfg.ServiceName)
+
+	tlsConfig, err := tlsconfig.NewServerConfig(cfg.TLSCertFile, cfg.TLSKeyFile, cfg.TLSMinVersion, cfg.TLSClientCAFile, log)
+	if err != nil {
+		log.Error("Failed to configure TLS: %v", err)
+		os.Exit(1)
+	}
 
 	server := &http.Server{
-		Addr:    fmt.Sprintf(":%s", cfg.ServiceAPort),
-		Handler: otelHandler,
+		Addr:      fmt.Sprintf(":%d", cfg.Port),
+		Handler:   otelHandler,
+		TLSConfig: tlsConfig,
 	}
 
 	go func() {
-		log.Info("Service A listening on port %s", cfg.ServiceAPort)
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		log.Info("Service A listening on port %d (TLS: %t)", cfg.Port, tlsConfig != nil)
+		if err := tlsconfig.ListenAndServe(server); err != nil && err != http.ErrServerClosed {
 			log.Error("Failed to start server: %v", err)
 			os.Exit(1)
 		}
 	}()
 
+	// Settings tagged reload:"true" in pkg/config are applied here on SIGHUP
+	// or when the config file or a secret file changes.
+	reloader := config.NewReloader(cfg, func() (*config.ServiceA, error) {
+		return config.LoadServiceA(flag.CommandLine)
+	}, func(next *config.ServiceA) (func(), error) {
+		// Everything that can fail is checked before anything changes.
+		limit, overrides, err := rateLimits(next)
+		if err != nil {
+			return nil, fmt.Errorf("RATE_LIMIT_CLIENTS: %w", err)
+		}
+		keys, err := hmacauth.ParseKeys(next.ServiceAuthKeys)
+		if err != nil {
+			return nil, fmt.Errorf("SERVICE_AUTH_KEYS: %w", err)
+		}
+		nextAPIKeys, err := auth.NewKeyStore(next)
+		if err != nil {
+			return nil, fmt.Errorf("API keys: %w", err)
+		}
+		level, _ := logger.ParseLevel(next.LogLevel)
+
+		return func() {
+			log.SetLevel(level)
+			handler.SetMaxBodyBytes(int64(next.MaxBodyBytes))
+			graphQLHandler.SetMaxBodyBytes(int64(next.MaxBodyBytes))
+			validator.SetMaxBodyBytes(int64(next.MaxBodyBytes))
+			limiter.SetLimits(limit, overrides)
+			setServiceBTimeout(next.ServiceBTimeout)
+			apiKeys.Replace(nextAPIKeys)
+			// ParseKeys returned at least one key, all SetKeys checks.
+			_ = signer.SetKeys(keys)
+		}, nil
+	}, log)
+	watchCtx, stopWatching := context.WithCancel(ctx)
+	defer stopWatching()
+	go reloader.Watch(watchCtx, configPollInterval)
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
 	log.Info("Shutting down Service A")
 }
+
+// rateLimits builds the default limit and the per-client overrides, keyed as
+// auth.RateLimitKey keys them.
+func rateLimits(cfg *config.ServiceA) (ratelimit.Limit, map[string]ratelimit.Limit, error) {
+	limit := ratelimit.NewLimit(cfg.RateLimitRequests, cfg.RateLimitPeriod, cfg.RateLimitBurst)
+	clientLimits, err := ratelimit.ParseOverrides(cfg.RateLimitClients, limit)
+	if err != nil {
+		return limit, nil, err
+	}
+	overrides := make(map[string]ratelimit.Limit, len(clientLimits))
+	for clientID, clientLimit := range clientLimits {
+		overrides[auth.ClientRateLimitKey(clientID)] = clientLimit
+	}
+	return limit, overrides, nil
+}
@@ -0,0 +1,193 @@
+package graphql
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"go-a-b-microservices/pkg/apperror"
+	"go-a-b-microservices/pkg/i18n"
+	"go-a-b-microservices/pkg/jsonbody"
+	"go-a-b-microservices/pkg/logger"
+	"go-a-b-microservices/pkg/ratelimit"
+	"go-a-b-microservices/service-a/internal/usecase"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Handler serves the GraphQL API over CEP, location and weather.
+type Handler struct {
+	schema         graphql.Schema
+	zipCodeUseCase *usecase.ZipCodeUseCase
+	logger         logger.Logger
+	decoder        *jsonbody.Decoder
+	limiter        *ratelimit.Limiter
+	rateLimitKey   ratelimit.KeyFunc
+}
+
+type request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+func NewHandler(zipCodeUseCase *usecase.ZipCodeUseCase, logger logger.Logger) (*Handler, error) {
+	schema, err := newSchema()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Handler{
+		schema:         schema,
+		zipCodeUseCase: zipCodeUseCase,
+		decoder:        jsonbody.NewDecoder(jsonbody.DefaultMaxBytes),
+		logger:         logger,
+	}, nil
+}
+
+func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/graphql", h.ServeGraphQL)
+}
+
+// ServeGraphQL executes a query sent as a JSON body with POST, or in the
+// query, operationName and variables parameters with GET. Every Service B
+// call the query needs is scoped to this request, so weather for several
+// CEPs is fetched in one batch. Calls past the first are charged to the
+// caller's rate limit.
+func (h *Handler) ServeGraphQL(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("service-a")
+	ctx, span := tracer.Start(r.Context(), "graphql.ServeGraphQL")
+	defer span.End()
+
+	var req request
+	switch r.Method {
+	case http.MethodGet:
+		query := r.URL.Query()
+		req.Query = query.Get("query")
+		req.OperationName = query.Get("operationName")
+		if variables := query.Get("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+				h.logger.Error("Failed to parse GraphQL variables: %v", err)
+				h.writeError(w, r, apperror.ErrInvalidRequest)
+				return
+			}
+		}
+	case http.MethodPost:
+		// Fields other than those of request, such as extensions, are
+		// ignored as GraphQL over HTTP allows.
+		body, err := h.decoder.Read(w, r)
+		if err != nil {
+			h.logger.Error("Failed to read request body: %v", err)
+			h.writeError(w, r, err)
+			return
+		}
+
+		if err := json.Unmarshal(body, &req); err != nil {
+			h.logger.Error("Failed to parse JSON: %v", err)
+			h.writeError(w, r, apperror.ErrInvalidRequest)
+			return
+		}
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if req.Query == "" {
+		h.writeError(w, r, apperror.ErrInvalidRequest)
+		return
+	}
+	span.SetAttributes(attribute.String("graphql.operation.name", req.OperationName))
+
+	result := graphql.Do(graphql.Params{
+		Schema:         h.schema,
+		RequestString:  req.Query,
+		OperationName:  req.OperationName,
+		VariableValues: req.Variables,
+		Context:        withLoaders(ctx, newLoaders(ctx, h.zipCodeUseCase, h.charge(r))),
+	})
+	h.localizeErrors(r, result.Errors)
+
+	jsonbody.Write(w, http.StatusOK, result)
+}
+
+// localizeErrors replaces the message of each resolver error with its
+// localized message and adds its code as an extension. Errors in the query
+// itself keep their message and get the invalid_request code.
+func (h *Handler) localizeErrors(r *http.Request, errs []gqlerrors.FormattedError) {
+	lang := i18n.FromContext(r.Context())
+	for i := range errs {
+		err := resolverError(errs[i].OriginalError())
+		if err == nil {
+			errs[i].Extensions = map[string]interface{}{"code": apperror.CodeInvalidRequest}
+			continue
+		}
+
+		switch apperror.Code(err) {
+		case apperror.CodeZipCodeRequired:
+			err = apperror.Replace(err, apperror.ErrZipCodeInvalid)
+		case apperror.CodeInternal:
+			h.logger.Error("Failed to resolve %v: %v", errs[i].Path, err)
+		}
+
+		localized := i18n.Localize(lang, err)
+		errs[i].Message = localized.Message
+		errs[i].Extensions = map[string]interface{}{"code": localized.Code}
+	}
+}
+
+// resolverError digs the error returned by a resolver out of the wrappers the
+// executor adds. Errors in the query itself wrap nothing and give nil.
+func resolverError(err error) error {
+	for {
+		switch wrapped := err.(type) {
+		case gqlerrors.FormattedError:
+			err = wrapped.OriginalError()
+		case *gqlerrors.Error:
+			err = wrapped.OriginalError
+		default:
+			return err
+		}
+	}
+}
+
+// SetRateLimiter charges the Service B calls of a query past the first to the
+// bucket keyFunc picks in limiter, the one the request itself was taken from.
+func (h *Handler) SetRateLimiter(limiter *ratelimit.Limiter, keyFunc ratelimit.KeyFunc) {
+	h.limiter = limiter
+	h.rateLimitKey = keyFunc
+}
+
+// charge returns the function that takes n more tokens from the caller's
+// bucket, or nil without a limiter. A store error lets the query through, as
+// it lets requests through.
+func (h *Handler) charge(r *http.Request) func(n int) error {
+	if h.limiter == nil {
+		return nil
+	}
+
+	key := h.rateLimitKey(r)
+	return func(n int) error {
+		result, err := h.limiter.AllowN(r.Context(), key, n)
+		if err != nil {
+			h.logger.Error("Rate limit store failed for %s: %v", key, err)
+			return nil
+		}
+		if !result.Allowed {
+			return apperror.ErrRateLimited
+		}
+		return nil
+	}
+}
+
+// SetMaxBodyBytes sets the size limit of request bodies, also while serving.
+func (h *Handler) SetMaxBodyBytes(maxBytes int64) {
+	h.decoder.SetMaxBytes(maxBytes)
+}
+
+func (h *Handler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	errs := []gqlerrors.FormattedError{gqlerrors.FormatError(&gqlerrors.Error{OriginalError: err})}
+	h.localizeErrors(r, errs)
+	jsonbody.Write(w, apperror.HTTPStatus(err), &graphql.Result{Errors: errs})
+}
@@ -0,0 +1,371 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go-a-b-microservices/pkg/apperror"
+	"go-a-b-microservices/pkg/i18n"
+	"go-a-b-microservices/pkg/ratelimit"
+	"go-a-b-microservices/pkg/zipcode"
+	"go-a-b-microservices/service-a/internal/repository"
+	"go-a-b-microservices/service-a/internal/usecase"
+)
+
+type MockServiceBClient struct {
+	mu          sync.Mutex
+	batches     [][]zipcode.ZipCodeRequest
+	singleCalls int
+	forecasts   []zipcode.ForecastRequest
+	// inFlight and maxInFlight count the forecast calls running at once.
+	inFlight    int
+	maxInFlight int
+}
+
+func (m *MockServiceBClient) GetWeatherByZipCode(ctx context.Context, request *zipcode.ZipCodeRequest) (*repository.WeatherResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.singleCalls++
+	return nil, errors.New("unexpected call")
+}
+
+func (m *MockServiceBClient) GetForecastByZipCode(ctx context.Context, request *zipcode.ForecastRequest) (*repository.ForecastResponse, error) {
+	m.mu.Lock()
+	m.forecasts = append(m.forecasts, *request)
+	m.inFlight++
+	m.maxInFlight = max(m.maxInFlight, m.inFlight)
+	m.mu.Unlock()
+
+	time.Sleep(time.Millisecond)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inFlight--
+	return &repository.ForecastResponse{City: "São Paulo", Days: []zipcode.ForecastDay{{Date: "2025-01-01", ChanceOfRain: 40}}}, nil
+}
+
+func (m *MockServiceBClient) GetHistoryByZipCode(ctx context.Context, request *zipcode.HistoryRequest) (*repository.HistoryResponse, error) {
+	return nil, errors.New("unexpected call")
+}
+
+func (m *MockServiceBClient) GetWeatherBatch(ctx context.Context, requests []zipcode.ZipCodeRequest, onResult func(index int, response *repository.WeatherResponse, err error)) error {
+	m.mu.Lock()
+	m.batches = append(m.batches, requests)
+	m.mu.Unlock()
+
+	for i, request := range requests {
+		if request.CEP == "99999999" {
+			onResult(i, nil, apperror.ErrZipCodeNotFound)
+			continue
+		}
+		tempC := 25.0
+		response := &repository.WeatherResponse{City: "City " + request.CEP, TempC: &tempC}
+		if request.CEP == "13484000" {
+			response.Stale, response.AgeSeconds = true, 840
+		}
+		onResult(i, response, nil)
+	}
+	return nil
+}
+
+type MockLogger struct{}
+
+func (m *MockLogger) Info(message string, args ...interface{})  {}
+func (m *MockLogger) Error(message string, args ...interface{}) {}
+func (m *MockLogger) Debug(message string, args ...interface{}) {}
+
+type response struct {
+	Data   map[string]json.RawMessage `json:"data"`
+	Errors []struct {
+		Message    string            `json:"message"`
+		Path       []interface{}     `json:"path"`
+		Extensions map[string]string `json:"extensions"`
+	} `json:"errors"`
+}
+
+func serve(t *testing.T, client *MockServiceBClient, r *http.Request) (int, response) {
+	t.Helper()
+
+	handler, err := NewHandler(usecase.NewZipCodeUseCase(client, &MockLogger{}), &MockLogger{})
+	if err != nil {
+		t.Fatalf("Failed to create handler: %v", err)
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeGraphQL(recorder, r)
+
+	var body response
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to decode response %q: %v", recorder.Body.String(), err)
+	}
+	return recorder.Code, body
+}
+
+func post(query string, lang i18n.Language) *http.Request {
+	body, _ := json.Marshal(map[string]string{"query": query})
+	r := postBody(string(body), "application/json")
+	return r.WithContext(i18n.WithLanguage(r.Context(), lang))
+}
+
+func postBody(body, contentType string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(body))
+	r.Header.Set("Content-Type", contentType)
+	return r
+}
+
+func TestHandler_BatchesWeatherAcrossCEPs(t *testing.T) {
+	client := &MockServiceBClient{}
+	status, body := serve(t, client, post(`{
+		locations(ceps: ["01001000", "13484000", "99999999"]) {
+			cep
+			city
+			weather(units: "C") { tempC tempF stale ageSeconds }
+		}
+		other: location(cep: "01001000") { city }
+	}`, i18n.English))
+
+	if status != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", status)
+	}
+	if len(client.batches) != 1 || client.singleCalls != 0 {
+		t.Fatalf("Expected a single batch call, got %d batches and %d single calls", len(client.batches), client.singleCalls)
+	}
+	// The cities come with the weather in units C.
+	if len(client.batches[0]) != 3 {
+		t.Errorf("Expected one request per CEP in the batch, got %v", client.batches[0])
+	}
+
+	var locations []struct {
+		CEP     string
+		City    *string
+		Weather *struct {
+			TempC      *float64
+			TempF      *float64
+			Stale      bool
+			AgeSeconds int
+		}
+	}
+	if err := json.Unmarshal(body.Data["locations"], &locations); err != nil {
+		t.Fatalf("Failed to decode locations: %v", err)
+	}
+	if len(locations) != 3 {
+		t.Fatalf("Expected 3 locations, got %d", len(locations))
+	}
+	if *locations[1].City != "City 13484000" || *locations[1].Weather.TempC != 25 || locations[1].Weather.TempF != nil {
+		t.Errorf("Unexpected location %+v", locations[1])
+	}
+	if !locations[1].Weather.Stale || locations[1].Weather.AgeSeconds != 840 || locations[0].Weather.Stale {
+		t.Errorf("Expected only the weather of 13484000 to be stale, got %+v and %+v", *locations[0].Weather, *locations[1].Weather)
+	}
+	if locations[2].City != nil || locations[2].Weather != nil {
+		t.Errorf("Expected no data for an unknown CEP, got %+v", locations[2])
+	}
+
+	if len(body.Errors) != 2 {
+		t.Fatalf("Expected 2 errors for the unknown CEP, got %+v", body.Errors)
+	}
+	for _, err := range body.Errors {
+		if err.Extensions["code"] != apperror.CodeZipCodeNotFound {
+			t.Errorf("Expected code %s, got %+v", apperror.CodeZipCodeNotFound, err)
+		}
+	}
+}
+
+func TestHandler_CityWithoutWeather(t *testing.T) {
+	client := &MockServiceBClient{}
+	status, body := serve(t, client, post(`{
+		location(cep: "01001000") { city }
+		other: location(cep: "13484000") { city weather(units: "X") { tempC } }
+	}`, i18n.English))
+
+	if status != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", status)
+	}
+	// Invalid units say nothing about the city, which is looked up alone.
+	if len(client.batches) != 1 || len(client.batches[0]) != 2 {
+		t.Errorf("Expected the cities of both CEPs in one batch, got %v", client.batches)
+	}
+	if got := string(body.Data["other"]); got != `{"city":"City 13484000","weather":null}` {
+		t.Errorf("Expected the city without weather, got %s", got)
+	}
+}
+
+func TestHandler_QueryCost(t *testing.T) {
+	var aliases strings.Builder
+	for i := 0; i <= zipcode.MaxBatchSize; i++ {
+		fmt.Fprintf(&aliases, "l%d: location(cep: \"%08d\") { cep }\n", i, 1001000+i)
+	}
+
+	// Each location asks for a forecast of every length.
+	var forecasts strings.Builder
+	for i := 0; i*zipcode.MaxForecastDays <= maxQueryLookups; i++ {
+		fmt.Fprintf(&forecasts, "l%d: location(cep: \"%08d\") {", i, 1001000+i)
+		for days := 1; days <= zipcode.MaxForecastDays; days++ {
+			fmt.Fprintf(&forecasts, " f%d: forecast(days: %d) { date }", days, days)
+		}
+		forecasts.WriteString(" }\n")
+	}
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{name: "aliased locations", query: "{" + aliases.String() + "}"},
+		{name: "aliased forecasts", query: "{" + forecasts.String() + "}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &MockServiceBClient{}
+			_, body := serve(t, client, post(tt.query, i18n.English))
+
+			if len(body.Errors) == 0 || body.Errors[0].Extensions["code"] != apperror.CodeInvalidRequest {
+				t.Errorf("Expected the query to be too expensive, got %+v", body.Errors)
+			}
+			if len(client.forecasts) > maxQueryForecasts {
+				t.Errorf("Expected at most %d forecasts, got %d", maxQueryForecasts, len(client.forecasts))
+			}
+			if client.maxInFlight > forecastConcurrency {
+				t.Errorf("Expected at most %d forecasts at once, got %d", forecastConcurrency, client.maxInFlight)
+			}
+		})
+	}
+}
+
+func TestHandler_ChargesServiceBCalls(t *testing.T) {
+	// The weather batch and three forecasts are four calls, three past the
+	// one the request paid for.
+	query := `{ locations(ceps: ["01001000", "20040002", "30130000"]) {
+		weather { tempC }
+		forecast(days: 2) { date }
+	} }`
+
+	tests := []struct {
+		name              string
+		burst             int
+		expectedForecasts int
+		expectedCode      string
+	}{
+		{name: "enough tokens", burst: 4, expectedForecasts: 3},
+		{name: "out of tokens", burst: 2, expectedForecasts: 2, expectedCode: apperror.CodeRateLimited},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &MockServiceBClient{}
+			handler, err := NewHandler(usecase.NewZipCodeUseCase(client, &MockLogger{}), &MockLogger{})
+			if err != nil {
+				t.Fatalf("Failed to create handler: %v", err)
+			}
+			limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), ratelimit.NewLimit(1, time.Hour, tt.burst), nil)
+			handler.SetRateLimiter(limiter, ratelimit.ClientIP)
+
+			// The middleware takes the token of the request itself.
+			request := post(query, i18n.English)
+			limiter.Allow(request.Context(), ratelimit.ClientIP(request))
+
+			recorder := httptest.NewRecorder()
+			handler.ServeGraphQL(recorder, request)
+
+			var body response
+			if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+				t.Fatalf("Failed to decode response %q: %v", recorder.Body.String(), err)
+			}
+			if len(client.forecasts) != tt.expectedForecasts {
+				t.Errorf("Expected %d forecasts, got %d", tt.expectedForecasts, len(client.forecasts))
+			}
+			if tt.expectedCode == "" && len(body.Errors) != 0 {
+				t.Errorf("Expected no errors, got %+v", body.Errors)
+			}
+			if tt.expectedCode != "" && (len(body.Errors) == 0 || body.Errors[0].Extensions["code"] != tt.expectedCode) {
+				t.Errorf("Expected a %s error, got %+v", tt.expectedCode, body.Errors)
+			}
+		})
+	}
+}
+
+func TestHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name            string
+		request         *http.Request
+		expectedStatus  int
+		expectedCode    string
+		expectedMessage string
+	}{
+		{
+			name:            "invalid CEP",
+			request:         post(`{ location(cep: "123") { city } }`, i18n.Portuguese),
+			expectedStatus:  http.StatusOK,
+			expectedCode:    apperror.CodeZipCodeInvalid,
+			expectedMessage: i18n.Message(i18n.Portuguese, apperror.CodeZipCodeInvalid),
+		},
+		{
+			name:           "syntax error",
+			request:        post(`{ location(cep: "01001000") { city `, i18n.English),
+			expectedStatus: http.StatusOK,
+			expectedCode:   apperror.CodeInvalidRequest,
+		},
+		{
+			name:            "malformed body",
+			request:         postBody("{", "application/json"),
+			expectedStatus:  http.StatusBadRequest,
+			expectedCode:    apperror.CodeInvalidRequest,
+			expectedMessage: i18n.Message(i18n.English, apperror.CodeInvalidRequest),
+		},
+		{
+			name:           "not JSON",
+			request:        postBody(`{"query":"{ location(cep: \"01001000\") { city } }"}`, "text/plain"),
+			expectedStatus: http.StatusUnsupportedMediaType,
+			expectedCode:   apperror.CodeUnsupportedMediaType,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := serve(t, &MockServiceBClient{}, tt.request)
+
+			if status != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, status)
+			}
+			if len(body.Errors) != 1 {
+				t.Fatalf("Expected 1 error, got %+v", body.Errors)
+			}
+			if body.Errors[0].Extensions["code"] != tt.expectedCode {
+				t.Errorf("Expected code %s, got %+v", tt.expectedCode, body.Errors[0])
+			}
+			if tt.expectedMessage != "" && body.Errors[0].Message != tt.expectedMessage {
+				t.Errorf("Expected message %q, got %q", tt.expectedMessage, body.Errors[0].Message)
+			}
+		})
+	}
+}
+
+func TestHandler_ForecastWithGet(t *testing.T) {
+	client := &MockServiceBClient{}
+	query := url.Values{"query": {`{ location(cep: "01001000") { forecast(days: 5) { date chanceOfRain } } }`}}
+	status, body := serve(t, client, httptest.NewRequest(http.MethodGet, "/graphql?"+query.Encode(), nil))
+
+	if status != http.StatusOK || len(body.Errors) != 0 {
+		t.Fatalf("Expected success, got %d %+v", status, body.Errors)
+	}
+	if len(client.forecasts) != 1 || client.forecasts[0].Days != 5 {
+		t.Errorf("Expected one forecast call for 5 days, got %+v", client.forecasts)
+	}
+	if len(client.batches) != 0 {
+		t.Errorf("Expected no weather calls, got %d", len(client.batches))
+	}
+
+	expected := `{"forecast":[{"chanceOfRain":40,"date":"2025-01-01"}]}`
+	if got := string(body.Data["location"]); got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+}
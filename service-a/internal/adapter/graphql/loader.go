@@ -0,0 +1,301 @@
+package graphql
+
+import (
+	"context"
+	"sync"
+
+	"go-a-b-microservices/pkg/apperror"
+	"go-a-b-microservices/pkg/zipcode"
+	"go-a-b-microservices/service-a/internal/repository"
+	"go-a-b-microservices/service-a/internal/usecase"
+)
+
+const (
+	// maxQueryLookups bounds the Service B lookups of a query, however many
+	// fields and aliases ask for them.
+	maxQueryLookups = 2 * zipcode.MaxBatchSize
+	// maxQueryForecasts bounds the forecasts among them. Service B has no
+	// batch call for forecasts, so each one is a call of its own.
+	maxQueryForecasts = 10
+	// forecastConcurrency bounds the forecast calls a query runs at once.
+	forecastConcurrency = 4
+)
+
+type loadersKey struct{}
+
+// loaders hold the Service B calls of a single GraphQL request.
+type loaders struct {
+	weather  *weatherLoader
+	forecast *forecastLoader
+	cost     *queryCost
+}
+
+// newLoaders builds the loaders of a request. charge, which may be nil, takes
+// the cost of Service B calls past the first from the caller's rate limit.
+func newLoaders(ctx context.Context, zipCodeUseCase *usecase.ZipCodeUseCase, charge func(n int) error) *loaders {
+	cost := &queryCost{charge: charge}
+	return &loaders{
+		weather: &weatherLoader{
+			ctx:            ctx,
+			zipCodeUseCase: zipCodeUseCase,
+			cost:           cost,
+			queued:         make(map[zipcode.ZipCodeRequest]bool),
+			cities:         make(map[string]bool),
+			byCEP:          make(map[string]zipcode.ZipCodeRequest),
+			results:        make(map[zipcode.ZipCodeRequest]usecase.WeatherResult),
+		},
+		forecast: &forecastLoader{
+			ctx:            ctx,
+			zipCodeUseCase: zipCodeUseCase,
+			cost:           cost,
+			calls:          make(map[zipcode.ForecastRequest]*forecastCall),
+			slots:          make(chan struct{}, forecastConcurrency),
+		},
+		cost: cost,
+	}
+}
+
+// queryCost counts the CEPs, the Service B lookups and the Service B calls of
+// a query, so it cannot make more of them than a batch request by naming each
+// CEP in its own aliased field, and pays for each call as a request would.
+type queryCost struct {
+	charge func(n int) error
+
+	mu        sync.Mutex
+	ceps      int
+	lookups   int
+	forecasts int
+	calls     int
+}
+
+// addCEPs counts n more CEPs, failing past zipcode.MaxBatchSize.
+func (c *queryCost) addCEPs(n int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.ceps+n > zipcode.MaxBatchSize {
+		return apperror.ErrInvalidRequest
+	}
+	c.ceps += n
+	return nil
+}
+
+// addLookups counts n more lookups, failing past maxQueryLookups.
+func (c *queryCost) addLookups(n int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.lookups+n > maxQueryLookups {
+		return apperror.ErrInvalidRequest
+	}
+	c.lookups += n
+	return nil
+}
+
+// addForecast counts one more forecast lookup, failing past
+// maxQueryForecasts or maxQueryLookups.
+func (c *queryCost) addForecast() error {
+	c.mu.Lock()
+	if c.forecasts == maxQueryForecasts {
+		c.mu.Unlock()
+		return apperror.ErrInvalidRequest
+	}
+	c.forecasts++
+	c.mu.Unlock()
+
+	return c.addLookups(1)
+}
+
+// addCalls charges n more Service B calls. The first call of the query is
+// paid for by the request itself.
+func (c *queryCost) addCalls(n int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	charged := n
+	if c.calls == 0 {
+		charged--
+	}
+	if charged > 0 && c.charge != nil {
+		if err := c.charge(charged); err != nil {
+			return err
+		}
+	}
+	c.calls += n
+	return nil
+}
+
+func withLoaders(ctx context.Context, l *loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, l)
+}
+
+func loadersFromContext(ctx context.Context) *loaders {
+	l, _ := ctx.Value(loadersKey{}).(*loaders)
+	return l
+}
+
+// weatherLoader batches weather requests. Resolvers queue their request and
+// return a thunk; the executor runs thunks only after resolving every sibling
+// field, so the first thunk sends all queued requests in one batch.
+type weatherLoader struct {
+	ctx            context.Context
+	zipCodeUseCase *usecase.ZipCodeUseCase
+	cost           *queryCost
+
+	mu      sync.Mutex
+	pending []zipcode.ZipCodeRequest
+	queued  map[zipcode.ZipCodeRequest]bool
+	// cities are the CEPs whose city is asked for, and byCEP the request
+	// each of them is answered from.
+	cities  map[string]bool
+	byCEP   map[string]zipcode.ZipCodeRequest
+	results map[zipcode.ZipCodeRequest]usecase.WeatherResult
+}
+
+func (l *weatherLoader) load(request zipcode.ZipCodeRequest) func() (*repository.WeatherResponse, error) {
+	l.mu.Lock()
+	l.queue(request)
+	l.mu.Unlock()
+
+	return func() (*repository.WeatherResponse, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		result := l.result(request)
+		return result.Weather, result.Err
+	}
+}
+
+// loadCity answers the city of cep from any weather request of the query for
+// it, so the city costs a request of its own only when no other field looks
+// cep up.
+func (l *weatherLoader) loadCity(cep string) func() (string, error) {
+	l.mu.Lock()
+	l.cities[cep] = true
+	l.mu.Unlock()
+
+	return func() (string, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		if _, ok := l.byCEP[cep]; !ok {
+			l.flush()
+		}
+		result := l.result(l.byCEP[cep])
+		if result.Err != nil {
+			return "", result.Err
+		}
+		return result.Weather.City, nil
+	}
+}
+
+func (l *weatherLoader) queue(request zipcode.ZipCodeRequest) {
+	if l.queued[request] {
+		return
+	}
+	l.queued[request] = true
+	l.pending = append(l.pending, request)
+
+	// A request that fails validation, on its units for instance, says
+	// nothing about the city.
+	if _, ok := l.byCEP[request.CEP]; !ok && request.Validate() == nil {
+		l.byCEP[request.CEP] = request
+	}
+}
+
+func (l *weatherLoader) result(request zipcode.ZipCodeRequest) usecase.WeatherResult {
+	if result, ok := l.results[request]; ok {
+		return result
+	}
+	l.flush()
+	return l.results[request]
+}
+
+// flush sends the queued requests in one batch, with one for each city no
+// other request answers.
+func (l *weatherLoader) flush() {
+	for cep := range l.cities {
+		if _, ok := l.byCEP[cep]; !ok {
+			l.queue(zipcode.ZipCodeRequest{CEP: cep})
+		}
+	}
+	clear(l.cities)
+
+	pending := l.pending
+	l.pending = nil
+	if len(pending) == 0 {
+		return
+	}
+
+	err := l.cost.addLookups(len(pending))
+	if err == nil {
+		// ProcessZipCodeBatch sends one call per zipcode.MaxBatchSize requests.
+		err = l.cost.addCalls((len(pending) + zipcode.MaxBatchSize - 1) / zipcode.MaxBatchSize)
+	}
+	if err != nil {
+		for _, request := range pending {
+			l.results[request] = usecase.WeatherResult{Err: err}
+		}
+		return
+	}
+	for i, result := range l.zipCodeUseCase.ProcessZipCodeBatch(l.ctx, pending) {
+		l.results[pending[i]] = result
+	}
+}
+
+// forecastLoader starts each distinct forecast request as soon as it is
+// resolved, so forecasts for several ZIP codes are fetched concurrently, up to
+// forecastConcurrency at a time.
+type forecastLoader struct {
+	ctx            context.Context
+	zipCodeUseCase *usecase.ZipCodeUseCase
+	cost           *queryCost
+	slots          chan struct{}
+
+	mu    sync.Mutex
+	calls map[zipcode.ForecastRequest]*forecastCall
+}
+
+type forecastCall struct {
+	done     chan struct{}
+	forecast *repository.ForecastResponse
+	err      error
+}
+
+func (l *forecastLoader) load(request zipcode.ForecastRequest) func() (*repository.ForecastResponse, error) {
+	l.mu.Lock()
+	call, ok := l.calls[request]
+	if !ok {
+		call = &forecastCall{done: make(chan struct{})}
+		l.calls[request] = call
+		err := l.cost.addForecast()
+		if err == nil {
+			err = l.cost.addCalls(1)
+		}
+		if err != nil {
+			call.err = err
+			close(call.done)
+		} else {
+			go l.fetch(request, call)
+		}
+	}
+	l.mu.Unlock()
+
+	return func() (*repository.ForecastResponse, error) {
+		<-call.done
+		return call.forecast, call.err
+	}
+}
+
+func (l *forecastLoader) fetch(request zipcode.ForecastRequest, call *forecastCall) {
+	defer close(call.done)
+
+	select {
+	case l.slots <- struct{}{}:
+		defer func() { <-l.slots }()
+	case <-l.ctx.Done():
+		call.err = l.ctx.Err()
+		return
+	}
+	call.forecast, call.err = l.zipCodeUseCase.ProcessForecast(l.ctx, &request)
+}
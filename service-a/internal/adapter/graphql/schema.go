@@ -0,0 +1,252 @@
+package graphql
+
+import (
+	"go-a-b-microservices/pkg/zipcode"
+
+	"github.com/graphql-go/graphql"
+)
+
+// location is the source of the Location type. Its other fields are loaded
+// from Service B only when the query selects them.
+type location struct {
+	CEP string
+}
+
+var temperatureType = graphql.NewObject(graphql.ObjectConfig{
+	Name:        "Temperature",
+	Description: "A temperature in each requested unit.",
+	Fields: graphql.Fields{
+		"c": &graphql.Field{Type: graphql.Float},
+		"f": &graphql.Field{Type: graphql.Float},
+		"k": &graphql.Field{Type: graphql.Float},
+	},
+})
+
+var conditionsType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Conditions",
+	Fields: graphql.Fields{
+		"feelsLikeC":    &graphql.Field{Type: graphql.Float},
+		"feelsLikeF":    &graphql.Field{Type: graphql.Float},
+		"feelsLikeK":    &graphql.Field{Type: graphql.Float},
+		"humidity":      &graphql.Field{Type: graphql.Int},
+		"windKph":       &graphql.Field{Type: graphql.Float},
+		"windDegree":    &graphql.Field{Type: graphql.Int},
+		"windDir":       &graphql.Field{Type: graphql.String},
+		"pressureMb":    &graphql.Field{Type: graphql.Float},
+		"precipMm":      &graphql.Field{Type: graphql.Float},
+		"uv":            &graphql.Field{Type: graphql.Float},
+		"condition":     &graphql.Field{Type: graphql.String},
+		"conditionIcon": &graphql.Field{Type: graphql.String},
+		"isDay":         &graphql.Field{Type: graphql.Boolean},
+	},
+})
+
+var alertType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Alert",
+	Fields: graphql.Fields{
+		"headline":    &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"event":       &graphql.Field{Type: graphql.String},
+		"severity":    &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"urgency":     &graphql.Field{Type: graphql.String},
+		"areas":       &graphql.Field{Type: graphql.String},
+		"description": &graphql.Field{Type: graphql.String},
+		"instruction": &graphql.Field{Type: graphql.String},
+		"effective":   &graphql.Field{Type: graphql.DateTime},
+		"expires":     &graphql.Field{Type: graphql.DateTime},
+	},
+})
+
+var airQualityType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "AirQuality",
+	Fields: graphql.Fields{
+		"co":           &graphql.Field{Type: graphql.Float},
+		"no2":          &graphql.Field{Type: graphql.Float},
+		"o3":           &graphql.Field{Type: graphql.Float},
+		"so2":          &graphql.Field{Type: graphql.Float},
+		"pm2_5":        &graphql.Field{Type: graphql.Float},
+		"pm10":         &graphql.Field{Type: graphql.Float},
+		"usEpaIndex":   &graphql.Field{Type: graphql.Int},
+		"gbDefraIndex": &graphql.Field{Type: graphql.Int},
+		"category":     &graphql.Field{Type: graphql.String},
+	},
+})
+
+var weatherType = graphql.NewObject(graphql.ObjectConfig{
+	Name:        "Weather",
+	Description: "Current weather. Temperatures are null for units that were not requested.",
+	Fields: graphql.Fields{
+		"tempC":      &graphql.Field{Type: graphql.Float},
+		"tempF":      &graphql.Field{Type: graphql.Float},
+		"tempK":      &graphql.Field{Type: graphql.Float},
+		"conditions": &graphql.Field{Type: graphql.NewNonNull(conditionsType)},
+		"alerts": &graphql.Field{
+			Type:        graphql.NewList(graphql.NewNonNull(alertType)),
+			Description: "Active alerts, when requested with alerts: true.",
+		},
+		"airQuality": &graphql.Field{
+			Type:        airQualityType,
+			Description: "Air quality, when requested with aqi: true.",
+		},
+		"stale": &graphql.Field{
+			Type:        graphql.NewNonNull(graphql.Boolean),
+			Description: "Whether this is a cached observation served past its soft TTL.",
+		},
+		"ageSeconds": &graphql.Field{
+			Type:        graphql.Int,
+			Description: "Age of a stale observation in seconds.",
+		},
+	},
+})
+
+var forecastHourType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "ForecastHour",
+	Fields: graphql.Fields{
+		"time":         &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"temp":         &graphql.Field{Type: graphql.NewNonNull(temperatureType)},
+		"chanceOfRain": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		"condition":    &graphql.Field{Type: graphql.String},
+	},
+})
+
+var forecastDayType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "ForecastDay",
+	Fields: graphql.Fields{
+		"date":         &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"minTemp":      &graphql.Field{Type: graphql.NewNonNull(temperatureType)},
+		"maxTemp":      &graphql.Field{Type: graphql.NewNonNull(temperatureType)},
+		"chanceOfRain": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		"condition":    &graphql.Field{Type: graphql.String},
+		"hourly": &graphql.Field{
+			Type:        graphql.NewList(graphql.NewNonNull(forecastHourType)),
+			Description: "Hourly forecast, when requested with hourly: true.",
+		},
+	},
+})
+
+var locationType = graphql.NewObject(graphql.ObjectConfig{
+	Name:        "Location",
+	Description: "The place a CEP belongs to.",
+	Fields: graphql.Fields{
+		"cep": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"city": &graphql.Field{
+			Type: graphql.String,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				city := loadersFromContext(p.Context).weather.loadCity(p.Source.(*location).CEP)
+				return func() (interface{}, error) {
+					return city()
+				}, nil
+			},
+		},
+		"weather": &graphql.Field{
+			Type: weatherType,
+			Args: graphql.FieldConfigArgument{
+				"units": &graphql.ArgumentConfig{
+					Type:        graphql.String,
+					Description: "Comma separated units among C, F and K. Defaults to all.",
+				},
+				"alerts": &graphql.ArgumentConfig{Type: graphql.Boolean, DefaultValue: false},
+				"aqi":    &graphql.ArgumentConfig{Type: graphql.Boolean, DefaultValue: false},
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				units, _ := p.Args["units"].(string)
+				request := zipcode.ZipCodeRequest{
+					CEP:    p.Source.(*location).CEP,
+					Units:  units,
+					Alerts: p.Args["alerts"].(bool),
+					AQI:    p.Args["aqi"].(bool),
+				}
+				weather := loadersFromContext(p.Context).weather.load(request)
+				return func() (interface{}, error) {
+					response, err := weather()
+					if err != nil {
+						return nil, err
+					}
+					return response, nil
+				}, nil
+			},
+		},
+		"forecast": &graphql.Field{
+			Type: graphql.NewList(graphql.NewNonNull(forecastDayType)),
+			Args: graphql.FieldConfigArgument{
+				"days": &graphql.ArgumentConfig{
+					Type:         graphql.Int,
+					DefaultValue: zipcode.DefaultForecastDays,
+				},
+				"units":  &graphql.ArgumentConfig{Type: graphql.String},
+				"hourly": &graphql.ArgumentConfig{Type: graphql.Boolean, DefaultValue: false},
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				units, _ := p.Args["units"].(string)
+				request := zipcode.ForecastRequest{
+					CEP:    p.Source.(*location).CEP,
+					Days:   p.Args["days"].(int),
+					Units:  units,
+					Hourly: p.Args["hourly"].(bool),
+				}
+				forecast := loadersFromContext(p.Context).forecast.load(request)
+				return func() (interface{}, error) {
+					response, err := forecast()
+					if err != nil {
+						return nil, err
+					}
+					return response.Days, nil
+				}, nil
+			},
+		},
+	},
+})
+
+var queryType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Query",
+	Fields: graphql.Fields{
+		"location": &graphql.Field{
+			Type: locationType,
+			Args: graphql.FieldConfigArgument{
+				"cep": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				if err := loadersFromContext(p.Context).cost.addCEPs(1); err != nil {
+					return nil, err
+				}
+				return newLocation(p.Args["cep"].(string))
+			},
+		},
+		"locations": &graphql.Field{
+			Type:        graphql.NewList(graphql.NewNonNull(locationType)),
+			Description: "Locations of up to 100 CEPs, counting those of every location field of the query. Their weather is fetched in one batch.",
+			Args: graphql.FieldConfigArgument{
+				"ceps": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String)))},
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				ceps := p.Args["ceps"].([]interface{})
+				if err := loadersFromContext(p.Context).cost.addCEPs(len(ceps)); err != nil {
+					return nil, err
+				}
+
+				locations := make([]*location, 0, len(ceps))
+				for _, cep := range ceps {
+					l, err := newLocation(cep.(string))
+					if err != nil {
+						return nil, err
+					}
+					locations = append(locations, l)
+				}
+				return locations, nil
+			},
+		},
+	},
+})
+
+// newLocation validates cep, so a malformed CEP is reported once on the
+// location instead of on each of its fields.
+func newLocation(cep string) (*location, error) {
+	request := zipcode.ZipCodeRequest{CEP: cep}
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+	return &location{CEP: cep}, nil
+}
+
+func newSchema() (graphql.Schema, error) {
+	return graphql.NewSchema(graphql.SchemaConfig{Query: queryType})
+}
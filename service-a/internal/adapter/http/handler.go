@@ -1,11 +1,12 @@
 package http
 
 import (
-	"encoding/json"
-	"io"
 	"net/http"
+	"strconv"
 
 	"go-a-b-microservices/pkg/apperror"
+	"go-a-b-microservices/pkg/i18n"
+	"go-a-b-microservices/pkg/jsonbody"
 	"go-a-b-microservices/pkg/logger"
 	"go-a-b-microservices/pkg/zipcode"
 	"go-a-b-microservices/service-a/internal/usecase"
@@ -16,17 +17,26 @@ import (
 type Handler struct {
 	zipCodeUseCase *usecase.ZipCodeUseCase
 	logger         logger.Logger
+	decoder        *jsonbody.Decoder
 }
 
 func NewHandler(zipCodeUseCase *usecase.ZipCodeUseCase, logger logger.Logger) *Handler {
 	return &Handler{
 		zipCodeUseCase: zipCodeUseCase,
 		logger:         logger,
+		decoder:        jsonbody.NewDecoder(jsonbody.DefaultMaxBytes),
 	}
 }
 
+// SetMaxBodyBytes sets the size limit of request bodies, also while serving.
+func (h *Handler) SetMaxBodyBytes(maxBytes int64) {
+	h.decoder.SetMaxBytes(maxBytes)
+}
+
 func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("/zipcode", h.ProcessZipCode)
+	mux.HandleFunc("/zipcode/forecast", h.ProcessForecast)
+	mux.HandleFunc("/zipcode/history", h.ProcessHistory)
 }
 
 func (h *Handler) ProcessZipCode(w http.ResponseWriter, r *http.Request) {
@@ -39,49 +49,148 @@ func (h *Handler) ProcessZipCode(w http.ResponseWriter, r *http.Request) {
 	ctx, span := tracer.Start(r.Context(), "http.ProcessZipCode")
 	defer span.End()
 
-	body, err := io.ReadAll(r.Body)
-	if err != nil {
-		h.logger.Error("Failed to read request body: %v", err)
-		writeJSONResponse(w, http.StatusBadRequest, map[string]string{"message": "invalid request"})
+	var request zipcode.ZipCodeRequest
+	if err := h.decoder.Decode(w, r, &request); err != nil {
+		h.logger.Error("Failed to decode request: %v", err)
+		h.writeErrorResponse(w, r, err)
 		return
 	}
-	defer r.Body.Close()
 
-	var request zipcode.ZipCodeRequest
-	if err := json.Unmarshal(body, &request); err != nil {
-		h.logger.Error("Failed to parse JSON: %v", err)
-		writeJSONResponse(w, http.StatusBadRequest, map[string]string{"message": "invalid request"})
-		return
+	// Units and the opt-in flags may also be given as query parameters,
+	// e.g. ?units=C,K&alerts=true&aqi=true.
+	query := r.URL.Query()
+	if units := query.Get("units"); units != "" {
+		request.Units = units
+	}
+	for name, flag := range map[string]*bool{"alerts": &request.Alerts, "aqi": &request.AQI} {
+		if value := query.Get(name); value != "" {
+			parsed, err := strconv.ParseBool(value)
+			if err != nil {
+				h.logger.Error("Invalid %s flag: %v", name, err)
+				h.writeErrorResponse(w, r, apperror.ErrInvalidRequest)
+				return
+			}
+			*flag = parsed
+		}
 	}
 
 	if err := request.Validate(); err != nil {
 		h.logger.Error("Invalid ZIP code: %v", err)
-		writeJSONResponse(w, http.StatusUnprocessableEntity, map[string]string{"message": apperror.ErrZipCodeInvalid.Error()})
+		h.writeErrorResponse(w, r, err)
 		return
 	}
 
 	response, err := h.zipCodeUseCase.ProcessZipCode(ctx, &request)
 	if err != nil {
-		switch err.Error() {
-		case apperror.ErrZipCodeInvalid.Error():
-			writeJSONResponse(w, http.StatusUnprocessableEntity, map[string]string{"message": apperror.ErrZipCodeInvalid.Error()})
-		case apperror.ErrZipCodeNotFound.Error():
-			writeJSONResponse(w, http.StatusNotFound, map[string]string{"message": apperror.ErrZipCodeNotFound.Error()})
-		default:
-			h.logger.Error("Failed to process ZIP code: %v", err)
-			writeJSONResponse(w, http.StatusInternalServerError, map[string]string{"message": "internal server error"})
+		h.writeErrorResponse(w, r, err)
+		return
+	}
+
+	jsonbody.Write(w, http.StatusOK, response)
+}
+
+// ProcessForecast accepts the forecast request as a JSON body on POST or as
+// query parameters (cep, days, units, hourly) on GET.
+func (h *Handler) ProcessForecast(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost && r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	tracer := otel.Tracer("service-a")
+	ctx, span := tracer.Start(r.Context(), "http.ProcessForecast")
+	defer span.End()
+
+	var request zipcode.ForecastRequest
+	if r.Method == http.MethodGet {
+		query := r.URL.Query()
+		request.CEP = query.Get("cep")
+		request.Units = query.Get("units")
+
+		if days := query.Get("days"); days != "" {
+			parsed, err := strconv.Atoi(days)
+			if err != nil {
+				h.logger.Error("Invalid forecast days: %v", err)
+				h.writeErrorResponse(w, r, apperror.ErrForecastDaysInvalid)
+				return
+			}
+			request.Days = parsed
+		}
+
+		if hourly := query.Get("hourly"); hourly != "" {
+			parsed, err := strconv.ParseBool(hourly)
+			if err != nil {
+				h.logger.Error("Invalid hourly flag: %v", err)
+				h.writeErrorResponse(w, r, apperror.ErrInvalidRequest)
+				return
+			}
+			request.Hourly = parsed
 		}
+	} else {
+		if err := h.decoder.Decode(w, r, &request); err != nil {
+			h.logger.Error("Failed to decode request: %v", err)
+			h.writeErrorResponse(w, r, err)
+			return
+		}
+	}
+
+	response, err := h.zipCodeUseCase.ProcessForecast(ctx, &request)
+	if err != nil {
+		h.writeErrorResponse(w, r, err)
+		return
+	}
+
+	jsonbody.Write(w, http.StatusOK, response)
+}
+
+// ProcessHistory accepts the history request as a JSON body on POST or as
+// query parameters (cep, date, end_date, units) on GET.
+func (h *Handler) ProcessHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost && r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	tracer := otel.Tracer("service-a")
+	ctx, span := tracer.Start(r.Context(), "http.ProcessHistory")
+	defer span.End()
+
+	var request zipcode.HistoryRequest
+	if r.Method == http.MethodGet {
+		query := r.URL.Query()
+		request.CEP = query.Get("cep")
+		request.Date = query.Get("date")
+		request.EndDate = query.Get("end_date")
+		request.Units = query.Get("units")
+	} else {
+		if err := h.decoder.Decode(w, r, &request); err != nil {
+			h.logger.Error("Failed to decode request: %v", err)
+			h.writeErrorResponse(w, r, err)
+			return
+		}
+	}
+
+	response, err := h.zipCodeUseCase.ProcessHistory(ctx, &request)
+	if err != nil {
+		h.writeErrorResponse(w, r, err)
 		return
 	}
 
-	writeJSONResponse(w, http.StatusOK, response)
+	jsonbody.Write(w, http.StatusOK, response)
 }
 
-func writeJSONResponse(w http.ResponseWriter, statusCode int, data interface{}) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
+// writeErrorResponse maps err to a status code and writes its code and message
+// in the language negotiated for the request.
+func (h *Handler) writeErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	status := apperror.HTTPStatus(err)
+	if status == http.StatusInternalServerError {
+		h.logger.Error("Failed to process request: %v", err)
+	}
 
-	if err := json.NewEncoder(w).Encode(data); err != nil {
-		http.Error(w, "Error encoding response", http.StatusInternalServerError)
+	switch apperror.Code(err) {
+	case apperror.CodeZipCodeRequired, apperror.CodeZipCodeInvalid:
+		err = apperror.Replace(err, apperror.ErrZipCodeInvalid)
 	}
+
+	jsonbody.Write(w, status, i18n.Localize(i18n.FromContext(r.Context()), err))
 }
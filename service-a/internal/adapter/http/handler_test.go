@@ -0,0 +1,131 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-a-b-microservices/pkg/apperror"
+	"go-a-b-microservices/pkg/openapi"
+	"go-a-b-microservices/pkg/zipcode"
+	"go-a-b-microservices/service-a/internal/repository"
+	"go-a-b-microservices/service-a/internal/usecase"
+)
+
+type MockServiceBClient struct {
+	GetWeatherByZipCodeFunc  func(ctx context.Context, request *zipcode.ZipCodeRequest) (*repository.WeatherResponse, error)
+	GetForecastByZipCodeFunc func(ctx context.Context, request *zipcode.ForecastRequest) (*repository.ForecastResponse, error)
+	GetHistoryByZipCodeFunc  func(ctx context.Context, request *zipcode.HistoryRequest) (*repository.HistoryResponse, error)
+}
+
+func (m *MockServiceBClient) GetWeatherByZipCode(ctx context.Context, request *zipcode.ZipCodeRequest) (*repository.WeatherResponse, error) {
+	return m.GetWeatherByZipCodeFunc(ctx, request)
+}
+
+func (m *MockServiceBClient) GetForecastByZipCode(ctx context.Context, request *zipcode.ForecastRequest) (*repository.ForecastResponse, error) {
+	return m.GetForecastByZipCodeFunc(ctx, request)
+}
+
+func (m *MockServiceBClient) GetHistoryByZipCode(ctx context.Context, request *zipcode.HistoryRequest) (*repository.HistoryResponse, error) {
+	return m.GetHistoryByZipCodeFunc(ctx, request)
+}
+
+func (m *MockServiceBClient) GetWeatherBatch(ctx context.Context, requests []zipcode.ZipCodeRequest, onResult func(index int, response *repository.WeatherResponse, err error)) error {
+	for i := range requests {
+		response, err := m.GetWeatherByZipCodeFunc(ctx, &requests[i])
+		onResult(i, response, err)
+	}
+	return nil
+}
+
+type MockLogger struct{}
+
+func (m *MockLogger) Info(message string, args ...interface{})  {}
+func (m *MockLogger) Error(message string, args ...interface{}) {}
+func (m *MockLogger) Debug(message string, args ...interface{}) {}
+
+func float64Ptr(value float64) *float64 {
+	return &value
+}
+
+func newMockServiceBClient() *MockServiceBClient {
+	temperature := zipcode.Temperature{C: float64Ptr(25), F: float64Ptr(77), K: float64Ptr(298.2)}
+
+	return &MockServiceBClient{
+		GetWeatherByZipCodeFunc: func(ctx context.Context, request *zipcode.ZipCodeRequest) (*repository.WeatherResponse, error) {
+			switch request.CEP {
+			case "99999999":
+				return nil, apperror.ErrZipCodeNotFound
+			case "88888888":
+				return nil, apperror.ErrWeatherUnavailable
+			}
+			return &repository.WeatherResponse{City: "São Paulo", TempC: float64Ptr(25), TempF: float64Ptr(77), TempK: float64Ptr(298.2)}, nil
+		},
+		GetForecastByZipCodeFunc: func(ctx context.Context, request *zipcode.ForecastRequest) (*repository.ForecastResponse, error) {
+			return &repository.ForecastResponse{City: "São Paulo", Days: []zipcode.ForecastDay{
+				{Date: "2024-01-01", MinTemp: temperature, MaxTemp: temperature, ChanceOfRain: 40},
+			}}, nil
+		},
+		GetHistoryByZipCodeFunc: func(ctx context.Context, request *zipcode.HistoryRequest) (*repository.HistoryResponse, error) {
+			return &repository.HistoryResponse{City: "São Paulo", Days: []zipcode.HistoryDay{
+				{Date: request.Date, MinTemp: temperature, MaxTemp: temperature, AvgTemp: temperature, AvgHumidity: 70},
+			}}, nil
+		},
+	}
+}
+
+// TestHandlerMatchesOpenAPI checks that the responses of the handlers, behind
+// the validation middleware, match the document served by Service A.
+func TestHandlerMatchesOpenAPI(t *testing.T) {
+	doc, err := OpenAPI()
+	if err != nil {
+		t.Fatalf("OpenAPI() error = %v", err)
+	}
+	validator, err := openapi.NewValidator(doc)
+	if err != nil {
+		t.Fatalf("NewValidator() error = %v", err)
+	}
+
+	mux := http.NewServeMux()
+	NewHandler(usecase.NewZipCodeUseCase(newMockServiceBClient(), &MockLogger{}), &MockLogger{}).RegisterRoutes(mux)
+	handler := openapi.Middleware(validator, &MockLogger{}, mux)
+
+	tests := []struct {
+		name           string
+		path           string
+		body           string
+		expectedStatus int
+	}{
+		{name: "Weather", path: "/zipcode", body: `{"cep":"01001000"}`, expectedStatus: http.StatusOK},
+		{name: "Weather with query parameters", path: "/zipcode?units=C,K&alerts=true", body: `{"cep":"01001000"}`, expectedStatus: http.StatusOK},
+		{name: "Weather not found", path: "/zipcode", body: `{"cep":"99999999"}`, expectedStatus: http.StatusNotFound},
+		{name: "Weather unavailable", path: "/zipcode", body: `{"cep":"88888888"}`, expectedStatus: http.StatusServiceUnavailable},
+		{name: "Weather invalid CEP", path: "/zipcode", body: `{"cep":"123"}`, expectedStatus: http.StatusUnprocessableEntity},
+		{name: "Weather invalid units", path: "/zipcode?units=X", body: `{"cep":"01001000"}`, expectedStatus: http.StatusUnprocessableEntity},
+		{name: "Weather malformed JSON", path: "/zipcode", body: `{"cep":`, expectedStatus: http.StatusBadRequest},
+		{name: "Forecast", path: "/zipcode/forecast", body: `{"cep":"01001000","days":1}`, expectedStatus: http.StatusOK},
+		{name: "Forecast invalid days", path: "/zipcode/forecast", body: `{"cep":"01001000","days":-1}`, expectedStatus: http.StatusUnprocessableEntity},
+		{name: "History", path: "/zipcode/history", body: `{"cep":"01001000","date":"2024-01-01"}`, expectedStatus: http.StatusOK},
+		{name: "History invalid date", path: "/zipcode/history", body: `{"cep":"01001000","date":"2024-13"}`, expectedStatus: http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, rec.Code, rec.Body.String())
+			}
+
+			req = httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			if err := validator.ValidateResponse(req, rec.Code, rec.Header(), rec.Body.Bytes()); err != nil {
+				t.Errorf("Response does not match the document: %v\n%s", err, rec.Body.String())
+			}
+		})
+	}
+}
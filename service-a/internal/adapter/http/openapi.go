@@ -0,0 +1,115 @@
+package http
+
+import (
+	"net/http"
+
+	"go-a-b-microservices/pkg/openapi"
+	"go-a-b-microservices/pkg/zipcode"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// errorStatuses are the error responses of the weather endpoints, including
+// those of the authentication and rate limiting middleware.
+var errorStatuses = []int{
+	http.StatusBadRequest,
+	http.StatusUnauthorized,
+	http.StatusForbidden,
+	http.StatusNotFound,
+	http.StatusRequestEntityTooLarge,
+	http.StatusUnsupportedMediaType,
+	http.StatusUnprocessableEntity,
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusServiceUnavailable,
+}
+
+// OpenAPI describes the endpoints of Service A. Request and response schemas
+// are generated from the types in pkg/zipcode.
+func OpenAPI() (*openapi3.T, error) {
+	doc, err := openapi.NewDocument(
+		"Service A",
+		"Weather for Brazilian ZIP codes (CEP).",
+		"1.0.0",
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	weather := openapi.JSONOperation("getWeather", "Current weather for a CEP",
+		openapi.SchemaRef(zipcode.ZipCodeRequest{}), openapi.SchemaRef(zipcode.WeatherResponse{}), errorStatuses...)
+	weather.Parameters = openapi3.Parameters{
+		openapi.QueryParameter("units", "Overrides units in the body", openapi.UnitsSchema()),
+		openapi.QueryParameter("alerts", "Overrides alerts in the body", openapi3.NewBoolSchema()),
+		openapi.QueryParameter("aqi", "Overrides aqi in the body", openapi3.NewBoolSchema()),
+	}
+	doc.AddOperation("/zipcode", http.MethodPost, weather)
+
+	doc.AddOperation("/zipcode/forecast", http.MethodPost, openapi.JSONOperation("getForecast", "Daily forecast for a CEP",
+		openapi.SchemaRef(zipcode.ForecastRequest{}), openapi.SchemaRef(zipcode.ForecastResponse{}), errorStatuses...))
+
+	doc.AddOperation("/zipcode/history", http.MethodPost, openapi.JSONOperation("getHistory", "Past weather for a CEP",
+		openapi.SchemaRef(zipcode.HistoryRequest{}), openapi.SchemaRef(zipcode.HistoryResponse{}), errorStatuses...))
+
+	addGraphQL(doc)
+
+	doc.Components.SecuritySchemes = openapi3.SecuritySchemes{
+		"bearerAuth": &openapi3.SecuritySchemeRef{
+			Value: openapi3.NewJWTSecurityScheme().WithDescription("An API key or a JWT, depending on AUTH_MODE"),
+		},
+		"apiKey": &openapi3.SecuritySchemeRef{
+			Value: openapi3.NewSecurityScheme().WithType("apiKey").WithIn("header").WithName("X-API-Key"),
+		},
+	}
+	// Authentication is optional with AUTH_MODE=none.
+	doc.Security = openapi3.SecurityRequirements{
+		{},
+		{"bearerAuth": []string{}},
+		{"apiKey": []string{}},
+	}
+
+	if err := openapi.Validate(doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// addGraphQL describes the GraphQL endpoint. Queries and results are only
+// described as far as the GraphQL over HTTP format goes; the schema itself is
+// available through introspection.
+func addGraphQL(doc *openapi3.T) {
+	query := openapi3.NewStringSchema()
+	query.MinLength = 1
+
+	request := openapi3.NewObjectSchema().
+		WithProperty("query", query).
+		WithProperty("operationName", openapi3.NewStringSchema()).
+		WithProperty("variables", openapi3.NewObjectSchema().WithAnyAdditionalProperties())
+	request.Required = []string{"query"}
+
+	response := openapi3.NewObjectSchema().
+		WithProperty("data", openapi3.NewObjectSchema().WithAnyAdditionalProperties().WithNullable()).
+		WithProperty("errors", openapi3.NewArraySchema().WithItems(openapi3.NewObjectSchema().WithAnyAdditionalProperties()))
+
+	// Malformed requests get 400 with the errors in the same format.
+	invalid := &openapi3.ResponseRef{
+		Value: openapi3.NewResponse().WithDescription(http.StatusText(http.StatusBadRequest)).WithJSONSchema(response),
+	}
+
+	post := openapi.JSONOperation("graphql", "Run a GraphQL query",
+		openapi3.NewSchemaRef("", request), openapi3.NewSchemaRef("", response))
+	post.Responses.Set("400", invalid)
+
+	get := openapi.JSONOperation("graphqlGet", "Run a GraphQL query given in the URL",
+		nil, openapi3.NewSchemaRef("", response))
+	get.Responses.Set("400", invalid)
+	get.Parameters = openapi3.Parameters{
+		openapi.QueryParameter("query", "The GraphQL query", query),
+		openapi.QueryParameter("operationName", "Operation to run", openapi3.NewStringSchema()),
+		openapi.QueryParameter("variables", "Variables as a JSON object", openapi3.NewStringSchema()),
+	}
+	get.Parameters[0].Value.Required = true
+
+	doc.AddOperation("/graphql", http.MethodPost, post)
+	doc.AddOperation("/graphql", http.MethodGet, get)
+}
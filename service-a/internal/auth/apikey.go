@@ -0,0 +1,195 @@
+package auth
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"go-a-b-microservices/pkg/apperror"
+	"go-a-b-microservices/pkg/keylist"
+)
+
+const hashPrefix = "sha256:"
+
+// KeyStore resolves an API key to the client that owns it.
+type KeyStore interface {
+	Lookup(key string) (clientID string, ok bool)
+}
+
+type keyEntry struct {
+	clientID string
+	hash     []byte
+}
+
+// Keys maps the SHA-256 hash of each accepted key to the client that owns it,
+// so that a client can hold several keys while rotating them.
+type Keys map[[sha256.Size]byte]string
+
+// Add accepts key for clientID. A key in the form "sha256:<hex>" is taken as
+// the hash of the real key. A key that was already added is an error, also
+// for the same client.
+func (k Keys) Add(clientID, key string) error {
+	if clientID == "" || key == "" {
+		return fmt.Errorf("api key entry needs a client id and a key")
+	}
+
+	hash, err := keyHash(key)
+	if err != nil {
+		return fmt.Errorf("invalid sha256 hash for client %s", clientID)
+	}
+	if owner, ok := k[hash]; ok {
+		return fmt.Errorf("client %s repeats a key of client %s", clientID, owner)
+	}
+	k[hash] = clientID
+	return nil
+}
+
+// keyHash returns the SHA-256 hash of key, or the hash key gives in the form
+// "sha256:<hex>".
+func keyHash(key string) ([sha256.Size]byte, error) {
+	var hash [sha256.Size]byte
+	if !strings.HasPrefix(key, hashPrefix) {
+		return sha256.Sum256([]byte(key)), nil
+	}
+
+	decoded, err := hex.DecodeString(strings.TrimPrefix(key, hashPrefix))
+	if err != nil || len(decoded) != sha256.Size {
+		return hash, fmt.Errorf("invalid sha256 hash")
+	}
+	copy(hash[:], decoded)
+	return hash, nil
+}
+
+// StaticKeyStore holds SHA-256 hashes of the accepted keys. Plain keys are
+// hashed on load so that they are never kept in memory as given.
+type StaticKeyStore struct {
+	entries atomic.Pointer[[]keyEntry]
+}
+
+func NewStaticKeyStore(keys Keys) *StaticKeyStore {
+	entries := make([]keyEntry, 0, len(keys))
+	for hash, clientID := range keys {
+		entries = append(entries, keyEntry{clientID: clientID, hash: hash[:]})
+	}
+
+	store := &StaticKeyStore{}
+	store.entries.Store(&entries)
+	return store
+}
+
+// Replace takes the keys of other, also while the store is in use.
+func (s *StaticKeyStore) Replace(other *StaticKeyStore) {
+	s.entries.Store(other.entries.Load())
+}
+
+// Lookup compares the key against every entry in constant time.
+func (s *StaticKeyStore) Lookup(key string) (string, bool) {
+	hash := sha256.Sum256([]byte(key))
+
+	clientID := ""
+	for _, entry := range *s.entries.Load() {
+		if subtle.ConstantTimeCompare(hash[:], entry.hash) == 1 {
+			clientID = entry.clientID
+		}
+	}
+
+	return clientID, clientID != ""
+}
+
+func (s *StaticKeyStore) Len() int {
+	return len(*s.entries.Load())
+}
+
+// ParseKeys parses the API_KEYS format: comma separated "client:key" pairs,
+// where key may be "sha256:<hex>". A client may be listed once per key.
+func ParseKeys(value string) (Keys, error) {
+	entries, err := keylist.Parse(value, "api key", func(entry keylist.Entry) (string, error) {
+		hash, err := keyHash(entry.Secret)
+		if err != nil {
+			return "", fmt.Errorf("invalid sha256 hash for client %s", entry.ID)
+		}
+		return string(hash[:]), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(Keys, len(entries))
+	for _, entry := range entries {
+		// Parse checked the hash, and that no two entries share it.
+		hash, _ := keyHash(entry.Secret)
+		keys[hash] = entry.ID
+	}
+	return keys, nil
+}
+
+// LoadKeyFile reads one "client key" pair per line. Blank lines and lines
+// starting with # are ignored.
+func LoadKeyFile(path string) (Keys, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	keys := make(Keys)
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%s:%d: expected \"client key\"", path, line)
+		}
+		if err := keys.Add(fields[0], fields[1]); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+	}
+
+	return keys, scanner.Err()
+}
+
+// APIKeyAuthenticator accepts keys sent as "Authorization: Bearer <key>" or
+// in the X-API-Key header.
+type APIKeyAuthenticator struct {
+	store KeyStore
+}
+
+func NewAPIKeyAuthenticator(store KeyStore) *APIKeyAuthenticator {
+	return &APIKeyAuthenticator{store: store}
+}
+
+func (a *APIKeyAuthenticator) Authenticate(r *http.Request) (*Identity, error) {
+	key := r.Header.Get("X-API-Key")
+	if key == "" {
+		key = bearerToken(r)
+	}
+
+	if key == "" {
+		return nil, apperror.ErrUnauthorized
+	}
+
+	clientID, ok := a.store.Lookup(key)
+	if !ok {
+		return nil, apperror.ErrUnauthorized
+	}
+
+	return &Identity{ClientID: clientID, Method: MethodAPIKey}, nil
+}
+
+func bearerToken(r *http.Request) string {
+	scheme, token, found := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
@@ -0,0 +1,222 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go-a-b-microservices/pkg/config"
+	"go-a-b-microservices/pkg/ratelimit"
+)
+
+type MockLogger struct{}
+
+func (m *MockLogger) Info(message string, args ...interface{})  {}
+func (m *MockLogger) Error(message string, args ...interface{}) {}
+func (m *MockLogger) Debug(message string, args ...interface{}) {}
+
+func TestStaticKeyStore_Lookup(t *testing.T) {
+	hash := sha256.Sum256([]byte("hashed-secret"))
+	store := mustKeyStore(t, "plain-client:plain-secret, hashed-client:sha256:"+hex.EncodeToString(hash[:]))
+
+	tests := []struct {
+		key        string
+		expectedID string
+		expectedOK bool
+	}{
+		{key: "plain-secret", expectedID: "plain-client", expectedOK: true},
+		{key: "hashed-secret", expectedID: "hashed-client", expectedOK: true},
+		{key: "sha256:" + hex.EncodeToString(hash[:]), expectedOK: false},
+		{key: "unknown", expectedOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			clientID, ok := store.Lookup(tt.key)
+			if ok != tt.expectedOK || clientID != tt.expectedID {
+				t.Errorf("Lookup(%q) = %q, %v, want %q, %v", tt.key, clientID, ok, tt.expectedID, tt.expectedOK)
+			}
+		})
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	hash := sha256.Sum256([]byte("abc123"))
+
+	tests := []struct {
+		name        string
+		value       string
+		expected    Keys
+		expectedErr string
+	}{
+		{
+			name:     "Plain and hashed keys",
+			value:    "mobile:abc123, web:sha256:" + hex.EncodeToString(make([]byte, sha256.Size)),
+			expected: Keys{hash: "mobile", {}: "web"},
+		},
+		{
+			name:     "Client rotating its key",
+			value:    "mobile:abc123,mobile:def456",
+			expected: Keys{hash: "mobile", sha256.Sum256([]byte("def456")): "mobile"},
+		},
+		{name: "Missing separator", value: "missing-separator", expectedErr: "api key entry 1 is not in the id:secret form"},
+		{name: "Invalid hash", value: "client:sha256:not-hex", expectedErr: "invalid sha256 hash for client client"},
+		{name: "Repeated entry", value: "mobile:abc123,mobile:abc123", expectedErr: "api key entry 2 repeats entry 1"},
+		{name: "Key shared by two clients", value: "mobile:abc123,web:sha256:" + hex.EncodeToString(hash[:]), expectedErr: "api key entry 2 repeats entry 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := ParseKeys(tt.value)
+			if tt.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+					t.Fatalf("Expected error containing %q, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if !maps.Equal(keys, tt.expected) {
+				t.Errorf("Expected keys %v, got %v", tt.expected, keys)
+			}
+		})
+	}
+}
+
+func TestLoadKeyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys")
+	content := "# client key\nmobile abc123\n\nweb def456\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write key file: %v", err)
+	}
+
+	keys, err := LoadKeyFile(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(keys) != 2 || keys[sha256.Sum256([]byte("abc123"))] != "mobile" {
+		t.Errorf("Unexpected keys %v", keys)
+	}
+
+	store, err := NewKeyStore(&config.ServiceA{APIKeys: "mobile:ghi789", APIKeysFile: path})
+	if err != nil || store.Len() != 3 {
+		t.Fatalf("Expected the keys of both sources, got %v", err)
+	}
+	if _, err := NewKeyStore(&config.ServiceA{APIKeys: "other:def456", APIKeysFile: path}); err == nil || !strings.Contains(err.Error(), "client web repeats a key of client other") {
+		t.Errorf("Expected a key listed in both sources to be refused, got %v", err)
+	}
+}
+
+func TestMiddleware_APIKey(t *testing.T) {
+	store := mustKeyStore(t, "mobile:abc123")
+	var identity *Identity
+	handler := Middleware(NewAPIKeyAuthenticator(store), &MockLogger{}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity, _ = IdentityFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name           string
+		headers        map[string]string
+		expectedStatus int
+		expectedClient string
+	}{
+		{
+			name:           "bearer token",
+			headers:        map[string]string{"Authorization": "Bearer abc123"},
+			expectedStatus: http.StatusOK,
+			expectedClient: "mobile",
+		},
+		{
+			name:           "api key header",
+			headers:        map[string]string{"X-API-Key": "abc123"},
+			expectedStatus: http.StatusOK,
+			expectedClient: "mobile",
+		},
+		{
+			name:           "missing credentials",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "wrong key",
+			headers:        map[string]string{"Authorization": "Bearer nope"},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "basic scheme is not accepted",
+			headers:        map[string]string{"Authorization": "Basic abc123"},
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			identity = nil
+			req := httptest.NewRequest(http.MethodPost, "/zipcode", nil)
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, rec.Code)
+			}
+			if tt.expectedClient != "" && (identity == nil || identity.ClientID != tt.expectedClient) {
+				t.Errorf("Expected client %s in context, got %+v", tt.expectedClient, identity)
+			}
+			if rec.Code == http.StatusUnauthorized && rec.Header().Get("WWW-Authenticate") == "" {
+				t.Errorf("Expected a WWW-Authenticate header on 401")
+			}
+		})
+	}
+}
+
+// TestIdentify_RateLimitsRejectedCallers checks that failed attempts are
+// rate limited by IP address and do not use up the budget of the client.
+func TestIdentify_RateLimitsRejectedCallers(t *testing.T) {
+	limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), ratelimit.NewLimit(2, time.Minute, 0), nil)
+	handler := Identify(NewAPIKeyAuthenticator(mustKeyStore(t, "mobile:abc123")), &MockLogger{},
+		ratelimit.Middleware(limiter, RateLimitKey, &MockLogger{}, Require(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))))
+
+	send := func(key string) int {
+		req := httptest.NewRequest(http.MethodPost, "/zipcode", nil)
+		req.RemoteAddr = "203.0.113.7:4000"
+		req.Header.Set("X-API-Key", key)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	for _, expected := range []int{http.StatusUnauthorized, http.StatusUnauthorized, http.StatusTooManyRequests} {
+		if status := send("guess"); status != expected {
+			t.Fatalf("Expected status %d for a wrong key, got %d", expected, status)
+		}
+	}
+	if status := send("abc123"); status != http.StatusOK {
+		t.Errorf("Expected the client to keep its own budget, got %d", status)
+	}
+}
+
+func TestStaticKeyStore_Replace(t *testing.T) {
+	store := mustKeyStore(t, "mobile:old-key")
+	store.Replace(mustKeyStore(t, "mobile:new-key"))
+
+	if _, ok := store.Lookup("old-key"); ok {
+		t.Error("Expected the old key to be rejected")
+	}
+	if clientID, ok := store.Lookup("new-key"); !ok || clientID != "mobile" {
+		t.Errorf("Expected the new key to belong to mobile, got %q, %v", clientID, ok)
+	}
+}
@@ -0,0 +1,109 @@
+package auth
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"go-a-b-microservices/pkg/config"
+)
+
+const (
+	ModeNone   = "none"
+	ModeAPIKey = "apikey"
+	ModeJWT    = "jwt"
+)
+
+// NewAuthenticator builds the authenticators selected by AUTH_MODE, a comma
+// separated list such as "apikey,jwt". API keys are looked up in keys, built
+// by NewKeyStore, which can be replaced on reload. It returns nil when
+// authentication is disabled.
+func NewAuthenticator(cfg *config.ServiceA, keys *StaticKeyStore) (Authenticator, error) {
+	var chain ChainAuthenticator
+
+	for _, mode := range strings.Split(cfg.AuthMode, ",") {
+		switch strings.TrimSpace(mode) {
+		case ModeNone, "":
+		case ModeAPIKey:
+			chain = append(chain, NewAPIKeyAuthenticator(keys))
+		case ModeJWT:
+			authenticator, err := newJWTAuthenticator(cfg)
+			if err != nil {
+				return nil, err
+			}
+			chain = append(chain, authenticator)
+		default:
+			return nil, fmt.Errorf("unknown auth mode %q", mode)
+		}
+	}
+
+	switch len(chain) {
+	case 0:
+		return nil, nil
+	case 1:
+		return chain[0], nil
+	default:
+		return chain, nil
+	}
+}
+
+// NewKeyStore accepts the keys from both API_KEYS_FILE and API_KEYS. A key
+// listed in both is an error. It fails when AUTH_MODE includes apikey and
+// there are no keys.
+func NewKeyStore(cfg *config.ServiceA) (*StaticKeyStore, error) {
+	keys, err := ParseKeys(cfg.APIKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.APIKeysFile != "" {
+		fileKeys, err := LoadKeyFile(cfg.APIKeysFile)
+		if err != nil {
+			return nil, err
+		}
+		for hash, clientID := range fileKeys {
+			if owner, ok := keys[hash]; ok {
+				return nil, fmt.Errorf("%s: client %s repeats a key of client %s in API_KEYS", cfg.APIKeysFile, clientID, owner)
+			}
+			keys[hash] = clientID
+		}
+	}
+
+	store := NewStaticKeyStore(keys)
+	if store.Len() == 0 && slices.Contains(strings.FieldsFunc(cfg.AuthMode, isListSeparator), ModeAPIKey) {
+		return nil, fmt.Errorf("auth mode %q needs API_KEYS or API_KEYS_FILE", ModeAPIKey)
+	}
+
+	return store, nil
+}
+
+func newJWTAuthenticator(cfg *config.ServiceA) (*JWTAuthenticator, error) {
+	if cfg.JWTIssuer == "" {
+		return nil, fmt.Errorf("auth mode %q needs JWT_ISSUER", ModeJWT)
+	}
+
+	var keys KeySource
+	switch {
+	case cfg.JWTPublicKeyFile != "":
+		source, err := LoadKeyFileSource(cfg.JWTPublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		keys = source
+	case cfg.JWTJWKSURL != nil:
+		keys = NewJWKSKeySource(cfg.JWTJWKSURL.String(), cfg.JWTJWKSCacheTTL)
+	default:
+		return nil, fmt.Errorf("auth mode %q needs JWT_JWKS_URL or JWT_PUBLIC_KEY_FILE", ModeJWT)
+	}
+
+	return NewJWTAuthenticator(JWTConfig{
+		Issuer:         cfg.JWTIssuer,
+		Audience:       cfg.JWTAudience,
+		RequiredScopes: strings.FieldsFunc(cfg.JWTRequiredScopes, isListSeparator),
+		Leeway:         cfg.JWTLeeway,
+	}, keys), nil
+}
+
+func isListSeparator(r rune) bool {
+	return r == ',' || r == ' '
+}
@@ -0,0 +1,45 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+
+	"go-a-b-microservices/pkg/ratelimit"
+)
+
+const MethodAPIKey = "api_key"
+
+// Identity describes the authenticated caller of a request. Subject, Scopes
+// and Claims are only set for JWT callers.
+type Identity struct {
+	ClientID string
+	Method   string
+	Subject  string
+	Scopes   []string
+	Claims   Claims
+}
+
+type identityKey struct{}
+
+func WithIdentity(ctx context.Context, identity *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// IdentityFromContext returns the caller attached by Middleware, if any.
+func IdentityFromContext(ctx context.Context) (*Identity, bool) {
+	identity, ok := ctx.Value(identityKey{}).(*Identity)
+	return identity, ok
+}
+
+// RateLimitKey keys authenticated callers by client ID and everyone else by
+// IP address.
+func RateLimitKey(r *http.Request) string {
+	if identity, ok := IdentityFromContext(r.Context()); ok && identity.ClientID != "" {
+		return ClientRateLimitKey(identity.ClientID)
+	}
+	return ratelimit.ClientIP(r)
+}
+
+func ClientRateLimitKey(clientID string) string {
+	return "client:" + clientID
+}
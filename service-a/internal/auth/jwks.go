@@ -0,0 +1,273 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+var errKeyNotFound = errors.New("signing key not found")
+
+// KeySource resolves the public key a token was signed with. An empty kid
+// means the token did not name its key.
+type KeySource interface {
+	Keys(ctx context.Context, kid string) ([]crypto.PublicKey, error)
+}
+
+type jwk struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jwkSet struct {
+	Keys []jwk `json:"keys"`
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+// parseJWKS returns the signing keys of a JWKS document by kid. Keys that
+// cannot be used for signatures are skipped.
+func parseJWKS(data []byte) (map[string]crypto.PublicKey, error) {
+	var set jwkSet
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]crypto.PublicKey)
+	for _, key := range set.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+		publicKey, err := key.publicKey()
+		if err != nil {
+			continue
+		}
+		keys[key.Kid] = publicKey
+	}
+	return keys, nil
+}
+
+// JWKSKeySource fetches keys from a JWKS endpoint. Keys are cached for the
+// configured TTL and refetched early when a token names an unknown kid, which
+// is how issuers roll their keys. A fetch runs outside the lock, once for all
+// the callers waiting on it, and the endpoint is asked again at most once per
+// minInterval whether the last attempt failed or not, so an outage or tokens
+// with made up kids do not reach it on every request.
+type JWKSKeySource struct {
+	url         string
+	client      *http.Client
+	ttl         time.Duration
+	minInterval time.Duration
+
+	mu          sync.Mutex
+	keys        map[string]crypto.PublicKey
+	fetchedAt   time.Time
+	attemptedAt time.Time
+	fetchErr    error
+	fetching    chan struct{}
+	now         func() time.Time
+}
+
+func NewJWKSKeySource(url string, ttl time.Duration) *JWKSKeySource {
+	return &JWKSKeySource{
+		url:         url,
+		client:      &http.Client{Transport: otelhttp.NewTransport(http.DefaultTransport), Timeout: 10 * time.Second},
+		ttl:         ttl,
+		minInterval: 30 * time.Second,
+		now:         time.Now,
+	}
+}
+
+func (s *JWKSKeySource) Keys(ctx context.Context, kid string) ([]crypto.PublicKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	expired := s.keys == nil || now.Sub(s.fetchedAt) > s.ttl
+	_, known := s.keys[kid]
+	unknownKid := kid != "" && !known
+	due := (expired || unknownKid) && (s.attemptedAt.IsZero() || now.Sub(s.attemptedAt) > s.minInterval)
+
+	if due && s.fetching == nil {
+		s.attemptedAt = now
+		s.fetching = make(chan struct{})
+		done := s.fetching
+
+		// Callers waiting on the fetch must not fail because this one
+		// went away; the client timeout still bounds it.
+		s.mu.Unlock()
+		keys, err := s.fetch(context.WithoutCancel(ctx))
+		s.mu.Lock()
+
+		if err == nil {
+			s.keys = keys
+			s.fetchedAt = s.now()
+		}
+		s.fetchErr = err
+		s.fetching = nil
+		close(done)
+	} else if done := s.fetching; done != nil && (s.keys == nil || unknownKid) {
+		// Only callers that cannot do with the keys at hand wait.
+		s.mu.Unlock()
+		select {
+		case <-done:
+		case <-ctx.Done():
+			s.mu.Lock()
+			return nil, ctx.Err()
+		}
+		s.mu.Lock()
+	}
+
+	if s.keys == nil {
+		return nil, s.fetchErr
+	}
+	return selectKeys(s.keys, kid)
+}
+
+func (s *JWKSKeySource) fetch(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("jwks endpoint returned status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+
+	return parseJWKS(body)
+}
+
+// StaticKeySource serves keys loaded once from a local file.
+type StaticKeySource struct {
+	keys map[string]crypto.PublicKey
+}
+
+// LoadKeyFileSource reads either a JWKS document or one or more PEM encoded
+// public keys. PEM keys have no kid and are tried in order.
+func LoadKeyFileSource(path string) (*StaticKeySource, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if keys, err := parseJWKS(data); err == nil && len(keys) > 0 {
+		return &StaticKeySource{keys: keys}, nil
+	}
+
+	keys := make(map[string]crypto.PublicKey)
+	for i := 0; ; i++ {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		keys[fmt.Sprintf("pem-%d", i)] = publicKey
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%s: no public keys found", path)
+	}
+
+	return &StaticKeySource{keys: keys}, nil
+}
+
+func (s *StaticKeySource) Keys(ctx context.Context, kid string) ([]crypto.PublicKey, error) {
+	if key, ok := s.keys[kid]; ok {
+		return []crypto.PublicKey{key}, nil
+	}
+
+	keys := make([]crypto.PublicKey, 0, len(s.keys))
+	for _, key := range s.keys {
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func selectKeys(keys map[string]crypto.PublicKey, kid string) ([]crypto.PublicKey, error) {
+	if kid != "" {
+		key, ok := keys[kid]
+		if !ok {
+			return nil, errKeyNotFound
+		}
+		return []crypto.PublicKey{key}, nil
+	}
+
+	list := make([]crypto.PublicKey, 0, len(keys))
+	for _, key := range keys {
+		list = append(list, key)
+	}
+	return list, nil
+}
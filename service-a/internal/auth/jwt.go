@@ -0,0 +1,268 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"go-a-b-microservices/pkg/apperror"
+)
+
+const MethodJWT = "jwt"
+
+// Claims are the decoded claims of a verified token.
+type Claims map[string]interface{}
+
+func (c Claims) String(name string) string {
+	value, _ := c[name].(string)
+	return value
+}
+
+// Strings returns a claim that may be a single string or an array of them.
+func (c Claims) Strings(name string) []string {
+	switch value := c[name].(type) {
+	case string:
+		return []string{value}
+	case []interface{}:
+		list := make([]string, 0, len(value))
+		for _, item := range value {
+			if s, ok := item.(string); ok {
+				list = append(list, s)
+			}
+		}
+		return list
+	default:
+		return nil
+	}
+}
+
+func (c Claims) Time(name string) (time.Time, bool) {
+	value, ok := c[name].(float64)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(value), 0), true
+}
+
+// Scopes reads the OAuth "scope" claim (space separated) or the "scp" claim
+// some issuers use instead.
+func (c Claims) Scopes() []string {
+	if scope := c.String("scope"); scope != "" {
+		return strings.Fields(scope)
+	}
+	return c.Strings("scp")
+}
+
+type JWTConfig struct {
+	Issuer         string
+	Audience       string
+	RequiredScopes []string
+	Leeway         time.Duration
+}
+
+// JWTAuthenticator verifies bearer tokens signed with RSA or ECDSA keys and
+// checks their issuer, audience, lifetime and scopes.
+type JWTAuthenticator struct {
+	config JWTConfig
+	keys   KeySource
+	now    func() time.Time
+}
+
+func NewJWTAuthenticator(config JWTConfig, keys KeySource) *JWTAuthenticator {
+	return &JWTAuthenticator{config: config, keys: keys, now: time.Now}
+}
+
+func (a *JWTAuthenticator) Authenticate(r *http.Request) (*Identity, error) {
+	token := bearerToken(r)
+	if token == "" {
+		return nil, apperror.ErrUnauthorized
+	}
+
+	claims, err := a.verify(r, token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", apperror.ErrUnauthorized, err)
+	}
+
+	if missing := missingScopes(claims.Scopes(), a.config.RequiredScopes); len(missing) > 0 {
+		return nil, fmt.Errorf("%w: missing %s", apperror.ErrForbidden, strings.Join(missing, ", "))
+	}
+
+	clientID := claims.String("azp")
+	if clientID == "" {
+		clientID = claims.String("client_id")
+	}
+	if clientID == "" {
+		clientID = claims.String("sub")
+	}
+
+	return &Identity{
+		ClientID: clientID,
+		Method:   MethodJWT,
+		Subject:  claims.String("sub"),
+		Scopes:   claims.Scopes(),
+		Claims:   claims,
+	}, nil
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+func (a *JWTAuthenticator) verify(r *http.Request, token string) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed token")
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("invalid header: %w", err)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature encoding: %w", err)
+	}
+
+	keys, err := a.keys.Keys(r.Context(), header.Kid)
+	if err != nil {
+		return nil, err
+	}
+
+	signed := []byte(parts[0] + "." + parts[1])
+	verified := false
+	for _, key := range keys {
+		if verifySignature(header.Alg, key, signed, signature) == nil {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return nil, errors.New("invalid signature")
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("invalid claims: %w", err)
+	}
+
+	if err := a.validateClaims(claims); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+func (a *JWTAuthenticator) validateClaims(claims Claims) error {
+	now := a.now()
+
+	expiresAt, ok := claims.Time("exp")
+	if !ok {
+		return errors.New("token has no expiry")
+	}
+	if now.After(expiresAt.Add(a.config.Leeway)) {
+		return errors.New("token expired")
+	}
+
+	if notBefore, ok := claims.Time("nbf"); ok && now.Add(a.config.Leeway).Before(notBefore) {
+		return errors.New("token not yet valid")
+	}
+
+	if a.config.Issuer != "" && claims.String("iss") != a.config.Issuer {
+		return fmt.Errorf("unexpected issuer %q", claims.String("iss"))
+	}
+
+	if a.config.Audience != "" && !contains(claims.Strings("aud"), a.config.Audience) {
+		return errors.New("token is not meant for this audience")
+	}
+
+	return nil
+}
+
+func verifySignature(alg string, key interface{}, signed, signature []byte) error {
+	var hashFunc crypto.Hash
+	var hasher func() hash.Hash
+	switch alg {
+	case "RS256", "ES256", "PS256":
+		hashFunc, hasher = crypto.SHA256, sha256.New
+	case "RS384", "ES384", "PS384":
+		hashFunc, hasher = crypto.SHA384, sha512.New384
+	case "RS512", "ES512", "PS512":
+		hashFunc, hasher = crypto.SHA512, sha512.New
+	default:
+		return fmt.Errorf("unsupported algorithm %q", alg)
+	}
+
+	h := hasher()
+	h.Write(signed)
+	digest := h.Sum(nil)
+
+	switch alg[:2] {
+	case "RS":
+		publicKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return errors.New("key type does not match algorithm")
+		}
+		return rsa.VerifyPKCS1v15(publicKey, hashFunc, digest, signature)
+	case "PS":
+		publicKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return errors.New("key type does not match algorithm")
+		}
+		return rsa.VerifyPSS(publicKey, hashFunc, digest, signature, nil)
+	default:
+		publicKey, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return errors.New("key type does not match algorithm")
+		}
+		size := (publicKey.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return errors.New("invalid signature length")
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(publicKey, digest, r, s) {
+			return errors.New("invalid signature")
+		}
+		return nil
+	}
+}
+
+func decodeSegment(segment string, out interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+func missingScopes(granted, required []string) []string {
+	var missing []string
+	for _, scope := range required {
+		if !contains(granted, scope) {
+			missing = append(missing, scope)
+		}
+	}
+	return missing
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,384 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"go-a-b-microservices/pkg/apperror"
+)
+
+const (
+	testIssuer   = "https://issuer.example.com/"
+	testAudience = "service-a"
+)
+
+type testJWKS struct {
+	mu      sync.Mutex
+	keys    []map[string]string
+	fetches int
+}
+
+func (j *testJWKS) add(kid string, key crypto.PublicKey) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		j.keys = append(j.keys, map[string]string{
+			"kty": "RSA",
+			"kid": kid,
+			"n":   base64.RawURLEncoding.EncodeToString(k.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.E)).Bytes()),
+		})
+	case *ecdsa.PublicKey:
+		size := (k.Curve.Params().BitSize + 7) / 8
+		j.keys = append(j.keys, map[string]string{
+			"kty": "EC",
+			"kid": kid,
+			"crv": k.Curve.Params().Name,
+			"x":   base64.RawURLEncoding.EncodeToString(k.X.FillBytes(make([]byte, size))),
+			"y":   base64.RawURLEncoding.EncodeToString(k.Y.FillBytes(make([]byte, size))),
+		})
+	}
+}
+
+func (j *testJWKS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.fetches++
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"keys": j.keys})
+}
+
+func signToken(t *testing.T, alg, kid string, key crypto.Signer, claims map[string]interface{}) string {
+	t.Helper()
+
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	digest := sha256.Sum256([]byte(signed))
+	var signature []byte
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		sig, err := rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+		if err != nil {
+			t.Fatalf("Failed to sign token: %v", err)
+		}
+		signature = sig
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest[:])
+		if err != nil {
+			t.Fatalf("Failed to sign token: %v", err)
+		}
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func validClaims(now time.Time) map[string]interface{} {
+	return map[string]interface{}{
+		"iss":   testIssuer,
+		"aud":   []string{testAudience, "other"},
+		"sub":   "user-42",
+		"azp":   "mobile-app",
+		"scope": "weather:read forecast:read",
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}
+}
+
+func bearerRequest(token string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/zipcode", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	return req
+}
+
+func TestJWTAuthenticator_Authenticate(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	otherKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+	jwks := &testJWKS{}
+	jwks.add("rsa-1", &rsaKey.PublicKey)
+	jwks.add("ec-1", &ecKey.PublicKey)
+	server := httptest.NewServer(jwks)
+	defer server.Close()
+
+	now := time.Now()
+	authenticator := NewJWTAuthenticator(JWTConfig{
+		Issuer:         testIssuer,
+		Audience:       testAudience,
+		RequiredScopes: []string{"weather:read"},
+		Leeway:         30 * time.Second,
+	}, NewJWKSKeySource(server.URL, time.Hour))
+
+	with := func(name string, value interface{}) map[string]interface{} {
+		claims := validClaims(now)
+		if value == nil {
+			delete(claims, name)
+		} else {
+			claims[name] = value
+		}
+		return claims
+	}
+
+	tests := []struct {
+		name        string
+		token       string
+		expectedErr error
+	}{
+		{name: "RS256", token: signToken(t, "RS256", "rsa-1", rsaKey, validClaims(now))},
+		{name: "ES256", token: signToken(t, "ES256", "ec-1", ecKey, validClaims(now))},
+		{name: "within leeway", token: signToken(t, "RS256", "rsa-1", rsaKey, with("exp", now.Add(-10*time.Second).Unix()))},
+		{name: "expired", token: signToken(t, "RS256", "rsa-1", rsaKey, with("exp", now.Add(-time.Minute).Unix())), expectedErr: apperror.ErrUnauthorized},
+		{name: "no expiry", token: signToken(t, "RS256", "rsa-1", rsaKey, with("exp", nil)), expectedErr: apperror.ErrUnauthorized},
+		{name: "not yet valid", token: signToken(t, "RS256", "rsa-1", rsaKey, with("nbf", now.Add(time.Hour).Unix())), expectedErr: apperror.ErrUnauthorized},
+		{name: "wrong issuer", token: signToken(t, "RS256", "rsa-1", rsaKey, with("iss", "https://evil.example.com/")), expectedErr: apperror.ErrUnauthorized},
+		{name: "wrong audience", token: signToken(t, "RS256", "rsa-1", rsaKey, with("aud", "another-service")), expectedErr: apperror.ErrUnauthorized},
+		{name: "missing scope", token: signToken(t, "RS256", "rsa-1", rsaKey, with("scope", "forecast:read")), expectedErr: apperror.ErrForbidden},
+		{name: "bad signature", token: signToken(t, "RS256", "rsa-1", otherKey, validClaims(now)), expectedErr: apperror.ErrUnauthorized},
+		{name: "algorithm mismatch", token: signToken(t, "ES256", "rsa-1", ecKey, validClaims(now)), expectedErr: apperror.ErrUnauthorized},
+		{name: "unsupported algorithm", token: "eyJhbGciOiJub25lIn0.eyJzdWIiOiJ4In0.", expectedErr: apperror.ErrUnauthorized},
+		{name: "malformed", token: "not-a-jwt", expectedErr: apperror.ErrUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			identity, err := authenticator.Authenticate(bearerRequest(tt.token))
+
+			if tt.expectedErr != nil {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Fatalf("Expected error %v, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if identity.ClientID != "mobile-app" || identity.Subject != "user-42" || identity.Method != MethodJWT {
+				t.Errorf("Unexpected identity %+v", identity)
+			}
+			if len(identity.Scopes) != 2 || identity.Claims.String("iss") != testIssuer {
+				t.Errorf("Expected scopes and claims on the identity, got %+v", identity)
+			}
+		})
+	}
+}
+
+func TestJWKSKeySource_RefetchesUnknownKid(t *testing.T) {
+	oldKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	newKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	jwks := &testJWKS{}
+	jwks.add("old", &oldKey.PublicKey)
+	server := httptest.NewServer(jwks)
+	defer server.Close()
+
+	clock := time.Now()
+	source := NewJWKSKeySource(server.URL, time.Hour)
+	source.now = func() time.Time { return clock }
+	authenticator := NewJWTAuthenticator(JWTConfig{Issuer: testIssuer}, source)
+
+	if _, err := authenticator.Authenticate(bearerRequest(signToken(t, "ES256", "old", oldKey, validClaims(clock)))); err != nil {
+		t.Fatalf("Expected the old key to verify, got %v", err)
+	}
+
+	// The issuer rotates keys; an unknown kid right after a fetch is not
+	// enough to hit the endpoint again.
+	jwks.add("new", &newKey.PublicKey)
+	token := signToken(t, "ES256", "new", newKey, validClaims(clock))
+	if _, err := authenticator.Authenticate(bearerRequest(token)); err == nil {
+		t.Fatalf("Expected the new kid to be unknown before the refetch interval")
+	}
+
+	clock = clock.Add(time.Minute)
+	if _, err := authenticator.Authenticate(bearerRequest(token)); err != nil {
+		t.Fatalf("Expected the new key to verify after a refetch, got %v", err)
+	}
+
+	if jwks.fetches != 2 {
+		t.Errorf("Expected 2 JWKS fetches, got %d", jwks.fetches)
+	}
+}
+
+func TestJWKSKeySource_BacksOffAfterFailure(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	jwks := &testJWKS{}
+	jwks.add("current", &key.PublicKey)
+
+	var mu sync.Mutex
+	failing, fetches := true, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		fetches++
+		down := failing
+		mu.Unlock()
+		if down {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		jwks.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	clock := time.Now()
+	source := NewJWKSKeySource(server.URL, time.Hour)
+	source.now = func() time.Time { return clock }
+
+	for i := 0; i < 5; i++ {
+		if _, err := source.Keys(context.Background(), "current"); err == nil {
+			t.Fatalf("Expected an error while the endpoint is down")
+		}
+	}
+	if fetches != 1 {
+		t.Errorf("Expected one fetch until the refetch interval passes, got %d", fetches)
+	}
+
+	mu.Lock()
+	failing = false
+	mu.Unlock()
+	clock = clock.Add(time.Minute)
+	if _, err := source.Keys(context.Background(), "current"); err != nil {
+		t.Fatalf("Expected the key once the endpoint is back, got %v", err)
+	}
+
+	// Tokens with made up kids do not reach the endpoint on every request.
+	clock = clock.Add(time.Minute)
+	for i := 0; i < 5; i++ {
+		source.Keys(context.Background(), fmt.Sprintf("made-up-%d", i))
+	}
+	if fetches != 3 {
+		t.Errorf("Expected 3 fetches in total, got %d", fetches)
+	}
+}
+
+func TestJWKSKeySource_SharesFetch(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	jwks := &testJWKS{}
+	jwks.add("current", &key.PublicKey)
+
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		jwks.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+	source := NewJWKSKeySource(server.URL, time.Hour)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 5)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := source.Keys(context.Background(), "current")
+			errs <- err
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Expected every caller to get the key, got %v", err)
+		}
+	}
+	if jwks.fetches != 1 {
+		t.Errorf("Expected concurrent callers to share one fetch, got %d", jwks.fetches)
+	}
+}
+
+func TestLoadKeyFileSource_PEM(t *testing.T) {
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "issuer.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("Failed to write key file: %v", err)
+	}
+
+	source, err := LoadKeyFileSource(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	now := time.Now()
+	authenticator := NewJWTAuthenticator(JWTConfig{Issuer: testIssuer, Audience: testAudience}, source)
+	if _, err := authenticator.Authenticate(bearerRequest(signToken(t, "RS256", "any", key, validClaims(now)))); err != nil {
+		t.Errorf("Expected the PEM key to verify, got %v", err)
+	}
+}
+
+func TestMiddleware_Forbidden(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	jwks := &testJWKS{}
+	jwks.add("k", &key.PublicKey)
+	server := httptest.NewServer(jwks)
+	defer server.Close()
+
+	authenticator := ChainAuthenticator{
+		NewAPIKeyAuthenticator(mustKeyStore(t, "web:secret")),
+		NewJWTAuthenticator(JWTConfig{Issuer: testIssuer, RequiredScopes: []string{"admin"}}, NewJWKSKeySource(server.URL, time.Hour)),
+	}
+	handler := Middleware(authenticator, &MockLogger{}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name           string
+		request        *http.Request
+		expectedStatus int
+	}{
+		{name: "api key", request: bearerRequest("secret"), expectedStatus: http.StatusOK},
+		{name: "token without scope", request: bearerRequest(signToken(t, "ES256", "k", key, validClaims(time.Now()))), expectedStatus: http.StatusForbidden},
+		{name: "garbage", request: bearerRequest("nope"), expectedStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, tt.request)
+
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, rec.Code)
+			}
+		})
+	}
+}
+
+func mustKeyStore(t *testing.T, value string) *StaticKeyStore {
+	t.Helper()
+
+	keys, err := ParseKeys(value)
+	if err != nil {
+		t.Fatalf("Failed to parse keys: %v", err)
+	}
+	return NewStaticKeyStore(keys)
+}
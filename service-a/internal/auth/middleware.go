@@ -0,0 +1,101 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"go-a-b-microservices/pkg/apperror"
+	"go-a-b-microservices/pkg/i18n"
+	"go-a-b-microservices/pkg/jsonbody"
+	"go-a-b-microservices/pkg/logger"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Authenticator identifies the caller of a request.
+type Authenticator interface {
+	Authenticate(r *http.Request) (*Identity, error)
+}
+
+// Middleware rejects requests the authenticator does not accept and attaches
+// the caller identity to the context and the current span. It is Identify
+// followed by Require, for handlers that need nothing in between.
+func Middleware(authenticator Authenticator, log logger.Logger, next http.Handler) http.Handler {
+	return Identify(authenticator, log, Require(next))
+}
+
+// Identify attaches the caller identity, or why the authenticator did not
+// accept the request, to the context and the current span. It lets every
+// request through, so that a rate limiter in between can key rejected callers
+// by IP address before Require turns them away.
+func Identify(authenticator Authenticator, log logger.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		span := trace.SpanFromContext(r.Context())
+
+		identity, err := authenticator.Authenticate(r)
+		if err != nil {
+			log.Error("Rejected request to %s: %v", r.URL.Path, err)
+			span.SetAttributes(attribute.Bool("auth.authenticated", false))
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), rejectionKey{}, err)))
+			return
+		}
+
+		span.SetAttributes(
+			attribute.Bool("auth.authenticated", true),
+			attribute.String("auth.method", identity.Method),
+			attribute.String("enduser.id", identity.ClientID),
+		)
+		log.Info("Authenticated client %s for %s %s", identity.ClientID, r.Method, r.URL.Path)
+
+		next.ServeHTTP(w, r.WithContext(WithIdentity(r.Context(), identity)))
+	})
+}
+
+type rejectionKey struct{}
+
+// Require responds 401, or 403 for missing scopes, to requests without an
+// identity from Identify.
+func Require(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := IdentityFromContext(r.Context()); ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		err, _ := r.Context().Value(rejectionKey{}).(error)
+		status, authErr := http.StatusUnauthorized, apperror.ErrUnauthorized
+		if errors.Is(err, apperror.ErrForbidden) {
+			status, authErr = http.StatusForbidden, apperror.ErrForbidden
+			w.Header().Set("WWW-Authenticate", `Bearer error="insufficient_scope"`)
+		} else {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+		}
+
+		jsonbody.Write(w, status, i18n.Localize(i18n.FromContext(r.Context()), authErr))
+	})
+}
+
+// ChainAuthenticator accepts a request when any of its authenticators does.
+type ChainAuthenticator []Authenticator
+
+func (c ChainAuthenticator) Authenticate(r *http.Request) (*Identity, error) {
+	var firstErr error
+	for _, authenticator := range c {
+		identity, err := authenticator.Authenticate(r)
+		if err == nil {
+			return identity, nil
+		}
+		// A valid token without the required scopes is more useful to report
+		// than a failure from an authenticator that did not apply.
+		if firstErr == nil || errors.Is(err, apperror.ErrForbidden) {
+			firstErr = err
+		}
+	}
+
+	if firstErr == nil {
+		firstErr = apperror.ErrUnauthorized
+	}
+	return nil, firstErr
+}
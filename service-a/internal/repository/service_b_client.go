@@ -3,14 +3,19 @@ package repository
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"time"
 
 	"go-a-b-microservices/pkg/apperror"
+	"go-a-b-microservices/pkg/calltimeout"
 	"go-a-b-microservices/pkg/config"
+	"go-a-b-microservices/pkg/hmacauth"
+	"go-a-b-microservices/pkg/i18n"
 	"go-a-b-microservices/pkg/logger"
 	"go-a-b-microservices/pkg/zipcode"
 
@@ -19,95 +24,199 @@ import (
 )
 
 type ServiceBClientInterface interface {
-	GetWeatherByZipCode(ctx context.Context, zipCode string) (*WeatherResponse, error)
+	GetWeatherByZipCode(ctx context.Context, request *zipcode.ZipCodeRequest) (*WeatherResponse, error)
+	GetForecastByZipCode(ctx context.Context, request *zipcode.ForecastRequest) (*ForecastResponse, error)
+	GetHistoryByZipCode(ctx context.Context, request *zipcode.HistoryRequest) (*HistoryResponse, error)
+	// GetWeatherBatch requests the weather for up to zipcode.MaxBatchSize ZIP
+	// codes in one call and passes each result to onResult with the index of
+	// its request. A failed request is reported to onResult; the returned
+	// error means the call itself failed.
+	GetWeatherBatch(ctx context.Context, requests []zipcode.ZipCodeRequest, onResult func(index int, response *WeatherResponse, err error)) error
 }
 
 type ServiceBClient struct {
 	client  *http.Client
-	cfg     *config.Config
+	cfg     *config.ServiceA
 	logger  logger.Logger
 	baseURL string
+	signer  *hmacauth.Signer
+	timeout calltimeout.Timeout
 }
 
-type WeatherResponse struct {
-	City  string  `json:"city"`
-	TempC float64 `json:"temp_C"`
-	TempF float64 `json:"temp_F"`
-	TempK float64 `json:"temp_K"`
-}
+type WeatherResponse = zipcode.WeatherResponse
 
-type ErrorResponse struct {
-	Message string `json:"message"`
-}
+type ForecastResponse = zipcode.ForecastResponse
+
+type HistoryResponse = zipcode.HistoryResponse
+
+type ErrorResponse = apperror.Response
 
-func NewServiceBClient(cfg *config.Config, log logger.Logger) *ServiceBClient {
+func NewServiceBClient(cfg *config.ServiceA, log logger.Logger) *ServiceBClient {
 	httpClient := &http.Client{
 		Transport: otelhttp.NewTransport(http.DefaultTransport),
 	}
 
-	return &ServiceBClient{
+	client := &ServiceBClient{
 		client:  httpClient,
 		cfg:     cfg,
 		logger:  log,
-		baseURL: cfg.ServiceBURL,
+		baseURL: cfg.ServiceBURL.String(),
 	}
+	client.SetTimeout(cfg.ServiceBTimeout)
+	return client
+}
+
+// SetTimeout bounds each call to Service B, also while the client is in use.
+func (c *ServiceBClient) SetTimeout(timeout time.Duration) {
+	c.timeout.Set(timeout)
+}
+
+// SetTLSConfig makes the client use tlsConfig, such as a private CA bundle or
+// a client certificate, when calling Service B over HTTPS.
+func (c *ServiceBClient) SetTLSConfig(tlsConfig *tls.Config) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	c.client.Transport = otelhttp.NewTransport(transport)
 }
 
-func (c *ServiceBClient) GetWeatherByZipCode(ctx context.Context, zipCode string) (*WeatherResponse, error) {
+// SetSigner makes the client sign every request to Service B.
+func (c *ServiceBClient) SetSigner(signer *hmacauth.Signer) {
+	c.signer = signer
+}
+
+func (c *ServiceBClient) GetWeatherByZipCode(ctx context.Context, request *zipcode.ZipCodeRequest) (*WeatherResponse, error) {
 	tracer := otel.Tracer("service-a")
 	ctx, span := tracer.Start(ctx, "repository.GetWeatherByZipCode")
 	defer span.End()
 
-	requestBody := zipcode.ZipCodeRequest{CEP: zipCode}
+	var weatherResp WeatherResponse
+	if err := c.post(ctx, "/weather", request, &weatherResp); err != nil {
+		return nil, err
+	}
+
+	return &weatherResp, nil
+}
+
+func (c *ServiceBClient) GetForecastByZipCode(ctx context.Context, request *zipcode.ForecastRequest) (*ForecastResponse, error) {
+	tracer := otel.Tracer("service-a")
+	ctx, span := tracer.Start(ctx, "repository.GetForecastByZipCode")
+	defer span.End()
+
+	var forecastResp ForecastResponse
+	if err := c.post(ctx, "/forecast", request, &forecastResp); err != nil {
+		return nil, err
+	}
+
+	return &forecastResp, nil
+}
+
+func (c *ServiceBClient) GetHistoryByZipCode(ctx context.Context, request *zipcode.HistoryRequest) (*HistoryResponse, error) {
+	tracer := otel.Tracer("service-a")
+	ctx, span := tracer.Start(ctx, "repository.GetHistoryByZipCode")
+	defer span.End()
+
+	var historyResp HistoryResponse
+	if err := c.post(ctx, "/history", request, &historyResp); err != nil {
+		return nil, err
+	}
+
+	return &historyResp, nil
+}
+
+func (c *ServiceBClient) GetWeatherBatch(ctx context.Context, requests []zipcode.ZipCodeRequest, onResult func(index int, response *WeatherResponse, err error)) error {
+	tracer := otel.Tracer("service-a")
+	ctx, span := tracer.Start(ctx, "repository.GetWeatherBatch")
+	defer span.End()
+
+	var batchResp zipcode.WeatherBatchResponse
+	if err := c.post(ctx, "/weather/batch", zipcode.WeatherBatchRequest{Requests: requests}, &batchResp); err != nil {
+		return err
+	}
+
+	for _, result := range batchResp.Results {
+		if result.Error != nil {
+			onResult(result.Index, nil, responseError(result.Error))
+		} else {
+			onResult(result.Index, result.Weather, nil)
+		}
+	}
+
+	return nil
+}
+
+// responseError maps an error response back to its apperror value, or to a
+// plain error carrying its message when the code is not known.
+func responseError(errResp *ErrorResponse) error {
+	if knownErr := apperror.FromCode(errResp.Code); knownErr != nil && errResp.Code != apperror.CodeInternal {
+		return knownErr
+	}
+	return errors.New(errResp.Message)
+}
+
+// post sends requestBody as JSON to the given Service B path and decodes a
+// successful response into out. Error responses are mapped back to apperror
+// values where possible.
+func (c *ServiceBClient) post(ctx context.Context, path string, requestBody interface{}, out interface{}) error {
 	jsonBody, err := json.Marshal(requestBody)
 	if err != nil {
 		c.logger.Error("Failed to marshal request: %v", err)
-		return nil, err
+		return err
 	}
 
-	url := fmt.Sprintf("%s/weather", c.baseURL)
+	ctx, cancel := c.timeout.Context(ctx)
+	defer cancel()
+
+	url := fmt.Sprintf("%s%s", c.baseURL, path)
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(jsonBody))
 	if err != nil {
 		c.logger.Error("Failed to create request: %v", err)
-		return nil, err
+		return err
 	}
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Language", string(i18n.FromContext(ctx)))
+	if c.signer != nil {
+		c.signer.Sign(req, jsonBody)
+	}
 
 	resp, err := c.client.Do(req)
 	if err != nil {
 		c.logger.Error("Failed to make request to Service B: %v", err)
-		return nil, err
+		return err
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		c.logger.Error("Failed to read response body: %v", err)
-		return nil, err
+		return err
 	}
 
 	if resp.StatusCode != http.StatusOK {
 		var errResp ErrorResponse
 		if err := json.Unmarshal(body, &errResp); err != nil {
 			c.logger.Error("Failed to unmarshal error response: %v", err)
-			return nil, fmt.Errorf("service B returned status %d: %s", resp.StatusCode, string(body))
+			return fmt.Errorf("service B returned status %d: %s", resp.StatusCode, string(body))
+		}
+
+		// Messages may be localized, so known errors are recognized by code.
+		if knownErr := apperror.FromCode(errResp.Code); knownErr != nil && errResp.Code != apperror.CodeInternal {
+			return knownErr
 		}
 
 		switch resp.StatusCode {
 		case http.StatusUnprocessableEntity:
-			return nil, apperror.ErrZipCodeInvalid
+			return apperror.ErrZipCodeInvalid
 		case http.StatusNotFound:
-			return nil, apperror.ErrZipCodeNotFound
+			return apperror.ErrZipCodeNotFound
 		default:
-			return nil, errors.New(errResp.Message)
+			return errors.New(errResp.Message)
 		}
 	}
 
-	var weatherResp WeatherResponse
-	if err := json.Unmarshal(body, &weatherResp); err != nil {
+	if err := json.Unmarshal(body, out); err != nil {
 		c.logger.Error("Failed to unmarshal response: %v", err)
-		return nil, err
+		return err
 	}
 
-	return &weatherResp, nil
+	return nil
 }
@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"go-a-b-microservices/pkg/calltimeout"
+	"go-a-b-microservices/pkg/hmacauth"
+	"go-a-b-microservices/pkg/i18n"
+	"go-a-b-microservices/pkg/logger"
+	"go-a-b-microservices/pkg/weatherpb"
+	"go-a-b-microservices/pkg/zipcode"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+// ServiceBGRPCClient calls Service B's WeatherService over gRPC.
+type ServiceBGRPCClient struct {
+	conn    *grpc.ClientConn
+	client  weatherpb.WeatherServiceClient
+	signer  *hmacauth.Signer
+	timeout calltimeout.Timeout
+	logger  logger.Logger
+}
+
+// NewServiceBGRPCClient connects to addr, over TLS when tlsConfig is set.
+// Connections are established lazily on the first call.
+func NewServiceBGRPCClient(addr string, tlsConfig *tls.Config, signer *hmacauth.Signer, log logger.Logger) (*ServiceBGRPCClient, error) {
+	transportCredentials := insecure.NewCredentials()
+	if tlsConfig != nil {
+		transportCredentials = credentials.NewTLS(tlsConfig)
+	}
+
+	conn, err := grpc.NewClient(addr,
+		grpc.WithTransportCredentials(transportCredentials),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ServiceBGRPCClient{
+		conn:   conn,
+		client: weatherpb.NewWeatherServiceClient(conn),
+		signer: signer,
+		logger: log,
+	}, nil
+}
+
+func (c *ServiceBGRPCClient) Close() error {
+	return c.conn.Close()
+}
+
+func (c *ServiceBGRPCClient) GetWeatherByZipCode(ctx context.Context, request *zipcode.ZipCodeRequest) (*WeatherResponse, error) {
+	tracer := otel.Tracer("service-a")
+	ctx, span := tracer.Start(ctx, "repository.GetWeatherByZipCode")
+	defer span.End()
+
+	message := weatherpb.NewWeatherRequest(request)
+	callCtx, cancel := c.outgoingContext(ctx, weatherpb.WeatherService_GetWeather_FullMethodName, message)
+	defer cancel()
+
+	response, err := c.client.GetWeather(callCtx, message)
+	if err != nil {
+		c.logger.Error("Service B call failed: %v", err)
+		return nil, weatherpb.AppError(err)
+	}
+
+	return response.WeatherResponse(), nil
+}
+
+func (c *ServiceBGRPCClient) GetForecastByZipCode(ctx context.Context, request *zipcode.ForecastRequest) (*ForecastResponse, error) {
+	tracer := otel.Tracer("service-a")
+	ctx, span := tracer.Start(ctx, "repository.GetForecastByZipCode")
+	defer span.End()
+
+	message := weatherpb.NewForecastRequest(request)
+	callCtx, cancel := c.outgoingContext(ctx, weatherpb.WeatherService_GetForecast_FullMethodName, message)
+	defer cancel()
+
+	response, err := c.client.GetForecast(callCtx, message)
+	if err != nil {
+		c.logger.Error("Service B call failed: %v", err)
+		return nil, weatherpb.AppError(err)
+	}
+
+	return response.ForecastResponse(), nil
+}
+
+func (c *ServiceBGRPCClient) GetHistoryByZipCode(ctx context.Context, request *zipcode.HistoryRequest) (*HistoryResponse, error) {
+	tracer := otel.Tracer("service-a")
+	ctx, span := tracer.Start(ctx, "repository.GetHistoryByZipCode")
+	defer span.End()
+
+	message := weatherpb.NewHistoryRequest(request)
+	callCtx, cancel := c.outgoingContext(ctx, weatherpb.WeatherService_GetHistory_FullMethodName, message)
+	defer cancel()
+
+	response, err := c.client.GetHistory(callCtx, message)
+	if err != nil {
+		c.logger.Error("Service B call failed: %v", err)
+		return nil, weatherpb.AppError(err)
+	}
+
+	return response.HistoryResponse(), nil
+}
+
+// GetWeatherBatch requests the weather for several ZIP codes in one call and
+// passes each result to onResult as it arrives, with the index of its request.
+func (c *ServiceBGRPCClient) GetWeatherBatch(ctx context.Context, requests []zipcode.ZipCodeRequest, onResult func(index int, response *WeatherResponse, err error)) error {
+	tracer := otel.Tracer("service-a")
+	ctx, span := tracer.Start(ctx, "repository.GetWeatherBatch")
+	defer span.End()
+
+	message := &weatherpb.WeatherBatchRequest{}
+	for i := range requests {
+		message.Requests = append(message.Requests, weatherpb.NewWeatherRequest(&requests[i]))
+	}
+
+	callCtx, cancel := c.outgoingContext(ctx, weatherpb.WeatherService_GetWeatherBatch_FullMethodName, message)
+	defer cancel()
+
+	stream, err := c.client.GetWeatherBatch(callCtx, message)
+	if err != nil {
+		return weatherpb.AppError(err)
+	}
+
+	for {
+		result, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			c.logger.Error("Service B batch failed: %v", err)
+			return weatherpb.AppError(err)
+		}
+
+		if resultErr := result.GetError(); resultErr != nil {
+			onResult(int(result.GetIndex()), nil, resultErr.AppError())
+		} else {
+			onResult(int(result.GetIndex()), result.GetWeather().WeatherResponse(), nil)
+		}
+	}
+}
+
+// SetTimeout bounds each call, including a whole batch stream, also while the
+// client is in use.
+func (c *ServiceBGRPCClient) SetTimeout(timeout time.Duration) {
+	c.timeout.Set(timeout)
+}
+
+// outgoingContext adds the language and the request signature to the call
+// metadata, and bounds the call by the timeout.
+func (c *ServiceBGRPCClient) outgoingContext(ctx context.Context, method string, message proto.Message) (context.Context, context.CancelFunc) {
+	pairs := []string{weatherpb.MetadataLanguage, string(i18n.FromContext(ctx))}
+
+	if c.signer != nil {
+		body, err := proto.MarshalOptions{Deterministic: true}.Marshal(message)
+		if err != nil {
+			c.logger.Error("Failed to marshal request for signing: %v", err)
+		} else {
+			signature := c.signer.SignValues("POST", method, body)
+			pairs = append(pairs,
+				strings.ToLower(hmacauth.HeaderKeyID), signature.KeyID,
+				strings.ToLower(hmacauth.HeaderTimestamp), signature.Timestamp,
+				strings.ToLower(hmacauth.HeaderNonce), signature.Nonce,
+				strings.ToLower(hmacauth.HeaderSignature), signature.Signature,
+			)
+		}
+	}
+
+	ctx, cancel := c.timeout.Context(ctx)
+	return metadata.AppendToOutgoingContext(ctx, pairs...), cancel
+}
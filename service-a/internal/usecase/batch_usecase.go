@@ -0,0 +1,67 @@
+package usecase
+
+import (
+	"context"
+
+	"go-a-b-microservices/pkg/apperror"
+	"go-a-b-microservices/pkg/zipcode"
+	"go-a-b-microservices/service-a/internal/repository"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// WeatherResult is the outcome of one request of a batch.
+type WeatherResult struct {
+	Weather *repository.WeatherResponse
+	Err     error
+}
+
+// ProcessZipCodeBatch validates the requests and sends the valid ones to
+// Service B in one batch call per zipcode.MaxBatchSize requests. Results are
+// returned in request order. If a batch call fails, each of its requests gets
+// that error.
+func (uc *ZipCodeUseCase) ProcessZipCodeBatch(ctx context.Context, requests []zipcode.ZipCodeRequest) []WeatherResult {
+	tracer := otel.Tracer("service-a")
+	ctx, span := tracer.Start(ctx, "usecase.ProcessZipCodeBatch")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int("batch.size", len(requests)))
+
+	results := make([]WeatherResult, len(requests))
+	var valid []zipcode.ZipCodeRequest
+	var indexes []int
+	for i := range requests {
+		if err := requests[i].Validate(); err != nil {
+			results[i].Err = err
+			continue
+		}
+		valid = append(valid, requests[i])
+		indexes = append(indexes, i)
+	}
+
+	for start := 0; start < len(valid); start += zipcode.MaxBatchSize {
+		end := min(start+zipcode.MaxBatchSize, len(valid))
+		batch, batchIndexes := valid[start:end], indexes[start:end]
+
+		// Requests Service B does not answer are reported as internal errors.
+		for _, index := range batchIndexes {
+			results[index].Err = apperror.ErrInternal
+		}
+
+		err := uc.serviceBClient.GetWeatherBatch(ctx, batch, func(index int, response *repository.WeatherResponse, err error) {
+			if index < 0 || index >= len(batchIndexes) {
+				return
+			}
+			results[batchIndexes[index]] = WeatherResult{Weather: response, Err: err}
+		})
+		if err != nil {
+			uc.logger.Error("Error getting weather batch: %v", err)
+			for _, index := range batchIndexes {
+				results[index] = WeatherResult{Err: err}
+			}
+		}
+	}
+
+	return results
+}
@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go-a-b-microservices/pkg/apperror"
+	"go-a-b-microservices/pkg/zipcode"
+	"go-a-b-microservices/service-a/internal/repository"
+)
+
+func TestZipCodeUseCase_ProcessZipCodeBatch(t *testing.T) {
+	requests := []zipcode.ZipCodeRequest{
+		{CEP: "13484000"},
+		{CEP: "invalid"},
+		{CEP: "99999999"},
+		{CEP: "01001000", Units: "K"},
+	}
+
+	tests := []struct {
+		name         string
+		batchErr     error
+		expectedErrs []error
+	}{
+		{
+			name:         "sends valid requests in one call",
+			expectedErrs: []error{nil, apperror.ErrZipCodeInvalid, apperror.ErrZipCodeNotFound, nil},
+		},
+		{
+			name:         "batch failure fails every sent request",
+			batchErr:     apperror.ErrWeatherUnavailable,
+			expectedErrs: []error{apperror.ErrWeatherUnavailable, apperror.ErrZipCodeInvalid, apperror.ErrWeatherUnavailable, apperror.ErrWeatherUnavailable},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			mockClient := &MockServiceBClient{
+				GetWeatherBatchFunc: func(ctx context.Context, batch []zipcode.ZipCodeRequest, onResult func(int, *repository.WeatherResponse, error)) error {
+					calls++
+					if tt.batchErr != nil {
+						return tt.batchErr
+					}
+					if len(batch) != 3 {
+						t.Fatalf("Expected 3 requests in the batch, got %d", len(batch))
+					}
+					for i := len(batch) - 1; i >= 0; i-- {
+						if batch[i].CEP == "99999999" {
+							onResult(i, nil, apperror.ErrZipCodeNotFound)
+						} else {
+							onResult(i, &repository.WeatherResponse{City: batch[i].CEP}, nil)
+						}
+					}
+					return nil
+				},
+			}
+
+			useCase := NewZipCodeUseCase(mockClient, &MockLogger{})
+			results := useCase.ProcessZipCodeBatch(context.Background(), requests)
+
+			if calls != 1 {
+				t.Errorf("Expected 1 batch call, got %d", calls)
+			}
+			for i, result := range results {
+				if !errors.Is(result.Err, tt.expectedErrs[i]) {
+					t.Errorf("Result %d: expected error %v, got %v", i, tt.expectedErrs[i], result.Err)
+				}
+				if result.Err == nil && result.Weather.City != requests[i].CEP {
+					t.Errorf("Result %d: expected weather for %s, got %+v", i, requests[i].CEP, result.Weather)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package usecase
+
+import (
+	"context"
+
+	"go-a-b-microservices/pkg/zipcode"
+	"go-a-b-microservices/service-a/internal/repository"
+
+	"go.opentelemetry.io/otel"
+)
+
+func (uc *ZipCodeUseCase) ProcessForecast(ctx context.Context, request *zipcode.ForecastRequest) (*repository.ForecastResponse, error) {
+	tracer := otel.Tracer("service-a")
+	ctx, span := tracer.Start(ctx, "usecase.ProcessForecast")
+	defer span.End()
+
+	if err := request.Validate(); err != nil {
+		uc.logger.Error("Invalid forecast request: %v", err)
+		return nil, err
+	}
+
+	response, err := uc.serviceBClient.GetForecastByZipCode(ctx, request)
+	if err != nil {
+		uc.logger.Error("Error getting forecast: %v", err)
+		return nil, err
+	}
+
+	return response, nil
+}
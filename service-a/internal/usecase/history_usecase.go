@@ -0,0 +1,29 @@
+package usecase
+
+import (
+	"context"
+
+	"go-a-b-microservices/pkg/zipcode"
+	"go-a-b-microservices/service-a/internal/repository"
+
+	"go.opentelemetry.io/otel"
+)
+
+func (uc *ZipCodeUseCase) ProcessHistory(ctx context.Context, request *zipcode.HistoryRequest) (*repository.HistoryResponse, error) {
+	tracer := otel.Tracer("service-a")
+	ctx, span := tracer.Start(ctx, "usecase.ProcessHistory")
+	defer span.End()
+
+	if err := request.Validate(); err != nil {
+		uc.logger.Error("Invalid history request: %v", err)
+		return nil, err
+	}
+
+	response, err := uc.serviceBClient.GetHistoryByZipCode(ctx, request)
+	if err != nil {
+		uc.logger.Error("Error getting history: %v", err)
+		return nil, err
+	}
+
+	return response, nil
+}
@@ -32,7 +32,7 @@ func (uc *ZipCodeUseCase) ProcessZipCode(ctx context.Context, request *zipcode.Z
 		return nil, err
 	}
 
-	response, err := uc.serviceBClient.GetWeatherByZipCode(ctx, request.CEP)
+	response, err := uc.serviceBClient.GetWeatherByZipCode(ctx, request)
 	if err != nil {
 		uc.logger.Error("Error getting weather information: %v", err)
 		return nil, err
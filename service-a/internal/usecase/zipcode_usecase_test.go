@@ -11,11 +11,26 @@ import (
 )
 
 type MockServiceBClient struct {
-	GetWeatherByZipCodeFunc func(ctx context.Context, zipCode string) (*repository.WeatherResponse, error)
+	GetWeatherByZipCodeFunc  func(ctx context.Context, request *zipcode.ZipCodeRequest) (*repository.WeatherResponse, error)
+	GetForecastByZipCodeFunc func(ctx context.Context, request *zipcode.ForecastRequest) (*repository.ForecastResponse, error)
+	GetHistoryByZipCodeFunc  func(ctx context.Context, request *zipcode.HistoryRequest) (*repository.HistoryResponse, error)
+	GetWeatherBatchFunc      func(ctx context.Context, requests []zipcode.ZipCodeRequest, onResult func(index int, response *repository.WeatherResponse, err error)) error
 }
 
-func (m *MockServiceBClient) GetWeatherByZipCode(ctx context.Context, zipCode string) (*repository.WeatherResponse, error) {
-	return m.GetWeatherByZipCodeFunc(ctx, zipCode)
+func (m *MockServiceBClient) GetWeatherByZipCode(ctx context.Context, request *zipcode.ZipCodeRequest) (*repository.WeatherResponse, error) {
+	return m.GetWeatherByZipCodeFunc(ctx, request)
+}
+
+func (m *MockServiceBClient) GetForecastByZipCode(ctx context.Context, request *zipcode.ForecastRequest) (*repository.ForecastResponse, error) {
+	return m.GetForecastByZipCodeFunc(ctx, request)
+}
+
+func (m *MockServiceBClient) GetHistoryByZipCode(ctx context.Context, request *zipcode.HistoryRequest) (*repository.HistoryResponse, error) {
+	return m.GetHistoryByZipCodeFunc(ctx, request)
+}
+
+func (m *MockServiceBClient) GetWeatherBatch(ctx context.Context, requests []zipcode.ZipCodeRequest, onResult func(index int, response *repository.WeatherResponse, err error)) error {
+	return m.GetWeatherBatchFunc(ctx, requests, onResult)
 }
 
 type MockLogger struct{}
@@ -47,9 +62,9 @@ func TestZipCodeUseCase_ProcessZipCode(t *testing.T) {
 			zipCode: validZipCode,
 			mockResponse: &repository.WeatherResponse{
 				City:  validCity,
-				TempC: tempC,
-				TempF: tempF,
-				TempK: tempK,
+				TempC: &tempC,
+				TempF: &tempF,
+				TempK: &tempK,
 			},
 			mockError:     nil,
 			expectedCity:  validCity,
@@ -84,7 +99,7 @@ func TestZipCodeUseCase_ProcessZipCode(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockClient := &MockServiceBClient{
-				GetWeatherByZipCodeFunc: func(ctx context.Context, zipCode string) (*repository.WeatherResponse, error) {
+				GetWeatherByZipCodeFunc: func(ctx context.Context, request *zipcode.ZipCodeRequest) (*repository.WeatherResponse, error) {
 					return tt.mockResponse, tt.mockError
 				},
 			}
@@ -123,16 +138,16 @@ func TestZipCodeUseCase_ProcessZipCode(t *testing.T) {
 				t.Errorf("Expected city %s, got %s", tt.expectedCity, response.City)
 			}
 
-			if response.TempC != tt.expectedTempC {
-				t.Errorf("Expected temperature in Celsius %f, got %f", tt.expectedTempC, response.TempC)
+			if *response.TempC != tt.expectedTempC {
+				t.Errorf("Expected temperature in Celsius %f, got %f", tt.expectedTempC, *response.TempC)
 			}
 
-			if response.TempF != tt.expectedTempF {
-				t.Errorf("Expected temperature in Fahrenheit %f, got %f", tt.expectedTempF, response.TempF)
+			if *response.TempF != tt.expectedTempF {
+				t.Errorf("Expected temperature in Fahrenheit %f, got %f", tt.expectedTempF, *response.TempF)
 			}
 
-			if response.TempK != tt.expectedTempK {
-				t.Errorf("Expected temperature in Kelvin %f, got %f", tt.expectedTempK, response.TempK)
+			if *response.TempK != tt.expectedTempK {
+				t.Errorf("Expected temperature in Kelvin %f, got %f", tt.expectedTempK, *response.TempK)
 			}
 		})
 	}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go-a-b-microservices/service-b/internal/adapter/sqlite"
+	"go-a-b-microservices/service-b/internal/repository"
+)
+
+// record is a location as export writes it.
+type record struct {
+	CEP       string `json:"cep"`
+	City      string `json:"city"`
+	Source    string `json:"source,omitempty"`
+	UpdatedAt string `json:"updated_at,omitempty"`
+}
+
+// runExport writes every stored location, as CSV with a header row or as a
+// JSON array, to FILE or to standard output. The format defaults to the
+// extension of FILE, or CSV.
+func runExport(ctx context.Context, store *sqlite.LocationStore, args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	format := flags.String("format", "", "csv or json")
+	flags.Parse(args)
+
+	var out io.Writer = os.Stdout
+	if flags.NArg() > 0 {
+		path := flags.Arg(0)
+		if *format == "" {
+			*format = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+		}
+		file, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		out = file
+	}
+	writer := bufio.NewWriter(out)
+
+	var err error
+	switch *format {
+	case "csv", "":
+		err = exportCSV(ctx, store, writer)
+	case "json":
+		err = exportJSON(ctx, store, writer)
+	default:
+		return fmt.Errorf("unsupported format %q, expected csv or json", *format)
+	}
+	if err != nil {
+		return err
+	}
+	return writer.Flush()
+}
+
+func exportCSV(ctx context.Context, store *sqlite.LocationStore, w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"cep", "city", "source", "updated_at"}); err != nil {
+		return err
+	}
+	err := store.EachLocation(ctx, func(location repository.StoredLocation) error {
+		return writer.Write([]string{location.CEP, location.City, location.Source, location.UpdatedAt.UTC().Format(time.RFC3339)})
+	})
+	if err != nil {
+		return err
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// exportJSON writes one location per line, so the array can be written as it
+// is read.
+func exportJSON(ctx context.Context, store *sqlite.LocationStore, w io.Writer) error {
+	separator := "[\n  "
+	err := store.EachLocation(ctx, func(location repository.StoredLocation) error {
+		data, err := json.Marshal(record{
+			CEP:       location.CEP,
+			City:      location.City,
+			Source:    location.Source,
+			UpdatedAt: location.UpdatedAt.UTC().Format(time.RFC3339),
+		})
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, separator); err != nil {
+			return err
+		}
+		separator = ",\n  "
+		_, err = w.Write(data)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if separator == "[\n  " {
+		_, err = io.WriteString(w, "[]\n")
+	} else {
+		_, err = io.WriteString(w, "\n]\n")
+	}
+	return err
+}
@@ -0,0 +1,124 @@
+// Command cepdb exports and summarizes the CEP directory Service B keeps in
+// SQLite. Files are imported with cepimport.
+//
+// Usage:
+//
+//	cepdb [-config file] [-db path] export [-format csv|json] [FILE]
+//	cepdb [-config file] [-db path] stats [-refresh-after 720h]
+//
+// The database defaults to the one Service B uses: CEP_STORE_PATH, or
+// cep_store.path in the config file named by -config or CONFIG_FILE, or
+// cep.db.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+	"time"
+
+	"go-a-b-microservices/pkg/config"
+	"go-a-b-microservices/service-b/internal/adapter/sqlite"
+)
+
+const usage = `Usage:
+  cepdb [-config file] [-db path] export [-format csv|json] [FILE]
+  cepdb [-config file] [-db path] stats [-refresh-after 720h]
+`
+
+func main() {
+	flags := flag.NewFlagSet("cepdb", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), usage)
+		flags.PrintDefaults()
+	}
+	configFile := flags.String("config", "", "Service B config file to read cep_store settings from (env CONFIG_FILE)")
+	path := flags.String("db", "", "path to the SQLite database (default CEP_STORE_PATH or cep_store.path)")
+	flags.Parse(os.Args[1:])
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	directory, err := config.LoadCEPDirectory(*configFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cepdb: %v\n", err)
+		os.Exit(1)
+	}
+	if *path == "" {
+		*path = directory.CEPStorePath
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	store, err := sqlite.NewLocationStore(ctx, *path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cepdb: %s: %v\n", *path, err)
+		os.Exit(1)
+	}
+	defer store.Close()
+
+	command, args := flags.Arg(0), flags.Args()[1:]
+	switch command {
+	case "import":
+		err = errors.New("files are imported with cepimport, which can resume")
+	case "export":
+		err = runExport(ctx, store, args)
+	case "stats":
+		err = runStats(ctx, store, directory.CEPStoreRefresh, args)
+	default:
+		fmt.Fprintf(os.Stderr, "cepdb: unknown command %q\n", command)
+		flags.Usage()
+		store.Close()
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cepdb %s: %v\n", command, err)
+		store.Close()
+		os.Exit(1)
+	}
+}
+
+func runStats(ctx context.Context, store *sqlite.LocationStore, defaultRefreshAfter time.Duration, args []string) error {
+	flags := flag.NewFlagSet("stats", flag.ExitOnError)
+	refreshAfter := flags.Duration("refresh-after", defaultRefreshAfter, "age at which Service B refreshes a location (CEP_STORE_REFRESH_AFTER)")
+	flags.Parse(args)
+
+	var staleBefore time.Time
+	if *refreshAfter > 0 {
+		staleBefore = time.Now().Add(-*refreshAfter)
+	}
+
+	stats, err := store.Stats(ctx, staleBefore)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("schema version: %d\n", stats.SchemaVersion)
+	fmt.Printf("locations:      %d\n", stats.Total)
+
+	sources := make([]string, 0, len(stats.BySource))
+	for source := range stats.BySource {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+	for _, source := range sources {
+		fmt.Printf("  %-13s %d\n", source+":", stats.BySource[source])
+	}
+
+	if stats.Total > 0 {
+		if *refreshAfter > 0 {
+			fmt.Printf("stale:          %d (older than %s)\n", stats.Stale, *refreshAfter)
+		}
+		fmt.Printf("oldest:         %s\n", stats.Oldest.Format(time.RFC3339))
+		fmt.Printf("newest:         %s\n", stats.Newest.Format(time.RFC3339))
+	}
+	return nil
+}
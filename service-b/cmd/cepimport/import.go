@@ -0,0 +1,277 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-a-b-microservices/pkg/zipcode"
+	"go-a-b-microservices/service-b/internal/adapter/cepfile"
+	"go-a-b-microservices/service-b/internal/adapter/sqlite"
+	"go-a-b-microservices/service-b/internal/repository"
+)
+
+var errInterrupted = errors.New("interrupted")
+
+// importer streams one file into the store. Locations are saved in batches
+// together with the number of records read, and rejects are flushed to the
+// report before each batch is committed, so after an interruption the import
+// resumes after the last committed record and no reject goes unreported.
+type importer struct {
+	store         *sqlite.LocationStore
+	path          string
+	format        string
+	opts          cepfile.Options
+	rejectsPath   string
+	batchSize     int
+	progressEvery time.Duration
+	restart       bool
+
+	progress sqlite.ImportProgress
+	batch    []repository.StoredLocation
+	rejects  *os.File
+	report   *csv.Writer
+	read     countingReader
+	started  time.Time
+	resumed  int64
+	reported time.Time
+}
+
+func (imp *importer) run(ctx context.Context) error {
+	source, err := filepath.Abs(imp.path)
+	if err != nil {
+		return err
+	}
+	if imp.format == "" {
+		if imp.format, err = cepfile.FormatOf(imp.path); err != nil {
+			return err
+		}
+	}
+
+	file, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	resume, err := imp.start(ctx, source, info)
+	if err != nil || imp.progress.Done {
+		return err
+	}
+
+	if err := imp.openRejects(resume); err != nil {
+		return err
+	}
+	defer imp.rejects.Close()
+
+	imp.read.r = file
+	input, err := cepfile.Decompress(&imp.read, imp.path)
+	if err != nil {
+		return fmt.Errorf("%s: %w", imp.path, err)
+	}
+	reader, err := cepfile.NewReader(input, imp.format, imp.opts)
+	if err != nil {
+		return fmt.Errorf("%s: %w", imp.path, err)
+	}
+	if err := imp.skip(reader); err != nil {
+		return err
+	}
+
+	imp.batch = make([]repository.StoredLocation, 0, imp.batchSize)
+	imp.started, imp.reported = time.Now(), time.Now()
+	imp.resumed = imp.progress.Records
+	now := time.Now()
+	// Batches are committed even once the import is interrupted, which is
+	// only noticed between records, so a signal never discards work read.
+	saveCtx := context.WithoutCancel(ctx)
+	for {
+		if ctx.Err() != nil {
+			if err := imp.commit(saveCtx); err != nil {
+				return err
+			}
+			return errInterrupted
+		}
+
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var recordErr *cepfile.RecordError
+		switch {
+		case errors.As(err, &recordErr):
+			err = imp.reject(recordErr.Number, "", "", recordErr.Err.Error())
+		case err != nil:
+			return fmt.Errorf("%s: after record %d: %w", imp.path, imp.progress.Records, err)
+		default:
+			err = imp.add(record, now)
+		}
+		if err != nil {
+			return err
+		}
+
+		imp.progress.Records++
+		if (imp.progress.Records-imp.resumed)%int64(imp.batchSize) == 0 {
+			if err := imp.commit(saveCtx); err != nil {
+				return err
+			}
+		}
+	}
+
+	imp.progress.Done = true
+	if err := imp.commit(saveCtx); err != nil {
+		return err
+	}
+	fmt.Printf("imported %d locations from %d records, rejected %d, in %s\n",
+		imp.progress.Imported, imp.progress.Records, imp.progress.Rejected, time.Since(imp.started).Round(time.Millisecond))
+	if imp.progress.Rejected > 0 {
+		fmt.Printf("rejected rows are listed in %s\n", imp.rejectsPath)
+	}
+	return nil
+}
+
+// start loads the progress of an earlier import of source and reports whether
+// to resume it.
+func (imp *importer) start(ctx context.Context, source string, info os.FileInfo) (bool, error) {
+	fresh := sqlite.ImportProgress{Source: source, Size: info.Size(), ModTime: info.ModTime(), UpdatedAt: time.Now()}
+
+	progress, ok, err := imp.store.ImportProgress(ctx, source)
+	if err != nil {
+		return false, err
+	}
+	switch {
+	case !ok || imp.restart:
+		// Recording the fresh start also clears the progress of an earlier
+		// import, so it cannot be resumed by mistake.
+		imp.progress = fresh
+		return false, imp.store.SaveImportBatch(ctx, nil, fresh)
+	case progress.Size != fresh.Size || !progress.ModTime.Equal(fresh.ModTime):
+		return false, fmt.Errorf("%s changed since it was imported on %s; use -restart to import it again",
+			imp.path, progress.UpdatedAt.Format(time.RFC3339))
+	case progress.Done:
+		imp.progress = progress
+		fmt.Printf("%s was already imported on %s: %d locations, %d rejected; use -restart to import it again\n",
+			imp.path, progress.UpdatedAt.Format(time.RFC3339), progress.Imported, progress.Rejected)
+		return false, nil
+	}
+
+	imp.progress = progress
+	fmt.Fprintf(os.Stderr, "cepimport: resuming %s after record %d\n", imp.path, progress.Records)
+	return true, nil
+}
+
+func (imp *importer) openRejects(resume bool) error {
+	if imp.rejectsPath == "" {
+		imp.rejectsPath = imp.path + ".rejects.csv"
+	}
+	mode := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if resume {
+		mode = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+	file, err := os.OpenFile(imp.rejectsPath, mode, 0o644)
+	if err != nil {
+		return err
+	}
+	imp.rejects = file
+	imp.report = csv.NewWriter(bufio.NewWriter(file))
+
+	if info, err := file.Stat(); err == nil && info.Size() == 0 {
+		return imp.report.Write([]string{"record", "cep", "city", "reason"})
+	}
+	return nil
+}
+
+// skip reads past the records a resumed import has already committed.
+func (imp *importer) skip(reader *cepfile.Reader) error {
+	var recordErr *cepfile.RecordError
+	for n := int64(0); n < imp.progress.Records; n++ {
+		_, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return fmt.Errorf("%s has fewer records than the %d already imported; use -restart to import it again", imp.path, imp.progress.Records)
+		}
+		if err != nil && !errors.As(err, &recordErr) {
+			return fmt.Errorf("%s: %w", imp.path, err)
+		}
+	}
+	return nil
+}
+
+// add validates a record and queues its location for the next batch.
+func (imp *importer) add(record cepfile.Record, now time.Time) error {
+	cep, err := zipcode.ParseCEP(record.CEP)
+	if err != nil {
+		return imp.reject(record.Number, record.CEP, record.City, err.Error())
+	}
+	city := strings.TrimSpace(record.City)
+	if city == "" {
+		return imp.reject(record.Number, record.CEP, record.City, "city is required")
+	}
+
+	imp.batch = append(imp.batch, repository.StoredLocation{CEP: cep, City: city, Source: repository.SourceImport, UpdatedAt: now})
+	return nil
+}
+
+func (imp *importer) reject(number int64, cep, city, reason string) error {
+	imp.progress.Rejected++
+	return imp.report.Write([]string{strconv.FormatInt(number, 10), cep, city, reason})
+}
+
+// commit saves the queued locations and the progress made so far. The
+// records and rejects counted since the last commit are only recorded here.
+func (imp *importer) commit(ctx context.Context) error {
+	imp.report.Flush()
+	if err := imp.report.Error(); err != nil {
+		return fmt.Errorf("%s: %w", imp.rejectsPath, err)
+	}
+	if err := imp.rejects.Sync(); err != nil {
+		return fmt.Errorf("%s: %w", imp.rejectsPath, err)
+	}
+
+	progress := imp.progress
+	progress.Imported += int64(len(imp.batch))
+	progress.UpdatedAt = time.Now()
+	if err := imp.store.SaveImportBatch(ctx, imp.batch, progress); err != nil {
+		return err
+	}
+	imp.progress = progress
+	imp.batch = imp.batch[:0]
+
+	if imp.progressEvery > 0 && time.Since(imp.reported) >= imp.progressEvery {
+		imp.reported = time.Now()
+		imp.printProgress()
+	}
+	return nil
+}
+
+func (imp *importer) printProgress() {
+	line := fmt.Sprintf("cepimport: %d records", imp.progress.Records)
+	if imp.progress.Size > 0 {
+		line += fmt.Sprintf(" (%.1f%% of the file)", 100*float64(imp.read.n)/float64(imp.progress.Size))
+	}
+	rate := float64(imp.progress.Records-imp.resumed) / time.Since(imp.started).Seconds()
+	fmt.Fprintf(os.Stderr, "%s, %d imported, %d rejected, %.0f records/s\n", line, imp.progress.Imported, imp.progress.Rejected, rate)
+}
+
+// countingReader counts the bytes read from the file, compressed or not, to
+// tell how far through it the import is.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
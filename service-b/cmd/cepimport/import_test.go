@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go-a-b-microservices/service-b/internal/adapter/sqlite"
+)
+
+const dump = `cep,city
+01001000,São Paulo
+123,Nowhere
+13480000,Limeira
+20040002,Rio de Janeiro
+999,Elsewhere
+30130000,Belo Horizonte
+`
+
+// interruptAt is a context interrupted once stop reports true, as a signal
+// arriving between two records would.
+type interruptAt struct {
+	context.Context
+	stop func() bool
+}
+
+func (c interruptAt) Err() error {
+	if c.stop() {
+		return context.Canceled
+	}
+	return c.Context.Err()
+}
+
+func newImporter(t *testing.T, dir string) *importer {
+	t.Helper()
+	store, err := sqlite.NewLocationStore(context.Background(), filepath.Join(dir, "cep.db"))
+	if err != nil {
+		t.Fatalf("NewLocationStore() error = %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	return &importer{
+		store:       store,
+		path:        filepath.Join(dir, "ceps.csv"),
+		rejectsPath: filepath.Join(dir, "rejects.csv"),
+		batchSize:   2,
+	}
+}
+
+func writeDump(t *testing.T, dir, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, "ceps.csv"), []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readLines(t *testing.T, path string) []string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Split(strings.TrimSpace(string(data)), "\n")
+}
+
+func TestImporter_InterruptAndResume(t *testing.T) {
+	dir := t.TempDir()
+	writeDump(t, dir, dump)
+
+	first := newImporter(t, dir)
+	ctx := interruptAt{Context: context.Background(), stop: func() bool { return first.progress.Records >= 3 }}
+	if err := first.run(ctx); err != errInterrupted {
+		t.Fatalf("Expected the import to be interrupted, got %v", err)
+	}
+	if first.progress.Records != 3 || first.progress.Imported != 2 || first.progress.Rejected != 1 {
+		t.Fatalf("Expected the 3 records read to be committed, got %+v", first.progress)
+	}
+	if lines := readLines(t, first.rejectsPath); len(lines) != 2 {
+		t.Fatalf("Expected the header and one reject after the interruption, got %q", lines)
+	}
+
+	second := newImporter(t, dir)
+	if err := second.run(context.Background()); err != nil {
+		t.Fatalf("Expected the import to resume, got %v", err)
+	}
+	if !second.progress.Done || second.progress.Records != 6 || second.progress.Imported != 4 || second.progress.Rejected != 2 {
+		t.Errorf("Unexpected progress after resuming %+v", second.progress)
+	}
+	for _, cep := range []string{"01001000", "13480000", "20040002", "30130000"} {
+		if _, ok, err := second.store.GetLocation(context.Background(), cep); err != nil || !ok {
+			t.Errorf("Expected CEP %s to be imported, got ok %v and error %v", cep, ok, err)
+		}
+	}
+
+	// The resumed import appends to the report instead of starting it over.
+	lines := readLines(t, second.rejectsPath)
+	if len(lines) != 3 || lines[0] != "record,cep,city,reason" || !strings.Contains(lines[1], ",123,") || !strings.Contains(lines[2], ",999,") {
+		t.Errorf("Expected one header and both rejects, got %q", lines)
+	}
+}
+
+func TestImporter_StartOverTruncatesRejects(t *testing.T) {
+	dir := t.TempDir()
+	writeDump(t, dir, dump)
+	if err := os.WriteFile(filepath.Join(dir, "rejects.csv"), []byte("record,cep,city,reason\n7,1,old,left over\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	imp := newImporter(t, dir)
+	if err := imp.run(context.Background()); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	if lines := readLines(t, imp.rejectsPath); len(lines) != 3 || strings.Contains(strings.Join(lines, "\n"), "left over") {
+		t.Errorf("Expected a new import to start a new report, got %q", lines)
+	}
+}
+
+func TestImporter_ChangedFile(t *testing.T) {
+	dir := t.TempDir()
+	writeDump(t, dir, dump)
+
+	if err := newImporter(t, dir).run(context.Background()); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+
+	// A finished import is not repeated.
+	again := newImporter(t, dir)
+	if err := again.run(context.Background()); err != nil || again.progress.Records != 6 {
+		t.Fatalf("Expected the finished import to be reported, got %+v, %v", again.progress, err)
+	}
+
+	writeDump(t, dir, dump+"40010000,Salvador\n")
+	changed := newImporter(t, dir)
+	err := changed.run(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "changed since it was imported") {
+		t.Fatalf("Expected a changed file to be refused, got %v", err)
+	}
+	if _, ok, _ := changed.store.GetLocation(context.Background(), "40010000"); ok {
+		t.Error("Expected nothing to be imported from the changed file")
+	}
+
+	restarted := newImporter(t, dir)
+	restarted.restart = true
+	if err := restarted.run(context.Background()); err != nil {
+		t.Fatalf("Expected -restart to import the changed file, got %v", err)
+	}
+	if restarted.progress.Records != 7 || restarted.progress.Imported != 5 {
+		t.Errorf("Expected the whole file to be imported again, got %+v", restarted.progress)
+	}
+	if _, ok, err := restarted.store.GetLocation(context.Background(), "40010000"); err != nil || !ok {
+		t.Errorf("Expected the new CEP to be imported, got ok %v and error %v", ok, err)
+	}
+}
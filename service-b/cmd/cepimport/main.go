@@ -0,0 +1,110 @@
+// Command cepimport preloads the CEP directory of Service B from a Correios
+// or open-data dump, so ViaCEP is only asked for CEPs the dump does not have.
+//
+// Usage:
+//
+//	cepimport [flags] FILE
+//
+// FILE is CSV, a JSON array or JSON Lines, optionally gzipped, and is read as
+// a stream so dumps of millions of rows are imported in bounded memory. Each
+// row is validated like a request CEP; rejected rows are written to a CSV
+// report. Progress is committed with every batch, so running the same command
+// again after an interruption resumes where the import stopped.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	"unicode/utf8"
+
+	"go-a-b-microservices/pkg/config"
+	"go-a-b-microservices/service-b/internal/adapter/cepfile"
+	"go-a-b-microservices/service-b/internal/adapter/sqlite"
+)
+
+const usage = `Usage:
+  cepimport [flags] FILE
+`
+
+func main() {
+	flags := flag.NewFlagSet("cepimport", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), usage)
+		flags.PrintDefaults()
+	}
+	configFile := flags.String("config", "", "Service B config file to read cep_store settings from (env CONFIG_FILE)")
+	path := flags.String("db", "", "path to the SQLite database (default CEP_STORE_PATH or cep_store.path)")
+	format := flags.String("format", "", "csv or json, by default from the file extension")
+	cepColumn := flags.String("cep-column", "", "column or field holding the CEP, by default one of "+fmt.Sprint(cepfile.CEPFields))
+	cityColumn := flags.String("city-column", "", "column or field holding the city, by default one of "+fmt.Sprint(cepfile.CityFields))
+	delimiter := flags.String("delimiter", "", "CSV field separator, by default detected from the header")
+	rejects := flags.String("rejects", "", "CSV report of rejected rows (default FILE.rejects.csv)")
+	batchSize := flags.Int("batch", 5000, "rows read per committed batch")
+	progressEvery := flags.Duration("progress", 10*time.Second, "how often to report progress, 0 to report only the summary")
+	restart := flags.Bool("restart", false, "import FILE from the start, even if an earlier import finished or the file changed")
+	flags.Parse(os.Args[1:])
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	if *batchSize < 1 {
+		fmt.Fprintln(os.Stderr, "cepimport: -batch must be at least 1")
+		os.Exit(2)
+	}
+
+	opts := cepfile.Options{CEPField: *cepColumn, CityField: *cityColumn}
+	if *delimiter != "" {
+		comma, size := utf8.DecodeRuneInString(*delimiter)
+		if size != len(*delimiter) {
+			fmt.Fprintln(os.Stderr, "cepimport: -delimiter must be a single character")
+			os.Exit(2)
+		}
+		opts.Comma = comma
+	}
+
+	if *path == "" {
+		directory, err := config.LoadCEPDirectory(*configFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "cepimport: %v\n", err)
+			os.Exit(1)
+		}
+		*path = directory.CEPStorePath
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	store, err := sqlite.NewLocationStore(ctx, *path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cepimport: %s: %v\n", *path, err)
+		os.Exit(1)
+	}
+	defer store.Close()
+
+	imp := &importer{
+		store:         store,
+		path:          flags.Arg(0),
+		format:        *format,
+		opts:          opts,
+		rejectsPath:   *rejects,
+		batchSize:     *batchSize,
+		progressEvery: *progressEvery,
+		restart:       *restart,
+	}
+	if err := imp.run(ctx); err != nil {
+		if errors.Is(err, errInterrupted) {
+			fmt.Fprintf(os.Stderr, "cepimport: interrupted after %d records; run the same command to resume\n", imp.progress.Records)
+		} else {
+			fmt.Fprintf(os.Stderr, "cepimport: %v\n", err)
+		}
+		store.Close()
+		os.Exit(1)
+	}
+}
@@ -2,35 +2,76 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
+	"go-a-b-microservices/pkg/cache"
 	"go-a-b-microservices/pkg/config"
+	"go-a-b-microservices/pkg/hmacauth"
+	"go-a-b-microservices/pkg/i18n"
 	"go-a-b-microservices/pkg/logger"
+	"go-a-b-microservices/pkg/openapi"
 	"go-a-b-microservices/pkg/otel"
+	"go-a-b-microservices/pkg/temperature"
+	"go-a-b-microservices/pkg/tlsconfig"
 	"go-a-b-microservices/service-b/internal/adapter/clients"
+	custom_grpc "go-a-b-microservices/service-b/internal/adapter/grpc"
 	custom_http "go-a-b-microservices/service-b/internal/adapter/http"
+	"go-a-b-microservices/service-b/internal/adapter/sqlite"
 	"go-a-b-microservices/service-b/internal/repository"
 	"go-a-b-microservices/service-b/internal/usecase"
 
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
+// configPollInterval is how often the config and secret files are checked for
+// changes.
+const configPollInterval = 2 * time.Second
+
+// shutdownTimeout bounds how long requests in flight are waited for.
+const shutdownTimeout = 10 * time.Second
+
+// budgetSaveInterval is how often the WeatherAPI call count is saved to
+// WEATHER_API_BUDGET_FILE while it changes.
+const budgetSaveInterval = 5 * time.Second
+
 func main() {
+	config.RegisterFlags(flag.CommandLine, &config.ServiceB{})
+	printConfig := flag.Bool("print-config", false, "print the effective configuration, with secrets redacted, and exit")
+	flag.Parse()
+
 	log := logger.NewLogger()
-	log.Info("Starting Service B")
 
-	cfg, err := config.LoadConfig("service-b")
+	cfg, err := config.LoadServiceB(flag.CommandLine)
+	if *printConfig {
+		if err := config.Print(os.Stdout, cfg); err != nil {
+			log.Error("Failed to print configuration: %v", err)
+			os.Exit(1)
+		}
+	}
 	if err != nil {
 		log.Error("Failed to load configuration: %v", err)
 		os.Exit(1)
 	}
+	if *printConfig {
+		return
+	}
+
+	level, _ := logger.ParseLevel(cfg.LogLevel)
+	log.SetLevel(level)
+	log.Info("Starting Service B")
 
 	ctx := context.Background()
-	tp, err := otel.InitTracer(cfg.ServiceName, cfg.ZipkinEndpoint, log)
+	tp, err := otel.InitTracer(cfg.ServiceName, cfg.ZipkinEndpoint.String(), log)
 	if err != nil {
 		log.Error("Failed to initialize tracer: %v", err)
 		os.Exit(1)
@@ -39,32 +80,297 @@ func main() {
 
 	viaCEPClient := clients.NewViaCEPClient(cfg, log)
 	weatherAPIClient := clients.NewWeatherAPIClient(cfg, log)
-	zipCodeRepository := repository.NewZipCodeRepository(viaCEPClient, weatherAPIClient, log)
+
+	budget := clients.NewBudget(cfg.WeatherAPIMonthly, cfg.WeatherAPIMinute, cfg.WeatherDegradeAt)
+	if cfg.WeatherBudgetFile != "" {
+		if err := budget.SetStateFile(cfg.WeatherBudgetFile); err != nil {
+			log.Error("Failed to load WEATHER_API_BUDGET_FILE: %v", err)
+			os.Exit(1)
+		}
+	}
+
+	// Open-Meteo is built even when it is not in the order so a reload can
+	// switch to it.
+	openMeteoClient := clients.NewOpenMeteoClient(cfg, log)
+	providers := map[string]clients.WeatherProvider{
+		"weatherapi": weatherAPIClient,
+		"openmeteo":  openMeteoClient,
+	}
+	settings, err := guardSettings(cfg, providers)
+	if err != nil {
+		log.Error("Invalid WEATHER_PROVIDER_ORDER: %v", err)
+		os.Exit(1)
+	}
+	weatherProvider, err := clients.NewQuotaGuard(weatherAPIClient, budget, settings, log)
+	if err != nil {
+		log.Error("Invalid WEATHER_API_DEGRADE_MODE: %v", err)
+		os.Exit(1)
+	}
+	budgetCtx, stopBudget := context.WithCancel(ctx)
+	budgetDone := make(chan struct{})
+	go func() {
+		defer close(budgetDone)
+		budget.Run(budgetCtx, budgetSaveInterval)
+	}()
+
+	historyCache := repository.NewHistoryCache(cfg.HistoryCacheTTL, cfg.HistoryCacheSize)
+	weatherCache := repository.NewWeatherCache(cfg.WeatherCacheTTL, cfg.WeatherMaxStale, cfg.WeatherCacheSize)
+	zipCodeRepository := repository.NewZipCodeRepository(viaCEPClient, weatherProvider, historyCache, log)
+	zipCodeRepository.SetWeatherCache(weatherCache)
+	if cfg.CEPStore == "sqlite" {
+		locationStore, err := sqlite.NewLocationStore(ctx, cfg.CEPStorePath)
+		if err != nil {
+			log.Error("Failed to open CEP_STORE_PATH: %v", err)
+			os.Exit(1)
+		}
+		defer locationStore.Close()
+		zipCodeRepository.SetLocationStore(locationStore, cfg.CEPStoreRefresh)
+		log.Info("CEP locations are stored in %s", cfg.CEPStorePath)
+	}
+	if cfg.CacheBackend == "redis" {
+		redis := cache.NewRedisBackend(cache.RedisOptions{
+			Addr:     cfg.RedisAddr,
+			Password: cfg.RedisPassword,
+			DB:       cfg.RedisDB,
+			Timeout:  cfg.RedisTimeout,
+		})
+		defer redis.Close()
+		if err := redis.Ping(ctx); err != nil {
+			// Redis coming up later is picked up after REDIS_RETRY_AFTER.
+			log.Error("Redis at %s is unreachable, caching locally until it answers: %v", cfg.RedisAddr, err)
+		}
+		shared := cache.NewFallbackBackend(redis, cache.NewMemoryBackend(cfg.CacheFallbackSize), cfg.RedisRetryAfter, log)
+		zipCodeRepository.SetSharedCache(shared, cfg.CacheNamespace, cfg.CacheLocationTTL)
+		log.Info("Caches are shared through Redis at %s", cfg.RedisAddr)
+	}
 	zipCodeUseCase := usecase.NewZipCodeUseCase(zipCodeRepository, log)
+	zipCodeUseCase.SetHistoryMaxAgeDays(cfg.HistoryMaxAgeDays)
+
+	warmer := repository.NewWeatherWarmer(zipCodeRepository, budget, warmerSettings(cfg), log)
+	if cfg.WarmerStateFile != "" {
+		if err := warmer.SetStateFile(cfg.WarmerStateFile); err != nil {
+			log.Error("Failed to load WEATHER_WARMER_STATE_FILE: %v", err)
+			os.Exit(1)
+		}
+	}
+	zipCodeUseCase.SetDemandRecorder(warmer)
+	warmCtx, stopWarming := context.WithCancel(ctx)
+	warmerDone := make(chan struct{})
+	go func() {
+		defer close(warmerDone)
+		warmer.Run(warmCtx)
+	}()
+
+	rounding, err := temperature.ParseRoundingMode(cfg.TempRounding)
+	if err != nil {
+		log.Error("Invalid TEMPERATURE_ROUNDING: %v", err)
+		os.Exit(1)
+	}
+	zipCodeUseCase.SetTemperatureConverter(temperature.NewConverter(cfg.TempPrecision, rounding))
 	handler := custom_http.NewHandler(zipCodeUseCase, log)
+	handler.SetMaxBodyBytes(int64(cfg.MaxBodyBytes))
 
 	mux := http.NewServeMux()
 
 	handler.RegisterRoutes(mux)
 
-	otelHandler := otelhttp.NewHandler(mux, cfg.ServiceName)
+	defaultLanguage, ok := i18n.Parse(cfg.DefaultLanguage)
+	if !ok {
+		log.Error("Unsupported DEFAULT_LANGUAGE: %s", cfg.DefaultLanguage)
+		os.Exit(1)
+	}
+
+	serviceKeys, err := hmacauth.ParseKeys(cfg.ServiceAuthKeys)
+	if err != nil {
+		log.Error("Invalid SERVICE_AUTH_KEYS: %v", err)
+		os.Exit(1)
+	}
+	verifier, err := hmacauth.NewVerifier(serviceKeys, cfg.ServiceAuthSkew)
+	if err != nil {
+		log.Error("Callers cannot be authenticated, set SERVICE_AUTH_KEYS: %v", err)
+		os.Exit(1)
+	}
+	verifier.SetMaxBodyBytes(int64(cfg.MaxBodyBytes))
+
+	apiDoc, err := custom_http.OpenAPI()
+	if err != nil {
+		log.Error("Failed to build OpenAPI document: %v", err)
+		os.Exit(1)
+	}
+	validator, err := openapi.NewValidator(apiDoc)
+	if err != nil {
+		log.Error("Failed to build request validator: %v", err)
+		os.Exit(1)
+	}
+	validator.SetMaxBodyBytes(int64(cfg.MaxBodyBytes))
+	specHandler, err := openapi.Handler(apiDoc)
+	if err != nil {
+		log.Error("Failed to serve OpenAPI document: %v", err)
+		os.Exit(1)
+	}
+
+	appHandler := hmacauth.Middleware(verifier, log, openapi.Middleware(validator, log, mux))
+	otelHandler := otelhttp.NewHandler(i18n.Middleware(defaultLanguage, appHandler), cfg.ServiceName)
+
+	tlsConfig, err := tlsconfig.NewServerConfig(cfg.TLSCertFile, cfg.TLSKeyFile, cfg.TLSMinVersion, cfg.TLSClientCAFile, log)
+	if err != nil {
+		log.Error("Failed to configure TLS: %v", err)
+		os.Exit(1)
+	}
 
 	server := &http.Server{
-		Addr:    fmt.Sprintf(":%s", cfg.ServiceBPort),
-		Handler: otelHandler,
+		Addr:      fmt.Sprintf(":%d", cfg.Port),
+		Handler:   otelHandler,
+		TLSConfig: tlsConfig,
 	}
 
 	go func() {
-		log.Info("Service B listening on port %s", cfg.ServiceBPort)
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		log.Info("Service B listening on port %d (TLS: %t)", cfg.Port, tlsConfig != nil)
+		if err := tlsconfig.ListenAndServe(server); err != nil && err != http.ErrServerClosed {
 			log.Error("Failed to start server: %v", err)
 			os.Exit(1)
 		}
 	}()
 
+	grpcOptions := []grpc.ServerOption{
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(
+			custom_grpc.LanguageUnaryInterceptor(defaultLanguage),
+			custom_grpc.AuthUnaryInterceptor(verifier, log),
+		),
+		grpc.ChainStreamInterceptor(
+			custom_grpc.LanguageStreamInterceptor(defaultLanguage),
+			custom_grpc.AuthStreamInterceptor(verifier, log),
+		),
+	}
+	if tlsConfig != nil {
+		grpcOptions = append(grpcOptions, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	grpcServer := grpc.NewServer(grpcOptions...)
+	custom_grpc.NewServer(zipCodeUseCase, log).Register(grpcServer)
+
+	go func() {
+		listener, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.GRPCPort))
+		if err != nil {
+			log.Error("Failed to listen for gRPC: %v", err)
+			os.Exit(1)
+		}
+		log.Info("Service B gRPC listening on port %d", cfg.GRPCPort)
+		if err := grpcServer.Serve(listener); err != nil {
+			log.Error("Failed to start gRPC server: %v", err)
+			os.Exit(1)
+		}
+	}()
+
+	adminMux := http.NewServeMux()
+	adminHandler := custom_http.NewAdminHandler(budget)
+	adminHandler.SetWeatherWarmer(warmer)
+	adminHandler.RegisterRoutes(adminMux)
+	// Callers cannot fetch the document without signing, so it is served with
+	// the other operational endpoints.
+	adminMux.Handle("/openapi.json", specHandler)
+	if cfg.OpenAPIDocs {
+		docs := openapi.DocsHandler("Service B", "/openapi.json")
+		adminMux.Handle("/docs", docs)
+		adminMux.Handle("/docs/", docs)
+	}
+	adminServer := &http.Server{
+		Addr:    fmt.Sprintf(":%d", cfg.AdminPort),
+		Handler: adminMux,
+	}
+
+	go func() {
+		log.Info("Service B admin listening on port %d", cfg.AdminPort)
+		if err := adminServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Error("Failed to start admin server: %v", err)
+			os.Exit(1)
+		}
+	}()
+
+	// Settings tagged reload:"true" in pkg/config are applied here on SIGHUP
+	// or when the config file or a secret file changes.
+	reloader := config.NewReloader(cfg, func() (*config.ServiceB, error) {
+		return config.LoadServiceB(flag.CommandLine)
+	}, func(next *config.ServiceB) (func(), error) {
+		// Everything that can fail is checked before anything changes.
+		keys, err := hmacauth.ParseKeys(next.ServiceAuthKeys)
+		if err != nil {
+			return nil, fmt.Errorf("SERVICE_AUTH_KEYS: %w", err)
+		}
+		settings, err := guardSettings(next, providers)
+		if err == nil {
+			err = weatherProvider.CheckSettings(settings)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("weather providers: %w", err)
+		}
+		level, _ := logger.ParseLevel(next.LogLevel)
+
+		return func() {
+			log.SetLevel(level)
+			handler.SetMaxBodyBytes(int64(next.MaxBodyBytes))
+			validator.SetMaxBodyBytes(int64(next.MaxBodyBytes))
+			verifier.SetMaxBodyBytes(int64(next.MaxBodyBytes))
+			budget.SetLimits(next.WeatherAPIMonthly, next.WeatherAPIMinute, next.WeatherDegradeAt)
+			weatherProvider.SetSettings(settings)
+			historyCache.SetTTL(next.HistoryCacheTTL)
+			weatherCache.SetTTLs(next.WeatherCacheTTL, next.WeatherMaxStale)
+			warmer.SetSettings(warmerSettings(next))
+			zipCodeRepository.SetLocationRefreshAfter(next.CEPStoreRefresh)
+			viaCEPClient.SetTimeout(next.ProviderTimeout)
+			weatherAPIClient.SetTimeout(next.ProviderTimeout)
+			weatherAPIClient.SetAPIKey(next.WeatherAPIKey)
+			openMeteoClient.SetTimeout(next.ProviderTimeout)
+			// ParseKeys returned at least one key, all SetKeys checks.
+			_ = verifier.SetKeys(keys)
+		}, nil
+	}, log)
+	watchCtx, stopWatching := context.WithCancel(ctx)
+	defer stopWatching()
+	go reloader.Watch(watchCtx, configPollInterval)
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
 	log.Info("Shutting down Service B")
+	shutdownCtx, cancel := context.WithTimeout(ctx, shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Error("Failed to shut down the server: %v", err)
+	}
+	grpcServer.GracefulStop()
+	// The warmer saves the hot set as it stops.
+	stopWarming()
+	<-warmerDone
+	// Refreshes started by the last requests finish with the provider
+	// timeout.
+	zipCodeRepository.Wait()
+	// The budget saves the call count as it stops.
+	stopBudget()
+	<-budgetDone
+}
+
+// guardSettings builds the settings of the quota guard, asking providers, by
+// name, in WEATHER_PROVIDER_ORDER.
+func guardSettings(cfg *config.ServiceB, providers map[string]clients.WeatherProvider) (clients.GuardSettings, error) {
+	order, err := config.ParseProviderOrder(cfg.ProviderOrder)
+	if err != nil {
+		return clients.GuardSettings{}, err
+	}
+
+	settings := clients.GuardSettings{Mode: cfg.WeatherDegrade, LastKnownMaxAge: cfg.WeatherLastKnown}
+	for _, name := range order {
+		settings.Providers = append(settings.Providers, providers[name])
+	}
+	return settings, nil
+}
+
+func warmerSettings(cfg *config.ServiceB) repository.WarmerSettings {
+	return repository.WarmerSettings{
+		TopN:          cfg.WarmerTopN,
+		Interval:      cfg.WarmerInterval,
+		Jitter:        cfg.WarmerJitter,
+		BudgetPercent: cfg.WarmerBudget,
+	}
 }
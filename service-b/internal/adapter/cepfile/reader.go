@@ -0,0 +1,293 @@
+// Package cepfile reads CEP datasets, such as the Correios and open-data
+// dumps, one record at a time, so files of any size are read in bounded
+// memory.
+package cepfile
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	FormatCSV = "csv"
+	// FormatJSON is a JSON array of objects, or one object per line.
+	FormatJSON = "json"
+)
+
+// CEPFields and CityFields are the column and field names looked for when
+// Options does not name them, compared without regard to case.
+var (
+	CEPFields  = []string{"cep", "zipcode", "zip_code", "postal_code"}
+	CityFields = []string{"city", "cidade", "localidade", "municipio", "município", "nome_municipio", "nome_cidade"}
+)
+
+// Options tell the reader where to find the CEP and the city.
+type Options struct {
+	CEPField  string
+	CityField string
+	// Comma separates CSV fields. When zero it is detected from the header:
+	// a comma, semicolon, tab or vertical bar.
+	Comma rune
+}
+
+// Record is a location as the dataset has it, not yet validated.
+type Record struct {
+	// Number counts the records from 1, leaving out the CSV header.
+	Number int64
+	CEP    string
+	City   string
+}
+
+// RecordError reports a record that could not be decoded. Reading can go on
+// with the next record.
+type RecordError struct {
+	Number int64
+	Err    error
+}
+
+func (e *RecordError) Error() string {
+	return fmt.Sprintf("record %d: %v", e.Number, e.Err)
+}
+
+func (e *RecordError) Unwrap() error {
+	return e.Err
+}
+
+// Reader reads the records of a dataset.
+type Reader struct {
+	next   func() (Record, error)
+	number int64
+}
+
+// FormatOf returns the format of the file at path from its extension, which
+// may be followed by .gz. JSON Lines files count as JSON.
+func FormatOf(path string) (string, error) {
+	ext := strings.ToLower(filepath.Ext(strings.TrimSuffix(strings.ToLower(path), ".gz")))
+	switch ext {
+	case ".csv", ".txt":
+		return FormatCSV, nil
+	case ".json", ".jsonl", ".ndjson":
+		return FormatJSON, nil
+	}
+	return "", fmt.Errorf("%s: unsupported format, expected .csv or .json", path)
+}
+
+// Decompress returns r, read through gzip when path ends in .gz.
+func Decompress(r io.Reader, path string) (io.Reader, error) {
+	if !strings.HasSuffix(strings.ToLower(path), ".gz") {
+		return r, nil
+	}
+	return gzip.NewReader(r)
+}
+
+// NewReader reads records of the given format from r, reading the CSV header
+// or the opening of the JSON array straight away.
+func NewReader(r io.Reader, format string, opts Options) (*Reader, error) {
+	buffered := bufio.NewReaderSize(r, 64*1024)
+	if bom, _ := buffered.Peek(3); bytes.Equal(bom, []byte("\xef\xbb\xbf")) {
+		buffered.Discard(3)
+	}
+
+	var next func() (Record, error)
+	var err error
+	switch format {
+	case FormatCSV:
+		next, err = csvRecords(buffered, opts)
+	case FormatJSON:
+		next, err = jsonRecords(buffered, opts)
+	default:
+		return nil, fmt.Errorf("unsupported format %q, expected csv or json", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Reader{next: next}, nil
+}
+
+// Read returns the next record, io.EOF after the last one, or a *RecordError
+// for a record that is skipped. Any other error ends the dataset.
+func (r *Reader) Read() (Record, error) {
+	record, err := r.next()
+	if errors.Is(err, io.EOF) {
+		return Record{}, io.EOF
+	}
+	r.number++
+
+	var recordErr *RecordError
+	if errors.As(err, &recordErr) {
+		recordErr.Number = r.number
+		return Record{}, recordErr
+	}
+	if err != nil {
+		return Record{}, err
+	}
+	record.Number = r.number
+	return record, nil
+}
+
+func csvRecords(r *bufio.Reader, opts Options) (func() (Record, error), error) {
+	reader := csv.NewReader(r)
+	reader.Comma = opts.Comma
+	if reader.Comma == 0 {
+		reader.Comma = detectComma(r)
+	}
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("the file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading the header: %w", err)
+	}
+	cepColumn, err := column(header, opts.CEPField, CEPFields)
+	if err != nil {
+		return nil, err
+	}
+	cityColumn, err := column(header, opts.CityField, CityFields)
+	if err != nil {
+		return nil, err
+	}
+
+	return func() (Record, error) {
+		fields, err := reader.Read()
+		if err != nil {
+			return Record{}, err
+		}
+		var record Record
+		if cepColumn < len(fields) {
+			record.CEP = fields[cepColumn]
+		}
+		if cityColumn < len(fields) {
+			record.City = fields[cityColumn]
+		}
+		return record, nil
+	}, nil
+}
+
+// detectComma picks the separator used most in the header line.
+func detectComma(r *bufio.Reader) rune {
+	line, _ := r.Peek(r.Size())
+	if end := bytes.IndexByte(line, '\n'); end >= 0 {
+		line = line[:end]
+	}
+	comma, most := ',', bytes.Count(line, []byte(","))
+	for _, candidate := range []rune{';', '\t', '|'} {
+		if count := bytes.Count(line, []byte(string(candidate))); count > most {
+			comma, most = candidate, count
+		}
+	}
+	return comma
+}
+
+func column(header []string, name string, candidates []string) (int, error) {
+	if name != "" {
+		candidates = []string{name}
+	}
+	for _, candidate := range candidates {
+		for i, field := range header {
+			if strings.EqualFold(strings.TrimSpace(field), candidate) {
+				return i, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("the header has no %s column", strings.Join(candidates, ", "))
+}
+
+// jsonRecords decodes one object at a time, from an array or from a stream
+// of objects such as JSON Lines.
+func jsonRecords(r *bufio.Reader, opts Options) (func() (Record, error), error) {
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+
+	array := false
+	if first, err := firstByte(r); err != nil {
+		return nil, errors.New("the file is empty")
+	} else if first == '[' {
+		decoder.Token()
+		array = true
+	}
+
+	cepFields, cityFields := CEPFields, CityFields
+	if opts.CEPField != "" {
+		cepFields = []string{opts.CEPField}
+	}
+	if opts.CityField != "" {
+		cityFields = []string{opts.CityField}
+	}
+
+	return func() (Record, error) {
+		if array && !decoder.More() {
+			return Record{}, io.EOF
+		}
+		var fields map[string]any
+		if err := decoder.Decode(&fields); err != nil {
+			var typeErr *json.UnmarshalTypeError
+			if errors.As(err, &typeErr) {
+				return Record{}, &RecordError{Err: errors.New("not an object")}
+			}
+			return Record{}, err
+		}
+		return Record{
+			CEP:  cep(field(fields, cepFields)),
+			City: text(field(fields, cityFields)),
+		}, nil
+	}, nil
+}
+
+func firstByte(r *bufio.Reader) (byte, error) {
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		if b != ' ' && b != '\t' && b != '\r' && b != '\n' {
+			return b, r.UnreadByte()
+		}
+	}
+}
+
+func field(fields map[string]any, names []string) any {
+	for _, name := range names {
+		for key, value := range fields {
+			if strings.EqualFold(key, name) {
+				return value
+			}
+		}
+	}
+	return nil
+}
+
+func text(value any) string {
+	switch value := value.(type) {
+	case string:
+		return value
+	case json.Number:
+		return value.String()
+	}
+	return ""
+}
+
+// cep restores the leading zeros of a CEP written as a JSON number, such as
+// 1001000 for 01001-000.
+func cep(value any) string {
+	number, ok := value.(json.Number)
+	if !ok {
+		return text(value)
+	}
+	digits := number.String()
+	if len(digits) < 8 && strings.Trim(digits, "0123456789") == "" {
+		digits = strings.Repeat("0", 8-len(digits)) + digits
+	}
+	return digits
+}
@@ -0,0 +1,166 @@
+package cepfile
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func readAll(t *testing.T, reader *Reader) ([]Record, []int64) {
+	t.Helper()
+	var records []Record
+	var rejected []int64
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return records, rejected
+		}
+		var recordErr *RecordError
+		if errors.As(err, &recordErr) {
+			rejected = append(rejected, recordErr.Number)
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Read() error = %v", err)
+		}
+		records = append(records, record)
+	}
+}
+
+func TestReader(t *testing.T) {
+	tests := []struct {
+		name             string
+		format           string
+		opts             Options
+		input            string
+		expectedRecords  []Record
+		expectedRejected []int64
+	}{
+		{
+			name:   "CSV",
+			format: FormatCSV,
+			input:  "city,cep\nLimeira,13480-000\nSao Paulo,01001000\n",
+			expectedRecords: []Record{
+				{Number: 1, CEP: "13480-000", City: "Limeira"},
+				{Number: 2, CEP: "01001000", City: "Sao Paulo"},
+			},
+		},
+		{
+			name:   "Correios CSV with a byte order mark and semicolons",
+			format: FormatCSV,
+			input:  "\xef\xbb\xbfCEP;LOGRADOURO;BAIRRO;MUNICIPIO;UF\n13480000;Rua \"A\";Centro;Limeira;SP\n01001000;Praça da Sé\n",
+			expectedRecords: []Record{
+				{Number: 1, CEP: "13480000", City: "Limeira"},
+				{Number: 2, CEP: "01001000"},
+			},
+		},
+		{
+			name:   "CSV with named columns",
+			format: FormatCSV,
+			opts:   Options{CEPField: "codigo", CityField: "nome", Comma: '|'},
+			input:  "codigo|nome|city\n13480000|Limeira|ignored\n",
+			expectedRecords: []Record{
+				{Number: 1, CEP: "13480000", City: "Limeira"},
+			},
+		},
+		{
+			name:   "JSON array",
+			format: FormatJSON,
+			input:  ` [{"cep":"13480-000","localidade":"Limeira"}, "not an object", {"CEP":1001000,"Cidade":"São Paulo"}]`,
+			expectedRecords: []Record{
+				{Number: 1, CEP: "13480-000", City: "Limeira"},
+				{Number: 3, CEP: "01001000", City: "São Paulo"},
+			},
+			expectedRejected: []int64{2},
+		},
+		{
+			name:   "JSON Lines",
+			format: FormatJSON,
+			input:  "{\"cep\":\"13480000\",\"city\":\"Limeira\"}\n[]\n{\"cep\":\"01001000\"}\n",
+			expectedRecords: []Record{
+				{Number: 1, CEP: "13480000", City: "Limeira"},
+				{Number: 3, CEP: "01001000"},
+			},
+			expectedRejected: []int64{2},
+		},
+		{
+			name:   "Empty JSON array",
+			format: FormatJSON,
+			input:  "[]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader, err := NewReader(strings.NewReader(tt.input), tt.format, tt.opts)
+			if err != nil {
+				t.Fatalf("NewReader() error = %v", err)
+			}
+			records, rejected := readAll(t, reader)
+			if !reflect.DeepEqual(records, tt.expectedRecords) {
+				t.Errorf("Expected records %+v, got %+v", tt.expectedRecords, records)
+			}
+			if !reflect.DeepEqual(rejected, tt.expectedRejected) {
+				t.Errorf("Expected rejected records %v, got %v", tt.expectedRejected, rejected)
+			}
+		})
+	}
+}
+
+func TestNewReader_Errors(t *testing.T) {
+	tests := []struct {
+		name          string
+		format        string
+		opts          Options
+		input         string
+		expectedError string
+	}{
+		{name: "Empty CSV", format: FormatCSV, input: "", expectedError: "the file is empty"},
+		{name: "No city column", format: FormatCSV, input: "cep,uf\n", expectedError: "no city, cidade"},
+		{name: "Named column missing", format: FormatCSV, opts: Options{CEPField: "codigo"}, input: "cep,city\n", expectedError: "no codigo column"},
+		{name: "Empty JSON", format: FormatJSON, input: " \n", expectedError: "the file is empty"},
+		{name: "Unknown format", format: "xml", expectedError: "unsupported format"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewReader(strings.NewReader(tt.input), tt.format, tt.opts)
+			if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+				t.Errorf("Expected an error containing %q, got %v", tt.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestReader_Truncated(t *testing.T) {
+	reader, err := NewReader(strings.NewReader(`[{"cep":"13480000","city":"Limeira"},{"cep":`), FormatJSON, Options{})
+	if err != nil {
+		t.Fatalf("NewReader() error = %v", err)
+	}
+	if _, err := reader.Read(); err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	_, err = reader.Read()
+	var recordErr *RecordError
+	if err == nil || errors.Is(err, io.EOF) || errors.As(err, &recordErr) {
+		t.Errorf("Expected the truncated file to end the import, got %v", err)
+	}
+}
+
+func TestFormatOf(t *testing.T) {
+	for path, expected := range map[string]string{
+		"ceps.csv":       FormatCSV,
+		"CEPS.CSV.gz":    FormatCSV,
+		"ceps.json":      FormatJSON,
+		"ceps.ndjson.gz": FormatJSON,
+		"ceps.jsonl":     FormatJSON,
+		"ceps.xml":       "",
+	} {
+		format, err := FormatOf(path)
+		if format != expected || (err != nil) != (expected == "") {
+			t.Errorf("FormatOf(%q) = %q, %v, expected %q", path, format, err, expected)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const monthLayout = "2006-01"
+
+// Budget counts calls against the monthly and per-minute limits of a paid
+// API plan. A zero limit is unlimited.
+//
+// The budget is considered exhausted once DegradeAt percent of the monthly
+// limit is used, keeping the rest in reserve, or when the current minute is
+// fully used.
+type Budget struct {
+	mu           sync.Mutex
+	monthlyLimit int
+	minuteLimit  int
+	degradeAt    int
+
+	month       string
+	monthCalls  int
+	minute      time.Time
+	minuteCalls int
+	denied      int
+
+	stateFile string
+	dirty     bool
+	now       func() time.Time
+}
+
+// BudgetUsage is a snapshot of a budget.
+type BudgetUsage struct {
+	Month            string `json:"month"`
+	MonthlyLimit     int    `json:"monthly_limit"`
+	MonthlyUsed      int    `json:"monthly_used"`
+	MonthlyRemaining int    `json:"monthly_remaining"`
+	MinuteLimit      int    `json:"minute_limit"`
+	MinuteUsed       int    `json:"minute_used"`
+	DegradeAtPercent int    `json:"degrade_at_percent"`
+	Degraded         bool   `json:"degraded"`
+	DeniedCalls      int    `json:"denied_calls"`
+}
+
+type budgetState struct {
+	Month string `json:"month"`
+	Calls int    `json:"calls"`
+}
+
+func NewBudget(monthlyLimit, minuteLimit, degradeAt int) *Budget {
+	b := &Budget{now: time.Now}
+	b.SetLimits(monthlyLimit, minuteLimit, degradeAt)
+	return b
+}
+
+// SetLimits changes the limits, as NewBudget sets them, keeping the calls
+// already counted.
+func (b *Budget) SetLimits(monthlyLimit, minuteLimit, degradeAt int) {
+	if degradeAt <= 0 || degradeAt > 100 {
+		degradeAt = 100
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.monthlyLimit = monthlyLimit
+	b.minuteLimit = minuteLimit
+	b.degradeAt = degradeAt
+}
+
+// SetStateFile persists the monthly count to path, so restarts do not reset
+// it, and loads the count already stored there. The count is written by Run.
+func (b *Budget) SetStateFile(path string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.stateFile = path
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var state budgetState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+
+	b.month = state.Month
+	b.monthCalls = state.Calls
+	return nil
+}
+
+// Take records a call if the budget allows one.
+func (b *Budget) Take() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.roll(b.now())
+	if b.exhausted() {
+		b.denied++
+		return false
+	}
+
+	b.monthCalls++
+	b.minuteCalls++
+	b.dirty = true
+	return true
+}
+
+// HasHeadroom reports whether less than percent of both the monthly and the
+// per-minute limits is used, so optional calls, such as cache warming, can be
+// made without eating into what requests need.
+func (b *Budget) HasHeadroom(percent int) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.roll(b.now())
+	if b.minuteLimit > 0 && b.minuteCalls*100 >= b.minuteLimit*percent {
+		return false
+	}
+	return b.monthlyLimit <= 0 || b.monthCalls*100 < b.monthlyLimit*percent
+}
+
+func (b *Budget) Usage() BudgetUsage {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.roll(b.now())
+
+	remaining := -1
+	if b.monthlyLimit > 0 {
+		remaining = max(b.monthlyLimit-b.monthCalls, 0)
+	}
+
+	return BudgetUsage{
+		Month:            b.month,
+		MonthlyLimit:     b.monthlyLimit,
+		MonthlyUsed:      b.monthCalls,
+		MonthlyRemaining: remaining,
+		MinuteLimit:      b.minuteLimit,
+		MinuteUsed:       b.minuteCalls,
+		DegradeAtPercent: b.degradeAt,
+		Degraded:         b.exhausted(),
+		DeniedCalls:      b.denied,
+	}
+}
+
+func (b *Budget) roll(now time.Time) {
+	now = now.UTC()
+	if month := now.Format(monthLayout); month != b.month {
+		b.month = month
+		b.monthCalls = 0
+	}
+	if minute := now.Truncate(time.Minute); !minute.Equal(b.minute) {
+		b.minute = minute
+		b.minuteCalls = 0
+	}
+}
+
+func (b *Budget) exhausted() bool {
+	if b.minuteLimit > 0 && b.minuteCalls >= b.minuteLimit {
+		return true
+	}
+	return b.monthlyLimit > 0 && b.monthCalls*100 >= b.monthlyLimit*b.degradeAt
+}
+
+// Run saves the monthly count to the state file every interval while calls
+// are counted, and once more when ctx is canceled, so calls never wait on the
+// disk. A crash loses the calls counted since the last save.
+func (b *Budget) Run(ctx context.Context, interval time.Duration) {
+	defer b.save()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			b.save()
+		}
+	}
+}
+
+// save writes the monthly count through a temporary file so a crash never
+// leaves a truncated state file behind. A failed save is tried again on the
+// next one.
+func (b *Budget) save() {
+	b.mu.Lock()
+	path := b.stateFile
+	dirty := b.dirty
+	state := budgetState{Month: b.month, Calls: b.monthCalls}
+	b.dirty = false
+	b.mu.Unlock()
+
+	if path == "" || !dirty || writeState(path, state) == nil {
+		return
+	}
+	b.mu.Lock()
+	b.dirty = true
+	b.mu.Unlock()
+}
+
+func writeState(path string, state budgetState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".budget-*")
+	if err != nil {
+		return err
+	}
+	_, writeErr := tmp.Write(data)
+	closeErr := tmp.Close()
+	if err := errors.Join(writeErr, closeErr); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
@@ -7,9 +7,15 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"path"
+	"strconv"
+	"sync/atomic"
+	"time"
 
 	"go-a-b-microservices/pkg/apperror"
+	"go-a-b-microservices/pkg/calltimeout"
 	"go-a-b-microservices/pkg/config"
+	"go-a-b-microservices/pkg/i18n"
 	"go-a-b-microservices/pkg/logger"
 	"go-a-b-microservices/pkg/zipcode"
 
@@ -24,19 +30,27 @@ type ViaCepErrorResponse struct {
 type ViaCEPClient struct {
 	client  *http.Client
 	baseURL string
+	timeout calltimeout.Timeout
 	logger  logger.Logger
 }
 
-func NewViaCEPClient(cfg *config.Config, log logger.Logger) *ViaCEPClient {
+func NewViaCEPClient(cfg *config.ServiceB, log logger.Logger) *ViaCEPClient {
 	httpClient := &http.Client{
 		Transport: otelhttp.NewTransport(http.DefaultTransport),
 	}
 
-	return &ViaCEPClient{
+	client := &ViaCEPClient{
 		client:  httpClient,
-		baseURL: cfg.ViaCepURL,
+		baseURL: cfg.ViaCepURL.String(),
 		logger:  log,
 	}
+	client.SetTimeout(cfg.ProviderTimeout)
+	return client
+}
+
+// SetTimeout bounds each ViaCEP call, also while the client is in use.
+func (c *ViaCEPClient) SetTimeout(timeout time.Duration) {
+	c.timeout.Set(timeout)
 }
 
 func (c *ViaCEPClient) GetLocationByZipCode(ctx context.Context, zipCode string) (*zipcode.Location, error) {
@@ -44,6 +58,9 @@ func (c *ViaCEPClient) GetLocationByZipCode(ctx context.Context, zipCode string)
 	ctx, span := tracer.Start(ctx, "client.ViaCEP.GetLocationByZipCode")
 	defer span.End()
 
+	ctx, cancel := c.timeout.Context(ctx)
+	defer cancel()
+
 	url := fmt.Sprintf("%s/%s/json", c.baseURL, zipCode)
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
@@ -87,68 +104,190 @@ func (c *ViaCEPClient) GetLocationByZipCode(ctx context.Context, zipCode string)
 type WeatherAPIClient struct {
 	client  *http.Client
 	baseURL string
-	apiKey  string
+	apiKey  *apiKeyTransport
+	timeout calltimeout.Timeout
 	logger  logger.Logger
 }
 
-func NewWeatherAPIClient(cfg *config.Config, log logger.Logger) *WeatherAPIClient {
+func NewWeatherAPIClient(cfg *config.ServiceB, log logger.Logger) *WeatherAPIClient {
+	apiKey := &apiKeyTransport{base: http.DefaultTransport}
+	apiKey.key.Store(cfg.WeatherAPIKey)
+
 	httpClient := &http.Client{
-		Transport: otelhttp.NewTransport(http.DefaultTransport),
+		Transport: otelhttp.NewTransport(apiKey),
 	}
 
-	return &WeatherAPIClient{
+	client := &WeatherAPIClient{
 		client:  httpClient,
-		baseURL: cfg.WeatherAPIURL,
-		apiKey:  cfg.WeatherAPIKey,
+		baseURL: cfg.WeatherAPIURL.String(),
+		apiKey:  apiKey,
 		logger:  log,
 	}
+	client.SetTimeout(cfg.ProviderTimeout)
+	return client
+}
+
+// SetAPIKey switches to a rotated key, also while the client is in use.
+func (c *WeatherAPIClient) SetAPIKey(key string) {
+	c.apiKey.key.Store(key)
 }
 
-func (c *WeatherAPIClient) GetWeatherByCity(ctx context.Context, city string) (*zipcode.WeatherData, error) {
+// apiKeyTransport adds the key to WeatherAPI requests below the tracing
+// transport, so it is not recorded on spans nor in the URL of logged errors.
+type apiKeyTransport struct {
+	base http.RoundTripper
+	key  atomic.Value
+}
+
+func (t *apiKeyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	q := req.URL.Query()
+	q.Set("key", t.key.Load().(string))
+	req.URL.RawQuery = q.Encode()
+	return t.base.RoundTrip(req)
+}
+
+// SetTimeout bounds each WeatherAPI call, also while the client is in use.
+func (c *WeatherAPIClient) SetTimeout(timeout time.Duration) {
+	c.timeout.Set(timeout)
+}
+
+// GetWeatherByCity returns the current conditions. WeatherAPI only returns
+// alerts from forecast.json, so that endpoint is used when alerts are requested.
+func (c *WeatherAPIClient) GetWeatherByCity(ctx context.Context, city string, options zipcode.WeatherOptions) (*zipcode.WeatherData, error) {
 	tracer := otel.Tracer("service-b")
 	ctx, span := tracer.Start(ctx, "client.WeatherAPI.GetWeatherByCity")
 	defer span.End()
 
+	params := url.Values{}
+	params.Set("q", city)
+	params.Set("aqi", yesNo(options.AirQuality))
+
+	endpoint := "current.json"
+	if options.Alerts {
+		endpoint = "forecast.json"
+		params.Set("days", "1")
+		params.Set("alerts", "yes")
+	}
+
+	var weather weatherAPIResponse
+	if err := c.get(ctx, endpoint, params, &weather); err != nil {
+		return nil, err
+	}
+
+	return weather.toWeatherData(options), nil
+}
+
+func (c *WeatherAPIClient) GetForecastByCity(ctx context.Context, city string, days int) (*zipcode.ForecastData, error) {
+	tracer := otel.Tracer("service-b")
+	ctx, span := tracer.Start(ctx, "client.WeatherAPI.GetForecastByCity")
+	defer span.End()
+
+	params := url.Values{}
+	params.Set("q", city)
+	params.Set("days", strconv.Itoa(days))
+
+	var forecast weatherAPIForecastResponse
+	if err := c.get(ctx, "forecast.json", params, &forecast); err != nil {
+		return nil, err
+	}
+
+	return forecast.toForecastData(), nil
+}
+
+func (c *WeatherAPIClient) GetHistoryByCity(ctx context.Context, city string, start, end time.Time) (*zipcode.HistoryData, error) {
+	tracer := otel.Tracer("service-b")
+	ctx, span := tracer.Start(ctx, "client.WeatherAPI.GetHistoryByCity")
+	defer span.End()
+
+	params := url.Values{}
+	params.Set("q", city)
+	params.Set("dt", start.Format(zipcode.DateLayout))
+	if end.After(start) {
+		params.Set("end_dt", end.Format(zipcode.DateLayout))
+	}
+
+	var history weatherAPIHistoryResponse
+	if err := c.get(ctx, "history.json", params, &history); err != nil {
+		return nil, err
+	}
+
+	return history.toHistoryData(), nil
+}
+
+// weatherAPILanguage maps a negotiated language to WeatherAPI's lang codes.
+// English is the provider default and needs no parameter.
+func weatherAPILanguage(lang i18n.Language) string {
+	switch lang {
+	case i18n.Portuguese:
+		return "pt"
+	case i18n.Spanish:
+		return "es"
+	default:
+		return ""
+	}
+}
+
+func yesNo(value bool) string {
+	if value {
+		return "yes"
+	}
+	return "no"
+}
+
+// get calls the given WeatherAPI endpoint and decodes the response into out.
+// The endpoint replaces the last path segment of the configured URL, so
+// WEATHER_API_URL can keep pointing at current.json.
+func (c *WeatherAPIClient) get(ctx context.Context, endpoint string, params url.Values, out interface{}) error {
 	reqURL, err := url.Parse(c.baseURL)
 	if err != nil {
 		c.logger.Error("Failed to parse URL: %v", err)
-		return nil, err
+		return err
 	}
+	reqURL.Path = path.Join(path.Dir(reqURL.Path), endpoint)
 
 	q := reqURL.Query()
-	q.Set("key", c.apiKey)
-	q.Set("q", city)
+	for key, values := range params {
+		for _, value := range values {
+			q.Add(key, value)
+		}
+	}
+	if lang := weatherAPILanguage(i18n.FromContext(ctx)); lang != "" {
+		q.Set("lang", lang)
+	}
 	reqURL.RawQuery = q.Encode()
 
+	ctx, cancel := c.timeout.Context(ctx)
+	defer cancel()
+
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL.String(), nil)
 	if err != nil {
 		c.logger.Error("Failed to create request: %v", err)
-		return nil, err
+		return err
 	}
 
 	resp, err := c.client.Do(req)
 	if err != nil {
 		c.logger.Error("Failed to make request to WeatherAPI: %v", err)
-		return nil, err
+		return err
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		c.logger.Error("Failed to read response body: %v", err)
-		return nil, err
+		return err
 	}
 
 	if resp.StatusCode != http.StatusOK {
 		c.logger.Error("WeatherAPI returned non-OK status: %d", resp.StatusCode)
-		return nil, fmt.Errorf("failed to get weather: status %d", resp.StatusCode)
+		return fmt.Errorf("failed to get weather: status %d", resp.StatusCode)
 	}
 
-	var weather zipcode.WeatherData
-	if err := json.Unmarshal(body, &weather); err != nil {
+	if err := json.Unmarshal(body, out); err != nil {
 		c.logger.Error("Failed to unmarshal response: %v", err)
-		return nil, err
+		return err
 	}
 
-	return &weather, nil
+	return nil
 }
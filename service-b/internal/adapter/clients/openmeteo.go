@@ -0,0 +1,208 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go-a-b-microservices/pkg/calltimeout"
+	"go-a-b-microservices/pkg/config"
+	"go-a-b-microservices/pkg/i18n"
+	"go-a-b-microservices/pkg/logger"
+	"go-a-b-microservices/pkg/zipcode"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+)
+
+const (
+	openMeteoCurrentFields = "temperature_2m,relative_humidity_2m,apparent_temperature,is_day,precipitation,weather_code,pressure_msl,wind_speed_10m,wind_direction_10m"
+	openMeteoDailyFields   = "temperature_2m_max,temperature_2m_min,precipitation_probability_max,weather_code"
+	openMeteoHourlyFields  = "temperature_2m,precipitation_probability,weather_code"
+	openMeteoHistoryFields = "temperature_2m_max,temperature_2m_min,temperature_2m_mean,precipitation_sum,relative_humidity_2m_mean,weather_code"
+)
+
+// OpenMeteoClient reads weather from Open-Meteo, which needs no API key. It
+// does not report alerts, air quality or UV, and condition text is English.
+type OpenMeteoClient struct {
+	client       *http.Client
+	forecastURL  string
+	geocodingURL string
+	archiveURL   string
+	timeout      calltimeout.Timeout
+	logger       logger.Logger
+
+	mu        sync.Mutex
+	locations map[string]openMeteoLocation
+}
+
+type openMeteoLocation struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+func NewOpenMeteoClient(cfg *config.ServiceB, log logger.Logger) *OpenMeteoClient {
+	httpClient := &http.Client{
+		Transport: otelhttp.NewTransport(http.DefaultTransport),
+	}
+
+	client := &OpenMeteoClient{
+		client:       httpClient,
+		forecastURL:  cfg.OpenMeteoURL.String(),
+		geocodingURL: cfg.OpenMeteoGeoURL.String(),
+		archiveURL:   cfg.OpenMeteoArchive.String(),
+		logger:       log,
+		locations:    make(map[string]openMeteoLocation),
+	}
+	client.SetTimeout(cfg.ProviderTimeout)
+	return client
+}
+
+// SetTimeout bounds each Open-Meteo call, also while the client is in use.
+func (c *OpenMeteoClient) SetTimeout(timeout time.Duration) {
+	c.timeout.Set(timeout)
+}
+
+func (c *OpenMeteoClient) GetWeatherByCity(ctx context.Context, city string, options zipcode.WeatherOptions) (*zipcode.WeatherData, error) {
+	tracer := otel.Tracer("service-b")
+	ctx, span := tracer.Start(ctx, "client.OpenMeteo.GetWeatherByCity")
+	defer span.End()
+
+	params, err := c.locationParams(ctx, city)
+	if err != nil {
+		return nil, err
+	}
+	params.Set("current", openMeteoCurrentFields)
+
+	var weather openMeteoCurrentResponse
+	if err := c.get(ctx, c.forecastURL, "forecast", params, &weather); err != nil {
+		return nil, err
+	}
+
+	return weather.toWeatherData(), nil
+}
+
+func (c *OpenMeteoClient) GetForecastByCity(ctx context.Context, city string, days int) (*zipcode.ForecastData, error) {
+	tracer := otel.Tracer("service-b")
+	ctx, span := tracer.Start(ctx, "client.OpenMeteo.GetForecastByCity")
+	defer span.End()
+
+	params, err := c.locationParams(ctx, city)
+	if err != nil {
+		return nil, err
+	}
+	params.Set("daily", openMeteoDailyFields)
+	params.Set("hourly", openMeteoHourlyFields)
+	params.Set("forecast_days", strconv.Itoa(days))
+
+	var forecast openMeteoForecastResponse
+	if err := c.get(ctx, c.forecastURL, "forecast", params, &forecast); err != nil {
+		return nil, err
+	}
+
+	return forecast.toForecastData(), nil
+}
+
+func (c *OpenMeteoClient) GetHistoryByCity(ctx context.Context, city string, start, end time.Time) (*zipcode.HistoryData, error) {
+	tracer := otel.Tracer("service-b")
+	ctx, span := tracer.Start(ctx, "client.OpenMeteo.GetHistoryByCity")
+	defer span.End()
+
+	params, err := c.locationParams(ctx, city)
+	if err != nil {
+		return nil, err
+	}
+	params.Set("daily", openMeteoHistoryFields)
+	params.Set("start_date", start.Format(zipcode.DateLayout))
+	params.Set("end_date", end.Format(zipcode.DateLayout))
+
+	var history openMeteoHistoryResponse
+	if err := c.get(ctx, c.archiveURL, "archive", params, &history); err != nil {
+		return nil, err
+	}
+
+	return history.toHistoryData(), nil
+}
+
+// locationParams geocodes city, which ViaCEP always places in Brazil, and
+// returns the coordinate parameters every Open-Meteo call takes. Coordinates
+// never change, so they are kept for the life of the client.
+func (c *OpenMeteoClient) locationParams(ctx context.Context, city string) (url.Values, error) {
+	c.mu.Lock()
+	location, ok := c.locations[city]
+	c.mu.Unlock()
+
+	if !ok {
+		params := url.Values{}
+		params.Set("name", city)
+		params.Set("count", "1")
+		params.Set("countryCode", "BR")
+		params.Set("language", strings.ToLower(strings.SplitN(string(i18n.FromContext(ctx)), "-", 2)[0]))
+
+		var geocoding struct {
+			Results []openMeteoLocation `json:"results"`
+		}
+		if err := c.get(ctx, c.geocodingURL, "search", params, &geocoding); err != nil {
+			return nil, err
+		}
+		if len(geocoding.Results) == 0 {
+			c.logger.Error("Open-Meteo could not geocode %s", city)
+			return nil, fmt.Errorf("open-meteo: unknown city %q", city)
+		}
+
+		location = geocoding.Results[0]
+		c.mu.Lock()
+		c.locations[city] = location
+		c.mu.Unlock()
+	}
+
+	params := url.Values{}
+	params.Set("latitude", strconv.FormatFloat(location.Latitude, 'f', -1, 64))
+	params.Set("longitude", strconv.FormatFloat(location.Longitude, 'f', -1, 64))
+	params.Set("timezone", "auto")
+	return params, nil
+}
+
+func (c *OpenMeteoClient) get(ctx context.Context, baseURL, endpoint string, params url.Values, out interface{}) error {
+	ctx, cancel := c.timeout.Context(ctx)
+	defer cancel()
+
+	reqURL := fmt.Sprintf("%s/%s?%s", strings.TrimSuffix(baseURL, "/"), endpoint, params.Encode())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		c.logger.Error("Failed to create request: %v", err)
+		return err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		c.logger.Error("Failed to make request to Open-Meteo: %v", err)
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		c.logger.Error("Failed to read response body: %v", err)
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		c.logger.Error("Open-Meteo returned non-OK status: %d", resp.StatusCode)
+		return fmt.Errorf("failed to get weather: status %d", resp.StatusCode)
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		c.logger.Error("Failed to unmarshal response: %v", err)
+		return err
+	}
+
+	return nil
+}
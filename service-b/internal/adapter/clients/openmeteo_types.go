@@ -0,0 +1,224 @@
+package clients
+
+import (
+	"math"
+	"strings"
+
+	"go-a-b-microservices/pkg/zipcode"
+)
+
+type openMeteoCurrentResponse struct {
+	Current struct {
+		Temperature   float64  `json:"temperature_2m"`
+		Humidity      *int     `json:"relative_humidity_2m"`
+		ApparentTemp  *float64 `json:"apparent_temperature"`
+		IsDay         *int     `json:"is_day"`
+		Precipitation *float64 `json:"precipitation"`
+		WeatherCode   *int     `json:"weather_code"`
+		PressureMsl   *float64 `json:"pressure_msl"`
+		WindSpeed     *float64 `json:"wind_speed_10m"`
+		WindDirection *int     `json:"wind_direction_10m"`
+	} `json:"current"`
+}
+
+type openMeteoForecastResponse struct {
+	Daily struct {
+		Time              []string  `json:"time"`
+		TemperatureMax    []float64 `json:"temperature_2m_max"`
+		TemperatureMin    []float64 `json:"temperature_2m_min"`
+		PrecipProbability []*int    `json:"precipitation_probability_max"`
+		WeatherCode       []*int    `json:"weather_code"`
+	} `json:"daily"`
+	Hourly struct {
+		Time              []string  `json:"time"`
+		Temperature       []float64 `json:"temperature_2m"`
+		PrecipProbability []*int    `json:"precipitation_probability"`
+		WeatherCode       []*int    `json:"weather_code"`
+	} `json:"hourly"`
+}
+
+// openMeteoHistoryResponse values are null for days the archive has not
+// processed yet.
+type openMeteoHistoryResponse struct {
+	Daily struct {
+		Time             []string   `json:"time"`
+		TemperatureMax   []*float64 `json:"temperature_2m_max"`
+		TemperatureMin   []*float64 `json:"temperature_2m_min"`
+		TemperatureMean  []*float64 `json:"temperature_2m_mean"`
+		PrecipitationSum []*float64 `json:"precipitation_sum"`
+		HumidityMean     []*float64 `json:"relative_humidity_2m_mean"`
+		WeatherCode      []*int     `json:"weather_code"`
+	} `json:"daily"`
+}
+
+func (r *openMeteoCurrentResponse) toWeatherData() *zipcode.WeatherData {
+	current := r.Current
+
+	var isDay *bool
+	if current.IsDay != nil {
+		value := *current.IsDay == 1
+		isDay = &value
+	}
+
+	var windDir string
+	if current.WindDirection != nil {
+		windDir = compassDirection(*current.WindDirection)
+	}
+
+	return &zipcode.WeatherData{
+		Current: zipcode.CurrentWeather{
+			TempC:      current.Temperature,
+			FeelsLikeC: current.ApparentTemp,
+			Humidity:   current.Humidity,
+			WindKph:    current.WindSpeed,
+			WindDegree: current.WindDirection,
+			WindDir:    windDir,
+			PressureMb: current.PressureMsl,
+			PrecipMm:   current.Precipitation,
+			Condition:  wmoCondition(current.WeatherCode),
+			IsDay:      isDay,
+		},
+	}
+}
+
+func (r *openMeteoForecastResponse) toForecastData() *zipcode.ForecastData {
+	hoursByDate := make(map[string][]zipcode.HourlyForecast)
+	for i, hourTime := range r.Hourly.Time {
+		// Open-Meteo uses ISO 8601 local times; WeatherAPI separates the
+		// date and time with a space.
+		date, _, _ := strings.Cut(hourTime, "T")
+		hoursByDate[date] = append(hoursByDate[date], zipcode.HourlyForecast{
+			Time:         strings.Replace(hourTime, "T", " ", 1),
+			TempC:        valueAt(r.Hourly.Temperature, i),
+			ChanceOfRain: intAt(r.Hourly.PrecipProbability, i),
+			Condition:    wmoCondition(pointerAt(r.Hourly.WeatherCode, i)),
+		})
+	}
+
+	data := &zipcode.ForecastData{Days: make([]zipcode.DailyForecast, 0, len(r.Daily.Time))}
+	for i, date := range r.Daily.Time {
+		data.Days = append(data.Days, zipcode.DailyForecast{
+			Date:         date,
+			MinTempC:     valueAt(r.Daily.TemperatureMin, i),
+			MaxTempC:     valueAt(r.Daily.TemperatureMax, i),
+			ChanceOfRain: intAt(r.Daily.PrecipProbability, i),
+			Condition:    wmoCondition(pointerAt(r.Daily.WeatherCode, i)),
+			Hours:        hoursByDate[date],
+		})
+	}
+
+	return data
+}
+
+// toHistoryData skips days the archive has no temperatures for yet.
+func (r *openMeteoHistoryResponse) toHistoryData() *zipcode.HistoryData {
+	data := &zipcode.HistoryData{Days: make([]zipcode.HistoricalDay, 0, len(r.Daily.Time))}
+	for i, date := range r.Daily.Time {
+		maxTemp := pointerAt(r.Daily.TemperatureMax, i)
+		minTemp := pointerAt(r.Daily.TemperatureMin, i)
+		if maxTemp == nil || minTemp == nil {
+			continue
+		}
+
+		avgTemp := (*maxTemp + *minTemp) / 2
+		if mean := pointerAt(r.Daily.TemperatureMean, i); mean != nil {
+			avgTemp = *mean
+		}
+
+		var precip float64
+		if sum := pointerAt(r.Daily.PrecipitationSum, i); sum != nil {
+			precip = *sum
+		}
+
+		var humidity int
+		if mean := pointerAt(r.Daily.HumidityMean, i); mean != nil {
+			humidity = int(math.Round(*mean))
+		}
+
+		data.Days = append(data.Days, zipcode.HistoricalDay{
+			Date:          date,
+			MinTempC:      *minTemp,
+			MaxTempC:      *maxTemp,
+			AvgTempC:      avgTemp,
+			TotalPrecipMm: precip,
+			AvgHumidity:   humidity,
+			Condition:     wmoCondition(pointerAt(r.Daily.WeatherCode, i)),
+		})
+	}
+
+	return data
+}
+
+func valueAt[T any](values []T, i int) T {
+	var zero T
+	if i >= len(values) {
+		return zero
+	}
+	return values[i]
+}
+
+func pointerAt[T any](values []*T, i int) *T {
+	if i >= len(values) {
+		return nil
+	}
+	return values[i]
+}
+
+func intAt(values []*int, i int) int {
+	if value := pointerAt(values, i); value != nil {
+		return *value
+	}
+	return 0
+}
+
+var compassPoints = []string{"N", "NNE", "NE", "ENE", "E", "ESE", "SE", "SSE", "S", "SSW", "SW", "WSW", "W", "WNW", "NW", "NNW"}
+
+// compassDirection converts degrees to the 16 point compass WeatherAPI uses.
+func compassDirection(degrees int) string {
+	index := int(math.Round(float64(((degrees%360)+360)%360)/22.5)) % len(compassPoints)
+	return compassPoints[index]
+}
+
+// wmoConditions describes the WMO weather interpretation codes Open-Meteo
+// reports.
+var wmoConditions = map[int]string{
+	0:  "Clear sky",
+	1:  "Mainly clear",
+	2:  "Partly cloudy",
+	3:  "Overcast",
+	45: "Fog",
+	48: "Depositing rime fog",
+	51: "Light drizzle",
+	53: "Moderate drizzle",
+	55: "Dense drizzle",
+	56: "Light freezing drizzle",
+	57: "Dense freezing drizzle",
+	61: "Slight rain",
+	63: "Moderate rain",
+	65: "Heavy rain",
+	66: "Light freezing rain",
+	67: "Heavy freezing rain",
+	71: "Slight snow fall",
+	73: "Moderate snow fall",
+	75: "Heavy snow fall",
+	77: "Snow grains",
+	80: "Slight rain showers",
+	81: "Moderate rain showers",
+	82: "Violent rain showers",
+	85: "Slight snow showers",
+	86: "Heavy snow showers",
+	95: "Thunderstorm",
+	96: "Thunderstorm with slight hail",
+	99: "Thunderstorm with heavy hail",
+}
+
+func wmoCondition(code *int) *zipcode.Condition {
+	if code == nil {
+		return nil
+	}
+	text, ok := wmoConditions[*code]
+	if !ok {
+		return nil
+	}
+	return &zipcode.Condition{Text: text}
+}
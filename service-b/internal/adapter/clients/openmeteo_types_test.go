@@ -0,0 +1,86 @@
+package clients
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+const openMeteoForecastFixture = `{
+	"daily": {
+		"time": ["2025-05-01", "2025-05-02"],
+		"temperature_2m_max": [29.1, 27.4],
+		"temperature_2m_min": [18.2, 17.9],
+		"precipitation_probability_max": [10, null],
+		"weather_code": [2, 63]
+	},
+	"hourly": {
+		"time": ["2025-05-01T00:00", "2025-05-01T01:00", "2025-05-02T00:00"],
+		"temperature_2m": [19.5, 19.1, 18.4],
+		"precipitation_probability": [0, 5, 40],
+		"weather_code": [0, 1, 61]
+	}
+}`
+
+func TestOpenMeteoForecastResponse_toForecastData(t *testing.T) {
+	var response openMeteoForecastResponse
+	if err := json.Unmarshal([]byte(openMeteoForecastFixture), &response); err != nil {
+		t.Fatalf("Failed to unmarshal fixture: %v", err)
+	}
+
+	forecast := response.toForecastData()
+	if len(forecast.Days) != 2 {
+		t.Fatalf("Expected 2 days, got %d", len(forecast.Days))
+	}
+
+	first := forecast.Days[0]
+	if first.MaxTempC != 29.1 || first.MinTempC != 18.2 || first.ChanceOfRain != 10 {
+		t.Errorf("Unexpected first day %+v", first)
+	}
+	if first.Condition == nil || first.Condition.Text != "Partly cloudy" {
+		t.Errorf("Expected WMO code 2 to map to Partly cloudy, got %+v", first.Condition)
+	}
+	if len(first.Hours) != 2 || first.Hours[1].Time != "2025-05-01 01:00" {
+		t.Errorf("Expected hours grouped by date, got %+v", first.Hours)
+	}
+	if second := forecast.Days[1]; second.ChanceOfRain != 0 || len(second.Hours) != 1 {
+		t.Errorf("Unexpected second day %+v", second)
+	}
+}
+
+func TestOpenMeteoHistoryResponse_toHistoryData(t *testing.T) {
+	var response openMeteoHistoryResponse
+	fixture := `{
+		"daily": {
+			"time": ["2025-04-01", "2025-04-02"],
+			"temperature_2m_max": [30.0, null],
+			"temperature_2m_min": [20.0, null],
+			"temperature_2m_mean": [null, null],
+			"precipitation_sum": [1.2, null],
+			"relative_humidity_2m_mean": [71.6, null],
+			"weather_code": [80, null]
+		}
+	}`
+	if err := json.Unmarshal([]byte(fixture), &response); err != nil {
+		t.Fatalf("Failed to unmarshal fixture: %v", err)
+	}
+
+	history := response.toHistoryData()
+	if len(history.Days) != 1 {
+		t.Fatalf("Expected days without data to be skipped, got %d days", len(history.Days))
+	}
+
+	day := history.Days[0]
+	if day.AvgTempC != 25 || day.TotalPrecipMm != 1.2 || day.AvgHumidity != 72 {
+		t.Errorf("Unexpected day %+v", day)
+	}
+}
+
+func TestCompassDirection(t *testing.T) {
+	tests := map[int]string{0: "N", 11: "N", 12: "NNE", 200: "SSW", 350: "N", 360: "N", -90: "W"}
+
+	for degrees, expected := range tests {
+		if got := compassDirection(degrees); got != expected {
+			t.Errorf("compassDirection(%d) = %s, want %s", degrees, got, expected)
+		}
+	}
+}
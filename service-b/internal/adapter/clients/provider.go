@@ -0,0 +1,15 @@
+package clients
+
+import (
+	"context"
+	"time"
+
+	"go-a-b-microservices/pkg/zipcode"
+)
+
+// WeatherProvider is a source of weather data for a city.
+type WeatherProvider interface {
+	GetWeatherByCity(ctx context.Context, city string, options zipcode.WeatherOptions) (*zipcode.WeatherData, error)
+	GetForecastByCity(ctx context.Context, city string, days int) (*zipcode.ForecastData, error)
+	GetHistoryByCity(ctx context.Context, city string, start, end time.Time) (*zipcode.HistoryData, error)
+}
@@ -0,0 +1,246 @@
+package clients
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go-a-b-microservices/pkg/apperror"
+	"go-a-b-microservices/pkg/i18n"
+	"go-a-b-microservices/pkg/logger"
+	"go-a-b-microservices/pkg/zipcode"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// DegradeCache serves the last known data once the budget is exhausted,
+	// as long as it is not older than the last known max age.
+	DegradeCache = "cache"
+	// DegradeFallback asks the other providers once the budget is exhausted,
+	// and serves the last known data if they fail too.
+	DegradeFallback = "fallback"
+
+	lastKnownCapacity = 1000
+)
+
+// GuardSettings are the settings of a QuotaGuard that can change while it is
+// in use. They are swapped as a whole, so a call sees either the old or the
+// new ones.
+type GuardSettings struct {
+	// Providers are asked in order until one answers.
+	Providers []WeatherProvider
+	// Mode is what happens once the budget of the paid provider is
+	// exhausted, DegradeCache or DegradeFallback.
+	Mode string
+	// LastKnownMaxAge is the age past which the last known data is no longer
+	// served. Zero serves it at any age.
+	LastKnownMaxAge time.Duration
+}
+
+// QuotaGuard keeps calls to a paid provider within its budget. Successful
+// responses are remembered so they can be served while degraded; weather
+// served that way is marked stale with the age of the observation.
+type QuotaGuard struct {
+	paid      WeatherProvider
+	budget    *Budget
+	settings  atomic.Pointer[GuardSettings]
+	lastKnown *lastKnownCache
+	now       func() time.Time
+	logger    logger.Logger
+}
+
+// NewQuotaGuard budgets the calls to paid, which is usually one of the
+// providers in settings.
+func NewQuotaGuard(paid WeatherProvider, budget *Budget, settings GuardSettings, log logger.Logger) (*QuotaGuard, error) {
+	g := &QuotaGuard{
+		paid:      paid,
+		budget:    budget,
+		lastKnown: newLastKnownCache(lastKnownCapacity),
+		now:       time.Now,
+		logger:    log,
+	}
+	if err := g.CheckSettings(settings); err != nil {
+		return nil, err
+	}
+	g.SetSettings(settings)
+	return g, nil
+}
+
+// CheckSettings reports whether settings can be set.
+func (g *QuotaGuard) CheckSettings(settings GuardSettings) error {
+	if len(settings.Providers) == 0 {
+		return errors.New("no weather providers configured")
+	}
+
+	switch settings.Mode {
+	case DegradeCache:
+	case DegradeFallback:
+		if !slices.ContainsFunc(settings.Providers, func(provider WeatherProvider) bool { return provider != g.paid }) {
+			return fmt.Errorf("degrade mode %q needs a fallback provider", settings.Mode)
+		}
+	default:
+		return fmt.Errorf("unknown degrade mode %q", settings.Mode)
+	}
+	return nil
+}
+
+// SetSettings replaces the settings, also while the guard is in use. They
+// must have passed CheckSettings.
+func (g *QuotaGuard) SetSettings(settings GuardSettings) {
+	settings.Providers = slices.Clone(settings.Providers)
+	g.settings.Store(&settings)
+}
+
+func (g *QuotaGuard) Budget() *Budget {
+	return g.budget
+}
+
+func (g *QuotaGuard) GetWeatherByCity(ctx context.Context, city string, options zipcode.WeatherOptions) (*zipcode.WeatherData, error) {
+	key := fmt.Sprintf("weather:%s:%s:%t:%t", i18n.FromContext(ctx), city, options.Alerts, options.AirQuality)
+	data, storedAt, err := guard(ctx, g, key, func(provider WeatherProvider) (*zipcode.WeatherData, error) {
+		return provider.GetWeatherByCity(ctx, city, options)
+	})
+	if err != nil || storedAt.IsZero() {
+		return data, err
+	}
+
+	// Caches downstream keep it by when it was observed, not by when it was
+	// served.
+	stale := *data
+	stale.Stale = true
+	stale.Age = g.now().Sub(storedAt)
+	return &stale, nil
+}
+
+func (g *QuotaGuard) GetForecastByCity(ctx context.Context, city string, days int) (*zipcode.ForecastData, error) {
+	key := fmt.Sprintf("forecast:%s:%s:%d", i18n.FromContext(ctx), city, days)
+	data, _, err := guard(ctx, g, key, func(provider WeatherProvider) (*zipcode.ForecastData, error) {
+		return provider.GetForecastByCity(ctx, city, days)
+	})
+	return data, err
+}
+
+// GetHistoryByCity is not remembered here; the repository already caches
+// observed days.
+func (g *QuotaGuard) GetHistoryByCity(ctx context.Context, city string, start, end time.Time) (*zipcode.HistoryData, error) {
+	data, _, err := guard(ctx, g, "", func(provider WeatherProvider) (*zipcode.HistoryData, error) {
+		return provider.GetHistoryByCity(ctx, city, start, end)
+	})
+	return data, err
+}
+
+// guard asks the providers in order until one answers, the paid one only
+// while the budget allows. Once the budget is exhausted it degrades according
+// to the configured mode. An empty key disables the last known data. storedAt
+// is when the last known data served was stored, zero when a provider
+// answered.
+func guard[T any](ctx context.Context, g *QuotaGuard, key string, call func(WeatherProvider) (*T, error)) (data *T, storedAt time.Time, err error) {
+	span := trace.SpanFromContext(ctx)
+	settings := g.settings.Load()
+
+	degraded := false
+	for _, provider := range settings.Providers {
+		if provider == g.paid && !g.budget.Take() {
+			degraded = true
+			g.logger.Debug("WeatherAPI budget exhausted, degrading to %s", settings.Mode)
+			if settings.Mode == DegradeCache {
+				break
+			}
+			continue
+		}
+
+		data, err = call(provider)
+		if err == nil {
+			span.SetAttributes(attribute.Bool("quota.degraded", degraded))
+			if degraded {
+				span.SetAttributes(attribute.String("quota.served_by", "fallback"))
+			}
+			if key != "" {
+				g.lastKnown.Set(key, data, g.now())
+			}
+			return data, time.Time{}, nil
+		}
+		if ctx.Err() != nil {
+			return nil, time.Time{}, err
+		}
+		g.logger.Error("Weather provider failed: %v", err)
+	}
+
+	span.SetAttributes(attribute.Bool("quota.degraded", degraded))
+	if !degraded {
+		return nil, time.Time{}, err
+	}
+
+	if key != "" {
+		maxAge := settings.LastKnownMaxAge
+		if data, storedAt, ok := g.lastKnown.Get(key); ok && (maxAge <= 0 || g.now().Sub(storedAt) <= maxAge) {
+			span.SetAttributes(attribute.String("quota.served_by", "cache"))
+			return data.(*T), storedAt, nil
+		}
+	}
+
+	return nil, time.Time{}, apperror.ErrWeatherUnavailable
+}
+
+// lastKnownCache is a fixed size LRU of the latest response per key.
+type lastKnownCache struct {
+	mu       sync.Mutex
+	entries  map[string]*list.Element
+	order    *list.List
+	capacity int
+}
+
+type lastKnownEntry struct {
+	key      string
+	value    interface{}
+	storedAt time.Time
+}
+
+func newLastKnownCache(capacity int) *lastKnownCache {
+	return &lastKnownCache{
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+		capacity: capacity,
+	}
+}
+
+// Get returns the value stored under key with the time it was stored.
+func (c *lastKnownCache) Get(key string) (interface{}, time.Time, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, time.Time{}, false
+	}
+	c.order.MoveToFront(element)
+	entry := element.Value.(*lastKnownEntry)
+	return entry.value, entry.storedAt, true
+}
+
+func (c *lastKnownCache) Set(key string, value interface{}, storedAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		entry := element.Value.(*lastKnownEntry)
+		entry.value = value
+		entry.storedAt = storedAt
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&lastKnownEntry{key: key, value: value, storedAt: storedAt})
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lastKnownEntry).key)
+	}
+}
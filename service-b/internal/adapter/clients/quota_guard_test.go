@@ -0,0 +1,347 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go-a-b-microservices/pkg/apperror"
+	"go-a-b-microservices/pkg/zipcode"
+)
+
+type MockLogger struct{}
+
+func (m *MockLogger) Info(message string, args ...interface{})  {}
+func (m *MockLogger) Error(message string, args ...interface{}) {}
+func (m *MockLogger) Debug(message string, args ...interface{}) {}
+
+type MockWeatherProvider struct {
+	GetWeatherByCityFunc  func(ctx context.Context, city string, options zipcode.WeatherOptions) (*zipcode.WeatherData, error)
+	GetForecastByCityFunc func(ctx context.Context, city string, days int) (*zipcode.ForecastData, error)
+	GetHistoryByCityFunc  func(ctx context.Context, city string, start, end time.Time) (*zipcode.HistoryData, error)
+	calls                 int
+}
+
+func (m *MockWeatherProvider) GetWeatherByCity(ctx context.Context, city string, options zipcode.WeatherOptions) (*zipcode.WeatherData, error) {
+	m.calls++
+	return m.GetWeatherByCityFunc(ctx, city, options)
+}
+
+func (m *MockWeatherProvider) GetForecastByCity(ctx context.Context, city string, days int) (*zipcode.ForecastData, error) {
+	m.calls++
+	return m.GetForecastByCityFunc(ctx, city, days)
+}
+
+func (m *MockWeatherProvider) GetHistoryByCity(ctx context.Context, city string, start, end time.Time) (*zipcode.HistoryData, error) {
+	m.calls++
+	return m.GetHistoryByCityFunc(ctx, city, start, end)
+}
+
+func weatherProviderAt(tempC float64) *MockWeatherProvider {
+	return &MockWeatherProvider{
+		GetWeatherByCityFunc: func(ctx context.Context, city string, options zipcode.WeatherOptions) (*zipcode.WeatherData, error) {
+			return &zipcode.WeatherData{Current: zipcode.CurrentWeather{TempC: tempC}}, nil
+		},
+		GetHistoryByCityFunc: func(ctx context.Context, city string, start, end time.Time) (*zipcode.HistoryData, error) {
+			return &zipcode.HistoryData{}, nil
+		},
+	}
+}
+
+func TestBudget_Take(t *testing.T) {
+	now := time.Date(2025, 5, 31, 23, 57, 0, 0, time.UTC)
+	budget := NewBudget(10, 3, 80)
+	budget.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		if !budget.Take() {
+			t.Fatalf("Expected call %d to be allowed", i+1)
+		}
+	}
+	if budget.Take() {
+		t.Fatalf("Expected the per-minute limit to deny the 4th call")
+	}
+
+	for _, calls := range []int{3, 2} {
+		now = now.Add(time.Minute)
+		for i := 0; i < calls; i++ {
+			budget.Take()
+		}
+	}
+	if budget.Take() {
+		t.Fatalf("Expected the monthly reserve to deny the 9th call")
+	}
+
+	usage := budget.Usage()
+	if usage.MonthlyUsed != 8 || !usage.Degraded || usage.MonthlyRemaining != 2 {
+		t.Errorf("Expected the 80%% reserve to be reached, got %+v", usage)
+	}
+	if usage.DeniedCalls != 2 {
+		t.Errorf("Expected 2 denied calls, got %d", usage.DeniedCalls)
+	}
+
+	now = now.Add(time.Minute)
+	if !budget.Take() {
+		t.Errorf("Expected a new month to reset the budget")
+	}
+	if usage := budget.Usage(); usage.Month != "2025-06" || usage.MonthlyUsed != 1 {
+		t.Errorf("Unexpected usage after rollover %+v", usage)
+	}
+}
+
+func TestBudget_StateFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "budget.json")
+	now := time.Date(2025, 5, 10, 12, 0, 0, 0, time.UTC)
+
+	budget := NewBudget(100, 0, 100)
+	budget.now = func() time.Time { return now }
+	if err := budget.SetStateFile(path); err != nil {
+		t.Fatalf("Expected a missing file to be accepted, got %v", err)
+	}
+	budget.Take()
+	budget.Take()
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected calls not to write the state file, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	budget.Run(ctx, time.Hour)
+
+	restarted := NewBudget(100, 0, 100)
+	restarted.now = func() time.Time { return now }
+	if err := restarted.SetStateFile(path); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if used := restarted.Usage().MonthlyUsed; used != 2 {
+		t.Errorf("Expected the count to survive a restart, got %d", used)
+	}
+}
+
+func TestBudget_HasHeadroom(t *testing.T) {
+	now := time.Date(2025, 5, 10, 12, 0, 0, 0, time.UTC)
+	budget := NewBudget(10, 4, 100)
+	budget.now = func() time.Time { return now }
+
+	if !budget.HasHeadroom(50) {
+		t.Fatalf("Expected an unused budget to have headroom")
+	}
+	budget.Take()
+	budget.Take()
+	if budget.HasHeadroom(50) || !budget.HasHeadroom(80) {
+		t.Errorf("Expected 2 of 4 calls this minute to leave headroom at 80%% only")
+	}
+
+	now = now.Add(time.Minute)
+	budget.Take()
+	if budget.HasHeadroom(30) || !budget.HasHeadroom(50) {
+		t.Errorf("Expected 3 of 10 calls this month to leave headroom at 50%% only")
+	}
+
+	if !NewBudget(0, 0, 100).HasHeadroom(1) {
+		t.Errorf("Expected an unlimited budget to always have headroom")
+	}
+}
+
+func TestQuotaGuard_Degrade(t *testing.T) {
+	failing := &MockWeatherProvider{
+		GetWeatherByCityFunc: func(ctx context.Context, city string, options zipcode.WeatherOptions) (*zipcode.WeatherData, error) {
+			return nil, errors.New("fallback down")
+		},
+	}
+
+	tests := []struct {
+		name          string
+		mode          string
+		fallback      *MockWeatherProvider
+		city          string
+		expectedTempC float64
+		expectedStale bool
+		expectedErr   error
+	}{
+		{name: "cache serves last known", mode: DegradeCache, city: "São Paulo", expectedTempC: 25, expectedStale: true},
+		{name: "cache miss", mode: DegradeCache, city: "Campinas", expectedErr: apperror.ErrWeatherUnavailable},
+		{name: "fallback", mode: DegradeFallback, fallback: weatherProviderAt(19), city: "Campinas", expectedTempC: 19},
+		{name: "failed fallback serves cache", mode: DegradeFallback, fallback: failing, city: "São Paulo", expectedTempC: 25, expectedStale: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primary := weatherProviderAt(25)
+			providers := []WeatherProvider{primary}
+			if tt.fallback != nil {
+				providers = append(providers, tt.fallback)
+			}
+
+			guard, err := NewQuotaGuard(primary, NewBudget(1, 0, 100), GuardSettings{Providers: providers, Mode: tt.mode}, &MockLogger{})
+			if err != nil {
+				t.Fatalf("Failed to build guard: %v", err)
+			}
+			now := time.Date(2025, 5, 10, 12, 0, 0, 0, time.UTC)
+			guard.now = func() time.Time { return now }
+
+			// The only call in the budget remembers São Paulo.
+			if _, err := guard.GetWeatherByCity(context.Background(), "São Paulo", zipcode.WeatherOptions{}); err != nil {
+				t.Fatalf("Expected the first call to succeed, got %v", err)
+			}
+			now = now.Add(10 * time.Minute)
+
+			weather, err := guard.GetWeatherByCity(context.Background(), tt.city, zipcode.WeatherOptions{})
+			if primary.calls != 1 {
+				t.Errorf("Expected the primary provider to be called once, got %d", primary.calls)
+			}
+
+			if tt.expectedErr != nil {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Fatalf("Expected error %v, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if weather.Current.TempC != tt.expectedTempC {
+				t.Errorf("Expected %.1f°C, got %.1f°C", tt.expectedTempC, weather.Current.TempC)
+			}
+			if tt.expectedStale && (!weather.Stale || weather.Age != 10*time.Minute) {
+				t.Errorf("Expected the last known weather to be served 10m stale, got %v, %s", weather.Stale, weather.Age)
+			}
+			if !tt.expectedStale && weather.Stale {
+				t.Errorf("Expected a provider response not to be stale")
+			}
+		})
+	}
+}
+
+func TestQuotaGuard_LastKnownMaxAge(t *testing.T) {
+	primary := weatherProviderAt(25)
+	settings := GuardSettings{Providers: []WeatherProvider{primary}, Mode: DegradeCache, LastKnownMaxAge: time.Hour}
+	guard, err := NewQuotaGuard(primary, NewBudget(1, 0, 100), settings, &MockLogger{})
+	if err != nil {
+		t.Fatalf("Failed to build guard: %v", err)
+	}
+	now := time.Date(2025, 5, 10, 12, 0, 0, 0, time.UTC)
+	guard.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	if _, err := guard.GetWeatherByCity(ctx, "São Paulo", zipcode.WeatherOptions{}); err != nil {
+		t.Fatalf("Expected the first call to succeed, got %v", err)
+	}
+
+	now = now.Add(time.Hour)
+	if weather, err := guard.GetWeatherByCity(ctx, "São Paulo", zipcode.WeatherOptions{}); err != nil || weather.Age != time.Hour {
+		t.Errorf("Expected the last known weather up to 1h old, got %+v, %v", weather, err)
+	}
+
+	now = now.Add(time.Second)
+	if _, err := guard.GetWeatherByCity(ctx, "São Paulo", zipcode.WeatherOptions{}); !errors.Is(err, apperror.ErrWeatherUnavailable) {
+		t.Errorf("Expected the last known weather to be too old, got %v", err)
+	}
+
+	settings.LastKnownMaxAge = 0
+	guard.SetSettings(settings)
+	if _, err := guard.GetWeatherByCity(ctx, "São Paulo", zipcode.WeatherOptions{}); err != nil {
+		t.Errorf("Expected a zero max age to serve it at any age, got %v", err)
+	}
+}
+
+func TestNewQuotaGuard_InvalidMode(t *testing.T) {
+	primary := weatherProviderAt(0)
+	only := []WeatherProvider{primary}
+	if _, err := NewQuotaGuard(primary, NewBudget(0, 0, 0), GuardSettings{Providers: only, Mode: DegradeFallback}, &MockLogger{}); err == nil {
+		t.Errorf("Expected an error for fallback mode without a fallback provider")
+	}
+	if _, err := NewQuotaGuard(primary, NewBudget(0, 0, 0), GuardSettings{Providers: only, Mode: "drop"}, &MockLogger{}); err == nil {
+		t.Errorf("Expected an error for an unknown mode")
+	}
+	if _, err := NewQuotaGuard(primary, NewBudget(0, 0, 0), GuardSettings{Mode: DegradeCache}, &MockLogger{}); err == nil {
+		t.Errorf("Expected an error for no providers")
+	}
+}
+
+func TestQuotaGuard_Reconfigure(t *testing.T) {
+	primary := weatherProviderAt(25)
+	fallback := weatherProviderAt(19)
+	budget := NewBudget(1, 0, 100)
+	settings := GuardSettings{Providers: []WeatherProvider{primary, fallback}, Mode: DegradeCache}
+	guard, err := NewQuotaGuard(primary, budget, settings, &MockLogger{})
+	if err != nil {
+		t.Fatalf("Failed to build guard: %v", err)
+	}
+
+	ctx := context.Background()
+	if _, err := guard.GetWeatherByCity(ctx, "São Paulo", zipcode.WeatherOptions{}); err != nil {
+		t.Fatalf("Expected the first call to succeed, got %v", err)
+	}
+	if _, err := guard.GetWeatherByCity(ctx, "Campinas", zipcode.WeatherOptions{}); !errors.Is(err, apperror.ErrWeatherUnavailable) {
+		t.Fatalf("Expected the cache mode to miss, got %v", err)
+	}
+
+	settings.Mode = DegradeFallback
+	guard.SetSettings(settings)
+	weather, err := guard.GetWeatherByCity(ctx, "Campinas", zipcode.WeatherOptions{})
+	if err != nil || weather.Current.TempC != 19 {
+		t.Fatalf("Expected the fallback to serve 19°C, got %v, %v", weather, err)
+	}
+
+	budget.SetLimits(2, 0, 100)
+	weather, err = guard.GetWeatherByCity(ctx, "Campinas", zipcode.WeatherOptions{})
+	if err != nil || weather.Current.TempC != 25 {
+		t.Fatalf("Expected the primary to serve 25°C after raising the budget, got %v, %v", weather, err)
+	}
+	if primary.calls != 2 {
+		t.Errorf("Expected the primary provider to be called twice, got %d", primary.calls)
+	}
+
+	// Asking Open-Meteo first leaves the budget alone.
+	settings.Providers = []WeatherProvider{fallback, primary}
+	guard.SetSettings(settings)
+	weather, err = guard.GetWeatherByCity(ctx, "Campinas", zipcode.WeatherOptions{})
+	if err != nil || weather.Current.TempC != 19 || primary.calls != 2 {
+		t.Fatalf("Expected the fallback to serve 19°C first, got %v, %v after %d primary calls", weather, err, primary.calls)
+	}
+
+	settings.Mode = "drop"
+	if err := guard.CheckSettings(settings); err == nil {
+		t.Error("Expected an error for an unknown mode")
+	}
+}
+
+func TestQuotaGuard_ProviderOrder(t *testing.T) {
+	primary := weatherProviderAt(25)
+	failing := &MockWeatherProvider{
+		GetWeatherByCityFunc: func(ctx context.Context, city string, options zipcode.WeatherOptions) (*zipcode.WeatherData, error) {
+			return nil, errors.New("down")
+		},
+	}
+	budget := NewBudget(1, 0, 100)
+	settings := GuardSettings{Providers: []WeatherProvider{failing, primary}, Mode: DegradeCache}
+	guard, err := NewQuotaGuard(primary, budget, settings, &MockLogger{})
+	if err != nil {
+		t.Fatalf("Failed to build guard: %v", err)
+	}
+
+	ctx := context.Background()
+	weather, err := guard.GetWeatherByCity(ctx, "São Paulo", zipcode.WeatherOptions{})
+	if err != nil || weather.Current.TempC != 25 || weather.Stale {
+		t.Fatalf("Expected the next provider to answer when the first fails, got %v, %v", weather, err)
+	}
+
+	// With the budget spent, the cache mode stops at the paid provider.
+	weather, err = guard.GetWeatherByCity(ctx, "São Paulo", zipcode.WeatherOptions{})
+	if err != nil || !weather.Stale {
+		t.Fatalf("Expected the last known weather, got %v, %v", weather, err)
+	}
+	if primary.calls != 1 {
+		t.Errorf("Expected the paid provider to be called once, got %d", primary.calls)
+	}
+
+	settings.Providers = []WeatherProvider{failing}
+	guard.SetSettings(settings)
+	if _, err := guard.GetWeatherByCity(ctx, "São Paulo", zipcode.WeatherOptions{}); err == nil || errors.Is(err, apperror.ErrWeatherUnavailable) {
+		t.Errorf("Expected the provider error without degrading, got %v", err)
+	}
+}
@@ -0,0 +1,217 @@
+package clients
+
+import (
+	"time"
+
+	"go-a-b-microservices/pkg/zipcode"
+)
+
+type weatherAPIResponse struct {
+	Current weatherAPICurrent `json:"current"`
+	Alerts  struct {
+		Alert []weatherAPIAlert `json:"alert"`
+	} `json:"alerts"`
+}
+
+type weatherAPIAlert struct {
+	Headline    string `json:"headline"`
+	Severity    string `json:"severity"`
+	Urgency     string `json:"urgency"`
+	Areas       string `json:"areas"`
+	Event       string `json:"event"`
+	Effective   string `json:"effective"`
+	Expires     string `json:"expires"`
+	Desc        string `json:"desc"`
+	Instruction string `json:"instruction"`
+}
+
+type weatherAPIAirQuality struct {
+	CO           *float64 `json:"co"`
+	NO2          *float64 `json:"no2"`
+	O3           *float64 `json:"o3"`
+	SO2          *float64 `json:"so2"`
+	PM2_5        *float64 `json:"pm2_5"`
+	PM10         *float64 `json:"pm10"`
+	USEPAIndex   *int     `json:"us-epa-index"`
+	GBDefraIndex *int     `json:"gb-defra-index"`
+}
+
+type weatherAPICondition struct {
+	Text string `json:"text"`
+	Icon string `json:"icon"`
+}
+
+func (c *weatherAPICondition) toCondition() *zipcode.Condition {
+	if c == nil {
+		return nil
+	}
+	return &zipcode.Condition{Text: c.Text, Icon: c.Icon}
+}
+
+type weatherAPICurrent struct {
+	TempC      float64               `json:"temp_c"`
+	FeelsLikeC *float64              `json:"feelslike_c"`
+	Humidity   *int                  `json:"humidity"`
+	WindKph    *float64              `json:"wind_kph"`
+	WindDegree *int                  `json:"wind_degree"`
+	WindDir    string                `json:"wind_dir"`
+	PressureMb *float64              `json:"pressure_mb"`
+	PrecipMm   *float64              `json:"precip_mm"`
+	UV         *float64              `json:"uv"`
+	IsDay      *int                  `json:"is_day"`
+	Condition  *weatherAPICondition  `json:"condition"`
+	AirQuality *weatherAPIAirQuality `json:"air_quality"`
+}
+
+func (r *weatherAPIResponse) toWeatherData(options zipcode.WeatherOptions) *zipcode.WeatherData {
+	current := zipcode.CurrentWeather{
+		TempC:      r.Current.TempC,
+		FeelsLikeC: r.Current.FeelsLikeC,
+		Humidity:   r.Current.Humidity,
+		WindKph:    r.Current.WindKph,
+		WindDegree: r.Current.WindDegree,
+		WindDir:    r.Current.WindDir,
+		PressureMb: r.Current.PressureMb,
+		PrecipMm:   r.Current.PrecipMm,
+		UV:         r.Current.UV,
+		Condition:  r.Current.Condition.toCondition(),
+	}
+
+	if r.Current.IsDay != nil {
+		isDay := *r.Current.IsDay == 1
+		current.IsDay = &isDay
+	}
+
+	weather := &zipcode.WeatherData{Current: current}
+
+	if options.Alerts {
+		weather.Alerts = make([]zipcode.Alert, 0, len(r.Alerts.Alert))
+		for _, alert := range r.Alerts.Alert {
+			weather.Alerts = append(weather.Alerts, zipcode.Alert{
+				Headline:    alert.Headline,
+				Event:       alert.Event,
+				Severity:    zipcode.NormalizeSeverity(alert.Severity),
+				Urgency:     alert.Urgency,
+				Areas:       alert.Areas,
+				Description: alert.Desc,
+				Instruction: alert.Instruction,
+				Effective:   parseAlertTime(alert.Effective),
+				Expires:     parseAlertTime(alert.Expires),
+			})
+		}
+	}
+
+	if options.AirQuality && r.Current.AirQuality != nil {
+		airQuality := r.Current.AirQuality
+		weather.AirQuality = &zipcode.AirQuality{
+			CO:           airQuality.CO,
+			NO2:          airQuality.NO2,
+			O3:           airQuality.O3,
+			SO2:          airQuality.SO2,
+			PM2_5:        airQuality.PM2_5,
+			PM10:         airQuality.PM10,
+			USEPAIndex:   airQuality.USEPAIndex,
+			GBDefraIndex: airQuality.GBDefraIndex,
+		}
+		if airQuality.USEPAIndex != nil {
+			weather.AirQuality.Category = zipcode.EPACategory(*airQuality.USEPAIndex)
+		}
+	}
+
+	return weather
+}
+
+func parseAlertTime(value string) *time.Time {
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil
+	}
+	return &parsed
+}
+
+type weatherAPIForecastResponse struct {
+	Forecast struct {
+		ForecastDay []weatherAPIForecastDay `json:"forecastday"`
+	} `json:"forecast"`
+}
+
+type weatherAPIForecastDay struct {
+	Date string `json:"date"`
+	Day  struct {
+		MinTempC          float64              `json:"mintemp_c"`
+		MaxTempC          float64              `json:"maxtemp_c"`
+		DailyChanceOfRain int                  `json:"daily_chance_of_rain"`
+		Condition         *weatherAPICondition `json:"condition"`
+	} `json:"day"`
+	Hour []struct {
+		Time         string               `json:"time"`
+		TempC        float64              `json:"temp_c"`
+		ChanceOfRain int                  `json:"chance_of_rain"`
+		Condition    *weatherAPICondition `json:"condition"`
+	} `json:"hour"`
+}
+
+func (r *weatherAPIForecastResponse) toForecastData() *zipcode.ForecastData {
+	forecast := &zipcode.ForecastData{
+		Days: make([]zipcode.DailyForecast, 0, len(r.Forecast.ForecastDay)),
+	}
+
+	for _, day := range r.Forecast.ForecastDay {
+		daily := zipcode.DailyForecast{
+			Date:         day.Date,
+			MinTempC:     day.Day.MinTempC,
+			MaxTempC:     day.Day.MaxTempC,
+			ChanceOfRain: day.Day.DailyChanceOfRain,
+			Condition:    day.Day.Condition.toCondition(),
+		}
+
+		for _, hour := range day.Hour {
+			daily.Hours = append(daily.Hours, zipcode.HourlyForecast{
+				Time:         hour.Time,
+				TempC:        hour.TempC,
+				ChanceOfRain: hour.ChanceOfRain,
+				Condition:    hour.Condition.toCondition(),
+			})
+		}
+
+		forecast.Days = append(forecast.Days, daily)
+	}
+
+	return forecast
+}
+
+type weatherAPIHistoryResponse struct {
+	Forecast struct {
+		ForecastDay []struct {
+			Date string `json:"date"`
+			Day  struct {
+				MinTempC      float64              `json:"mintemp_c"`
+				MaxTempC      float64              `json:"maxtemp_c"`
+				AvgTempC      float64              `json:"avgtemp_c"`
+				TotalPrecipMm float64              `json:"totalprecip_mm"`
+				AvgHumidity   float64              `json:"avghumidity"`
+				Condition     *weatherAPICondition `json:"condition"`
+			} `json:"day"`
+		} `json:"forecastday"`
+	} `json:"forecast"`
+}
+
+func (r *weatherAPIHistoryResponse) toHistoryData() *zipcode.HistoryData {
+	history := &zipcode.HistoryData{
+		Days: make([]zipcode.HistoricalDay, 0, len(r.Forecast.ForecastDay)),
+	}
+
+	for _, day := range r.Forecast.ForecastDay {
+		history.Days = append(history.Days, zipcode.HistoricalDay{
+			Date:          day.Date,
+			MinTempC:      day.Day.MinTempC,
+			MaxTempC:      day.Day.MaxTempC,
+			AvgTempC:      day.Day.AvgTempC,
+			TotalPrecipMm: day.Day.TotalPrecipMm,
+			AvgHumidity:   int(day.Day.AvgHumidity),
+			Condition:     day.Day.Condition.toCondition(),
+		})
+	}
+
+	return history
+}
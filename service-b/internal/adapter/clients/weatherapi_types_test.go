@@ -0,0 +1,86 @@
+package clients
+
+import (
+	"encoding/json"
+	"testing"
+
+	"go-a-b-microservices/pkg/zipcode"
+)
+
+const weatherAPIFixture = `{
+	"current": {
+		"temp_c": 28.3,
+		"feelslike_c": 30.1,
+		"humidity": 62,
+		"is_day": 1,
+		"condition": {"text": "Partly cloudy", "icon": "//cdn.weatherapi.com/116.png"},
+		"air_quality": {"co": 223.6, "pm2_5": 8.1, "us-epa-index": 1, "gb-defra-index": 1}
+	},
+	"alerts": {
+		"alert": [
+			{
+				"headline": "Heavy rain",
+				"severity": "Severe",
+				"event": "Rain",
+				"effective": "2025-05-01T10:00:00-03:00",
+				"expires": "2025-05-02T10:00:00-03:00",
+				"desc": "Accumulated rain above 50mm"
+			}
+		]
+	}
+}`
+
+func TestWeatherAPIResponse_toWeatherData(t *testing.T) {
+	var response weatherAPIResponse
+	if err := json.Unmarshal([]byte(weatherAPIFixture), &response); err != nil {
+		t.Fatalf("Failed to unmarshal fixture: %v", err)
+	}
+
+	weather := response.toWeatherData(zipcode.WeatherOptions{Alerts: true, AirQuality: true})
+
+	if weather.Current.TempC != 28.3 || weather.Current.FeelsLikeC == nil || *weather.Current.FeelsLikeC != 30.1 {
+		t.Errorf("Unexpected temperatures %+v", weather.Current)
+	}
+
+	if weather.Current.IsDay == nil || !*weather.Current.IsDay {
+		t.Errorf("Expected is_day to be true, got %v", weather.Current.IsDay)
+	}
+
+	if weather.Current.Condition == nil || weather.Current.Condition.Text != "Partly cloudy" {
+		t.Errorf("Unexpected condition %+v", weather.Current.Condition)
+	}
+
+	if weather.Current.WindKph != nil {
+		t.Errorf("Expected missing wind speed to stay nil, got %v", *weather.Current.WindKph)
+	}
+
+	if len(weather.Alerts) != 1 {
+		t.Fatalf("Expected one alert, got %d", len(weather.Alerts))
+	}
+
+	alert := weather.Alerts[0]
+	if alert.Severity != zipcode.SeveritySevere || alert.Description != "Accumulated rain above 50mm" {
+		t.Errorf("Unexpected alert %+v", alert)
+	}
+
+	if alert.Effective == nil || alert.Expires == nil || !alert.Expires.After(*alert.Effective) {
+		t.Errorf("Expected alert times to be parsed, got %v and %v", alert.Effective, alert.Expires)
+	}
+
+	if weather.AirQuality == nil || weather.AirQuality.Category != "good" || *weather.AirQuality.PM2_5 != 8.1 {
+		t.Errorf("Unexpected air quality %+v", weather.AirQuality)
+	}
+}
+
+func TestWeatherAPIResponse_toWeatherData_NotRequested(t *testing.T) {
+	var response weatherAPIResponse
+	if err := json.Unmarshal([]byte(weatherAPIFixture), &response); err != nil {
+		t.Fatalf("Failed to unmarshal fixture: %v", err)
+	}
+
+	weather := response.toWeatherData(zipcode.WeatherOptions{})
+
+	if weather.Alerts != nil || weather.AirQuality != nil {
+		t.Errorf("Expected no alerts or air quality when not requested, got %+v and %+v", weather.Alerts, weather.AirQuality)
+	}
+}
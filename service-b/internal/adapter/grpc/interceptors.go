@@ -0,0 +1,117 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+
+	"go-a-b-microservices/pkg/apperror"
+	"go-a-b-microservices/pkg/hmacauth"
+	"go-a-b-microservices/pkg/i18n"
+	"go-a-b-microservices/pkg/logger"
+	"go-a-b-microservices/pkg/weatherpb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+// LanguageUnaryInterceptor negotiates the language from the accept-language
+// metadata, as i18n.Middleware does for HTTP.
+func LanguageUnaryInterceptor(fallback i18n.Language) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(withLanguage(ctx, fallback), req)
+	}
+}
+
+func LanguageStreamInterceptor(fallback i18n.Language) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &serverStream{ServerStream: stream, ctx: withLanguage(stream.Context(), fallback)})
+	}
+}
+
+// AuthUnaryInterceptor rejects calls whose request is not signed by a known
+// key. Signatures cover the full method name and the deterministic encoding
+// of the request message.
+func AuthUnaryInterceptor(verifier *hmacauth.Verifier, log logger.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := verify(ctx, verifier, info.FullMethod, req); err != nil {
+			log.Error("Rejected unsigned call to %s: %v", info.FullMethod, err)
+			return nil, weatherpb.StatusError(i18n.FromContext(ctx), apperror.ErrUnauthorized)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// AuthStreamInterceptor verifies the request message of server streaming
+// calls when the handler receives it.
+func AuthStreamInterceptor(verifier *hmacauth.Verifier, log logger.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &verifiedStream{ServerStream: stream, verifier: verifier, method: info.FullMethod, logger: log})
+	}
+}
+
+func withLanguage(ctx context.Context, fallback i18n.Language) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	return i18n.WithLanguage(ctx, i18n.Negotiate(strings.Join(md.Get(weatherpb.MetadataLanguage), ","), fallback))
+}
+
+func verify(ctx context.Context, verifier *hmacauth.Verifier, method string, req interface{}) error {
+	message, ok := req.(proto.Message)
+	if !ok {
+		return hmacauth.ErrInvalidSignature
+	}
+
+	body, err := proto.MarshalOptions{Deterministic: true}.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	signature := hmacauth.Signature{
+		KeyID:     first(md.Get(strings.ToLower(hmacauth.HeaderKeyID))),
+		Timestamp: first(md.Get(strings.ToLower(hmacauth.HeaderTimestamp))),
+		Nonce:     first(md.Get(strings.ToLower(hmacauth.HeaderNonce))),
+		Signature: first(md.Get(strings.ToLower(hmacauth.HeaderSignature))),
+	}
+	return verifier.VerifyValues(signature, "POST", method, body)
+}
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+type verifiedStream struct {
+	grpc.ServerStream
+	verifier *hmacauth.Verifier
+	method   string
+	logger   logger.Logger
+	verified bool
+}
+
+func (s *verifiedStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if s.verified {
+		return nil
+	}
+
+	if err := verify(s.Context(), s.verifier, s.method, m); err != nil {
+		s.logger.Error("Rejected unsigned call to %s: %v", s.method, err)
+		return weatherpb.StatusError(i18n.FromContext(s.Context()), apperror.ErrUnauthorized)
+	}
+	s.verified = true
+	return nil
+}
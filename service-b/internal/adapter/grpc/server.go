@@ -0,0 +1,128 @@
+package grpc
+
+import (
+	"context"
+
+	"go-a-b-microservices/pkg/apperror"
+	"go-a-b-microservices/pkg/i18n"
+	"go-a-b-microservices/pkg/logger"
+	"go-a-b-microservices/pkg/weatherpb"
+	"go-a-b-microservices/pkg/zipcode"
+	"go-a-b-microservices/service-b/internal/usecase"
+
+	"go.opentelemetry.io/otel"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// Server exposes the same use cases as the HTTP handler over gRPC.
+type Server struct {
+	weatherpb.UnimplementedWeatherServiceServer
+	zipCodeUseCase *usecase.ZipCodeUseCase
+	logger         logger.Logger
+}
+
+func NewServer(zipCodeUseCase *usecase.ZipCodeUseCase, logger logger.Logger) *Server {
+	return &Server{
+		zipCodeUseCase: zipCodeUseCase,
+		logger:         logger,
+	}
+}
+
+func (s *Server) Register(server *grpc.Server) {
+	weatherpb.RegisterWeatherServiceServer(server, s)
+}
+
+func (s *Server) GetWeather(ctx context.Context, request *weatherpb.WeatherRequest) (*weatherpb.WeatherResponse, error) {
+	tracer := otel.Tracer("service-b")
+	ctx, span := tracer.Start(ctx, "grpc.GetWeather")
+	defer span.End()
+
+	response, err := s.zipCodeUseCase.ProcessZipCode(ctx, request.ZipCodeRequest())
+	if err != nil {
+		return nil, s.statusError(ctx, err)
+	}
+
+	return weatherpb.NewWeatherResponse(response), nil
+}
+
+func (s *Server) GetForecast(ctx context.Context, request *weatherpb.ForecastRequest) (*weatherpb.ForecastResponse, error) {
+	tracer := otel.Tracer("service-b")
+	ctx, span := tracer.Start(ctx, "grpc.GetForecast")
+	defer span.End()
+
+	response, err := s.zipCodeUseCase.ProcessForecast(ctx, request.ZipCodeRequest())
+	if err != nil {
+		return nil, s.statusError(ctx, err)
+	}
+
+	return weatherpb.NewForecastResponse(response), nil
+}
+
+func (s *Server) GetHistory(ctx context.Context, request *weatherpb.HistoryRequest) (*weatherpb.HistoryResponse, error) {
+	tracer := otel.Tracer("service-b")
+	ctx, span := tracer.Start(ctx, "grpc.GetHistory")
+	defer span.End()
+
+	response, err := s.zipCodeUseCase.ProcessHistory(ctx, request.ZipCodeRequest())
+	if err != nil {
+		return nil, s.statusError(ctx, err)
+	}
+
+	return weatherpb.NewHistoryResponse(response), nil
+}
+
+// GetWeatherBatch processes the requests concurrently and streams each result
+// as it completes. A failed request is reported in its result and does not
+// end the stream.
+func (s *Server) GetWeatherBatch(request *weatherpb.WeatherBatchRequest, stream grpc.ServerStreamingServer[weatherpb.WeatherBatchResult]) error {
+	tracer := otel.Tracer("service-b")
+	ctx, span := tracer.Start(stream.Context(), "grpc.GetWeatherBatch")
+	defer span.End()
+
+	requests := make([]zipcode.ZipCodeRequest, len(request.GetRequests()))
+	for i, weatherRequest := range request.GetRequests() {
+		requests[i] = *weatherRequest.ZipCodeRequest()
+	}
+
+	lang := i18n.FromContext(ctx)
+	var sendErr error
+	err := s.zipCodeUseCase.ProcessZipCodeBatch(ctx, requests, func(index int, response *zipcode.WeatherResponse, err error) error {
+		result := &weatherpb.WeatherBatchResult{Index: int32(index)}
+		if err != nil {
+			result.Result = &weatherpb.WeatherBatchResult_Error{Error: weatherpb.NewError(lang, publicError(err))}
+		} else {
+			result.Result = &weatherpb.WeatherBatchResult_Weather{Weather: weatherpb.NewWeatherResponse(response)}
+		}
+
+		if sendErr = stream.Send(result); sendErr != nil {
+			s.logger.Error("Failed to send batch result: %v", sendErr)
+		}
+		return sendErr
+	})
+	switch {
+	case sendErr != nil:
+		return sendErr
+	case ctx.Err() != nil:
+		return status.FromContextError(ctx.Err()).Err()
+	case err != nil:
+		return s.statusError(ctx, err)
+	}
+
+	return nil
+}
+
+func (s *Server) statusError(ctx context.Context, err error) error {
+	if apperror.Code(err) == apperror.CodeInternal {
+		s.logger.Error("Failed to process request: %v", err)
+	}
+	return weatherpb.StatusError(i18n.FromContext(ctx), publicError(err))
+}
+
+// publicError reports a missing ZIP code as invalid, as the HTTP API does.
+func publicError(err error) error {
+	if apperror.Code(err) == apperror.CodeZipCodeRequired {
+		return apperror.Replace(err, apperror.ErrZipCodeInvalid)
+	}
+	return err
+}
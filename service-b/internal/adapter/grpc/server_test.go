@@ -0,0 +1,221 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"go-a-b-microservices/pkg/apperror"
+	"go-a-b-microservices/pkg/hmacauth"
+	"go-a-b-microservices/pkg/i18n"
+	"go-a-b-microservices/pkg/weatherpb"
+	"go-a-b-microservices/pkg/zipcode"
+	"go-a-b-microservices/service-b/internal/usecase"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+)
+
+type MockZipCodeRepository struct {
+	GetLocationByZipCodeFunc func(ctx context.Context, zipCode string) (*zipcode.Location, error)
+	GetWeatherByCityFunc     func(ctx context.Context, city string, options zipcode.WeatherOptions) (*zipcode.WeatherData, error)
+}
+
+func (m *MockZipCodeRepository) GetLocationByZipCode(ctx context.Context, zipCode string) (*zipcode.Location, error) {
+	return m.GetLocationByZipCodeFunc(ctx, zipCode)
+}
+
+func (m *MockZipCodeRepository) GetWeatherByCity(ctx context.Context, city string, options zipcode.WeatherOptions) (*zipcode.WeatherData, error) {
+	return m.GetWeatherByCityFunc(ctx, city, options)
+}
+
+func (m *MockZipCodeRepository) GetForecastByCity(ctx context.Context, city string, days int) (*zipcode.ForecastData, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockZipCodeRepository) GetHistoryByCity(ctx context.Context, city string, start, end time.Time) (*zipcode.HistoryData, error) {
+	return nil, errors.New("not implemented")
+}
+
+type MockLogger struct{}
+
+func (m *MockLogger) Info(message string, args ...interface{})  {}
+func (m *MockLogger) Error(message string, args ...interface{}) {}
+func (m *MockLogger) Debug(message string, args ...interface{}) {}
+
+var testKeys = []hmacauth.Key{{ID: "k1", Secret: []byte("secret")}}
+
+func startServer(t *testing.T) weatherpb.WeatherServiceClient {
+	t.Helper()
+
+	repository := &MockZipCodeRepository{
+		GetLocationByZipCodeFunc: func(ctx context.Context, zipCode string) (*zipcode.Location, error) {
+			if zipCode == "99999999" {
+				return nil, apperror.ErrZipCodeNotFound
+			}
+			return &zipcode.Location{City: "São Paulo", CEP: zipCode}, nil
+		},
+		GetWeatherByCityFunc: func(ctx context.Context, city string, options zipcode.WeatherOptions) (*zipcode.WeatherData, error) {
+			return &zipcode.WeatherData{Current: zipcode.CurrentWeather{TempC: 25}}, nil
+		},
+	}
+
+	verifier, err := hmacauth.NewVerifier(testKeys, time.Minute)
+	if err != nil {
+		t.Fatalf("Failed to build verifier: %v", err)
+	}
+
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(LanguageUnaryInterceptor(i18n.English), AuthUnaryInterceptor(verifier, &MockLogger{})),
+		grpc.ChainStreamInterceptor(LanguageStreamInterceptor(i18n.English), AuthStreamInterceptor(verifier, &MockLogger{})),
+	)
+	NewServer(usecase.NewZipCodeUseCase(repository, &MockLogger{}), &MockLogger{}).Register(server)
+
+	listener := bufconn.Listen(1 << 20)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return weatherpb.NewWeatherServiceClient(conn)
+}
+
+func signedContext(t *testing.T, method string, message proto.Message, lang string) context.Context {
+	t.Helper()
+
+	signer, _ := hmacauth.NewSigner(testKeys)
+	body, _ := proto.MarshalOptions{Deterministic: true}.Marshal(message)
+	signature := signer.SignValues("POST", method, body)
+
+	return metadata.AppendToOutgoingContext(context.Background(),
+		weatherpb.MetadataLanguage, lang,
+		strings.ToLower(hmacauth.HeaderKeyID), signature.KeyID,
+		strings.ToLower(hmacauth.HeaderTimestamp), signature.Timestamp,
+		strings.ToLower(hmacauth.HeaderNonce), signature.Nonce,
+		strings.ToLower(hmacauth.HeaderSignature), signature.Signature,
+	)
+}
+
+func TestServer_GetWeather(t *testing.T) {
+	client := startServer(t)
+
+	tests := []struct {
+		name         string
+		request      *weatherpb.WeatherRequest
+		unsigned     bool
+		expectedCode codes.Code
+		expectedErr  error
+	}{
+		{name: "valid", request: &weatherpb.WeatherRequest{Cep: "01001000", Units: "C,K"}, expectedCode: codes.OK},
+		{name: "invalid zipcode", request: &weatherpb.WeatherRequest{Cep: "123"}, expectedCode: codes.InvalidArgument, expectedErr: apperror.ErrZipCodeInvalid},
+		{name: "missing zipcode", request: &weatherpb.WeatherRequest{}, expectedCode: codes.InvalidArgument, expectedErr: apperror.ErrZipCodeInvalid},
+		{name: "not found", request: &weatherpb.WeatherRequest{Cep: "99999999"}, expectedCode: codes.NotFound, expectedErr: apperror.ErrZipCodeNotFound},
+		{name: "unsigned", request: &weatherpb.WeatherRequest{Cep: "01001000"}, unsigned: true, expectedCode: codes.Unauthenticated, expectedErr: apperror.ErrUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := signedContext(t, weatherpb.WeatherService_GetWeather_FullMethodName, tt.request, "pt-BR")
+			if tt.unsigned {
+				ctx = context.Background()
+			}
+
+			response, err := client.GetWeather(ctx, tt.request)
+			if status.Code(err) != tt.expectedCode {
+				t.Fatalf("Expected code %v, got %v", tt.expectedCode, err)
+			}
+
+			if tt.expectedErr != nil {
+				if !errors.Is(weatherpb.AppError(err), tt.expectedErr) {
+					t.Errorf("Expected error %v, got %v", tt.expectedErr, weatherpb.AppError(err))
+				}
+				return
+			}
+
+			weather := response.WeatherResponse()
+			if weather.City != "São Paulo" || *weather.TempC != 25 || *weather.TempK != 298.2 || weather.TempF != nil {
+				t.Errorf("Unexpected response %+v", weather)
+			}
+		})
+	}
+}
+
+func TestServer_LocalizedError(t *testing.T) {
+	client := startServer(t)
+
+	request := &weatherpb.WeatherRequest{Cep: "123"}
+	_, err := client.GetWeather(signedContext(t, weatherpb.WeatherService_GetWeather_FullMethodName, request, "es"), request)
+
+	expected := i18n.Message(i18n.Spanish, apperror.CodeZipCodeInvalid)
+	if message := status.Convert(err).Message(); message != expected {
+		t.Errorf("Expected message %q, got %q", expected, message)
+	}
+}
+
+func TestServer_GetWeatherBatch(t *testing.T) {
+	client := startServer(t)
+
+	request := &weatherpb.WeatherBatchRequest{Requests: []*weatherpb.WeatherRequest{
+		{Cep: "01001000"},
+		{Cep: "123"},
+		{Cep: "99999999"},
+		{Cep: "13484000"},
+	}}
+	stream, err := client.GetWeatherBatch(signedContext(t, weatherpb.WeatherService_GetWeatherBatch_FullMethodName, request, "en"), request)
+	if err != nil {
+		t.Fatalf("Failed to start batch: %v", err)
+	}
+
+	results := make(map[int32]*weatherpb.WeatherBatchResult)
+	for {
+		result, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Unexpected stream error: %v", err)
+		}
+		results[result.GetIndex()] = result
+	}
+
+	if len(results) != 4 {
+		t.Fatalf("Expected 4 results, got %d", len(results))
+	}
+	if results[0].GetWeather().GetCity() != "São Paulo" || results[3].GetWeather() == nil {
+		t.Errorf("Expected weather for valid requests, got %v and %v", results[0], results[3])
+	}
+	if results[1].GetError().GetCode() != apperror.CodeZipCodeInvalid {
+		t.Errorf("Expected invalid_zipcode, got %v", results[1])
+	}
+	if !errors.Is(results[2].GetError().AppError(), apperror.ErrZipCodeNotFound) {
+		t.Errorf("Expected zipcode_not_found, got %v", results[2])
+	}
+}
+
+func TestServer_GetWeatherBatch_Unsigned(t *testing.T) {
+	client := startServer(t)
+
+	stream, err := client.GetWeatherBatch(context.Background(), &weatherpb.WeatherBatchRequest{})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Expected Unauthenticated, got %v", err)
+	}
+}
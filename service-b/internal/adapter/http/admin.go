@@ -0,0 +1,82 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+
+	"go-a-b-microservices/pkg/jsonbody"
+	"go-a-b-microservices/service-b/internal/adapter/clients"
+	"go-a-b-microservices/service-b/internal/repository"
+)
+
+// AdminHandler serves operational endpoints. It is meant for a port that is
+// not exposed to callers of the API.
+type AdminHandler struct {
+	budget *clients.Budget
+	warmer *repository.WeatherWarmer
+}
+
+func NewAdminHandler(budget *clients.Budget) *AdminHandler {
+	return &AdminHandler{budget: budget}
+}
+
+// SetWeatherWarmer serves the hot set of warmer on /admin/warmer.
+func (h *AdminHandler) SetWeatherWarmer(warmer *repository.WeatherWarmer) {
+	h.warmer = warmer
+}
+
+func (h *AdminHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/admin/budget", h.Budget)
+	if h.warmer != nil {
+		mux.HandleFunc("/admin/warmer", h.Warmer)
+	}
+	mux.HandleFunc("/metrics", h.Metrics)
+}
+
+func (h *AdminHandler) Budget(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	jsonbody.Write(w, http.StatusOK, h.budget.Usage())
+}
+
+// Warmer lists the cities kept warm, most requested first.
+func (h *AdminHandler) Warmer(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	jsonbody.Write(w, http.StatusOK, h.warmer.HotSet())
+}
+
+// Metrics writes the budget in the Prometheus text format.
+func (h *AdminHandler) Metrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	usage := h.budget.Usage()
+	degraded := 0
+	if usage.Degraded {
+		degraded = 1
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	for _, metric := range []struct {
+		name, kind, help string
+		value            int
+	}{
+		{"weatherapi_budget_monthly_limit", "gauge", "Monthly WeatherAPI call limit, 0 when unlimited.", usage.MonthlyLimit},
+		{"weatherapi_budget_monthly_used", "gauge", "WeatherAPI calls made this month.", usage.MonthlyUsed},
+		{"weatherapi_budget_minute_limit", "gauge", "Per-minute WeatherAPI call limit, 0 when unlimited.", usage.MinuteLimit},
+		{"weatherapi_budget_minute_used", "gauge", "WeatherAPI calls made this minute.", usage.MinuteUsed},
+		{"weatherapi_budget_degraded", "gauge", "1 while calls are served by the degrade mode.", degraded},
+		{"weatherapi_budget_denied_total", "counter", "Calls kept from WeatherAPI by the budget.", usage.DeniedCalls},
+	} {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %d\n", metric.name, metric.help, metric.name, metric.kind, metric.name, metric.value)
+	}
+}
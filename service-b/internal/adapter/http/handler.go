@@ -1,11 +1,11 @@
 package http
 
 import (
-	"encoding/json"
-	"io"
 	"net/http"
 
 	"go-a-b-microservices/pkg/apperror"
+	"go-a-b-microservices/pkg/i18n"
+	"go-a-b-microservices/pkg/jsonbody"
 	"go-a-b-microservices/pkg/logger"
 	"go-a-b-microservices/pkg/zipcode"
 	"go-a-b-microservices/service-b/internal/usecase"
@@ -16,17 +16,27 @@ import (
 type Handler struct {
 	zipCodeUseCase *usecase.ZipCodeUseCase
 	logger         logger.Logger
+	decoder        *jsonbody.Decoder
 }
 
 func NewHandler(zipCodeUseCase *usecase.ZipCodeUseCase, logger logger.Logger) *Handler {
 	return &Handler{
 		zipCodeUseCase: zipCodeUseCase,
 		logger:         logger,
+		decoder:        jsonbody.NewDecoder(jsonbody.DefaultMaxBytes),
 	}
 }
 
+// SetMaxBodyBytes sets the size limit of request bodies, also while serving.
+func (h *Handler) SetMaxBodyBytes(maxBytes int64) {
+	h.decoder.SetMaxBytes(maxBytes)
+}
+
 func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("/weather", h.ProcessZipCode)
+	mux.HandleFunc("/weather/batch", h.ProcessZipCodeBatch)
+	mux.HandleFunc("/forecast", h.ProcessForecast)
+	mux.HandleFunc("/history", h.ProcessHistory)
 }
 
 func (h *Handler) ProcessZipCode(w http.ResponseWriter, r *http.Request) {
@@ -39,49 +49,134 @@ func (h *Handler) ProcessZipCode(w http.ResponseWriter, r *http.Request) {
 	ctx, span := tracer.Start(r.Context(), "http.ProcessZipCode")
 	defer span.End()
 
-	body, err := io.ReadAll(r.Body)
-	if err != nil {
-		h.logger.Error("Failed to read request body: %v", err)
-		writeJSONResponse(w, http.StatusBadRequest, map[string]string{"message": "invalid request"})
-		return
-	}
-	defer r.Body.Close()
-
 	var request zipcode.ZipCodeRequest
-	if err := json.Unmarshal(body, &request); err != nil {
-		h.logger.Error("Failed to parse JSON: %v", err)
-		writeJSONResponse(w, http.StatusBadRequest, map[string]string{"message": "invalid request"})
+	if err := h.decoder.Decode(w, r, &request); err != nil {
+		h.logger.Error("Failed to decode request: %v", err)
+		h.writeErrorResponse(w, r, err)
 		return
 	}
 
 	if err := request.Validate(); err != nil {
 		h.logger.Error("Invalid ZIP code: %v", err)
-		writeJSONResponse(w, http.StatusUnprocessableEntity, map[string]string{"message": apperror.ErrZipCodeInvalid.Error()})
+		h.writeErrorResponse(w, r, err)
 		return
 	}
 
 	response, err := h.zipCodeUseCase.ProcessZipCode(ctx, &request)
 	if err != nil {
-		switch err.Error() {
-		case apperror.ErrZipCodeInvalid.Error():
-			writeJSONResponse(w, http.StatusUnprocessableEntity, map[string]string{"message": apperror.ErrZipCodeInvalid.Error()})
-		case apperror.ErrZipCodeNotFound.Error():
-			writeJSONResponse(w, http.StatusNotFound, map[string]string{"message": apperror.ErrZipCodeNotFound.Error()})
-		default:
-			h.logger.Error("Failed to process ZIP code: %v", err)
-			writeJSONResponse(w, http.StatusInternalServerError, map[string]string{"message": "internal server error"})
+		h.writeErrorResponse(w, r, err)
+		return
+	}
+
+	jsonbody.Write(w, http.StatusOK, response)
+}
+
+// ProcessZipCodeBatch answers several weather requests at once. Each result
+// carries either the weather or the localized error of its request, so one
+// failed ZIP code does not fail the batch.
+func (h *Handler) ProcessZipCodeBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	tracer := otel.Tracer("service-b")
+	ctx, span := tracer.Start(r.Context(), "http.ProcessZipCodeBatch")
+	defer span.End()
+
+	var request zipcode.WeatherBatchRequest
+	if err := h.decoder.Decode(w, r, &request); err != nil {
+		h.logger.Error("Failed to decode request: %v", err)
+		h.writeErrorResponse(w, r, err)
+		return
+	}
+
+	lang := i18n.FromContext(ctx)
+	response := zipcode.WeatherBatchResponse{Results: make([]zipcode.WeatherBatchResult, len(request.Requests))}
+	err := h.zipCodeUseCase.ProcessZipCodeBatch(ctx, request.Requests, func(index int, weather *zipcode.WeatherResponse, err error) error {
+		result := zipcode.WeatherBatchResult{Index: index, Weather: weather}
+		if err != nil {
+			if apperror.Code(err) == apperror.CodeZipCodeRequired {
+				err = apperror.Replace(err, apperror.ErrZipCodeInvalid)
+			}
+			errResp := i18n.Localize(lang, err)
+			result.Error = &errResp
 		}
+		response.Results[index] = result
+		return nil
+	})
+	if err != nil {
+		h.writeErrorResponse(w, r, err)
 		return
 	}
 
-	writeJSONResponse(w, http.StatusOK, response)
+	jsonbody.Write(w, http.StatusOK, response)
 }
 
-func writeJSONResponse(w http.ResponseWriter, statusCode int, data interface{}) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
+func (h *Handler) ProcessForecast(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	tracer := otel.Tracer("service-b")
+	ctx, span := tracer.Start(r.Context(), "http.ProcessForecast")
+	defer span.End()
 
-	if err := json.NewEncoder(w).Encode(data); err != nil {
-		http.Error(w, "Error encoding response", http.StatusInternalServerError)
+	var request zipcode.ForecastRequest
+	if err := h.decoder.Decode(w, r, &request); err != nil {
+		h.logger.Error("Failed to decode request: %v", err)
+		h.writeErrorResponse(w, r, err)
+		return
+	}
+
+	response, err := h.zipCodeUseCase.ProcessForecast(ctx, &request)
+	if err != nil {
+		h.writeErrorResponse(w, r, err)
+		return
 	}
+
+	jsonbody.Write(w, http.StatusOK, response)
+}
+
+func (h *Handler) ProcessHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	tracer := otel.Tracer("service-b")
+	ctx, span := tracer.Start(r.Context(), "http.ProcessHistory")
+	defer span.End()
+
+	var request zipcode.HistoryRequest
+	if err := h.decoder.Decode(w, r, &request); err != nil {
+		h.logger.Error("Failed to decode request: %v", err)
+		h.writeErrorResponse(w, r, err)
+		return
+	}
+
+	response, err := h.zipCodeUseCase.ProcessHistory(ctx, &request)
+	if err != nil {
+		h.writeErrorResponse(w, r, err)
+		return
+	}
+
+	jsonbody.Write(w, http.StatusOK, response)
+}
+
+// writeErrorResponse maps err to a status code and writes its code and message
+// in the language negotiated for the request.
+func (h *Handler) writeErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	status := apperror.HTTPStatus(err)
+	if status == http.StatusInternalServerError {
+		h.logger.Error("Failed to process request: %v", err)
+	}
+
+	switch apperror.Code(err) {
+	case apperror.CodeZipCodeRequired, apperror.CodeZipCodeInvalid:
+		err = apperror.Replace(err, apperror.ErrZipCodeInvalid)
+	}
+
+	jsonbody.Write(w, status, i18n.Localize(i18n.FromContext(r.Context()), err))
 }
@@ -0,0 +1,262 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go-a-b-microservices/pkg/apperror"
+	"go-a-b-microservices/pkg/i18n"
+	"go-a-b-microservices/pkg/openapi"
+	"go-a-b-microservices/pkg/zipcode"
+	"go-a-b-microservices/service-b/internal/usecase"
+)
+
+type MockZipCodeRepository struct {
+	GetLocationByZipCodeFunc func(ctx context.Context, zipCode string) (*zipcode.Location, error)
+	GetWeatherByCityFunc     func(ctx context.Context, city string, options zipcode.WeatherOptions) (*zipcode.WeatherData, error)
+	GetForecastByCityFunc    func(ctx context.Context, city string, days int) (*zipcode.ForecastData, error)
+	GetHistoryByCityFunc     func(ctx context.Context, city string, start, end time.Time) (*zipcode.HistoryData, error)
+}
+
+func (m *MockZipCodeRepository) GetLocationByZipCode(ctx context.Context, zipCode string) (*zipcode.Location, error) {
+	return m.GetLocationByZipCodeFunc(ctx, zipCode)
+}
+
+func (m *MockZipCodeRepository) GetWeatherByCity(ctx context.Context, city string, options zipcode.WeatherOptions) (*zipcode.WeatherData, error) {
+	return m.GetWeatherByCityFunc(ctx, city, options)
+}
+
+func (m *MockZipCodeRepository) GetForecastByCity(ctx context.Context, city string, days int) (*zipcode.ForecastData, error) {
+	return m.GetForecastByCityFunc(ctx, city, days)
+}
+
+func (m *MockZipCodeRepository) GetHistoryByCity(ctx context.Context, city string, start, end time.Time) (*zipcode.HistoryData, error) {
+	return m.GetHistoryByCityFunc(ctx, city, start, end)
+}
+
+type MockLogger struct{}
+
+func (m *MockLogger) Info(message string, args ...interface{})  {}
+func (m *MockLogger) Error(message string, args ...interface{}) {}
+func (m *MockLogger) Debug(message string, args ...interface{}) {}
+
+func newMockRepository() *MockZipCodeRepository {
+	return &MockZipCodeRepository{
+		GetLocationByZipCodeFunc: func(ctx context.Context, zipCode string) (*zipcode.Location, error) {
+			if zipCode == "99999999" {
+				return nil, apperror.ErrZipCodeNotFound
+			}
+			return &zipcode.Location{City: "São Paulo", CEP: zipCode}, nil
+		},
+		GetWeatherByCityFunc: func(ctx context.Context, city string, options zipcode.WeatherOptions) (*zipcode.WeatherData, error) {
+			weather := &zipcode.WeatherData{Current: zipcode.CurrentWeather{TempC: 25}}
+			if options.Alerts {
+				weather.Alerts = []zipcode.Alert{{Headline: "Heavy rain", Severity: zipcode.SeverityModerate}}
+			}
+			return weather, nil
+		},
+		GetForecastByCityFunc: func(ctx context.Context, city string, days int) (*zipcode.ForecastData, error) {
+			return &zipcode.ForecastData{Days: []zipcode.DailyForecast{
+				{Date: "2024-01-01", MinTempC: 18, MaxTempC: 28, ChanceOfRain: 40},
+			}}, nil
+		},
+		GetHistoryByCityFunc: func(ctx context.Context, city string, start, end time.Time) (*zipcode.HistoryData, error) {
+			return &zipcode.HistoryData{Days: []zipcode.HistoricalDay{
+				{Date: start.Format(zipcode.DateLayout), MinTempC: 18, MaxTempC: 28, AvgTempC: 23, AvgHumidity: 70},
+			}}, nil
+		},
+	}
+}
+
+// TestHandlerMatchesOpenAPI checks that the responses of the handlers, behind
+// the validation middleware, match the document served by Service B.
+func TestHandlerMatchesOpenAPI(t *testing.T) {
+	doc, err := OpenAPI()
+	if err != nil {
+		t.Fatalf("OpenAPI() error = %v", err)
+	}
+	validator, err := openapi.NewValidator(doc)
+	if err != nil {
+		t.Fatalf("NewValidator() error = %v", err)
+	}
+
+	mux := http.NewServeMux()
+	NewHandler(usecase.NewZipCodeUseCase(newMockRepository(), &MockLogger{}), &MockLogger{}).RegisterRoutes(mux)
+	handler := openapi.Middleware(validator, &MockLogger{}, mux)
+
+	yesterday := time.Now().AddDate(0, 0, -1).Format(zipcode.DateLayout)
+
+	tests := []struct {
+		name           string
+		path           string
+		body           string
+		expectedStatus int
+	}{
+		{name: "Weather", path: "/weather", body: `{"cep":"01001000"}`, expectedStatus: http.StatusOK},
+		{name: "Weather with alerts", path: "/weather", body: `{"cep":"01001000","units":"F","alerts":true}`, expectedStatus: http.StatusOK},
+		{name: "Weather not found", path: "/weather", body: `{"cep":"99999999"}`, expectedStatus: http.StatusNotFound},
+		{name: "Weather invalid CEP", path: "/weather", body: `{"cep":"123"}`, expectedStatus: http.StatusUnprocessableEntity},
+		{name: "Weather wrong type", path: "/weather", body: `{"cep":12345678}`, expectedStatus: http.StatusBadRequest},
+		{name: "Batch", path: "/weather/batch", body: `{"requests":[{"cep":"01001000"},{"cep":"99999999"}]}`, expectedStatus: http.StatusOK},
+		{name: "Batch invalid CEP", path: "/weather/batch", body: `{"requests":[{"cep":"01001000"},{"cep":"123"}]}`, expectedStatus: http.StatusUnprocessableEntity},
+		{name: "Forecast", path: "/forecast", body: `{"cep":"01001000","days":1}`, expectedStatus: http.StatusOK},
+		{name: "Forecast invalid days", path: "/forecast", body: `{"cep":"01001000","days":30}`, expectedStatus: http.StatusUnprocessableEntity},
+		{name: "History", path: "/history", body: `{"cep":"01001000","date":"` + yesterday + `"}`, expectedStatus: http.StatusOK},
+		{name: "History out of range", path: "/history", body: `{"cep":"01001000","date":"1990-01-01"}`, expectedStatus: http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, rec.Code, rec.Body.String())
+			}
+
+			req = httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			if err := validator.ValidateResponse(req, rec.Code, rec.Header(), rec.Body.Bytes()); err != nil {
+				t.Errorf("Response does not match the document: %v\n%s", err, rec.Body.String())
+			}
+		})
+	}
+}
+
+// TestHandler_StrictDecoding checks the handlers on their own, without the
+// validation middleware in front of them.
+func TestHandler_StrictDecoding(t *testing.T) {
+	handler := NewHandler(usecase.NewZipCodeUseCase(newMockRepository(), &MockLogger{}), &MockLogger{})
+	handler.SetMaxBodyBytes(64)
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux)
+
+	tests := []struct {
+		name           string
+		contentType    string
+		body           string
+		expectedStatus int
+		expectedCode   string
+		expectedField  string
+	}{
+		{
+			name:           "Valid request",
+			contentType:    "application/json; charset=utf-8",
+			body:           `{"cep":"01001000"}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Unknown field",
+			contentType:    "application/json",
+			body:           `{"zip":"01001000"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   apperror.CodeInvalidRequest,
+			expectedField:  "/zip",
+		},
+		{
+			name:           "Trailing data",
+			contentType:    "application/json",
+			body:           `{"cep":"01001000"} {}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   apperror.CodeInvalidRequest,
+			expectedField:  "/",
+		},
+		{
+			name:           "Wrong type",
+			contentType:    "application/json",
+			body:           `{"cep":"01001000","alerts":"yes"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   apperror.CodeInvalidRequest,
+			expectedField:  "/alerts",
+		},
+		{
+			name:           "Missing content type",
+			body:           `{"cep":"01001000"}`,
+			expectedStatus: http.StatusUnsupportedMediaType,
+			expectedCode:   apperror.CodeUnsupportedMediaType,
+		},
+		{
+			name:           "Too large",
+			contentType:    "application/json",
+			body:           `{"cep":"01001000","units":"` + strings.Repeat("C,", 32) + `C"}`,
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectedCode:   apperror.CodeRequestTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/weather", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			req = req.WithContext(i18n.WithLanguage(req.Context(), i18n.English))
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, rec.Code, rec.Body.String())
+			}
+			if tt.expectedCode == "" {
+				return
+			}
+
+			var response apperror.Response
+			if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if response.Code != tt.expectedCode {
+				t.Errorf("Expected code %q, got %q", tt.expectedCode, response.Code)
+			}
+			if tt.expectedField == "" {
+				return
+			}
+			if len(response.Fields) != 1 || response.Fields[0].Field != tt.expectedField {
+				t.Errorf("Expected field %q, got %+v", tt.expectedField, response.Fields)
+			}
+		})
+	}
+}
+
+func TestHandler_WriteErrorResponseKeepsFields(t *testing.T) {
+	handler := NewHandler(usecase.NewZipCodeUseCase(newMockRepository(), &MockLogger{}), &MockLogger{})
+	fields := []apperror.FieldError{{Field: "/cep", Message: "is required"}}
+
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{name: "Required", err: &apperror.ValidationError{Err: apperror.ErrZipCodeRequired, Fields: fields}},
+		{name: "Invalid", err: &apperror.ValidationError{Err: apperror.ErrZipCodeInvalid, Fields: fields}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/weather", nil)
+			req = req.WithContext(i18n.WithLanguage(req.Context(), i18n.English))
+			rec := httptest.NewRecorder()
+			handler.writeErrorResponse(rec, req, tt.err)
+
+			if rec.Code != http.StatusUnprocessableEntity {
+				t.Fatalf("Expected status 422, got %d", rec.Code)
+			}
+
+			var response apperror.Response
+			if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if response.Code != apperror.CodeZipCodeInvalid {
+				t.Errorf("Expected code %q, got %q", apperror.CodeZipCodeInvalid, response.Code)
+			}
+			if len(response.Fields) != 1 || response.Fields[0] != fields[0] {
+				t.Errorf("Expected fields %v, got %v", fields, response.Fields)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package http
+
+import (
+	"net/http"
+
+	"go-a-b-microservices/pkg/hmacauth"
+	"go-a-b-microservices/pkg/openapi"
+	"go-a-b-microservices/pkg/zipcode"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// errorStatuses are the error responses of the weather endpoints, including
+// 401 for requests without a valid signature.
+var errorStatuses = []int{
+	http.StatusBadRequest,
+	http.StatusUnauthorized,
+	http.StatusNotFound,
+	http.StatusRequestEntityTooLarge,
+	http.StatusUnsupportedMediaType,
+	http.StatusUnprocessableEntity,
+	http.StatusInternalServerError,
+	http.StatusServiceUnavailable,
+}
+
+// OpenAPI describes the endpoints of Service B. Request and response schemas
+// are generated from the types in pkg/zipcode.
+func OpenAPI() (*openapi3.T, error) {
+	doc, err := openapi.NewDocument(
+		"Service B",
+		"Weather lookups for Service A. Every request must be signed with a SERVICE_AUTH_KEYS key.",
+		"1.0.0",
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	doc.AddOperation("/weather", http.MethodPost, openapi.JSONOperation("getWeather", "Current weather for a CEP",
+		openapi.SchemaRef(zipcode.ZipCodeRequest{}), openapi.SchemaRef(zipcode.WeatherResponse{}), errorStatuses...))
+
+	doc.AddOperation("/weather/batch", http.MethodPost, openapi.JSONOperation("getWeatherBatch", "Current weather for up to 100 CEPs",
+		openapi.SchemaRef(zipcode.WeatherBatchRequest{}), openapi.SchemaRef(zipcode.WeatherBatchResponse{}), errorStatuses...))
+
+	doc.AddOperation("/forecast", http.MethodPost, openapi.JSONOperation("getForecast", "Daily forecast for a CEP",
+		openapi.SchemaRef(zipcode.ForecastRequest{}), openapi.SchemaRef(zipcode.ForecastResponse{}), errorStatuses...))
+
+	doc.AddOperation("/history", http.MethodPost, openapi.JSONOperation("getHistory", "Past weather for a CEP",
+		openapi.SchemaRef(zipcode.HistoryRequest{}), openapi.SchemaRef(zipcode.HistoryResponse{}), errorStatuses...))
+
+	doc.Components.SecuritySchemes = openapi3.SecuritySchemes{}
+	requirement := openapi3.SecurityRequirement{}
+	for name, header := range map[string]string{
+		"signatureKeyId":     hmacauth.HeaderKeyID,
+		"signatureTimestamp": hmacauth.HeaderTimestamp,
+		"signatureNonce":     hmacauth.HeaderNonce,
+		"signature":          hmacauth.HeaderSignature,
+	} {
+		doc.Components.SecuritySchemes[name] = &openapi3.SecuritySchemeRef{
+			Value: openapi3.NewSecurityScheme().WithType("apiKey").WithIn("header").WithName(header),
+		}
+		requirement[name] = []string{}
+	}
+	doc.Security = openapi3.SecurityRequirements{requirement}
+
+	if err := openapi.Validate(doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
@@ -0,0 +1,75 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"go-a-b-microservices/service-b/internal/repository"
+)
+
+// ImportProgress records how far the import of a file has got, so an
+// interrupted import can resume where it stopped.
+type ImportProgress struct {
+	// Source is the absolute path of the file.
+	Source string
+	// Size and ModTime identify the version of the file being imported.
+	Size    int64
+	ModTime time.Time
+	// Records counts the records read, Imported and Rejected how they went.
+	Records   int64
+	Imported  int64
+	Rejected  int64
+	Done      bool
+	UpdatedAt time.Time
+}
+
+// ImportProgress returns the progress recorded for source, if any.
+func (s *LocationStore) ImportProgress(ctx context.Context, source string) (ImportProgress, bool, error) {
+	progress := ImportProgress{Source: source}
+	var modTime, updatedAt int64
+	err := s.db.QueryRowContext(ctx, `
+		SELECT size, mod_time, records, imported, rejected, done, updated_at
+		FROM imports WHERE source = ?`, source,
+	).Scan(&progress.Size, &modTime, &progress.Records, &progress.Imported, &progress.Rejected, &progress.Done, &updatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ImportProgress{}, false, nil
+	}
+	if err != nil {
+		return ImportProgress{}, false, err
+	}
+
+	progress.ModTime = time.Unix(0, modTime)
+	progress.UpdatedAt = time.Unix(updatedAt, 0)
+	return progress, true, nil
+}
+
+// SaveImportBatch stores a batch of imported locations together with the
+// progress it brings the import to, in a single transaction, so the recorded
+// progress never runs ahead of or behind the locations.
+func (s *LocationStore) SaveImportBatch(ctx context.Context, locations []repository.StoredLocation, progress ImportProgress) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := saveLocations(ctx, tx, locations); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO imports (source, size, mod_time, records, imported, rejected, done, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (source) DO UPDATE SET
+			size = excluded.size, mod_time = excluded.mod_time, records = excluded.records,
+			imported = excluded.imported, rejected = excluded.rejected, done = excluded.done,
+			updated_at = excluded.updated_at`,
+		progress.Source, progress.Size, progress.ModTime.UnixNano(), progress.Records,
+		progress.Imported, progress.Rejected, progress.Done, progress.UpdatedAt.Unix(),
+	)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
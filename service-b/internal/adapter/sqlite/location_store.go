@@ -0,0 +1,192 @@
+// Package sqlite keeps the CEP directory of Service B in a local SQLite
+// database.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/url"
+	"time"
+
+	"go-a-b-microservices/service-b/internal/repository"
+
+	"go.opentelemetry.io/otel"
+	_ "modernc.org/sqlite"
+)
+
+// LocationStore is a repository.LocationStore backed by a SQLite file.
+type LocationStore struct {
+	db *sql.DB
+}
+
+// Stats summarizes the stored locations.
+type Stats struct {
+	SchemaVersion int
+	Total         int
+	BySource      map[string]int
+	// Stale counts the locations updated before the time given to Stats.
+	Stale  int
+	Oldest time.Time
+	Newest time.Time
+}
+
+// NewLocationStore opens the database at path, creating it if needed, and
+// brings its schema up to date.
+func NewLocationStore(ctx context.Context, path string) (*LocationStore, error) {
+	// WAL lets lookups read while an import writes; the busy timeout makes
+	// concurrent writers wait for each other instead of failing.
+	dsn := "file:" + (&url.URL{Path: path}).EscapedPath() + "?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := migrate(ctx, db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &LocationStore{db: db}, nil
+}
+
+func (s *LocationStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *LocationStore) GetLocation(ctx context.Context, cep string) (repository.StoredLocation, bool, error) {
+	tracer := otel.Tracer("service-b")
+	ctx, span := tracer.Start(ctx, "sqlite.LocationStore.GetLocation")
+	defer span.End()
+
+	location := repository.StoredLocation{CEP: cep}
+	var updatedAt int64
+	err := s.db.QueryRowContext(ctx,
+		"SELECT city, source, updated_at FROM locations WHERE cep = ?", cep,
+	).Scan(&location.City, &location.Source, &updatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return repository.StoredLocation{}, false, nil
+	}
+	if err != nil {
+		return repository.StoredLocation{}, false, err
+	}
+
+	location.UpdatedAt = time.Unix(updatedAt, 0)
+	return location, true, nil
+}
+
+func (s *LocationStore) SaveLocation(ctx context.Context, location repository.StoredLocation) error {
+	tracer := otel.Tracer("service-b")
+	ctx, span := tracer.Start(ctx, "sqlite.LocationStore.SaveLocation")
+	defer span.End()
+
+	return s.SaveLocations(ctx, []repository.StoredLocation{location})
+}
+
+func (s *LocationStore) DeleteLocation(ctx context.Context, cep string) error {
+	tracer := otel.Tracer("service-b")
+	ctx, span := tracer.Start(ctx, "sqlite.LocationStore.DeleteLocation")
+	defer span.End()
+
+	_, err := s.db.ExecContext(ctx, "DELETE FROM locations WHERE cep = ?", cep)
+	return err
+}
+
+// SaveLocations inserts or replaces locations in a single transaction.
+func (s *LocationStore) SaveLocations(ctx context.Context, locations []repository.StoredLocation) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := saveLocations(ctx, tx, locations); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func saveLocations(ctx context.Context, tx *sql.Tx, locations []repository.StoredLocation) error {
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO locations (cep, city, source, updated_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT (cep) DO UPDATE SET
+			city = excluded.city, source = excluded.source, updated_at = excluded.updated_at`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, location := range locations {
+		if _, err := stmt.ExecContext(ctx, location.CEP, location.City, location.Source, location.UpdatedAt.Unix()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EachLocation calls fn with every stored location in CEP order, stopping at
+// the first error fn returns.
+func (s *LocationStore) EachLocation(ctx context.Context, fn func(repository.StoredLocation) error) error {
+	rows, err := s.db.QueryContext(ctx, "SELECT cep, city, source, updated_at FROM locations ORDER BY cep")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var location repository.StoredLocation
+		var updatedAt int64
+		if err := rows.Scan(&location.CEP, &location.City, &location.Source, &updatedAt); err != nil {
+			return err
+		}
+		location.UpdatedAt = time.Unix(updatedAt, 0)
+		if err := fn(location); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// Stats counts the stored locations, reporting those updated before
+// staleBefore as stale.
+func (s *LocationStore) Stats(ctx context.Context, staleBefore time.Time) (Stats, error) {
+	stats := Stats{BySource: make(map[string]int)}
+
+	version, err := schemaVersion(ctx, s.db)
+	if err != nil {
+		return Stats{}, err
+	}
+	stats.SchemaVersion = version
+
+	rows, err := s.db.QueryContext(ctx, "SELECT source, COUNT(*) FROM locations GROUP BY source")
+	if err != nil {
+		return Stats{}, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var source string
+		var count int
+		if err := rows.Scan(&source, &count); err != nil {
+			return Stats{}, err
+		}
+		stats.BySource[source] = count
+		stats.Total += count
+	}
+	if err := rows.Err(); err != nil {
+		return Stats{}, err
+	}
+	if stats.Total == 0 {
+		return stats, nil
+	}
+
+	var oldest, newest int64
+	err = s.db.QueryRowContext(ctx, `
+		SELECT MIN(updated_at), MAX(updated_at), COUNT(*) FILTER (WHERE updated_at < ?)
+		FROM locations`, staleBefore.Unix(),
+	).Scan(&oldest, &newest, &stats.Stale)
+	if err != nil {
+		return Stats{}, err
+	}
+	stats.Oldest = time.Unix(oldest, 0)
+	stats.Newest = time.Unix(newest, 0)
+	return stats, nil
+}
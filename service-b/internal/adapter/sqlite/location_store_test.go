@@ -0,0 +1,199 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go-a-b-microservices/service-b/internal/repository"
+)
+
+func openStore(t *testing.T, path string) *LocationStore {
+	t.Helper()
+	store, err := NewLocationStore(context.Background(), path)
+	if err != nil {
+		t.Fatalf("NewLocationStore() error = %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestLocationStore(t *testing.T) {
+	ctx := context.Background()
+	store := openStore(t, filepath.Join(t.TempDir(), "cep.db"))
+	updated := time.Date(2025, 5, 10, 12, 0, 0, 0, time.UTC)
+
+	if _, ok, err := store.GetLocation(ctx, "13480000"); err != nil || ok {
+		t.Fatalf("Expected no location, got ok %v and error %v", ok, err)
+	}
+
+	err := store.SaveLocations(ctx, []repository.StoredLocation{
+		{CEP: "13480000", City: "Limeira", Source: repository.SourceImport, UpdatedAt: updated},
+		{CEP: "01001000", City: "Sao Paulo", Source: repository.SourceImport, UpdatedAt: updated},
+	})
+	if err != nil {
+		t.Fatalf("SaveLocations() error = %v", err)
+	}
+	err = store.SaveLocation(ctx, repository.StoredLocation{
+		CEP: "01001000", City: "São Paulo", Source: repository.SourceViaCEP, UpdatedAt: updated.Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("SaveLocation() error = %v", err)
+	}
+
+	location, ok, err := store.GetLocation(ctx, "01001000")
+	if err != nil || !ok {
+		t.Fatalf("Expected a location, got ok %v and error %v", ok, err)
+	}
+	expected := repository.StoredLocation{CEP: "01001000", City: "São Paulo", Source: repository.SourceViaCEP, UpdatedAt: updated.Add(time.Hour)}
+	if !location.UpdatedAt.Equal(expected.UpdatedAt) {
+		t.Errorf("Expected updated at %s, got %s", expected.UpdatedAt, location.UpdatedAt)
+	}
+	location.UpdatedAt = expected.UpdatedAt
+	if location != expected {
+		t.Errorf("Expected the replaced location %+v, got %+v", expected, location)
+	}
+
+	var ceps []string
+	err = store.EachLocation(ctx, func(location repository.StoredLocation) error {
+		ceps = append(ceps, location.CEP)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("EachLocation() error = %v", err)
+	}
+	if !reflect.DeepEqual(ceps, []string{"01001000", "13480000"}) {
+		t.Errorf("Expected the locations in CEP order, got %v", ceps)
+	}
+
+	stats, err := store.Stats(ctx, updated.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("Stats() error = %v", err)
+	}
+	if stats.Total != 2 || stats.Stale != 1 || stats.SchemaVersion != len(migrations) {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+	if !reflect.DeepEqual(stats.BySource, map[string]int{repository.SourceImport: 1, repository.SourceViaCEP: 1}) {
+		t.Errorf("Unexpected counts by source %v", stats.BySource)
+	}
+	if !stats.Oldest.Equal(updated) || !stats.Newest.Equal(updated.Add(time.Hour)) {
+		t.Errorf("Unexpected oldest %s and newest %s", stats.Oldest, stats.Newest)
+	}
+
+	if err := store.DeleteLocation(ctx, "13480000"); err != nil {
+		t.Fatalf("DeleteLocation() error = %v", err)
+	}
+	if _, ok, err := store.GetLocation(ctx, "13480000"); err != nil || ok {
+		t.Errorf("Expected the location to be deleted, got ok %v and error %v", ok, err)
+	}
+}
+
+func TestLocationStore_Reopen(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "cep.db")
+
+	store := openStore(t, path)
+	err := store.SaveLocation(ctx, repository.StoredLocation{CEP: "13480000", City: "Limeira", Source: repository.SourceViaCEP, UpdatedAt: time.Now()})
+	if err != nil {
+		t.Fatalf("SaveLocation() error = %v", err)
+	}
+	store.Close()
+
+	reopened := openStore(t, path)
+	if _, ok, err := reopened.GetLocation(ctx, "13480000"); err != nil || !ok {
+		t.Errorf("Expected the location to survive a restart, got ok %v and error %v", ok, err)
+	}
+}
+
+func TestLocationStore_NewerSchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cep.db")
+	openStore(t, path).Close()
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("PRAGMA user_version = 99"); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	_, err = NewLocationStore(context.Background(), path)
+	if err == nil || !strings.Contains(err.Error(), "newer than this build supports") {
+		t.Errorf("Expected a schema version error, got %v", err)
+	}
+}
+
+func TestLocationStore_ImportProgress(t *testing.T) {
+	ctx := context.Background()
+	store := openStore(t, filepath.Join(t.TempDir(), "cep.db"))
+	updated := time.Date(2025, 5, 10, 12, 0, 0, 0, time.UTC)
+
+	if _, ok, err := store.ImportProgress(ctx, "/data/ceps.csv"); err != nil || ok {
+		t.Fatalf("Expected no progress, got ok %v and error %v", ok, err)
+	}
+
+	progress := ImportProgress{
+		Source:    "/data/ceps.csv",
+		Size:      1 << 30,
+		ModTime:   updated.Add(123 * time.Nanosecond),
+		Records:   2,
+		Imported:  1,
+		Rejected:  1,
+		UpdatedAt: updated,
+	}
+	err := store.SaveImportBatch(ctx, []repository.StoredLocation{
+		{CEP: "13480000", City: "Limeira", Source: repository.SourceImport, UpdatedAt: updated},
+	}, progress)
+	if err != nil {
+		t.Fatalf("SaveImportBatch() error = %v", err)
+	}
+
+	progress.Records, progress.Imported, progress.Done = 3, 2, true
+	err = store.SaveImportBatch(ctx, []repository.StoredLocation{
+		{CEP: "01001000", City: "São Paulo", Source: repository.SourceImport, UpdatedAt: updated},
+	}, progress)
+	if err != nil {
+		t.Fatalf("SaveImportBatch() error = %v", err)
+	}
+
+	saved, ok, err := store.ImportProgress(ctx, "/data/ceps.csv")
+	if err != nil || !ok {
+		t.Fatalf("Expected progress, got ok %v and error %v", ok, err)
+	}
+	if !saved.ModTime.Equal(progress.ModTime) || !saved.UpdatedAt.Equal(progress.UpdatedAt) {
+		t.Errorf("Expected mod time %s and updated at %s, got %s and %s", progress.ModTime, progress.UpdatedAt, saved.ModTime, saved.UpdatedAt)
+	}
+	saved.ModTime, saved.UpdatedAt = progress.ModTime, progress.UpdatedAt
+	if saved != progress {
+		t.Errorf("Expected progress %+v, got %+v", progress, saved)
+	}
+
+	if stats, err := store.Stats(ctx, time.Time{}); err != nil || stats.Total != 2 {
+		t.Errorf("Expected both batches to be stored, got %+v and error %v", stats, err)
+	}
+}
+
+func TestLocationStore_SaveImportBatch_Canceled(t *testing.T) {
+	store := openStore(t, filepath.Join(t.TempDir(), "cep.db"))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := store.SaveImportBatch(ctx, []repository.StoredLocation{
+		{CEP: "13480000", City: "Limeira", Source: repository.SourceImport, UpdatedAt: time.Now()},
+	}, ImportProgress{Source: "/data/ceps.csv", Records: 1, Imported: 1})
+	if err == nil {
+		t.Fatal("Expected the canceled batch to fail")
+	}
+
+	if _, ok, _ := store.ImportProgress(context.Background(), "/data/ceps.csv"); ok {
+		t.Error("Expected no progress to be recorded")
+	}
+	if _, ok, _ := store.GetLocation(context.Background(), "13480000"); ok {
+		t.Error("Expected no location to be stored")
+	}
+}
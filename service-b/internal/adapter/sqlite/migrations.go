@@ -0,0 +1,68 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// migrations build the schema, in order. The database records how many have
+// run in PRAGMA user_version, so new migrations are appended and applied ones
+// are never edited.
+var migrations = []string{
+	`CREATE TABLE locations (
+		cep        TEXT PRIMARY KEY,
+		city       TEXT NOT NULL,
+		source     TEXT NOT NULL,
+		updated_at INTEGER NOT NULL
+	) WITHOUT ROWID`,
+	`CREATE INDEX locations_updated_at ON locations (updated_at)`,
+	`CREATE TABLE imports (
+		source     TEXT PRIMARY KEY,
+		size       INTEGER NOT NULL,
+		mod_time   INTEGER NOT NULL,
+		records    INTEGER NOT NULL,
+		imported   INTEGER NOT NULL,
+		rejected   INTEGER NOT NULL,
+		done       INTEGER NOT NULL,
+		updated_at INTEGER NOT NULL
+	) WITHOUT ROWID`,
+}
+
+// migrate applies the migrations the database has not run yet, each in its
+// own transaction.
+func migrate(ctx context.Context, db *sql.DB) error {
+	version, err := schemaVersion(ctx, db)
+	if err != nil {
+		return err
+	}
+	if version > len(migrations) {
+		return fmt.Errorf("database schema version %d is newer than this build supports (%d)", version, len(migrations))
+	}
+
+	for ; version < len(migrations); version++ {
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, migrations[version]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d: %w", version+1, err)
+		}
+		// PRAGMA statements do not take parameters.
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("PRAGMA user_version = %d", version+1)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d: %w", version+1, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("migration %d: %w", version+1, err)
+		}
+	}
+	return nil
+}
+
+func schemaVersion(ctx context.Context, db *sql.DB) (int, error) {
+	var version int
+	err := db.QueryRowContext(ctx, "PRAGMA user_version").Scan(&version)
+	return version, err
+}
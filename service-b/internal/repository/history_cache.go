@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+	"time"
+
+	"go-a-b-microservices/pkg/zipcode"
+)
+
+// HistoryCache keeps observed days per city. A day that has fully elapsed
+// everywhere can no longer change, so it is kept until evicted for space;
+// more recent days expire after the configured TTL.
+type HistoryCache struct {
+	mu       sync.Mutex
+	entries  map[string]*list.Element
+	order    *list.List
+	ttl      time.Duration
+	capacity int
+	now      func() time.Time
+}
+
+type historyCacheEntry struct {
+	key       string
+	day       zipcode.HistoricalDay
+	expiresAt time.Time
+}
+
+func NewHistoryCache(ttl time.Duration, capacity int) *HistoryCache {
+	return &HistoryCache{
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+		ttl:      ttl,
+		capacity: capacity,
+		now:      time.Now,
+	}
+}
+
+// SetTTL changes the TTL of the days cached from now on.
+func (c *HistoryCache) SetTTL(ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ttl = ttl
+}
+
+func (c *HistoryCache) Get(city string, date time.Time) (zipcode.HistoricalDay, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := historyCacheKey(city, date.Format(zipcode.DateLayout))
+	element, ok := c.entries[key]
+	if !ok {
+		return zipcode.HistoricalDay{}, false
+	}
+
+	entry := element.Value.(*historyCacheEntry)
+	if !entry.expiresAt.IsZero() && c.now().After(entry.expiresAt) {
+		c.order.Remove(element)
+		delete(c.entries, key)
+		return zipcode.HistoricalDay{}, false
+	}
+
+	c.order.MoveToFront(element)
+	return entry.day, true
+}
+
+func (c *HistoryCache) Set(city string, day zipcode.HistoricalDay) {
+	date, err := time.Parse(zipcode.DateLayout, day.Date)
+	if err != nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	entry := &historyCacheEntry{
+		key: historyCacheKey(city, day.Date),
+		day: day,
+	}
+
+	// Dates before yesterday (UTC) have ended in every Brazilian time zone.
+	if !date.Before(now.UTC().AddDate(0, 0, -1).Truncate(24 * time.Hour)) {
+		if c.ttl <= 0 {
+			return
+		}
+		entry.expiresAt = now.Add(c.ttl)
+	}
+
+	if element, ok := c.entries[entry.key]; ok {
+		element.Value = entry
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[entry.key] = c.order.PushFront(entry)
+
+	for c.capacity > 0 && c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*historyCacheEntry).key)
+	}
+}
+
+func historyCacheKey(city, date string) string {
+	return strings.ToLower(city) + "|" + date
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"go-a-b-microservices/pkg/zipcode"
+)
+
+func TestHistoryCache(t *testing.T) {
+	now := time.Date(2025, 5, 10, 12, 0, 0, 0, time.UTC)
+	cache := NewHistoryCache(10*time.Minute, 2)
+	cache.now = func() time.Time { return now }
+
+	pastDate := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	recentDate := time.Date(2025, 5, 9, 0, 0, 0, 0, time.UTC)
+
+	cache.Set("Limeira", zipcode.HistoricalDay{Date: "2025-05-01", AvgTempC: 21})
+	cache.Set("Limeira", zipcode.HistoricalDay{Date: "2025-05-09", AvgTempC: 23})
+
+	if day, ok := cache.Get("limeira", pastDate); !ok || day.AvgTempC != 21 {
+		t.Errorf("Expected cached past day, got %+v (found %v)", day, ok)
+	}
+
+	now = now.Add(time.Hour)
+
+	if _, ok := cache.Get("Limeira", recentDate); ok {
+		t.Errorf("Expected recent day to expire after the TTL")
+	}
+
+	if _, ok := cache.Get("Limeira", pastDate); !ok {
+		t.Errorf("Expected past day to be kept permanently")
+	}
+
+	cache.Set("Campinas", zipcode.HistoricalDay{Date: "2025-04-01"})
+	cache.Set("Santos", zipcode.HistoricalDay{Date: "2025-04-01"})
+
+	if _, ok := cache.Get("Limeira", pastDate); ok {
+		t.Errorf("Expected least recently used day to be evicted when over capacity")
+	}
+}
@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// Location sources recorded with each stored location.
+const (
+	SourceViaCEP = "viacep"
+	SourceImport = "import"
+)
+
+// StoredLocation is a CEP location kept by a LocationStore.
+type StoredLocation struct {
+	// CEP holds the eight digits, without a hyphen.
+	CEP       string
+	City      string
+	Source    string
+	UpdatedAt time.Time
+}
+
+// LocationStore keeps CEP locations so lookups survive restarts and ViaCEP
+// outages.
+type LocationStore interface {
+	// GetLocation returns the location stored for cep, with ok false when
+	// there is none.
+	GetLocation(ctx context.Context, cep string) (location StoredLocation, ok bool, err error)
+	// SaveLocation inserts location or replaces the one stored for its CEP.
+	SaveLocation(ctx context.Context, location StoredLocation) error
+	// DeleteLocation removes the location stored for cep, if any.
+	DeleteLocation(ctx context.Context, cep string) error
+}
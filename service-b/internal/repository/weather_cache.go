@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"go-a-b-microservices/pkg/zipcode"
+)
+
+// weatherRefreshRetry is how long to wait after a failed background refresh
+// before trying again, so a provider outage is not hit by every request.
+const weatherRefreshRetry = 30 * time.Second
+
+// WeatherCache keeps the last good observation per city. Observations younger
+// than the soft TTL are fresh. Older ones are stale: they are still served,
+// flagged as such, while one caller refreshes them in the background, until
+// they are older than the maximum staleness and are dropped.
+type WeatherCache struct {
+	mu       sync.Mutex
+	entries  map[string]*list.Element
+	order    *list.List
+	softTTL  time.Duration
+	maxStale time.Duration
+	capacity int
+	now      func() time.Time
+}
+
+type weatherCacheEntry struct {
+	key        string
+	data       zipcode.WeatherData
+	observedAt time.Time
+	refreshing bool
+	retryAt    time.Time
+}
+
+// NewWeatherCache returns a cache of up to capacity observations, or any
+// number when capacity is zero. A soft TTL of zero disables it.
+func NewWeatherCache(softTTL, maxStale time.Duration, capacity int) *WeatherCache {
+	return &WeatherCache{
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+		softTTL:  softTTL,
+		maxStale: maxStale,
+		capacity: capacity,
+		now:      time.Now,
+	}
+}
+
+// SetTTLs changes the soft TTL and the maximum staleness, also of the
+// observations already cached.
+func (c *WeatherCache) SetTTLs(softTTL, maxStale time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.softTTL = softTTL
+	c.maxStale = maxStale
+}
+
+// Get returns the observation cached under key. A stale observation comes
+// back with Stale and Age set, and refresh is true for the one caller that
+// should refresh it, until it calls Set or RefreshFailed.
+func (c *WeatherCache) Get(key string) (data *zipcode.WeatherData, refresh bool, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false, false
+	}
+
+	entry := element.Value.(*weatherCacheEntry)
+	now := c.now()
+	age := now.Sub(entry.observedAt)
+	stale := age >= c.softTTL
+	if c.softTTL <= 0 || stale && age > c.maxStale {
+		c.order.Remove(element)
+		delete(c.entries, key)
+		return nil, false, false
+	}
+
+	c.order.MoveToFront(element)
+	observation := entry.data
+	if stale {
+		observation.Stale = true
+		observation.Age = age
+		if !entry.refreshing && !now.Before(entry.retryAt) {
+			entry.refreshing = true
+			refresh = true
+		}
+	}
+	return &observation, refresh, true
+}
+
+// Set stores a fresh observation under key.
+func (c *WeatherCache) Set(key string, data *zipcode.WeatherData) {
+	c.SetAt(key, data, c.now())
+}
+
+// SetAt stores an observation made at observedAt under key, such as one
+// shared by another replica, unless the one cached is more recent. A stale
+// observation does not end a refresh in progress.
+func (c *WeatherCache) SetAt(key string, data *zipcode.WeatherData, observedAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.softTTL <= 0 {
+		return
+	}
+
+	entry := &weatherCacheEntry{key: key, data: *data, observedAt: observedAt}
+	if element, ok := c.entries[key]; ok {
+		cached := element.Value.(*weatherCacheEntry)
+		if observedAt.Before(cached.observedAt) {
+			return
+		}
+		if c.now().Sub(observedAt) >= c.softTTL {
+			entry.refreshing = cached.refreshing
+			entry.retryAt = cached.retryAt
+		}
+		element.Value = entry
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(entry)
+
+	for c.capacity > 0 && c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*weatherCacheEntry).key)
+	}
+}
+
+// Enabled reports whether observations are cached, that is whether the soft
+// TTL is set.
+func (c *WeatherCache) Enabled() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.softTTL > 0
+}
+
+// MaxAge returns the age after which observations are no longer served,
+// zero when the cache is disabled.
+func (c *WeatherCache) MaxAge() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.softTTL <= 0 {
+		return 0
+	}
+	return max(c.softTTL, c.maxStale)
+}
+
+// FreshFor returns how long the observation cached under key stays fresh;
+// zero or less when it is stale or not cached.
+func (c *WeatherCache) FreshFor(key string) time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return 0
+	}
+	return c.softTTL - c.now().Sub(element.Value.(*weatherCacheEntry).observedAt)
+}
+
+// RefreshFailed ends the refresh of key handed out by Get. The stale
+// observation is kept, and refreshed again once weatherRefreshRetry passes.
+func (c *WeatherCache) RefreshFailed(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		entry := element.Value.(*weatherCacheEntry)
+		entry.refreshing = false
+		entry.retryAt = c.now().Add(weatherRefreshRetry)
+	}
+}
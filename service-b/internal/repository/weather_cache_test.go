@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"go-a-b-microservices/pkg/zipcode"
+)
+
+func TestWeatherCache(t *testing.T) {
+	now := time.Date(2025, 5, 10, 12, 0, 0, 0, time.UTC)
+	cache := NewWeatherCache(5*time.Minute, time.Hour, 2)
+	cache.now = func() time.Time { return now }
+
+	cache.Set("pt_br:limeira", &zipcode.WeatherData{Current: zipcode.CurrentWeather{TempC: 28}})
+
+	data, refresh, ok := cache.Get("pt_br:limeira")
+	if !ok || data.Stale || refresh || data.Current.TempC != 28 {
+		t.Fatalf("Expected a fresh observation, got %+v (refresh %v, found %v)", data, refresh, ok)
+	}
+
+	now = now.Add(10 * time.Minute)
+	data, refresh, ok = cache.Get("pt_br:limeira")
+	if !ok || !data.Stale || data.Age != 10*time.Minute || !refresh {
+		t.Fatalf("Expected a stale observation to refresh, got %+v (refresh %v, found %v)", data, refresh, ok)
+	}
+	if _, refresh, _ := cache.Get("pt_br:limeira"); refresh {
+		t.Errorf("Expected a single refresh at a time")
+	}
+
+	cache.RefreshFailed("pt_br:limeira")
+	if _, refresh, _ := cache.Get("pt_br:limeira"); refresh {
+		t.Errorf("Expected a failed refresh to wait before the next")
+	}
+	now = now.Add(weatherRefreshRetry)
+	if _, refresh, _ := cache.Get("pt_br:limeira"); !refresh {
+		t.Errorf("Expected the refresh to be retried")
+	}
+
+	now = now.Add(time.Hour)
+	if _, _, ok := cache.Get("pt_br:limeira"); ok {
+		t.Errorf("Expected the observation to be dropped past the maximum staleness")
+	}
+
+	cache.Set("pt_br:campinas", &zipcode.WeatherData{})
+	cache.Set("pt_br:santos", &zipcode.WeatherData{})
+	cache.Set("pt_br:limeira", &zipcode.WeatherData{})
+	if _, _, ok := cache.Get("pt_br:campinas"); ok {
+		t.Errorf("Expected least recently used observation to be evicted when over capacity")
+	}
+
+	cache.SetTTLs(0, time.Hour)
+	if _, _, ok := cache.Get("pt_br:limeira"); ok {
+		t.Errorf("Expected a zero soft TTL to disable the cache")
+	}
+}
@@ -0,0 +1,377 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"go-a-b-microservices/pkg/i18n"
+	"go-a-b-microservices/pkg/logger"
+	"go-a-b-microservices/pkg/zipcode"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	// demandHalfLife is how long it takes a request to count half as much
+	// towards the hot set, so it follows traffic as it shifts.
+	demandHalfLife = time.Hour
+	// minDemand is the weight below which a CEP is forgotten.
+	minDemand = 0.05
+	// maxTrackedDemand bounds how many CEPs are counted at once.
+	maxTrackedDemand = 10000
+	// minWarmDelay keeps a large jitter from warming back to back.
+	minWarmDelay = time.Second
+)
+
+// DemandRecorder is told about each weather lookup served for a CEP.
+type DemandRecorder interface {
+	Record(ctx context.Context, cep, city string, options zipcode.WeatherOptions)
+}
+
+// OutboundQuota tells whether optional calls to the weather provider fit in
+// its plan; see clients.Budget.
+type OutboundQuota interface {
+	HasHeadroom(percent int) bool
+}
+
+// WarmerSettings configures a WeatherWarmer. A TopN of zero disables it.
+type WarmerSettings struct {
+	TopN          int
+	Interval      time.Duration
+	Jitter        time.Duration
+	BudgetPercent int
+}
+
+// HotCity is a city in the hot set with the CEPs its demand comes from.
+type HotCity struct {
+	City       string             `json:"city"`
+	Language   i18n.Language      `json:"language"`
+	Alerts     bool               `json:"alerts,omitempty"`
+	AirQuality bool               `json:"air_quality,omitempty"`
+	Demand     float64            `json:"demand"`
+	CEPs       map[string]float64 `json:"ceps"`
+}
+
+type demandKey struct {
+	cep      string
+	city     string
+	language i18n.Language
+	options  zipcode.WeatherOptions
+}
+
+type warmerState struct {
+	SavedAt time.Time `json:"saved_at"`
+	HotSet  []HotCity `json:"hot_set"`
+}
+
+// WeatherWarmer keeps the cached weather of the most requested cities fresh.
+// Requests are counted per CEP and city, older ones counting less and less,
+// and the top N cities are refreshed on a jittered schedule before their
+// observations turn stale, as long as the outbound quota has headroom.
+type WeatherWarmer struct {
+	repository *ZipCodeRepository
+	quota      OutboundQuota
+	logger     logger.Logger
+
+	mu        sync.Mutex
+	settings  WarmerSettings
+	demand    map[demandKey]float64
+	decayedAt time.Time
+	stateFile string
+	// saveFailed is set while saves to stateFile fail.
+	saveFailed bool
+
+	now    func() time.Time
+	jitter func(spread time.Duration) time.Duration
+}
+
+// NewWeatherWarmer returns a warmer of the weather cache of repository. A nil
+// quota never holds it back.
+func NewWeatherWarmer(repository *ZipCodeRepository, quota OutboundQuota, settings WarmerSettings, log logger.Logger) *WeatherWarmer {
+	return &WeatherWarmer{
+		repository: repository,
+		quota:      quota,
+		logger:     log,
+		settings:   settings,
+		demand:     make(map[demandKey]float64),
+		now:        time.Now,
+		jitter: func(spread time.Duration) time.Duration {
+			if spread <= 0 {
+				return 0
+			}
+			return rand.N(2*spread+1) - spread
+		},
+	}
+}
+
+// SetSettings changes the settings, also while the warmer runs. The demand
+// already counted is kept.
+func (w *WeatherWarmer) SetSettings(settings WarmerSettings) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.settings = settings
+}
+
+// SetStateFile persists the hot set to path, so a restart does not have to
+// learn it again, and loads the one already stored there.
+func (w *WeatherWarmer) SetStateFile(path string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.stateFile = path
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var state warmerState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+
+	for _, city := range state.HotSet {
+		options := zipcode.WeatherOptions{Alerts: city.Alerts, AirQuality: city.AirQuality}
+		for cep, demand := range city.CEPs {
+			w.demand[demandKey{cep: cep, city: city.City, language: city.Language, options: options}] = demand
+		}
+	}
+	// The demand decays through the downtime, so requests recorded from now
+	// on weigh one each.
+	w.decayedAt = state.SavedAt
+	w.decay()
+	return nil
+}
+
+// Record counts a weather lookup of city for cep.
+func (w *WeatherWarmer) Record(ctx context.Context, cep, city string, options zipcode.WeatherOptions) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.settings.TopN <= 0 {
+		return
+	}
+
+	key := demandKey{cep: cep, city: city, language: i18n.FromContext(ctx), options: options}
+	if _, ok := w.demand[key]; !ok && len(w.demand) >= maxTrackedDemand {
+		// Make room by forgetting the CEPs asked about only once.
+		w.decay()
+		w.forget(1)
+		if len(w.demand) >= maxTrackedDemand {
+			return
+		}
+	}
+	// Counts are as of the last decay, so a request now weighs more than one.
+	if w.decayedAt.IsZero() {
+		w.decayedAt = w.now()
+	}
+	w.demand[key] += math.Exp2(float64(w.now().Sub(w.decayedAt)) / float64(demandHalfLife))
+}
+
+// HotSet returns the cities with the highest demand, highest first.
+func (w *WeatherWarmer) HotSet() []HotCity {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.decay()
+	return w.hotSet()
+}
+
+func (w *WeatherWarmer) hotSet() []HotCity {
+	cities := make(map[string]*HotCity)
+	for key, demand := range w.demand {
+		cacheKey := weatherCacheKeyFor(key.language, key.city, key.options)
+		city, ok := cities[cacheKey]
+		if !ok {
+			city = &HotCity{
+				City:       key.city,
+				Language:   key.language,
+				Alerts:     key.options.Alerts,
+				AirQuality: key.options.AirQuality,
+				CEPs:       make(map[string]float64),
+			}
+			cities[cacheKey] = city
+		}
+		city.Demand += demand
+		city.CEPs[key.cep] += demand
+	}
+
+	hot := make([]HotCity, 0, len(cities))
+	for _, city := range cities {
+		hot = append(hot, *city)
+	}
+	sort.Slice(hot, func(i, j int) bool {
+		if hot[i].Demand != hot[j].Demand {
+			return hot[i].Demand > hot[j].Demand
+		}
+		return weatherCacheKeyFor(hot[i].Language, hot[i].City, hot[i].options()) <
+			weatherCacheKeyFor(hot[j].Language, hot[j].City, hot[j].options())
+	})
+	if len(hot) > w.settings.TopN {
+		hot = hot[:w.settings.TopN]
+	}
+	return hot
+}
+
+func (c HotCity) options() zipcode.WeatherOptions {
+	return zipcode.WeatherOptions{Alerts: c.Alerts, AirQuality: c.AirQuality}
+}
+
+// decay weighs the demand down by the time passed since the last decay.
+func (w *WeatherWarmer) decay() {
+	now := w.now()
+	if !w.decayedAt.IsZero() && now.After(w.decayedAt) {
+		factor := math.Exp2(-float64(now.Sub(w.decayedAt)) / float64(demandHalfLife))
+		for key := range w.demand {
+			w.demand[key] *= factor
+		}
+		w.forget(minDemand)
+	}
+	w.decayedAt = now
+}
+
+// forget drops the CEPs with a demand of at most threshold.
+func (w *WeatherWarmer) forget(threshold float64) {
+	for key, demand := range w.demand {
+		if demand <= threshold {
+			delete(w.demand, key)
+		}
+	}
+}
+
+// Run warms the hot set every interval, give or take the jitter, until ctx
+// is canceled, then saves the hot set to the state file.
+func (w *WeatherWarmer) Run(ctx context.Context) {
+	defer w.save()
+
+	for {
+		timer := time.NewTimer(w.delay())
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		w.warm(ctx)
+	}
+}
+
+func (w *WeatherWarmer) delay() time.Duration {
+	w.mu.Lock()
+	settings := w.settings
+	w.mu.Unlock()
+
+	return max(settings.Interval+w.jitter(settings.Jitter), minWarmDelay)
+}
+
+// warm refreshes the hot cities whose observation would turn stale before
+// the next run, stopping when the outbound quota runs low.
+func (w *WeatherWarmer) warm(ctx context.Context) {
+	tracer := otel.Tracer("service-b")
+	ctx, span := tracer.Start(ctx, "repository.WarmWeather")
+	defer span.End()
+
+	w.mu.Lock()
+	w.decay()
+	settings := w.settings
+	hot := w.hotSet()
+	w.mu.Unlock()
+	w.save()
+
+	cache := w.repository.weatherCache
+	if settings.TopN <= 0 || cache == nil || !cache.Enabled() {
+		return
+	}
+
+	lead := settings.Interval + settings.Jitter
+	warmed := 0
+	for _, city := range hot {
+		if ctx.Err() != nil {
+			break
+		}
+
+		key := weatherCacheKeyFor(city.Language, city.City, city.options())
+		if cache.FreshFor(key) > lead {
+			continue
+		}
+		// Another replica may have warmed it already.
+		if w.repository.loadSharedWeather(ctx, key) && cache.FreshFor(key) > lead {
+			continue
+		}
+		if w.quota != nil && !w.quota.HasHeadroom(settings.BudgetPercent) {
+			w.logger.Debug("Stopped warming the weather cache, %d%% of the WeatherAPI budget is used", settings.BudgetPercent)
+			break
+		}
+
+		weather, err := w.repository.weatherProvider.GetWeatherByCity(i18n.WithLanguage(ctx, city.Language), city.City, city.options())
+		if err != nil {
+			if ctx.Err() == nil {
+				w.logger.Error("Failed to warm the weather of %s: %v", city.City, err)
+			}
+			continue
+		}
+		w.repository.storeWeather(ctx, key, weather)
+		warmed++
+	}
+
+	span.SetAttributes(attribute.Int("warmer.hot", len(hot)), attribute.Int("warmer.warmed", warmed))
+	w.logger.Debug("Warmed the weather of %d of %d hot cities", warmed, len(hot))
+}
+
+// save writes the hot set through a temporary file so a crash never leaves a
+// truncated state file behind. Failures only lose persistence; the first of a
+// run of them is logged, so an unwritable state file is reported once.
+func (w *WeatherWarmer) save() {
+	w.mu.Lock()
+	w.decay()
+	path := w.stateFile
+	state := warmerState{SavedAt: w.decayedAt, HotSet: w.hotSet()}
+	w.mu.Unlock()
+
+	if path == "" {
+		return
+	}
+
+	err := writeWarmerState(path, state)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err != nil && !w.saveFailed {
+		w.logger.Error("Failed to save the weather warmer state to %s, the hot set will not survive a restart: %v", path, err)
+	}
+	w.saveFailed = err != nil
+}
+
+func writeWarmerState(path string, state warmerState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".warmer-*")
+	if err != nil {
+		return err
+	}
+	_, writeErr := tmp.Write(data)
+	closeErr := tmp.Close()
+	if err := errors.Join(writeErr, closeErr); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,209 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go-a-b-microservices/pkg/i18n"
+	"go-a-b-microservices/pkg/zipcode"
+)
+
+type quotaFunc func(percent int) bool
+
+func (f quotaFunc) HasHeadroom(percent int) bool { return f(percent) }
+
+func newTestWarmer(now *time.Time, quota OutboundQuota) (*WeatherWarmer, *weatherProviderFunc, *WeatherCache) {
+	provider := &weatherProviderFunc{get: func() (*zipcode.WeatherData, error) {
+		return &zipcode.WeatherData{Current: zipcode.CurrentWeather{TempC: 21}}, nil
+	}}
+	cache := NewWeatherCache(5*time.Minute, time.Hour, 10)
+	cache.now = func() time.Time { return *now }
+	repo := NewZipCodeRepository(nil, provider, nil, &MockLogger{})
+	repo.SetWeatherCache(cache)
+
+	warmer := NewWeatherWarmer(repo, quota, WarmerSettings{TopN: 2, Interval: time.Minute, BudgetPercent: 80}, &MockLogger{})
+	warmer.now = func() time.Time { return *now }
+	warmer.jitter = func(time.Duration) time.Duration { return 0 }
+	return warmer, provider, cache
+}
+
+func record(warmer *WeatherWarmer, language i18n.Language, cep, city string, times int) {
+	ctx := i18n.WithLanguage(context.Background(), language)
+	for i := 0; i < times; i++ {
+		warmer.Record(ctx, cep, city, zipcode.WeatherOptions{})
+	}
+}
+
+func TestWeatherWarmer_HotSet(t *testing.T) {
+	now := time.Date(2025, 5, 10, 12, 0, 0, 0, time.UTC)
+	warmer, _, _ := newTestWarmer(&now, nil)
+
+	record(warmer, i18n.Portuguese, "13480000", "Limeira", 3)
+	record(warmer, i18n.Portuguese, "13484000", "Limeira", 2)
+	record(warmer, i18n.Portuguese, "01001000", "São Paulo", 4)
+	record(warmer, i18n.English, "01001000", "São Paulo", 1)
+
+	hot := warmer.HotSet()
+	if len(hot) != 2 {
+		t.Fatalf("Expected the top 2 cities, got %+v", hot)
+	}
+	if hot[0].City != "Limeira" || hot[0].Demand != 5 || hot[0].CEPs["13484000"] != 2 {
+		t.Errorf("Expected Limeira first with the demand of both CEPs, got %+v", hot[0])
+	}
+	if hot[1].City != "São Paulo" || hot[1].Language != i18n.Portuguese || hot[1].Demand != 4 {
+		t.Errorf("Expected São Paulo in Portuguese second, got %+v", hot[1])
+	}
+
+	now = now.Add(2 * time.Hour)
+	record(warmer, i18n.English, "01001000", "São Paulo", 2)
+	hot = warmer.HotSet()
+	if hot[0].City != "São Paulo" || hot[0].Language != i18n.English || hot[0].Demand != 2.25 {
+		t.Errorf("Expected recent requests to outweigh older ones, got %+v", hot)
+	}
+
+	warmer.SetSettings(WarmerSettings{})
+	record(warmer, i18n.English, "80010000", "Curitiba", 10)
+	warmer.SetSettings(WarmerSettings{TopN: 10})
+	for _, city := range warmer.HotSet() {
+		if city.City == "Curitiba" {
+			t.Errorf("Expected no demand to be recorded while disabled, got %+v", city)
+		}
+	}
+}
+
+func TestWeatherWarmer_Warm(t *testing.T) {
+	now := time.Date(2025, 5, 10, 12, 0, 0, 0, time.UTC)
+	headroom := true
+	warmer, provider, cache := newTestWarmer(&now, quotaFunc(func(percent int) bool {
+		if percent != 80 {
+			t.Errorf("Expected the headroom to be checked at 80%%, got %d%%", percent)
+		}
+		return headroom
+	}))
+
+	record(warmer, i18n.Portuguese, "13480000", "Limeira", 3)
+	record(warmer, i18n.Portuguese, "01001000", "São Paulo", 2)
+	record(warmer, i18n.Portuguese, "80010000", "Curitiba", 1)
+
+	limeira := weatherCacheKeyFor(i18n.Portuguese, "Limeira", zipcode.WeatherOptions{})
+	saoPaulo := weatherCacheKeyFor(i18n.Portuguese, "São Paulo", zipcode.WeatherOptions{})
+	curitiba := weatherCacheKeyFor(i18n.Portuguese, "Curitiba", zipcode.WeatherOptions{})
+	cache.Set(limeira, &zipcode.WeatherData{})
+
+	warmer.warm(context.Background())
+	if calls := provider.calls.Load(); calls != 1 {
+		t.Fatalf("Expected only São Paulo to be warmed, got %d calls", calls)
+	}
+	if cache.FreshFor(saoPaulo) != 5*time.Minute || cache.FreshFor(curitiba) > 0 {
+		t.Errorf("Expected the hot city to be cached and the cold one not")
+	}
+
+	// Limeira turns stale before the next run, São Paulo not yet.
+	now = now.Add(4*time.Minute + 30*time.Second)
+	cache.Set(saoPaulo, &zipcode.WeatherData{})
+	warmer.warm(context.Background())
+	if calls := provider.calls.Load(); calls != 2 {
+		t.Fatalf("Expected Limeira to be warmed before it turns stale, got %d calls", calls)
+	}
+	if cache.FreshFor(limeira) != 5*time.Minute {
+		t.Errorf("Expected Limeira to be fresh again")
+	}
+
+	now = now.Add(5 * time.Minute)
+	headroom = false
+	warmer.warm(context.Background())
+	if calls := provider.calls.Load(); calls != 2 {
+		t.Errorf("Expected no calls without quota headroom, got %d", calls-2)
+	}
+}
+
+type errorLogger struct {
+	MockLogger
+	errors []string
+}
+
+func (l *errorLogger) Error(message string, args ...interface{}) {
+	l.errors = append(l.errors, fmt.Sprintf(message, args...))
+}
+
+func TestWeatherWarmer_SaveFailure(t *testing.T) {
+	now := time.Date(2025, 5, 10, 12, 0, 0, 0, time.UTC)
+	warmer, _, _ := newTestWarmer(&now, nil)
+	log := &errorLogger{}
+	warmer.logger = log
+
+	dir := filepath.Join(t.TempDir(), "missing")
+	if err := warmer.SetStateFile(filepath.Join(dir, "warmer.json")); err != nil {
+		t.Fatalf("Expected a missing file to be accepted, got %v", err)
+	}
+
+	warmer.save()
+	warmer.save()
+	if len(log.errors) != 1 || !strings.Contains(log.errors[0], "warmer.json") {
+		t.Fatalf("Expected one error naming the state file, got %q", log.errors)
+	}
+
+	if err := os.Mkdir(dir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	warmer.save()
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	warmer.save()
+	if len(log.errors) != 2 {
+		t.Errorf("Expected a new failure to be logged after a save succeeded, got %q", log.errors)
+	}
+}
+
+func TestWeatherWarmer_StateFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "warmer.json")
+	now := time.Date(2025, 5, 10, 12, 0, 0, 0, time.UTC)
+
+	warmer, _, _ := newTestWarmer(&now, nil)
+	if err := warmer.SetStateFile(path); err != nil {
+		t.Fatalf("Expected a missing file to be accepted, got %v", err)
+	}
+	record(warmer, i18n.Portuguese, "13480000", "Limeira", 4)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	done := make(chan struct{})
+	go func() {
+		warmer.Run(ctx)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Expected Run to stop once its context is canceled")
+	}
+
+	now = now.Add(time.Hour)
+	restarted, _, _ := newTestWarmer(&now, nil)
+	if err := restarted.SetStateFile(path); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	hot := restarted.HotSet()
+	if len(hot) != 1 || hot[0].City != "Limeira" || hot[0].CEPs["13480000"] != 2 {
+		t.Errorf("Expected the hot set to survive a restart, decayed by the downtime, got %+v", hot)
+	}
+
+	// After a long downtime the old demand is forgotten, and new requests
+	// weigh one each rather than overflowing.
+	now = now.Add(60 * 24 * time.Hour)
+	longDown, _, _ := newTestWarmer(&now, nil)
+	if err := longDown.SetStateFile(path); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	record(longDown, i18n.Portuguese, "01001000", "São Paulo", 1)
+	hot = longDown.HotSet()
+	if len(hot) != 1 || hot[0].City != "São Paulo" || hot[0].Demand != 1 {
+		t.Errorf("Expected only the new request to count, got %+v", hot)
+	}
+}
@@ -2,49 +2,350 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"go-a-b-microservices/pkg/apperror"
+	"go-a-b-microservices/pkg/cache"
+	"go-a-b-microservices/pkg/i18n"
 	"go-a-b-microservices/pkg/logger"
 	"go-a-b-microservices/pkg/zipcode"
 	"go-a-b-microservices/service-b/internal/adapter/clients"
 
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type ZipCodeRepositoryInterface interface {
 	GetLocationByZipCode(ctx context.Context, zipCode string) (*zipcode.Location, error)
-	GetWeatherByCity(ctx context.Context, city string) (*zipcode.WeatherData, error)
+	GetWeatherByCity(ctx context.Context, city string, options zipcode.WeatherOptions) (*zipcode.WeatherData, error)
+	GetForecastByCity(ctx context.Context, city string, days int) (*zipcode.ForecastData, error)
+	GetHistoryByCity(ctx context.Context, city string, start, end time.Time) (*zipcode.HistoryData, error)
 }
 
+// Versions of the values shared with other replicas; bump one when the
+// shared type changes so replicas running the old code keep to their keys.
+const (
+	sharedLocationVersion = 1
+	sharedWeatherVersion  = 1
+)
+
 type ZipCodeRepository struct {
-	viaCEPClient     *clients.ViaCEPClient
-	weatherAPIClient *clients.WeatherAPIClient
-	logger           logger.Logger
+	viaCEPClient      *clients.ViaCEPClient
+	weatherProvider   clients.WeatherProvider
+	historyCache      *HistoryCache
+	weatherCache      *WeatherCache
+	refreshes         sync.WaitGroup
+	locationStore     LocationStore
+	refreshAfter      atomic.Int64
+	sharedLocations   *cache.Store[zipcode.Location]
+	sharedWeather     *cache.Store[sharedObservation]
+	sharedLocationTTL time.Duration
+	now               func() time.Time
+	logger            logger.Logger
+}
+
+// sharedObservation is a weather observation shared with other replicas,
+// with the time it was made so they all agree on its age.
+type sharedObservation struct {
+	Data       zipcode.WeatherData `json:"data"`
+	ObservedAt time.Time           `json:"observed_at"`
 }
 
 func NewZipCodeRepository(
 	viaCEPClient *clients.ViaCEPClient,
-	weatherAPIClient *clients.WeatherAPIClient,
+	weatherProvider clients.WeatherProvider,
+	historyCache *HistoryCache,
 	log logger.Logger,
 ) *ZipCodeRepository {
 	return &ZipCodeRepository{
-		viaCEPClient:     viaCEPClient,
-		weatherAPIClient: weatherAPIClient,
-		logger:           log,
+		viaCEPClient:    viaCEPClient,
+		weatherProvider: weatherProvider,
+		historyCache:    historyCache,
+		now:             time.Now,
+		logger:          log,
 	}
 }
 
+// SetLocationStore makes CEP lookups consult store before ViaCEP and keeps
+// the locations ViaCEP returns in it. Stored locations older than
+// refreshAfter are looked up again, and still served while ViaCEP fails; a
+// refreshAfter of zero never refreshes them.
+func (r *ZipCodeRepository) SetLocationStore(store LocationStore, refreshAfter time.Duration) {
+	r.locationStore = store
+	r.SetLocationRefreshAfter(refreshAfter)
+}
+
+// SetWeatherCache makes current weather be served from cache, stale while
+// it is refreshed in the background; see WeatherCache.
+func (r *ZipCodeRepository) SetWeatherCache(cache *WeatherCache) {
+	r.weatherCache = cache
+}
+
+// SetSharedCache shares the locations looked up on ViaCEP and the weather
+// observations cached with the other replicas through backend, under keys
+// prefixed with namespace. The in-memory caches and the location store still
+// come first. Shared locations expire after locationTTL, so a CEP that moves
+// to another city is eventually looked up again.
+func (r *ZipCodeRepository) SetSharedCache(backend cache.Backend, namespace string, locationTTL time.Duration) {
+	r.sharedLocationTTL = locationTTL
+	r.sharedLocations = cache.NewStore[zipcode.Location](backend, namespace+":location", sharedLocationVersion)
+	r.sharedWeather = cache.NewStore[sharedObservation](backend, namespace+":weather", sharedWeatherVersion)
+}
+
+// Wait waits for the weather refreshes running in the background, which
+// outlive the requests that started them.
+func (r *ZipCodeRepository) Wait() {
+	r.refreshes.Wait()
+}
+
+// SetLocationRefreshAfter changes the age at which stored locations are
+// refreshed, also while the repository is in use.
+func (r *ZipCodeRepository) SetLocationRefreshAfter(refreshAfter time.Duration) {
+	r.refreshAfter.Store(int64(refreshAfter))
+}
+
 func (r *ZipCodeRepository) GetLocationByZipCode(ctx context.Context, zipCode string) (*zipcode.Location, error) {
 	tracer := otel.Tracer("service-b")
 	ctx, span := tracer.Start(ctx, "repository.GetLocationByZipCode")
 	defer span.End()
 
-	return r.viaCEPClient.GetLocationByZipCode(ctx, zipCode)
+	if r.locationStore == nil {
+		return r.lookUpLocation(ctx, zipCode)
+	}
+
+	stored, found, err := r.locationStore.GetLocation(ctx, zipCode)
+	if err != nil {
+		// The directory is an optimization; ViaCEP can still answer.
+		r.logger.Error("Failed to read CEP %s from the location store: %v", zipCode, err)
+	}
+	refreshAfter := time.Duration(r.refreshAfter.Load())
+	fresh := found && (refreshAfter <= 0 || r.now().Sub(stored.UpdatedAt) < refreshAfter)
+	span.SetAttributes(attribute.Bool("cep_store.hit", found), attribute.Bool("cep_store.fresh", fresh))
+	if fresh {
+		return storedLocation(stored), nil
+	}
+
+	location, err := r.lookUpLocation(ctx, zipCode)
+	switch {
+	case err == nil:
+	case !found:
+		return nil, err
+	case errors.Is(err, apperror.ErrZipCodeNotFound):
+		// Kept, the stale location would send every later lookup to ViaCEP.
+		if err := r.locationStore.DeleteLocation(ctx, zipCode); err != nil {
+			r.logger.Error("Failed to delete CEP %s from the location store: %v", zipCode, err)
+		}
+		return nil, err
+	default:
+		r.logger.Info("ViaCEP failed, serving the stored location of CEP %s from %s", zipCode, stored.UpdatedAt.Format(time.RFC3339))
+		return storedLocation(stored), nil
+	}
+
+	err = r.locationStore.SaveLocation(ctx, StoredLocation{
+		CEP:       zipCode,
+		City:      location.City,
+		Source:    SourceViaCEP,
+		UpdatedAt: r.now(),
+	})
+	if err != nil {
+		r.logger.Error("Failed to save CEP %s to the location store: %v", zipCode, err)
+	}
+	return location, nil
 }
 
-func (r *ZipCodeRepository) GetWeatherByCity(ctx context.Context, city string) (*zipcode.WeatherData, error) {
+// lookUpLocation asks the shared cache, then ViaCEP, sharing what ViaCEP
+// returns.
+func (r *ZipCodeRepository) lookUpLocation(ctx context.Context, zipCode string) (*zipcode.Location, error) {
+	if r.sharedLocations == nil {
+		return r.viaCEPClient.GetLocationByZipCode(ctx, zipCode)
+	}
+
+	shared, ok, err := r.sharedLocations.Get(ctx, zipCode)
+	if err != nil {
+		r.logger.Error("Failed to read CEP %s from the shared cache: %v", zipCode, err)
+	}
+	if ok {
+		return shared, nil
+	}
+
+	location, err := r.viaCEPClient.GetLocationByZipCode(ctx, zipCode)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.sharedLocations.Set(ctx, zipCode, location, r.sharedLocationTTL); err != nil {
+		r.logger.Error("Failed to share CEP %s: %v", zipCode, err)
+	}
+	return location, nil
+}
+
+// storedLocation returns a stored location as ViaCEP writes it, with the CEP
+// hyphenated.
+func storedLocation(stored StoredLocation) *zipcode.Location {
+	cep := stored.CEP
+	if len(cep) == 8 {
+		cep = cep[:5] + "-" + cep[5:]
+	}
+	return &zipcode.Location{City: stored.City, CEP: cep}
+}
+
+func (r *ZipCodeRepository) GetWeatherByCity(ctx context.Context, city string, options zipcode.WeatherOptions) (*zipcode.WeatherData, error) {
 	tracer := otel.Tracer("service-b")
 	ctx, span := tracer.Start(ctx, "repository.GetWeatherByCity")
 	defer span.End()
 
-	return r.weatherAPIClient.GetWeatherByCity(ctx, city)
+	if r.weatherCache == nil {
+		return r.weatherProvider.GetWeatherByCity(ctx, city, options)
+	}
+
+	key := weatherCacheKey(ctx, city, options)
+	cached, refresh, ok := r.weatherCache.Get(key)
+	if !ok && r.loadSharedWeather(ctx, key) {
+		cached, refresh, ok = r.weatherCache.Get(key)
+	}
+	span.SetAttributes(attribute.Bool("cache.hit", ok))
+	if ok {
+		span.SetAttributes(attribute.Bool("cache.stale", cached.Stale))
+		if refresh {
+			// The refresh outlives the request, but keeps its language.
+			r.refreshes.Add(1)
+			go r.refreshWeather(context.WithoutCancel(ctx), key, city, options)
+		}
+		return cached, nil
+	}
+
+	weather, err := r.weatherProvider.GetWeatherByCity(ctx, city, options)
+	if err != nil {
+		return nil, err
+	}
+	r.storeWeather(ctx, key, weather)
+	return weather, nil
+}
+
+// refreshWeather replaces a stale observation after it has been served. When
+// the provider fails, the stale observation keeps being served until it is
+// too old.
+func (r *ZipCodeRepository) refreshWeather(ctx context.Context, key, city string, options zipcode.WeatherOptions) {
+	defer r.refreshes.Done()
+
+	tracer := otel.Tracer("service-b")
+	ctx, span := tracer.Start(ctx, "repository.RefreshWeather")
+	defer span.End()
+
+	// Another replica may have refreshed it already.
+	if r.loadSharedWeather(ctx, key) && r.weatherCache.FreshFor(key) > 0 {
+		return
+	}
+
+	weather, err := r.weatherProvider.GetWeatherByCity(ctx, city, options)
+	if err != nil {
+		r.logger.Error("Failed to refresh the weather of %s, serving it stale: %v", city, err)
+		r.weatherCache.RefreshFailed(key)
+		return
+	}
+	r.storeWeather(ctx, key, weather)
+	if weather.Stale {
+		// Only the last known observation was available.
+		r.weatherCache.RefreshFailed(key)
+	}
+}
+
+// loadSharedWeather copies the observation other replicas shared under key
+// into the weather cache, reporting whether there was one.
+func (r *ZipCodeRepository) loadSharedWeather(ctx context.Context, key string) bool {
+	if r.sharedWeather == nil {
+		return false
+	}
+
+	shared, ok, err := r.sharedWeather.Get(ctx, key)
+	if err != nil {
+		r.logger.Error("Failed to read the weather %s from the shared cache: %v", key, err)
+	}
+	if !ok {
+		return false
+	}
+	r.weatherCache.SetAt(key, &shared.Data, shared.ObservedAt)
+	return true
+}
+
+// storeWeather caches an observation from the provider and shares it for as
+// long as it can be served. A stale one, served by the quota guard once the
+// budget is exhausted, is kept by when it was observed, so it is never served
+// past the maximum staleness.
+func (r *ZipCodeRepository) storeWeather(ctx context.Context, key string, weather *zipcode.WeatherData) {
+	observation := *weather
+	observation.Stale = false
+	observation.Age = 0
+	observedAt := r.weatherCache.now().Add(-weather.Age)
+	r.weatherCache.SetAt(key, &observation, observedAt)
+
+	ttl := r.weatherCache.MaxAge() - weather.Age
+	if r.sharedWeather == nil || ttl <= 0 {
+		return
+	}
+	err := r.sharedWeather.Set(ctx, key, &sharedObservation{Data: observation, ObservedAt: observedAt}, ttl)
+	if err != nil {
+		r.logger.Error("Failed to share the weather %s: %v", key, err)
+	}
+}
+
+// weatherCacheKey tells observations apart by everything that changes the
+// provider response: the language of condition texts and the options.
+func weatherCacheKey(ctx context.Context, city string, options zipcode.WeatherOptions) string {
+	return weatherCacheKeyFor(i18n.FromContext(ctx), city, options)
+}
+
+func weatherCacheKeyFor(language i18n.Language, city string, options zipcode.WeatherOptions) string {
+	return fmt.Sprintf("%s:%s:%t:%t", language, strings.ToLower(city), options.Alerts, options.AirQuality)
+}
+
+func (r *ZipCodeRepository) GetForecastByCity(ctx context.Context, city string, days int) (*zipcode.ForecastData, error) {
+	tracer := otel.Tracer("service-b")
+	ctx, span := tracer.Start(ctx, "repository.GetForecastByCity")
+	defer span.End()
+
+	return r.weatherProvider.GetForecastByCity(ctx, city, days)
+}
+
+// GetHistoryByCity serves the range from the history cache when every day is
+// cached and otherwise fetches the whole range from the weather provider.
+func (r *ZipCodeRepository) GetHistoryByCity(ctx context.Context, city string, start, end time.Time) (*zipcode.HistoryData, error) {
+	tracer := otel.Tracer("service-b")
+	ctx, span := tracer.Start(ctx, "repository.GetHistoryByCity")
+	defer span.End()
+
+	// Condition text is localized by the provider, so each language is cached
+	// separately.
+	cacheScope := string(i18n.FromContext(ctx)) + ":" + city
+
+	expectedDays := int(end.Sub(start).Hours()/24) + 1
+	cached := &zipcode.HistoryData{}
+	for date := start; !date.After(end); date = date.AddDate(0, 0, 1) {
+		day, ok := r.historyCache.Get(cacheScope, date)
+		if !ok {
+			break
+		}
+		cached.Days = append(cached.Days, day)
+	}
+
+	if len(cached.Days) == expectedDays {
+		span.SetAttributes(attribute.Bool("cache.hit", true))
+		return cached, nil
+	}
+	span.SetAttributes(attribute.Bool("cache.hit", false))
+
+	history, err := r.weatherProvider.GetHistoryByCity(ctx, city, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, day := range history.Days {
+		r.historyCache.Set(cacheScope, day)
+	}
+
+	return history, nil
 }
@@ -0,0 +1,371 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go-a-b-microservices/pkg/apperror"
+	"go-a-b-microservices/pkg/cache"
+	"go-a-b-microservices/pkg/config"
+	"go-a-b-microservices/pkg/zipcode"
+	"go-a-b-microservices/service-b/internal/adapter/clients"
+)
+
+type MockLogger struct{}
+
+func (m *MockLogger) Info(message string, args ...interface{})  {}
+func (m *MockLogger) Error(message string, args ...interface{}) {}
+func (m *MockLogger) Debug(message string, args ...interface{}) {}
+
+type memoryLocationStore struct {
+	locations map[string]StoredLocation
+	err       error
+}
+
+func (s *memoryLocationStore) GetLocation(ctx context.Context, cep string) (StoredLocation, bool, error) {
+	if s.err != nil {
+		return StoredLocation{}, false, s.err
+	}
+	location, ok := s.locations[cep]
+	return location, ok, nil
+}
+
+func (s *memoryLocationStore) SaveLocation(ctx context.Context, location StoredLocation) error {
+	s.locations[location.CEP] = location
+	return nil
+}
+
+func (s *memoryLocationStore) DeleteLocation(ctx context.Context, cep string) error {
+	delete(s.locations, cep)
+	return nil
+}
+
+// weatherProviderFunc serves current weather from a function and counts the
+// calls, which may come from background refreshes.
+type weatherProviderFunc struct {
+	clients.WeatherProvider
+	get   func() (*zipcode.WeatherData, error)
+	calls atomic.Int32
+}
+
+func (p *weatherProviderFunc) GetWeatherByCity(ctx context.Context, city string, options zipcode.WeatherOptions) (*zipcode.WeatherData, error) {
+	p.calls.Add(1)
+	return p.get()
+}
+
+func TestZipCodeRepository_GetLocationByZipCode(t *testing.T) {
+	now := time.Date(2025, 5, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		stored         *StoredLocation
+		storeErr       error
+		viaCEPStatus   int
+		viaCEPBody     string
+		expectedCity   string
+		expectedErr    error
+		expectedCalls  int
+		expectedStored string
+		expectedGone   bool
+	}{
+		{
+			name:          "Fresh stored location",
+			stored:        &StoredLocation{CEP: "13480000", City: "Limeira", Source: SourceImport, UpdatedAt: now.Add(-time.Hour)},
+			expectedCity:  "Limeira",
+			expectedCalls: 0,
+		},
+		{
+			name:           "Not stored",
+			viaCEPStatus:   http.StatusOK,
+			viaCEPBody:     `{"cep":"13480-000","localidade":"Limeira"}`,
+			expectedCity:   "Limeira",
+			expectedCalls:  1,
+			expectedStored: "Limeira",
+		},
+		{
+			name:           "Stale stored location",
+			stored:         &StoredLocation{CEP: "13480000", City: "Limeyra", Source: SourceImport, UpdatedAt: now.Add(-48 * time.Hour)},
+			viaCEPStatus:   http.StatusOK,
+			viaCEPBody:     `{"cep":"13480-000","localidade":"Limeira"}`,
+			expectedCity:   "Limeira",
+			expectedCalls:  1,
+			expectedStored: "Limeira",
+		},
+		{
+			name:           "Stale stored location while ViaCEP fails",
+			stored:         &StoredLocation{CEP: "13480000", City: "Limeira", Source: SourceViaCEP, UpdatedAt: now.Add(-48 * time.Hour)},
+			viaCEPStatus:   http.StatusServiceUnavailable,
+			expectedCity:   "Limeira",
+			expectedCalls:  1,
+			expectedStored: "Limeira",
+		},
+		{
+			name:          "Stale stored location no longer known",
+			stored:        &StoredLocation{CEP: "13480000", City: "Limeira", Source: SourceViaCEP, UpdatedAt: now.Add(-48 * time.Hour)},
+			viaCEPStatus:  http.StatusOK,
+			viaCEPBody:    `{"erro":"true"}`,
+			expectedErr:   apperror.ErrZipCodeNotFound,
+			expectedCalls: 1,
+			expectedGone:  true,
+		},
+		{
+			name:          "Store unavailable",
+			storeErr:      errors.New("database is locked"),
+			viaCEPStatus:  http.StatusOK,
+			viaCEPBody:    `{"cep":"13480-000","localidade":"Limeira"}`,
+			expectedCity:  "Limeira",
+			expectedCalls: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				w.WriteHeader(tt.viaCEPStatus)
+				w.Write([]byte(tt.viaCEPBody))
+			}))
+			defer server.Close()
+
+			viaCepURL, _ := url.Parse(server.URL)
+			viaCEPClient := clients.NewViaCEPClient(&config.ServiceB{ViaCepURL: viaCepURL}, &MockLogger{})
+
+			store := &memoryLocationStore{locations: make(map[string]StoredLocation), err: tt.storeErr}
+			if tt.stored != nil {
+				store.locations[tt.stored.CEP] = *tt.stored
+			}
+
+			repo := NewZipCodeRepository(viaCEPClient, nil, nil, &MockLogger{})
+			repo.SetLocationStore(store, 24*time.Hour)
+			repo.now = func() time.Time { return now }
+
+			location, err := repo.GetLocationByZipCode(context.Background(), "13480000")
+			if tt.expectedErr != nil {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Fatalf("Expected error %v, got %v", tt.expectedErr, err)
+				}
+			} else if err != nil {
+				t.Fatalf("Unexpected error %v", err)
+			} else if location.City != tt.expectedCity || location.CEP != "13480-000" {
+				t.Errorf("Expected %s at 13480-000, got %+v", tt.expectedCity, location)
+			}
+
+			if calls != tt.expectedCalls {
+				t.Errorf("Expected %d ViaCEP calls, got %d", tt.expectedCalls, calls)
+			}
+			if tt.expectedStored != "" && store.locations["13480000"].City != tt.expectedStored {
+				t.Errorf("Expected %s to be stored, got %+v", tt.expectedStored, store.locations["13480000"])
+			}
+			if _, ok := store.locations["13480000"]; tt.expectedGone && ok {
+				t.Errorf("Expected the stored location to be deleted")
+			}
+		})
+	}
+}
+
+func TestZipCodeRepository_GetWeatherByCity_StaleWhileRevalidate(t *testing.T) {
+	now := time.Date(2025, 5, 10, 12, 0, 0, 0, time.UTC)
+	tempC := 20.0
+	var providerErr error
+	provider := &weatherProviderFunc{get: func() (*zipcode.WeatherData, error) {
+		if providerErr != nil {
+			return nil, providerErr
+		}
+		return &zipcode.WeatherData{Current: zipcode.CurrentWeather{TempC: tempC}}, nil
+	}}
+
+	cache := NewWeatherCache(5*time.Minute, time.Hour, 10)
+	cache.now = func() time.Time { return now }
+	repo := NewZipCodeRepository(nil, provider, nil, &MockLogger{})
+	repo.SetWeatherCache(cache)
+
+	get := func() (*zipcode.WeatherData, error) {
+		t.Helper()
+		weather, err := repo.GetWeatherByCity(context.Background(), "Limeira", zipcode.WeatherOptions{})
+		repo.refreshes.Wait()
+		return weather, err
+	}
+
+	steps := []struct {
+		name          string
+		advance       time.Duration
+		providerErr   error
+		tempC         float64
+		expectedTempC float64
+		expectedStale bool
+		expectedAge   time.Duration
+		expectedErr   bool
+		expectedCalls int32
+	}{
+		{name: "Nothing cached while the provider fails", providerErr: apperror.ErrWeatherUnavailable, expectedErr: true, expectedCalls: 1},
+		{name: "Fetched live", tempC: 20, expectedTempC: 20, expectedCalls: 2},
+		{name: "Fresh", advance: time.Minute, tempC: 21, expectedTempC: 20, expectedCalls: 2},
+		{name: "Stale and refreshed", advance: 9 * time.Minute, tempC: 22, expectedTempC: 20, expectedStale: true, expectedAge: 10 * time.Minute, expectedCalls: 3},
+		{name: "Refreshed", tempC: 23, expectedTempC: 22, expectedCalls: 3},
+		{name: "Stale while the provider fails", advance: 20 * time.Minute, providerErr: apperror.ErrWeatherUnavailable, expectedTempC: 22, expectedStale: true, expectedAge: 20 * time.Minute, expectedCalls: 4},
+		{name: "Still stale, waiting to retry", advance: 10 * time.Second, providerErr: apperror.ErrWeatherUnavailable, expectedTempC: 22, expectedStale: true, expectedAge: 20*time.Minute + 10*time.Second, expectedCalls: 4},
+		{name: "Past the maximum staleness", advance: time.Hour, providerErr: apperror.ErrWeatherUnavailable, expectedErr: true, expectedCalls: 5},
+	}
+
+	for _, step := range steps {
+		now = now.Add(step.advance)
+		providerErr, tempC = step.providerErr, step.tempC
+
+		weather, err := get()
+		if step.expectedErr {
+			if err == nil {
+				t.Errorf("%s: expected an error, got %+v", step.name, weather)
+			}
+		} else if err != nil {
+			t.Errorf("%s: unexpected error %v", step.name, err)
+		} else if weather.Current.TempC != step.expectedTempC || weather.Stale != step.expectedStale || weather.Age != step.expectedAge {
+			t.Errorf("%s: expected %.0f°C, stale %v, age %s, got %.0f°C, stale %v, age %s", step.name,
+				step.expectedTempC, step.expectedStale, step.expectedAge, weather.Current.TempC, weather.Stale, weather.Age)
+		}
+		if calls := provider.calls.Load(); calls != step.expectedCalls {
+			t.Errorf("%s: expected %d provider calls in total, got %d", step.name, step.expectedCalls, calls)
+		}
+	}
+}
+
+func TestZipCodeRepository_GetWeatherByCity_LastKnown(t *testing.T) {
+	now := time.Date(2025, 5, 10, 12, 0, 0, 0, time.UTC)
+	// The quota guard serves an observation made 50 minutes ago.
+	observedAt := now.Add(-50 * time.Minute)
+	provider := &weatherProviderFunc{get: func() (*zipcode.WeatherData, error) {
+		return &zipcode.WeatherData{Current: zipcode.CurrentWeather{TempC: 20}, Stale: true, Age: now.Sub(observedAt)}, nil
+	}}
+
+	weatherCache := NewWeatherCache(5*time.Minute, time.Hour, 10)
+	weatherCache.now = func() time.Time { return now }
+	shared := &ttlBackend{Backend: cache.NewMemoryBackend(0), ttls: make(map[string]time.Duration)}
+	repo := NewZipCodeRepository(nil, provider, nil, &MockLogger{})
+	repo.SetWeatherCache(weatherCache)
+	repo.SetSharedCache(shared, "service-b", time.Hour)
+
+	get := func() *zipcode.WeatherData {
+		t.Helper()
+		weather, err := repo.GetWeatherByCity(context.Background(), "Limeira", zipcode.WeatherOptions{})
+		repo.refreshes.Wait()
+		if err != nil {
+			t.Fatalf("Unexpected error %v", err)
+		}
+		return weather
+	}
+
+	if weather := get(); !weather.Stale || weather.Age != 50*time.Minute {
+		t.Errorf("Expected the observation to be served 50m stale, got %+v", weather)
+	}
+	if ttl := shared.ttls[repo.sharedWeather.Key(weatherCacheKey(context.Background(), "Limeira", zipcode.WeatherOptions{}))]; ttl != 10*time.Minute {
+		t.Errorf("Expected it to be shared for the 10m left to serve it, got %s", ttl)
+	}
+
+	now = now.Add(5 * time.Minute)
+	if weather := get(); !weather.Stale || weather.Age != 55*time.Minute || provider.calls.Load() != 2 {
+		t.Errorf("Expected the cached copy 55m old and a refresh, got %+v and %d calls", weather, provider.calls.Load())
+	}
+
+	// The refresh only got the same observation back, so it is not retried
+	// right away.
+	now = now.Add(20 * time.Second)
+	if get(); provider.calls.Load() != 2 {
+		t.Errorf("Expected no refresh until the retry delay passes, got %d calls", provider.calls.Load())
+	}
+
+	now = now.Add(5*time.Minute + 40*time.Second)
+	if weather := get(); weather.Age != 61*time.Minute || provider.calls.Load() != 3 {
+		t.Errorf("Expected the provider to be asked once the copy is past the maximum staleness, got %+v and %d calls", weather, provider.calls.Load())
+	}
+}
+
+func TestZipCodeRepository_SharedCache(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 5, 10, 12, 0, 0, 0, time.UTC)
+	shared := &ttlBackend{Backend: cache.NewMemoryBackend(0), ttls: make(map[string]time.Duration)}
+
+	viaCEPCalls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		viaCEPCalls++
+		w.Write([]byte(`{"cep":"13480-000","localidade":"Limeira"}`))
+	}))
+	defer server.Close()
+	viaCepURL, _ := url.Parse(server.URL)
+
+	type replica struct {
+		repo     *ZipCodeRepository
+		provider *weatherProviderFunc
+	}
+	newReplica := func(tempC float64) replica {
+		provider := &weatherProviderFunc{get: func() (*zipcode.WeatherData, error) {
+			return &zipcode.WeatherData{Current: zipcode.CurrentWeather{TempC: tempC}}, nil
+		}}
+		weatherCache := NewWeatherCache(5*time.Minute, time.Hour, 10)
+		weatherCache.now = func() time.Time { return now }
+		viaCEPClient := clients.NewViaCEPClient(&config.ServiceB{ViaCepURL: viaCepURL}, &MockLogger{})
+		repo := NewZipCodeRepository(viaCEPClient, provider, nil, &MockLogger{})
+		repo.SetWeatherCache(weatherCache)
+		repo.SetSharedCache(shared, "service-b", 720*time.Hour)
+		return replica{repo: repo, provider: provider}
+	}
+	a, b := newReplica(20), newReplica(25)
+
+	get := func(r replica) *zipcode.WeatherData {
+		t.Helper()
+		weather, err := r.repo.GetWeatherByCity(ctx, "Limeira", zipcode.WeatherOptions{})
+		r.repo.refreshes.Wait()
+		if err != nil {
+			t.Fatalf("Unexpected error %v", err)
+		}
+		return weather
+	}
+
+	for _, r := range []replica{a, b} {
+		location, err := r.repo.GetLocationByZipCode(ctx, "13480000")
+		if err != nil || location.City != "Limeira" {
+			t.Fatalf("Expected Limeira, got %+v, %v", location, err)
+		}
+	}
+	if viaCEPCalls != 1 {
+		t.Errorf("Expected the second replica to use the shared location, got %d ViaCEP calls", viaCEPCalls)
+	}
+	if ttl := shared.ttls["service-b:location:v1:13480000"]; ttl != 720*time.Hour {
+		t.Errorf("Expected the shared location to expire after 720h, got %s", ttl)
+	}
+
+	if weather := get(a); weather.Current.TempC != 20 {
+		t.Fatalf("Expected 20°C from replica A's provider, got %.0f°C", weather.Current.TempC)
+	}
+	if weather := get(b); weather.Current.TempC != 20 || b.provider.calls.Load() != 0 {
+		t.Errorf("Expected replica B to serve the shared observation, got %.0f°C and %d calls", weather.Current.TempC, b.provider.calls.Load())
+	}
+
+	// Both go stale; B refreshes first, and A picks up its refresh.
+	now = now.Add(6 * time.Minute)
+	if weather := get(b); !weather.Stale || weather.Age != 6*time.Minute || b.provider.calls.Load() != 1 {
+		t.Errorf("Expected replica B to serve stale and refresh, got %+v and %d calls", weather, b.provider.calls.Load())
+	}
+	if weather := get(a); !weather.Stale || a.provider.calls.Load() != 1 {
+		t.Errorf("Expected replica A to serve stale without calling its provider, got %+v and %d calls", weather, a.provider.calls.Load())
+	}
+	if weather := get(a); weather.Stale || weather.Current.TempC != 25 {
+		t.Errorf("Expected replica A to serve replica B's refresh, got %+v", weather)
+	}
+}
+
+// ttlBackend records the TTL each key is set with.
+type ttlBackend struct {
+	cache.Backend
+	ttls map[string]time.Duration
+}
+
+func (b *ttlBackend) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	b.ttls[key] = ttl
+	return b.Backend.Set(ctx, key, value, ttl)
+}
@@ -0,0 +1,73 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+
+	"go-a-b-microservices/pkg/apperror"
+	"go-a-b-microservices/pkg/zipcode"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const batchConcurrency = 4
+
+// ProcessZipCodeBatch processes up to zipcode.MaxBatchSize requests
+// concurrently and passes each result to onResult as it completes, with the
+// index of its request. onResult is never called concurrently. A failed
+// request is reported to onResult and does not stop the batch; an error
+// returned by onResult does.
+func (uc *ZipCodeUseCase) ProcessZipCodeBatch(ctx context.Context, requests []zipcode.ZipCodeRequest, onResult func(index int, response *zipcode.WeatherResponse, err error) error) error {
+	tracer := otel.Tracer("service-b")
+	ctx, span := tracer.Start(ctx, "usecase.ProcessZipCodeBatch")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int("batch.size", len(requests)))
+
+	if len(requests) > zipcode.MaxBatchSize {
+		return apperror.ErrInvalidRequest
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		resultErr error
+	)
+	semaphore := make(chan struct{}, batchConcurrency)
+
+	for i := range requests {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			select {
+			case semaphore <- struct{}{}:
+				defer func() { <-semaphore }()
+			case <-ctx.Done():
+				return
+			}
+
+			response, err := uc.ProcessZipCode(ctx, &requests[i])
+
+			mu.Lock()
+			defer mu.Unlock()
+			if resultErr != nil {
+				return
+			}
+			if resultErr = onResult(i, response, err); resultErr != nil {
+				cancel()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if resultErr != nil {
+		return resultErr
+	}
+	return ctx.Err()
+}
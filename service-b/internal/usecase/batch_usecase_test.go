@@ -0,0 +1,79 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go-a-b-microservices/pkg/apperror"
+	"go-a-b-microservices/pkg/zipcode"
+)
+
+func TestZipCodeUseCase_ProcessZipCodeBatch(t *testing.T) {
+	repository := &MockZipCodeRepository{
+		GetLocationByZipCodeFunc: func(ctx context.Context, zipCode string) (*zipcode.Location, error) {
+			if zipCode == "99999999" {
+				return nil, apperror.ErrZipCodeNotFound
+			}
+			return &zipcode.Location{City: "City " + zipCode, CEP: zipCode}, nil
+		},
+		GetWeatherByCityFunc: func(ctx context.Context, city string, options zipcode.WeatherOptions) (*zipcode.WeatherData, error) {
+			return &zipcode.WeatherData{Current: zipcode.CurrentWeather{TempC: 20}}, nil
+		},
+	}
+	useCase := NewZipCodeUseCase(repository, &MockLogger{})
+
+	t.Run("reports every result with its index", func(t *testing.T) {
+		requests := []zipcode.ZipCodeRequest{{CEP: "01001000"}, {CEP: "123"}, {CEP: "99999999"}, {CEP: "13484000"}}
+		expectedErrs := []error{nil, apperror.ErrZipCodeInvalid, apperror.ErrZipCodeNotFound, nil}
+
+		seen := make(map[int]bool)
+		err := useCase.ProcessZipCodeBatch(context.Background(), requests, func(index int, response *zipcode.WeatherResponse, err error) error {
+			seen[index] = true
+			if !errors.Is(err, expectedErrs[index]) {
+				t.Errorf("Result %d: expected error %v, got %v", index, expectedErrs[index], err)
+			}
+			if err == nil && response.City != "City "+requests[index].CEP {
+				t.Errorf("Result %d: unexpected response %+v", index, response)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(seen) != len(requests) {
+			t.Errorf("Expected %d results, got %d", len(requests), len(seen))
+		}
+	})
+
+	t.Run("stops when onResult fails", func(t *testing.T) {
+		requests := make([]zipcode.ZipCodeRequest, 20)
+		for i := range requests {
+			requests[i] = zipcode.ZipCodeRequest{CEP: "01001000"}
+		}
+		stop := errors.New("stream closed")
+
+		calls := 0
+		err := useCase.ProcessZipCodeBatch(context.Background(), requests, func(int, *zipcode.WeatherResponse, error) error {
+			calls++
+			return stop
+		})
+		if !errors.Is(err, stop) {
+			t.Errorf("Expected error %v, got %v", stop, err)
+		}
+		if calls != 1 {
+			t.Errorf("Expected onResult to be called once, got %d", calls)
+		}
+	})
+
+	t.Run("rejects oversized batches", func(t *testing.T) {
+		requests := make([]zipcode.ZipCodeRequest, zipcode.MaxBatchSize+1)
+		err := useCase.ProcessZipCodeBatch(context.Background(), requests, func(int, *zipcode.WeatherResponse, error) error {
+			t.Fatal("Unexpected result")
+			return nil
+		})
+		if !errors.Is(err, apperror.ErrInvalidRequest) {
+			t.Errorf("Expected error %v, got %v", apperror.ErrInvalidRequest, err)
+		}
+	})
+}
@@ -0,0 +1,68 @@
+package usecase
+
+import (
+	"context"
+
+	"go-a-b-microservices/pkg/temperature"
+	"go-a-b-microservices/pkg/zipcode"
+
+	"go.opentelemetry.io/otel"
+)
+
+func (uc *ZipCodeUseCase) ProcessForecast(ctx context.Context, request *zipcode.ForecastRequest) (*zipcode.ForecastResponse, error) {
+	tracer := otel.Tracer("service-b")
+	ctx, span := tracer.Start(ctx, "usecase.ProcessForecast")
+	defer span.End()
+
+	if err := request.Validate(); err != nil {
+		uc.logger.Error("Invalid forecast request: %v", err)
+		return nil, err
+	}
+
+	units, err := temperature.ParseUnits(request.Units)
+	if err != nil {
+		return nil, err
+	}
+
+	location, err := uc.repository.GetLocationByZipCode(ctx, request.CEP)
+	if err != nil {
+		uc.logger.Error("Error getting location: %v", err)
+		return nil, err
+	}
+
+	forecast, err := uc.repository.GetForecastByCity(ctx, location.City, request.ForecastDays())
+	if err != nil {
+		uc.logger.Error("Error getting forecast: %v", err)
+		return nil, err
+	}
+
+	response := &zipcode.ForecastResponse{
+		City: location.City,
+		Days: make([]zipcode.ForecastDay, 0, len(forecast.Days)),
+	}
+
+	for _, daily := range forecast.Days {
+		day := zipcode.ForecastDay{
+			Date:         daily.Date,
+			MinTemp:      uc.newTemperature(daily.MinTempC, units),
+			MaxTemp:      uc.newTemperature(daily.MaxTempC, units),
+			ChanceOfRain: daily.ChanceOfRain,
+			Condition:    conditionText(daily.Condition),
+		}
+
+		if request.Hourly {
+			for _, hourly := range daily.Hours {
+				day.Hourly = append(day.Hourly, zipcode.ForecastHour{
+					Time:         hourly.Time,
+					Temp:         uc.newTemperature(hourly.TempC, units),
+					ChanceOfRain: hourly.ChanceOfRain,
+					Condition:    conditionText(hourly.Condition),
+				})
+			}
+		}
+
+		response.Days = append(response.Days, day)
+	}
+
+	return response, nil
+}
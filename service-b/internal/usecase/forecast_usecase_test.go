@@ -0,0 +1,147 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"go-a-b-microservices/pkg/apperror"
+	"go-a-b-microservices/pkg/temperature"
+	"go-a-b-microservices/pkg/zipcode"
+)
+
+func TestZipCodeUseCase_ProcessForecast(t *testing.T) {
+	forecastData := &zipcode.ForecastData{
+		Days: []zipcode.DailyForecast{
+			{
+				Date:         "2025-05-01",
+				MinTempC:     15,
+				MaxTempC:     27.5,
+				ChanceOfRain: 40,
+				Condition:    &zipcode.Condition{Text: "Patchy rain possible"},
+				Hours: []zipcode.HourlyForecast{
+					{Time: "2025-05-01 00:00", TempC: 16, ChanceOfRain: 10},
+					{Time: "2025-05-01 01:00", TempC: 15.5, ChanceOfRain: 20},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		request      zipcode.ForecastRequest
+		expectedDays int
+		expectedErr  error
+	}{
+		{
+			name:         "defaults to three days",
+			request:      zipcode.ForecastRequest{CEP: "13484000"},
+			expectedDays: zipcode.DefaultForecastDays,
+		},
+		{
+			name:         "uses requested days",
+			request:      zipcode.ForecastRequest{CEP: "13484000", Days: 7},
+			expectedDays: 7,
+		},
+		{
+			name:        "rejects too many days",
+			request:     zipcode.ForecastRequest{CEP: "13484000", Days: zipcode.MaxForecastDays + 1},
+			expectedErr: apperror.ErrForecastDaysInvalid,
+		},
+		{
+			name:        "rejects unknown units",
+			request:     zipcode.ForecastRequest{CEP: "13484000", Units: "C,X"},
+			expectedErr: apperror.ErrUnitsInvalid,
+		},
+		{
+			name:        "rejects invalid zip code",
+			request:     zipcode.ForecastRequest{CEP: "invalid"},
+			expectedErr: apperror.ErrZipCodeInvalid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requestedDays int
+			mockRepo := &MockZipCodeRepository{
+				GetLocationByZipCodeFunc: func(ctx context.Context, zipCode string) (*zipcode.Location, error) {
+					return &zipcode.Location{City: "Limeira", CEP: zipCode}, nil
+				},
+				GetForecastByCityFunc: func(ctx context.Context, city string, days int) (*zipcode.ForecastData, error) {
+					requestedDays = days
+					return forecastData, nil
+				},
+			}
+			useCase := NewZipCodeUseCase(mockRepo, &MockLogger{})
+
+			response, err := useCase.ProcessForecast(context.Background(), &tt.request)
+
+			if tt.expectedErr != nil {
+				if err != tt.expectedErr {
+					t.Errorf("Expected error %v, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected no error, but got %v", err)
+			}
+
+			if requestedDays != tt.expectedDays {
+				t.Errorf("Expected %d days to be requested, got %d", tt.expectedDays, requestedDays)
+			}
+
+			if response.City != "Limeira" || len(response.Days) != 1 {
+				t.Fatalf("Unexpected response %+v", response)
+			}
+
+			day := response.Days[0]
+			if day.ChanceOfRain != 40 || day.Condition != "Patchy rain possible" {
+				t.Errorf("Unexpected day %+v", day)
+			}
+
+			if len(day.Hourly) != 0 {
+				t.Errorf("Expected no hourly points when not requested, got %d", len(day.Hourly))
+			}
+		})
+	}
+}
+
+func TestZipCodeUseCase_ProcessForecast_UnitsAndHourly(t *testing.T) {
+	mockRepo := &MockZipCodeRepository{
+		GetLocationByZipCodeFunc: func(ctx context.Context, zipCode string) (*zipcode.Location, error) {
+			return &zipcode.Location{City: "Limeira", CEP: zipCode}, nil
+		},
+		GetForecastByCityFunc: func(ctx context.Context, city string, days int) (*zipcode.ForecastData, error) {
+			return &zipcode.ForecastData{
+				Days: []zipcode.DailyForecast{
+					{
+						Date:     "2025-05-01",
+						MinTempC: 10,
+						MaxTempC: 20,
+						Hours:    []zipcode.HourlyForecast{{Time: "2025-05-01 00:00", TempC: 12}},
+					},
+				},
+			}, nil
+		},
+	}
+	useCase := NewZipCodeUseCase(mockRepo, &MockLogger{})
+
+	request := &zipcode.ForecastRequest{CEP: "13484000", Units: "f", Hourly: true}
+	response, err := useCase.ProcessForecast(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+
+	day := response.Days[0]
+	if day.MinTemp.C != nil || day.MinTemp.K != nil {
+		t.Errorf("Expected only Fahrenheit, got %+v", day.MinTemp)
+	}
+
+	if day.MinTemp.F == nil || *day.MinTemp.F != temperature.CelsiusToFahrenheit(10) {
+		t.Errorf("Expected min temperature %v F, got %v", temperature.CelsiusToFahrenheit(10), day.MinTemp.F)
+	}
+
+	if len(day.Hourly) != 1 || day.Hourly[0].Temp.F == nil {
+		t.Errorf("Expected one hourly point in Fahrenheit, got %+v", day.Hourly)
+	}
+}
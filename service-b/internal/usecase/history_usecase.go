@@ -0,0 +1,67 @@
+package usecase
+
+import (
+	"context"
+
+	"go-a-b-microservices/pkg/temperature"
+	"go-a-b-microservices/pkg/zipcode"
+
+	"go.opentelemetry.io/otel"
+)
+
+func (uc *ZipCodeUseCase) ProcessHistory(ctx context.Context, request *zipcode.HistoryRequest) (*zipcode.HistoryResponse, error) {
+	tracer := otel.Tracer("service-b")
+	ctx, span := tracer.Start(ctx, "usecase.ProcessHistory")
+	defer span.End()
+
+	if err := request.Validate(); err != nil {
+		uc.logger.Error("Invalid history request: %v", err)
+		return nil, err
+	}
+
+	if err := request.ValidateBounds(uc.now(), uc.historyMaxAgeDays); err != nil {
+		uc.logger.Error("History request out of bounds: %v", err)
+		return nil, err
+	}
+
+	units, err := temperature.ParseUnits(request.Units)
+	if err != nil {
+		return nil, err
+	}
+
+	start, end, err := request.Dates()
+	if err != nil {
+		return nil, err
+	}
+
+	location, err := uc.repository.GetLocationByZipCode(ctx, request.CEP)
+	if err != nil {
+		uc.logger.Error("Error getting location: %v", err)
+		return nil, err
+	}
+
+	history, err := uc.repository.GetHistoryByCity(ctx, location.City, start, end)
+	if err != nil {
+		uc.logger.Error("Error getting history: %v", err)
+		return nil, err
+	}
+
+	response := &zipcode.HistoryResponse{
+		City: location.City,
+		Days: make([]zipcode.HistoryDay, 0, len(history.Days)),
+	}
+
+	for _, day := range history.Days {
+		response.Days = append(response.Days, zipcode.HistoryDay{
+			Date:          day.Date,
+			MinTemp:       uc.newTemperature(day.MinTempC, units),
+			MaxTemp:       uc.newTemperature(day.MaxTempC, units),
+			AvgTemp:       uc.newTemperature(day.AvgTempC, units),
+			TotalPrecipMm: day.TotalPrecipMm,
+			AvgHumidity:   day.AvgHumidity,
+			Condition:     conditionText(day.Condition),
+		})
+	}
+
+	return response, nil
+}
@@ -0,0 +1,98 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-a-b-microservices/pkg/apperror"
+	"go-a-b-microservices/pkg/zipcode"
+)
+
+func TestZipCodeUseCase_ProcessHistory(t *testing.T) {
+	now := time.Date(2025, 5, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		request       zipcode.HistoryRequest
+		maxAgeDays    int
+		expectedStart string
+		expectedEnd   string
+		expectedErr   error
+	}{
+		{
+			name:          "single past date",
+			request:       zipcode.HistoryRequest{CEP: "13484000", Date: "2025-05-01"},
+			maxAgeDays:    365,
+			expectedStart: "2025-05-01",
+			expectedEnd:   "2025-05-01",
+		},
+		{
+			name:          "date range",
+			request:       zipcode.HistoryRequest{CEP: "13484000", Date: "2025-05-01", EndDate: "2025-05-03"},
+			maxAgeDays:    365,
+			expectedStart: "2025-05-01",
+			expectedEnd:   "2025-05-03",
+		},
+		{
+			name:        "future date",
+			request:     zipcode.HistoryRequest{CEP: "13484000", Date: "2025-05-11"},
+			maxAgeDays:  365,
+			expectedErr: apperror.ErrDateOutOfRange,
+		},
+		{
+			name:        "older than the configured retention",
+			request:     zipcode.HistoryRequest{CEP: "13484000", Date: "2025-05-01"},
+			maxAgeDays:  7,
+			expectedErr: apperror.ErrDateOutOfRange,
+		},
+		{
+			name:        "malformed date",
+			request:     zipcode.HistoryRequest{CEP: "13484000", Date: "yesterday"},
+			maxAgeDays:  365,
+			expectedErr: apperror.ErrDateInvalid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var start, end time.Time
+			mockRepo := &MockZipCodeRepository{
+				GetLocationByZipCodeFunc: func(ctx context.Context, zipCode string) (*zipcode.Location, error) {
+					return &zipcode.Location{City: "Limeira", CEP: zipCode}, nil
+				},
+				GetHistoryByCityFunc: func(ctx context.Context, city string, s, e time.Time) (*zipcode.HistoryData, error) {
+					start, end = s, e
+					return &zipcode.HistoryData{
+						Days: []zipcode.HistoricalDay{{Date: tt.request.Date, MinTempC: 14, MaxTempC: 26, AvgTempC: 20}},
+					}, nil
+				},
+			}
+			useCase := NewZipCodeUseCase(mockRepo, &MockLogger{})
+			useCase.SetHistoryMaxAgeDays(tt.maxAgeDays)
+			useCase.now = func() time.Time { return now }
+
+			response, err := useCase.ProcessHistory(context.Background(), &tt.request)
+
+			if tt.expectedErr != nil {
+				if err != tt.expectedErr {
+					t.Errorf("Expected error %v, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected no error, but got %v", err)
+			}
+
+			if start.Format(zipcode.DateLayout) != tt.expectedStart || end.Format(zipcode.DateLayout) != tt.expectedEnd {
+				t.Errorf("Expected range %s..%s, got %s..%s", tt.expectedStart, tt.expectedEnd,
+					start.Format(zipcode.DateLayout), end.Format(zipcode.DateLayout))
+			}
+
+			if len(response.Days) != 1 || response.Days[0].AvgTemp.C == nil || *response.Days[0].AvgTemp.C != 20 {
+				t.Errorf("Unexpected response %+v", response)
+			}
+		})
+	}
+}
@@ -2,82 +2,73 @@ package usecase
 
 import (
 	"testing"
+
+	"go-a-b-microservices/pkg/temperature"
 )
 
-func Test_celsiusToFahrenheit(t *testing.T) {
+func Test_newTemperature(t *testing.T) {
 	tests := []struct {
-		name     string
-		celsius  float64
-		expected float64
+		name      string
+		converter temperature.Converter
+		celsius   float64
+		units     []temperature.Unit
+		expectedC *float64
+		expectedF *float64
+		expectedK *float64
 	}{
 		{
-			name:     "zero celsius",
-			celsius:  0,
-			expected: 32,
-		},
-		{
-			name:     "positive value",
-			celsius:  25,
-			expected: 77,
+			name:      "rounds to provider precision",
+			converter: temperature.DefaultConverter,
+			celsius:   28.3,
+			units:     temperature.AllUnits,
+			expectedC: floatPtr(28.3),
+			expectedF: floatPtr(82.9),
+			expectedK: floatPtr(301.5),
 		},
 		{
-			name:     "negative value",
-			celsius:  -10,
-			expected: 14,
+			name:      "configured precision",
+			converter: temperature.NewConverter(2, temperature.RoundHalfUp),
+			celsius:   28.3,
+			units:     temperature.AllUnits,
+			expectedC: floatPtr(28.3),
+			expectedF: floatPtr(82.94),
+			expectedK: floatPtr(301.45),
 		},
 		{
-			name:     "decimal value",
-			celsius:  37.5,
-			expected: 99.5,
+			name:      "only requested units",
+			converter: temperature.DefaultConverter,
+			celsius:   -1.2,
+			units:     []temperature.Unit{temperature.Kelvin},
+			expectedK: floatPtr(272),
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := celsiusToFahrenheit(tt.celsius)
+			useCase := NewZipCodeUseCase(nil, &MockLogger{})
+			useCase.SetTemperatureConverter(tt.converter)
 
-			if result != tt.expected {
-				t.Errorf("celsiusToFahrenheit(%v) = %v, want %v", tt.celsius, result, tt.expected)
-			}
+			result := useCase.newTemperature(tt.celsius, tt.units)
+
+			assertTemperature(t, "C", result.C, tt.expectedC)
+			assertTemperature(t, "F", result.F, tt.expectedF)
+			assertTemperature(t, "K", result.K, tt.expectedK)
 		})
 	}
 }
 
-func Test_celsiusToKelvin(t *testing.T) {
-	tests := []struct {
-		name     string
-		celsius  float64
-		expected float64
-	}{
-		{
-			name:     "zero celsius",
-			celsius:  0,
-			expected: 273,
-		},
-		{
-			name:     "positive value",
-			celsius:  25,
-			expected: 298,
-		},
-		{
-			name:     "negative value",
-			celsius:  -10,
-			expected: 263,
-		},
-		{
-			name:     "decimal value",
-			celsius:  37.5,
-			expected: 310.5,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := celsiusToKelvin(tt.celsius)
+func assertTemperature(t *testing.T, unit string, got, want *float64) {
+	t.Helper()
 
-			if result != tt.expected {
-				t.Errorf("celsiusToKelvin(%v) = %v, want %v", tt.celsius, result, tt.expected)
-			}
-		})
+	if (got == nil) != (want == nil) {
+		t.Errorf("%s: got %v, want %v", unit, got, want)
+		return
+	}
+	if got != nil && *got != *want {
+		t.Errorf("%s: got %v, want %v", unit, *got, *want)
 	}
 }
+
+func floatPtr(value float64) *float64 {
+	return &value
+}
@@ -2,8 +2,10 @@ package usecase
 
 import (
 	"context"
+	"time"
 
 	"go-a-b-microservices/pkg/logger"
+	"go-a-b-microservices/pkg/temperature"
 	"go-a-b-microservices/pkg/zipcode"
 	"go-a-b-microservices/service-b/internal/repository"
 
@@ -11,17 +13,42 @@ import (
 )
 
 type ZipCodeUseCase struct {
-	repository repository.ZipCodeRepositoryInterface
-	logger     logger.Logger
+	repository        repository.ZipCodeRepositoryInterface
+	logger            logger.Logger
+	converter         temperature.Converter
+	historyMaxAgeDays int
+	demand            repository.DemandRecorder
+	now               func() time.Time
 }
 
 func NewZipCodeUseCase(repository repository.ZipCodeRepositoryInterface, logger logger.Logger) *ZipCodeUseCase {
 	return &ZipCodeUseCase{
-		repository: repository,
-		logger:     logger,
+		repository:        repository,
+		logger:            logger,
+		converter:         temperature.DefaultConverter,
+		historyMaxAgeDays: zipcode.DefaultHistoryMaxAgeDays,
+		now:               time.Now,
 	}
 }
 
+// SetHistoryMaxAgeDays limits how far back history can be requested, matching
+// the retention of the configured WeatherAPI plan.
+func (uc *ZipCodeUseCase) SetHistoryMaxAgeDays(days int) {
+	uc.historyMaxAgeDays = days
+}
+
+// SetTemperatureConverter sets the precision and rounding applied to every
+// temperature in the responses.
+func (uc *ZipCodeUseCase) SetTemperatureConverter(converter temperature.Converter) {
+	uc.converter = converter
+}
+
+// SetDemandRecorder has every weather lookup served for a CEP counted by
+// recorder, such as a repository.WeatherWarmer.
+func (uc *ZipCodeUseCase) SetDemandRecorder(recorder repository.DemandRecorder) {
+	uc.demand = recorder
+}
+
 func (uc *ZipCodeUseCase) ProcessZipCode(ctx context.Context, request *zipcode.ZipCodeRequest) (*zipcode.WeatherResponse, error) {
 	tracer := otel.Tracer("service-b")
 	ctx, span := tracer.Start(ctx, "usecase.ProcessZipCode")
@@ -32,36 +59,91 @@ func (uc *ZipCodeUseCase) ProcessZipCode(ctx context.Context, request *zipcode.Z
 		return nil, err
 	}
 
+	units, err := temperature.ParseUnits(request.Units)
+	if err != nil {
+		return nil, err
+	}
+
 	location, err := uc.repository.GetLocationByZipCode(ctx, request.CEP)
 	if err != nil {
 		uc.logger.Error("Error getting location: %v", err)
 		return nil, err
 	}
 
-	weather, err := uc.repository.GetWeatherByCity(ctx, location.City)
+	weather, err := uc.repository.GetWeatherByCity(ctx, location.City, request.Options())
 	if err != nil {
 		uc.logger.Error("Error getting weather: %v", err)
 		return nil, err
 	}
+	if uc.demand != nil {
+		uc.demand.Record(ctx, request.CEP, location.City, request.Options())
+	}
 
-	tempC := weather.Current.TempC
-	tempF := celsiusToFahrenheit(tempC)
-	tempK := celsiusToKelvin(tempC)
+	temp := uc.newTemperature(weather.Current.TempC, units)
 
 	response := &zipcode.WeatherResponse{
-		City:  location.City,
-		TempC: tempC,
-		TempF: tempF,
-		TempK: tempK,
+		City:       location.City,
+		TempC:      temp.C,
+		TempF:      temp.F,
+		TempK:      temp.K,
+		Conditions: uc.buildConditions(&weather.Current, units),
+		Alerts:     weather.Alerts,
+		AirQuality: weather.AirQuality,
+		Stale:      weather.Stale,
+		AgeSeconds: int64(weather.Age / time.Second),
 	}
 
 	return response, nil
 }
 
-func celsiusToFahrenheit(celsius float64) float64 {
-	return celsius*1.8 + 32
+func (uc *ZipCodeUseCase) buildConditions(current *zipcode.CurrentWeather, units []temperature.Unit) zipcode.Conditions {
+	conditions := zipcode.Conditions{
+		Humidity:   current.Humidity,
+		WindKph:    current.WindKph,
+		WindDegree: current.WindDegree,
+		WindDir:    current.WindDir,
+		PressureMb: current.PressureMb,
+		PrecipMm:   current.PrecipMm,
+		UV:         current.UV,
+		IsDay:      current.IsDay,
+	}
+
+	if current.FeelsLikeC != nil {
+		feelsLike := uc.newTemperature(*current.FeelsLikeC, units)
+		conditions.FeelsLikeC = feelsLike.C
+		conditions.FeelsLikeF = feelsLike.F
+		conditions.FeelsLikeK = feelsLike.K
+	}
+
+	if current.Condition != nil {
+		conditions.Condition = current.Condition.Text
+		conditions.ConditionIcon = current.Condition.Icon
+	}
+
+	return conditions
 }
 
-func celsiusToKelvin(celsius float64) float64 {
-	return celsius + 273
+// newTemperature converts a Celsius reading into each requested unit, rounded
+// by the configured converter.
+func (uc *ZipCodeUseCase) newTemperature(celsius float64, units []temperature.Unit) zipcode.Temperature {
+	var result zipcode.Temperature
+	for _, unit := range units {
+		value := uc.converter.Convert(celsius, unit)
+		switch unit {
+		case temperature.Celsius:
+			result.C = &value
+		case temperature.Fahrenheit:
+			result.F = &value
+		case temperature.Kelvin:
+			result.K = &value
+		}
+	}
+	return result
+}
+
+func conditionText(condition *zipcode.Condition) string {
+	if condition == nil {
+		return ""
+	}
+	return condition.Text
 }
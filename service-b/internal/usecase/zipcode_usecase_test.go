@@ -3,24 +3,36 @@ package usecase
 import (
 	"context"
 	"testing"
+	"time"
 
 	"go-a-b-microservices/pkg/apperror"
 	"go-a-b-microservices/pkg/logger"
+	"go-a-b-microservices/pkg/temperature"
 	"go-a-b-microservices/pkg/zipcode"
 	"go-a-b-microservices/service-b/internal/repository"
 )
 
 type MockZipCodeRepository struct {
 	GetLocationByZipCodeFunc func(ctx context.Context, zipCode string) (*zipcode.Location, error)
-	GetWeatherByCityFunc     func(ctx context.Context, city string) (*zipcode.WeatherData, error)
+	GetWeatherByCityFunc     func(ctx context.Context, city string, options zipcode.WeatherOptions) (*zipcode.WeatherData, error)
+	GetForecastByCityFunc    func(ctx context.Context, city string, days int) (*zipcode.ForecastData, error)
+	GetHistoryByCityFunc     func(ctx context.Context, city string, start, end time.Time) (*zipcode.HistoryData, error)
 }
 
 func (m *MockZipCodeRepository) GetLocationByZipCode(ctx context.Context, zipCode string) (*zipcode.Location, error) {
 	return m.GetLocationByZipCodeFunc(ctx, zipCode)
 }
 
-func (m *MockZipCodeRepository) GetWeatherByCity(ctx context.Context, city string) (*zipcode.WeatherData, error) {
-	return m.GetWeatherByCityFunc(ctx, city)
+func (m *MockZipCodeRepository) GetWeatherByCity(ctx context.Context, city string, options zipcode.WeatherOptions) (*zipcode.WeatherData, error) {
+	return m.GetWeatherByCityFunc(ctx, city, options)
+}
+
+func (m *MockZipCodeRepository) GetForecastByCity(ctx context.Context, city string, days int) (*zipcode.ForecastData, error) {
+	return m.GetForecastByCityFunc(ctx, city, days)
+}
+
+func (m *MockZipCodeRepository) GetHistoryByCity(ctx context.Context, city string, start, end time.Time) (*zipcode.HistoryData, error) {
+	return m.GetHistoryByCityFunc(ctx, city, start, end)
 }
 
 type MockLogger struct{}
@@ -53,21 +65,21 @@ func (uc *ZipCodeUseCaseForTesting) ProcessZipCode(ctx context.Context, request
 		return nil, err
 	}
 
-	weather, err := uc.repository.GetWeatherByCity(ctx, location.City)
+	weather, err := uc.repository.GetWeatherByCity(ctx, location.City, request.Options())
 	if err != nil {
 		uc.logger.Error("Error getting weather: %v", err)
 		return nil, err
 	}
 
 	tempC := weather.Current.TempC
-	tempF := celsiusToFahrenheit(tempC)
-	tempK := celsiusToKelvin(tempC)
+	tempF := temperature.CelsiusToFahrenheit(tempC)
+	tempK := temperature.CelsiusToKelvin(tempC)
 
 	response := &zipcode.WeatherResponse{
 		City:  location.City,
-		TempC: tempC,
-		TempF: tempF,
-		TempK: tempK,
+		TempC: &tempC,
+		TempF: &tempF,
+		TempK: &tempK,
 	}
 
 	return response, nil
@@ -100,17 +112,15 @@ func TestZipCodeUseCase_ProcessZipCode(t *testing.T) {
 			},
 			locationErr: nil,
 			mockWeather: &zipcode.WeatherData{
-				Current: struct {
-					TempC float64 `json:"temp_c"`
-				}{
+				Current: zipcode.CurrentWeather{
 					TempC: tempC,
 				},
 			},
 			weatherErr:    nil,
 			expectedCity:  validCity,
 			expectedTempC: tempC,
-			expectedTempF: celsiusToFahrenheit(tempC),
-			expectedTempK: celsiusToKelvin(tempC),
+			expectedTempF: temperature.CelsiusToFahrenheit(tempC),
+			expectedTempK: temperature.CelsiusToKelvin(tempC),
 			expectedErr:   nil,
 		},
 		{
@@ -151,7 +161,7 @@ func TestZipCodeUseCase_ProcessZipCode(t *testing.T) {
 				GetLocationByZipCodeFunc: func(ctx context.Context, zipCode string) (*zipcode.Location, error) {
 					return tt.mockLocation, tt.locationErr
 				},
-				GetWeatherByCityFunc: func(ctx context.Context, city string) (*zipcode.WeatherData, error) {
+				GetWeatherByCityFunc: func(ctx context.Context, city string, options zipcode.WeatherOptions) (*zipcode.WeatherData, error) {
 					return tt.mockWeather, tt.weatherErr
 				},
 			}
@@ -180,17 +190,169 @@ func TestZipCodeUseCase_ProcessZipCode(t *testing.T) {
 				t.Errorf("Expected city %s, got %s", tt.expectedCity, response.City)
 			}
 
-			if response.TempC != tt.expectedTempC {
-				t.Errorf("Expected temperature in Celsius %f, got %f", tt.expectedTempC, response.TempC)
+			if *response.TempC != tt.expectedTempC {
+				t.Errorf("Expected temperature in Celsius %f, got %f", tt.expectedTempC, *response.TempC)
 			}
 
-			if response.TempF != tt.expectedTempF {
-				t.Errorf("Expected temperature in Fahrenheit %f, got %f", tt.expectedTempF, response.TempF)
+			if *response.TempF != tt.expectedTempF {
+				t.Errorf("Expected temperature in Fahrenheit %f, got %f", tt.expectedTempF, *response.TempF)
 			}
 
-			if response.TempK != tt.expectedTempK {
-				t.Errorf("Expected temperature in Kelvin %f, got %f", tt.expectedTempK, response.TempK)
+			if *response.TempK != tt.expectedTempK {
+				t.Errorf("Expected temperature in Kelvin %f, got %f", tt.expectedTempK, *response.TempK)
 			}
 		})
 	}
 }
+
+func TestZipCodeUseCase_ProcessZipCode_Conditions(t *testing.T) {
+	feelsLikeC := 30.0
+	humidity := 65
+	windKph := 11.2
+	isDay := true
+
+	mockRepo := &MockZipCodeRepository{
+		GetLocationByZipCodeFunc: func(ctx context.Context, zipCode string) (*zipcode.Location, error) {
+			return &zipcode.Location{City: "Limeira", CEP: zipCode}, nil
+		},
+		GetWeatherByCityFunc: func(ctx context.Context, city string, options zipcode.WeatherOptions) (*zipcode.WeatherData, error) {
+			return &zipcode.WeatherData{
+				Current: zipcode.CurrentWeather{
+					TempC:      28.3,
+					FeelsLikeC: &feelsLikeC,
+					Humidity:   &humidity,
+					WindKph:    &windKph,
+					WindDir:    "SE",
+					IsDay:      &isDay,
+					Condition:  &zipcode.Condition{Text: "Sunny", Icon: "//cdn.weatherapi.com/113.png"},
+				},
+			}, nil
+		},
+	}
+	useCase := NewZipCodeUseCase(mockRepo, &MockLogger{})
+
+	response, err := useCase.ProcessZipCode(context.Background(), &zipcode.ZipCodeRequest{CEP: "13484000"})
+	if err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+
+	if response.FeelsLikeC == nil || *response.FeelsLikeC != feelsLikeC {
+		t.Errorf("Expected feels like %v, got %v", feelsLikeC, response.FeelsLikeC)
+	}
+
+	if response.FeelsLikeF == nil || *response.FeelsLikeF != temperature.CelsiusToFahrenheit(feelsLikeC) {
+		t.Errorf("Expected feels like in Fahrenheit %v, got %v", temperature.CelsiusToFahrenheit(feelsLikeC), response.FeelsLikeF)
+	}
+
+	if response.Humidity == nil || *response.Humidity != humidity {
+		t.Errorf("Expected humidity %d, got %v", humidity, response.Humidity)
+	}
+
+	if response.Condition != "Sunny" {
+		t.Errorf("Expected condition Sunny, got %s", response.Condition)
+	}
+
+	if response.IsDay == nil || !*response.IsDay {
+		t.Errorf("Expected is_day true, got %v", response.IsDay)
+	}
+
+	if response.PressureMb != nil || response.UV != nil {
+		t.Errorf("Expected unreported fields to be nil, got pressure %v and uv %v", response.PressureMb, response.UV)
+	}
+}
+
+func TestZipCodeUseCase_ProcessZipCode_Stale(t *testing.T) {
+	mockRepo := &MockZipCodeRepository{
+		GetLocationByZipCodeFunc: func(ctx context.Context, zipCode string) (*zipcode.Location, error) {
+			return &zipcode.Location{City: "Limeira", CEP: zipCode}, nil
+		},
+		GetWeatherByCityFunc: func(ctx context.Context, city string, options zipcode.WeatherOptions) (*zipcode.WeatherData, error) {
+			return &zipcode.WeatherData{
+				Current: zipcode.CurrentWeather{TempC: 28.3},
+				Stale:   true,
+				Age:     14*time.Minute + 500*time.Millisecond,
+			}, nil
+		},
+	}
+	useCase := NewZipCodeUseCase(mockRepo, &MockLogger{})
+
+	response, err := useCase.ProcessZipCode(context.Background(), &zipcode.ZipCodeRequest{CEP: "13484000"})
+	if err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	if !response.Stale || response.AgeSeconds != 840 {
+		t.Errorf("Expected a stale response 840 seconds old, got stale %v and age %d", response.Stale, response.AgeSeconds)
+	}
+}
+
+type demandFunc func(ctx context.Context, cep, city string, options zipcode.WeatherOptions)
+
+func (f demandFunc) Record(ctx context.Context, cep, city string, options zipcode.WeatherOptions) {
+	f(ctx, cep, city, options)
+}
+
+func TestZipCodeUseCase_ProcessZipCode_RecordsDemand(t *testing.T) {
+	var recorded []string
+	mockRepo := &MockZipCodeRepository{
+		GetLocationByZipCodeFunc: func(ctx context.Context, zipCode string) (*zipcode.Location, error) {
+			if zipCode == "99999999" {
+				return nil, apperror.ErrZipCodeNotFound
+			}
+			return &zipcode.Location{City: "Limeira", CEP: zipCode}, nil
+		},
+		GetWeatherByCityFunc: func(ctx context.Context, city string, options zipcode.WeatherOptions) (*zipcode.WeatherData, error) {
+			return &zipcode.WeatherData{Current: zipcode.CurrentWeather{TempC: 28.3}}, nil
+		},
+	}
+	useCase := NewZipCodeUseCase(mockRepo, &MockLogger{})
+	useCase.SetDemandRecorder(demandFunc(func(ctx context.Context, cep, city string, options zipcode.WeatherOptions) {
+		recorded = append(recorded, cep+" "+city)
+	}))
+
+	for _, cep := range []string{"13484000", "99999999"} {
+		useCase.ProcessZipCode(context.Background(), &zipcode.ZipCodeRequest{CEP: cep})
+	}
+	if len(recorded) != 1 || recorded[0] != "13484000 Limeira" {
+		t.Errorf("Expected only the served lookup to be recorded, got %v", recorded)
+	}
+}
+
+func TestZipCodeUseCase_ProcessZipCode_AlertsAndAirQuality(t *testing.T) {
+	epaIndex := 2
+	var requestedOptions zipcode.WeatherOptions
+
+	mockRepo := &MockZipCodeRepository{
+		GetLocationByZipCodeFunc: func(ctx context.Context, zipCode string) (*zipcode.Location, error) {
+			return &zipcode.Location{City: "Limeira", CEP: zipCode}, nil
+		},
+		GetWeatherByCityFunc: func(ctx context.Context, city string, options zipcode.WeatherOptions) (*zipcode.WeatherData, error) {
+			requestedOptions = options
+			return &zipcode.WeatherData{
+				Current: zipcode.CurrentWeather{TempC: 28.3},
+				Alerts: []zipcode.Alert{
+					{Headline: "Heavy rain warning", Severity: zipcode.SeveritySevere},
+				},
+				AirQuality: &zipcode.AirQuality{USEPAIndex: &epaIndex, Category: zipcode.EPACategory(epaIndex)},
+			}, nil
+		},
+	}
+	useCase := NewZipCodeUseCase(mockRepo, &MockLogger{})
+
+	request := &zipcode.ZipCodeRequest{CEP: "13484000", Alerts: true, AQI: true}
+	response, err := useCase.ProcessZipCode(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+
+	if !requestedOptions.Alerts || !requestedOptions.AirQuality {
+		t.Errorf("Expected alerts and air quality to be requested, got %+v", requestedOptions)
+	}
+
+	if len(response.Alerts) != 1 || response.Alerts[0].Severity != zipcode.SeveritySevere {
+		t.Errorf("Expected one severe alert, got %+v", response.Alerts)
+	}
+
+	if response.AirQuality == nil || response.AirQuality.Category != "moderate" {
+		t.Errorf("Expected moderate air quality, got %+v", response.AirQuality)
+	}
+}